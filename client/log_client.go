@@ -37,6 +37,7 @@ type LogClient struct {
 	MinMergeDelay time.Duration
 	client        trillian.TrillianLogClient
 	root          types.LogRootV1
+	store         TrustedRootStore
 	rootLock      sync.Mutex
 	updateLock    sync.Mutex
 }
@@ -61,6 +62,28 @@ func NewFromTree(client trillian.TrillianLogClient, config *trillian.Tree, root
 	return New(config.GetTreeId(), client, verifier, root), nil
 }
 
+// UseTrustedRootStore makes store the persistent home of the trusted root.
+// Any root already held by store replaces the currently trusted root, and
+// every root accepted by UpdateRoot from then on is written to store before
+// it is trusted.
+func (c *LogClient) UseTrustedRootStore(ctx context.Context, store TrustedRootStore) error {
+	c.updateLock.Lock()
+	defer c.updateLock.Unlock()
+
+	root, err := store.LoadRoot(ctx)
+	if err != nil {
+		return fmt.Errorf("LoadRoot(): %v", err)
+	}
+
+	c.rootLock.Lock()
+	defer c.rootLock.Unlock()
+	if root != nil {
+		c.root = *root
+	}
+	c.store = store
+	return nil
+}
+
 // AddSequencedLeafAndWait adds a leaf at a specific index to the log.
 // Blocks and continuously updates the trusted root until it has been included in a signed log root.
 func (c *LogClient) AddSequencedLeafAndWait(ctx context.Context, data []byte, index int64) error {
@@ -189,7 +212,8 @@ func (c *LogClient) getAndVerifyLatestRoot(ctx context.Context, trusted *types.L
 	}
 
 	// Verify root update if the tree / the latest signed log root isn't empty.
-	if logRoot.TreeSize > 0 {
+	// A root smaller than the trusted one fails verification as a fork.
+	if logRoot.TreeSize > 0 || trusted.TreeSize > 0 {
 		if _, err := c.VerifyRoot(trusted, resp.GetSignedLogRoot(), resp.GetProof().GetHashes()); err != nil {
 			return nil, err
 		}
@@ -209,7 +233,8 @@ func (c *LogClient) GetRoot() *types.LogRootV1 {
 
 // UpdateRoot retrieves the current SignedLogRoot, verifying it against roots this client has
 // seen in the past, and updating the currently trusted root if the new root verifies, and is
-// newer than the currently trusted root.
+// newer than the currently trusted root. If the new root is smaller than, or inconsistent
+// with, the trusted root then a *ForkError is returned.
 func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 	// Only one root update should be running at any point in time, because
 	// the update involves a consistency proof from the old value, and if the
@@ -240,6 +265,14 @@ func (c *LogClient) UpdateRoot(ctx context.Context) (*types.LogRootV1, error) {
 	if newTrusted.TimestampNanos > currentlyTrusted.TimestampNanos &&
 		newTrusted.TreeSize >= currentlyTrusted.TreeSize {
 
+		// Persist the new root before trusting it, so that a restarted client
+		// never trusts a root older than one it has already acted upon.
+		if c.store != nil {
+			if err := c.store.StoreRoot(ctx, newTrusted); err != nil {
+				return nil, fmt.Errorf("StoreRoot(): %v", err)
+			}
+		}
+
 		// Take a copy of the new trusted root in order to prevent clients from modifying it.
 		c.root = *newTrusted
 
@@ -0,0 +1,140 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/trillian/types"
+)
+
+// TrustedRootStore persists the root a LogClient trusts, so that the trust
+// survives process restarts.
+type TrustedRootStore interface {
+	// LoadRoot returns the most recently stored root, or nil if no root has
+	// been stored yet.
+	LoadRoot(ctx context.Context) (*types.LogRootV1, error)
+	// StoreRoot replaces the stored root. It is only called with roots which
+	// have been verified to be consistent with the previously stored one.
+	StoreRoot(ctx context.Context, root *types.LogRootV1) error
+}
+
+// FileRootStore is a TrustedRootStore which keeps the serialized root in a
+// single file. Updates are written to a temporary file in the same directory
+// and renamed into place, so a crash never leaves a partially written root.
+// The directory is synced after the rename, so that a crash can't undo it and
+// bring back an older root.
+type FileRootStore struct {
+	path string
+}
+
+// NewFileRootStore returns a FileRootStore backed by the file at path. The
+// file does not need to exist until the first root is stored.
+func NewFileRootStore(path string) *FileRootStore {
+	return &FileRootStore{path: path}
+}
+
+// LoadRoot implements TrustedRootStore.
+func (s *FileRootStore) LoadRoot(ctx context.Context) (*types.LogRootV1, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("failed to parse trusted root in %s: %v", s.path, err)
+	}
+	return &root, nil
+}
+
+// StoreRoot implements TrustedRootStore.
+func (s *FileRootStore) StoreRoot(ctx context.Context, root *types.LogRootV1) error {
+	data, err := root.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := writeAndSync(f, data); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(s.path))
+}
+
+// syncDir flushes the entries of the given directory to stable storage.
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// writeAndSync writes data to f, flushes it to stable storage and closes f.
+func writeAndSync(f *os.File, data []byte) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ForkError is returned when the log presents a root which cannot be
+// reconciled with the trusted root: either it is smaller, or no valid
+// consistency proof links the two. Both roots are kept as evidence.
+type ForkError struct {
+	// Trusted is the root the client trusted before the update.
+	Trusted types.LogRootV1
+	// Root is the root presented by the log.
+	Root types.LogRootV1
+	// Err is the underlying verification error, if any.
+	Err error
+}
+
+func (e *ForkError) Error() string {
+	msg := fmt.Sprintf("log fork detected: trusted root %d:%x, log root %d:%x",
+		e.Trusted.TreeSize, e.Trusted.RootHash, e.Root.TreeSize, e.Root.RootHash)
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying verification error.
+func (e *ForkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,287 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/extension"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/testonly/integration"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+
+	tcrypto "github.com/google/trillian/crypto"
+	stestonly "github.com/google/trillian/storage/testonly"
+)
+
+// fixedRootClient always serves the same signed log root.
+type fixedRootClient struct {
+	trillian.TrillianLogClient
+	root *trillian.SignedLogRoot
+}
+
+func (c *fixedRootClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: c.root}, nil
+}
+
+func TestFileRootStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rootstore")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := NewFileRootStore(filepath.Join(dir, "root"))
+
+	root, err := store.LoadRoot(ctx)
+	if err != nil || root != nil {
+		t.Fatalf("LoadRoot() on missing file: %v, %v, want nil, nil", root, err)
+	}
+
+	for _, want := range []*types.LogRootV1{
+		{TreeSize: 1, RootHash: []byte("first"), TimestampNanos: 1},
+		{TreeSize: 10, RootHash: []byte("second"), TimestampNanos: 2, Revision: 3},
+	} {
+		if err := store.StoreRoot(ctx, want); err != nil {
+			t.Fatalf("StoreRoot(): %v", err)
+		}
+		got, err := store.LoadRoot(ctx)
+		if err != nil {
+			t.Fatalf("LoadRoot(): %v", err)
+		}
+		if !bytes.Equal(mustMarshalRoot(t, got), mustMarshalRoot(t, want)) {
+			t.Errorf("LoadRoot(): %+v, want %+v", got, want)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir(): %v", err)
+	}
+	if len(files) != 1 || files[0].Name() != "root" {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("ReadDir(): %v, want [root]", names)
+	}
+}
+
+func TestUpdateRootForks(t *testing.T) {
+	ctx := context.Background()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key, err=%v", err)
+	}
+	signer := tcrypto.NewSigner(0, key, crypto.SHA256)
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key, err=%v", err)
+	}
+	verifier := NewLogVerifier(rfc6962.DefaultHasher, pk, crypto.SHA256)
+
+	trusted := &types.LogRootV1{TreeSize: 5, RootHash: []byte("trusted"), TimestampNanos: 5}
+	for _, test := range []struct {
+		desc     string
+		root     *types.LogRootV1
+		wantFork bool
+	}{
+		{desc: "unchanged", root: trusted},
+		{desc: "smaller", root: &types.LogRootV1{TreeSize: 3, RootHash: []byte("smaller"), TimestampNanos: 6}, wantFork: true},
+		{desc: "empty", root: &types.LogRootV1{TimestampNanos: 6}, wantFork: true},
+		{desc: "same size", root: &types.LogRootV1{TreeSize: 5, RootHash: []byte("other"), TimestampNanos: 6}, wantFork: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rootstore")
+			if err != nil {
+				t.Fatalf("TempDir(): %v", err)
+			}
+			defer os.RemoveAll(dir)
+			store := NewFileRootStore(filepath.Join(dir, "root"))
+			if err := store.StoreRoot(ctx, trusted); err != nil {
+				t.Fatalf("StoreRoot(): %v", err)
+			}
+
+			slr, err := signer.SignLogRoot(test.root)
+			if err != nil {
+				t.Fatalf("SignLogRoot(): %v", err)
+			}
+			client := New(0, &fixedRootClient{root: slr}, verifier, types.LogRootV1{})
+			if err := client.UseTrustedRootStore(ctx, store); err != nil {
+				t.Fatalf("UseTrustedRootStore(): %v", err)
+			}
+			if got, want := client.GetRoot().TreeSize, trusted.TreeSize; got != want {
+				t.Fatalf("GetRoot().TreeSize after UseTrustedRootStore(): %v, want %v", got, want)
+			}
+
+			_, err = client.UpdateRoot(ctx)
+			var forkErr *ForkError
+			if got := errors.As(err, &forkErr); got != test.wantFork {
+				t.Fatalf("UpdateRoot(): %v, want ForkError: %v", err, test.wantFork)
+			}
+			if !test.wantFork {
+				if err != nil {
+					t.Fatalf("UpdateRoot(): %v", err)
+				}
+				return
+			}
+			if got, want := forkErr.Trusted.TreeSize, trusted.TreeSize; got != want {
+				t.Errorf("ForkError.Trusted.TreeSize: %v, want %v", got, want)
+			}
+			if got, want := forkErr.Root.TreeSize, test.root.TreeSize; got != want {
+				t.Errorf("ForkError.Root.TreeSize: %v, want %v", got, want)
+			}
+			if got, err := store.LoadRoot(ctx); err != nil || got.TreeSize != trusted.TreeSize {
+				t.Errorf("LoadRoot() after fork: %+v, %v, want TreeSize %v", got, err, trusted.TreeSize)
+			}
+		})
+	}
+}
+
+func TestUpdateRootPersists(t *testing.T) {
+	ctx := context.Background()
+	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("Failed to open test key, err=%v", err)
+	}
+	signer := tcrypto.NewSigner(0, key, crypto.SHA256)
+	pk, err := pem.UnmarshalPublicKey(testonly.DemoPublicKey)
+	if err != nil {
+		t.Fatalf("Failed to load public key, err=%v", err)
+	}
+	verifier := NewLogVerifier(rfc6962.DefaultHasher, pk, crypto.SHA256)
+
+	dir, err := ioutil.TempDir("", "rootstore")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "root")
+
+	root := &types.LogRootV1{TreeSize: 2, RootHash: []byte("root"), TimestampNanos: 1}
+	slr, err := signer.SignLogRoot(root)
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	client := New(0, &fixedRootClient{root: slr}, verifier, types.LogRootV1{})
+	if err := client.UseTrustedRootStore(ctx, NewFileRootStore(path)); err != nil {
+		t.Fatalf("UseTrustedRootStore(): %v", err)
+	}
+	if _, err := client.UpdateRoot(ctx); err != nil {
+		t.Fatalf("UpdateRoot(): %v", err)
+	}
+
+	// A new client using the same file starts from the persisted root.
+	restarted := New(0, &fixedRootClient{root: slr}, verifier, types.LogRootV1{})
+	if err := restarted.UseTrustedRootStore(ctx, NewFileRootStore(path)); err != nil {
+		t.Fatalf("UseTrustedRootStore(): %v", err)
+	}
+	if got, want := restarted.GetRoot().TreeSize, root.TreeSize; got != want {
+		t.Errorf("GetRoot().TreeSize: %v, want %v", got, want)
+	}
+}
+
+func TestUpdateRootRollbackAgainstServer(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	env, err := integration.NewLogEnvWithRegistry(ctx, 0, extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+		QuotaManager: quota.Noop(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	tree, err := CreateAndInitTree(ctx, &trillian.CreateTreeRequest{Tree: stestonly.LogTree}, env.Admin, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	client, err := NewFromTree(env.Log, tree, types.LogRootV1{})
+	if err != nil {
+		t.Fatalf("NewFromTree(): %v", err)
+	}
+	for _, data := range []string{"A", "B"} {
+		if err := client.QueueLeaf(ctx, []byte(data)); err != nil {
+			t.Fatalf("QueueLeaf(): %v", err)
+		}
+	}
+	env.Sequencer.OperationSingle(ctx)
+	root, err := client.UpdateRoot(ctx)
+	if err != nil || root == nil || root.TreeSize != 2 {
+		t.Fatalf("UpdateRoot()=%+v,%v, want a root of size 2", root, err)
+	}
+
+	for _, test := range []struct {
+		desc    string
+		trusted *types.LogRootV1
+	}{
+		{desc: "rolled-back", trusted: &types.LogRootV1{TreeSize: 5, RootHash: make([]byte, 32), TimestampNanos: root.TimestampNanos}},
+		{desc: "same-size", trusted: &types.LogRootV1{TreeSize: 2, RootHash: make([]byte, 32), TimestampNanos: root.TimestampNanos}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rootstore")
+			if err != nil {
+				t.Fatalf("TempDir(): %v", err)
+			}
+			defer os.RemoveAll(dir)
+			store := NewFileRootStore(filepath.Join(dir, "root"))
+			if err := store.StoreRoot(ctx, test.trusted); err != nil {
+				t.Fatalf("StoreRoot(): %v", err)
+			}
+			restarted, err := NewFromTree(env.Log, tree, types.LogRootV1{})
+			if err != nil {
+				t.Fatalf("NewFromTree(): %v", err)
+			}
+			if err := restarted.UseTrustedRootStore(ctx, store); err != nil {
+				t.Fatalf("UseTrustedRootStore(): %v", err)
+			}
+
+			_, err = restarted.UpdateRoot(ctx)
+			var forkErr *ForkError
+			if !errors.As(err, &forkErr) {
+				t.Fatalf("UpdateRoot(): %v, want ForkError", err)
+			}
+			if got, want := forkErr.Trusted.TreeSize, test.trusted.TreeSize; got != want {
+				t.Errorf("ForkError.Trusted.TreeSize: %v, want %v", got, want)
+			}
+			if got, want := forkErr.Root.TreeSize, root.TreeSize; got != want {
+				t.Errorf("ForkError.Root.TreeSize: %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func mustMarshalRoot(t *testing.T, r *types.LogRootV1) []byte {
+	t.Helper()
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The compactsubtrees binary deletes the stored subtree revisions of a log
+// tree which are superseded by later revisions, and so are not needed to read
+// any signed root stored within the retention period. It works in small
+// transactions, so it can be run while the log is serving traffic.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/sqlite"
+)
+
+var (
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	treeID        = flag.Int64("tree_id", 0, "The ID of the log tree whose subtrees are compacted")
+	retention     = flag.Duration("retention", 24*time.Hour, "How long signed log roots remain readable after a later root is stored; must exceed the longest read transaction")
+	batchSize     = flag.Int("batch_size", 100, "Max number of subtrees to compact per transaction")
+	batchPause    = flag.Duration("batch_pause", 100*time.Millisecond, "Time to wait between transactions, to limit the load on the storage")
+
+	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *treeID == 0 {
+		glog.Exit("--tree_id must be set")
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	registry := extension.Registry{
+		AdminStorage: sp.AdminStorage(),
+		LogStorage:   sp.LogStorage(),
+	}
+	compactor := log.NewCompactor(registry, log.CompactionOptions{
+		Retention:  *retention,
+		BatchSize:  *batchSize,
+		BatchPause: *batchPause,
+	})
+	n, err := compactor.CompactLog(context.Background(), *treeID)
+	if err != nil {
+		glog.Exitf("Failed to compact subtrees of tree %d after deleting %d revisions: %v", *treeID, n, err)
+	}
+	glog.Infof("Deleted %d superseded subtree revisions of tree %d", n, *treeID)
+}
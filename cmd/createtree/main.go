@@ -58,6 +58,9 @@ var (
 	signatureAlgorithm = flag.String("signature_algorithm", sigpb.DigitallySigned_ECDSA.String(), "Signature algorithm of the new tree")
 	displayName        = flag.String("display_name", "", "Display name of the new tree")
 	description        = flag.String("description", "", "Description of the new tree")
+	dequeueOrder       = flag.String("dequeue_order", trillian.DequeueOrder_QUEUE_TIMESTAMP_ORDER.String(), "Order in which queued leaves of the new tree are sequenced")
+	leafCompression    = flag.String("leaf_compression", trillian.LeafCompression_NO_LEAF_COMPRESSION.String(), "Compression applied to the stored data of leaves of the new tree")
+	dictionaryID       = flag.Uint("leaf_compression_dictionary_id", 0, "ID of the zstd dictionary used to compress leaf data of the new tree; zero means no dictionary")
 	maxRootDuration    = flag.Duration("max_root_duration", time.Hour, "Interval after which a new signed root is produced despite no submissions; zero means never")
 	privateKeyFormat   = flag.String("private_key_format", "", "Type of protobuf message to send the key as (PrivateKey, PEMKeyFile, or PKCS11ConfigFile). If empty, a key will be generated for you by Trillian.")
 
@@ -120,15 +123,28 @@ func newRequest() (*trillian.CreateTreeRequest, error) {
 		return nil, fmt.Errorf("unknown SignatureAlgorithm: %v", *signatureAlgorithm)
 	}
 
+	do, ok := trillian.DequeueOrder_value[*dequeueOrder]
+	if !ok {
+		return nil, fmt.Errorf("unknown DequeueOrder: %v", *dequeueOrder)
+	}
+
+	lc, ok := trillian.LeafCompression_value[*leafCompression]
+	if !ok {
+		return nil, fmt.Errorf("unknown LeafCompression: %v", *leafCompression)
+	}
+
 	ctr := &trillian.CreateTreeRequest{Tree: &trillian.Tree{
-		TreeState:          trillian.TreeState(ts),
-		TreeType:           trillian.TreeType(tt),
-		HashStrategy:       trillian.HashStrategy(hs),
-		HashAlgorithm:      sigpb.DigitallySigned_HashAlgorithm(ha),
-		SignatureAlgorithm: sigpb.DigitallySigned_SignatureAlgorithm(sa),
-		DisplayName:        *displayName,
-		Description:        *description,
-		MaxRootDuration:    ptypes.DurationProto(*maxRootDuration),
+		TreeState:                   trillian.TreeState(ts),
+		TreeType:                    trillian.TreeType(tt),
+		HashStrategy:                trillian.HashStrategy(hs),
+		HashAlgorithm:               sigpb.DigitallySigned_HashAlgorithm(ha),
+		SignatureAlgorithm:          sigpb.DigitallySigned_SignatureAlgorithm(sa),
+		DisplayName:                 *displayName,
+		Description:                 *description,
+		MaxRootDuration:             ptypes.DurationProto(*maxRootDuration),
+		DequeueOrder:                trillian.DequeueOrder(do),
+		LeafCompression:             trillian.LeafCompression(lc),
+		LeafCompressionDictionaryId: uint32(*dictionaryID),
 	}}
 	glog.Infof("Creating tree %+v", ctr.Tree)
 
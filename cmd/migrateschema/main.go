@@ -0,0 +1,101 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The migrateschema binary upgrades the schema of a MySQL or PostgreSQL
+// Trillian database to the version expected by this release. The SQL which is
+// executed, or with --dry_run would be executed, is written to stdout.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/storage/migrate"
+	"github.com/google/trillian/storage/mysql"
+	"github.com/google/trillian/storage/postgres"
+)
+
+var (
+	storageSystem = flag.String("storage_system", "mysql", "Storage system whose schema to migrate. One of: mysql, postgres")
+	dryRun        = flag.Bool("dry_run", false, "If true, only print the SQL which would be executed")
+	assumeVersion = flag.Int("assume_version", 0, "If set, record that a database without a schema version table is at this version before migrating it, e.g. for databases created from a development version of storage.sql")
+
+	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func newMigrator() (*migrate.Migrator, *sql.DB, error) {
+	switch *storageSystem {
+	case "mysql":
+		db, err := mysql.GetDatabase()
+		if err != nil {
+			return nil, nil, err
+		}
+		return mysql.NewMigrator(db), db, nil
+	case "postgres":
+		db, err := postgres.GetDatabase()
+		if err != nil {
+			return nil, nil, err
+		}
+		return postgres.NewMigrator(db), db, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported storage system %q", *storageSystem)
+}
+
+func run(ctx context.Context) error {
+	if *dryRun && *assumeVersion != 0 {
+		return errors.New("--assume_version changes the database, so can't be used with --dry_run")
+	}
+	m, db, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *assumeVersion != 0 {
+		if err := m.Baseline(ctx, *assumeVersion); err != nil {
+			return fmt.Errorf("failed to record schema version: %v", err)
+		}
+	}
+
+	version, _, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("-- Schema version %d, latest version %d\n", version, m.Latest())
+	if version == m.Latest() {
+		return nil
+	}
+	return m.Migrate(ctx, os.Stdout, *dryRun)
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+
+	if err := run(context.Background()); err != nil {
+		glog.Exitf("Failed to migrate schema: %v", err)
+	}
+}
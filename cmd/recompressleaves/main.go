@@ -0,0 +1,112 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The recompressleaves binary rewrites the stored leaf data of a log tree so
+// that it matches the tree's current leaf_compression settings. It works in
+// small transactions, so it can be run while the log is serving traffic.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/sqlite"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	treeID        = flag.Int64("tree_id", 0, "The ID of the tree whose leaf data is recompressed")
+	batchSize     = flag.Int("batch_size", 1000, "Max number of leaves to recompress per transaction")
+	batchPause    = flag.Duration("batch_pause", 100*time.Millisecond, "Time to wait between transactions, to limit the load on the storage")
+
+	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func recompress(ctx context.Context, ls storage.LogStorage, as storage.AdminStorage) (int, error) {
+	tree, err := storage.GetTree(ctx, as, *treeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tree %d: %v", *treeID, err)
+	}
+
+	var after []byte
+	total := 0
+	for {
+		var last []byte
+		var n int
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			rtx, ok := tx.(storage.RecompressLogTreeTX)
+			if !ok {
+				return status.Errorf(codes.Unimplemented, "storage system %q does not support recompression", *storageSystem)
+			}
+			var err error
+			last, n, err = rtx.RecompressLeaves(ctx, after, *batchSize)
+			return err
+		}); err != nil {
+			return total, err
+		}
+		total += n
+		if last == nil {
+			return total, nil
+		}
+		glog.V(1).Infof("Recompressed %d leaves so far, up to identity hash %x", total, last)
+		after = last
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(*batchPause):
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *treeID == 0 {
+		glog.Exit("--tree_id must be set")
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	n, err := recompress(context.Background(), sp.LogStorage(), sp.AdminStorage())
+	if err != nil {
+		glog.Exitf("Failed to recompress leaves of tree %d after rewriting %d: %v", *treeID, n, err)
+	}
+	glog.Infof("Recompressed %d leaves of tree %d", n, *treeID)
+}
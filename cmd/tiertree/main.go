@@ -0,0 +1,86 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The tiertree binary moves the data of the leaves of a log tree below a given
+// tree size, and of the complete tiles covering them, out of the database and
+// into the cold storage set by --cold_storage_dir. The log servers read the
+// moved data from there, so they must be given the same cold storage. It works
+// in small transactions, so it can be run while the log is serving traffic.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/sqlite"
+)
+
+var (
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	treeID        = flag.Int64("tree_id", 0, "The ID of the log tree whose data is tiered")
+	belowTreeSize = flag.Int64("below_tree_size", 0, "Leaves with indices below this tree size, and the tiles only covering them, are moved to cold storage; capped at the size of the latest signed root")
+	batchSize     = flag.Int("batch_size", 100, "Max number of leaves or subtrees to visit per transaction")
+	batchPause    = flag.Duration("batch_pause", 100*time.Millisecond, "Time to wait between transactions, to limit the load on the storage")
+
+	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *treeID == 0 {
+		glog.Exit("--tree_id must be set")
+	}
+	if *belowTreeSize <= 0 {
+		glog.Exit("--below_tree_size must be positive")
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	registry := extension.Registry{
+		AdminStorage: sp.AdminStorage(),
+		LogStorage:   sp.LogStorage(),
+	}
+	tierer := log.NewTierer(registry, log.TieringOptions{
+		BatchSize:  *batchSize,
+		BatchPause: *batchPause,
+	})
+	leaves, tiles, err := tierer.TierLog(context.Background(), *treeID, *belowTreeSize)
+	if err != nil {
+		glog.Exitf("Failed to tier tree %d after moving %d leaves and %d tiles: %v", *treeID, leaves, tiles, err)
+	}
+	glog.Infof("Moved %d leaves and %d tiles of tree %d to cold storage", leaves, tiles, *treeID)
+}
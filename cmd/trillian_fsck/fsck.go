@@ -0,0 +1,330 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+)
+
+// Kinds of problems found by the checker.
+const (
+	// The stored leaves are not densely indexed from zero.
+	kindLeafMissing = "leaf_missing"
+	// A leaf's Merkle hash has the wrong size, or is not the hash of its value.
+	kindLeafHash = "leaf_hash"
+	// A stored subtree node is missing, or inconsistent with the leaves.
+	kindNode = "node"
+	// A signed root does not verify with the tree's public key.
+	kindRootSignature = "root_signature"
+	// A signed root has a smaller size or timestamp than its predecessor.
+	kindRootOrder = "root_order"
+	// A signed root's hash does not match the one recomputed from the leaves.
+	kindRootHash = "root_hash"
+)
+
+// Problem is an inconsistency found in the stored data of a tree.
+type Problem struct {
+	Kind string `json:"kind"`
+	// Index is the leaf index for leaf and node problems, and the revision of
+	// the signed root for root problems.
+	Index   int64  `json:"index"`
+	Message string `json:"message"`
+}
+
+// Report is the result of checking a tree.
+type Report struct {
+	TreeID int64 `json:"tree_id"`
+	// TreeSize is the size of the latest signed root.
+	TreeSize      uint64    `json:"tree_size"`
+	LeavesChecked uint64    `json:"leaves_checked"`
+	RootsChecked  int       `json:"roots_checked"`
+	Problems      []Problem `json:"problems"`
+	OK            bool      `json:"ok"`
+}
+
+func (r *Report) addf(kind string, index int64, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Kind: kind, Index: index, Message: fmt.Sprintf(format, args...)})
+}
+
+// checker checks the stored data of a single log tree. It only reads from
+// the storage, in snapshot transactions.
+type checker struct {
+	ls        storage.LogStorage
+	tree      *trillian.Tree
+	hasher    hashers.LogHasher
+	pubKey    gocrypto.PublicKey
+	sigHash   gocrypto.Hash
+	batchSize int
+	report    *Report
+}
+
+// checkTree checks the leaves, subtree nodes and signed roots of the given
+// tree, and returns a report of the problems found. An error is returned if
+// the check could not be completed.
+func checkTree(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree, batchSize int) (*Report, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", batchSize)
+	}
+	if tt := tree.TreeType; tt != trillian.TreeType_LOG && tt != trillian.TreeType_PREORDERED_LOG {
+		return nil, fmt.Errorf("tree %d is a %v, not a log", tree.TreeId, tt)
+	}
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hasher: %v", err)
+	}
+	pubKey, err := der.UnmarshalPublicKey(tree.PublicKey.GetDer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	sigHash, err := trees.Hash(tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature hash: %v", err)
+	}
+	c := &checker{
+		ls:        ls,
+		tree:      tree,
+		hasher:    hasher,
+		pubKey:    pubKey,
+		sigHash:   sigHash,
+		batchSize: batchSize,
+		report:    &Report{TreeID: tree.TreeId},
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	roots, err := c.readRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkLeaves(ctx, roots); err != nil {
+		return nil, err
+	}
+	c.report.OK = len(c.report.Problems) == 0
+	return c.report, nil
+}
+
+// readRoots reads all the stored signed roots of the tree, if the storage
+// supports it, or otherwise only the latest one. It checks their signatures
+// and order, and returns them in order of revision.
+func (c *checker) readRoots(ctx context.Context) ([]*types.LogRootV1, error) {
+	var slrs []*trillian.SignedLogRoot
+	if err := c.snapshot(ctx, func(tx storage.ReadOnlyLogTreeTX) error {
+		htx, ok := tx.(storage.LogRootHistoryTX)
+		if !ok {
+			slr, err := tx.LatestSignedLogRoot(ctx)
+			if err != nil {
+				return err
+			}
+			slrs = append(slrs, slr)
+			return nil
+		}
+		for minRevision := int64(0); ; {
+			batch, err := htx.GetSignedLogRoots(ctx, storage.LogRootFilter{RevisionBegin: minRevision}, c.batchSize)
+			if err != nil {
+				return err
+			}
+			slrs = append(slrs, batch...)
+			if len(batch) < c.batchSize {
+				return nil
+			}
+			var last types.LogRootV1
+			if err := last.UnmarshalBinary(batch[len(batch)-1].LogRoot); err != nil {
+				return err
+			}
+			minRevision = int64(last.Revision) + 1
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read signed roots: %v", err)
+	}
+
+	roots := make([]*types.LogRootV1, 0, len(slrs))
+	for _, slr := range slrs {
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return nil, fmt.Errorf("failed to parse signed root: %v", err)
+		}
+		rev := int64(root.Revision)
+		if _, err := crypto.VerifySignedLogRoot(c.pubKey, c.sigHash, slr); err != nil {
+			c.report.addf(kindRootSignature, rev, "signature does not verify: %v", err)
+		}
+		if n := len(roots); n > 0 {
+			prev := roots[n-1]
+			if root.TreeSize < prev.TreeSize {
+				c.report.addf(kindRootOrder, rev, "tree size %d is smaller than %d at revision %d", root.TreeSize, prev.TreeSize, prev.Revision)
+			}
+			if root.TimestampNanos < prev.TimestampNanos {
+				c.report.addf(kindRootOrder, rev, "timestamp %d is before %d at revision %d", root.TimestampNanos, prev.TimestampNanos, prev.Revision)
+			}
+		}
+		roots = append(roots, &root)
+	}
+	c.report.RootsChecked = len(roots)
+	if n := len(roots); n > 0 {
+		c.report.TreeSize = roots[n-1].TreeSize
+	}
+	return roots, nil
+}
+
+// checkLeaves reads the leaves up to the size of the largest root, and checks
+// them against the subtree nodes and the root hashes at each root's size.
+func (c *checker) checkLeaves(ctx context.Context, roots []*types.LogRootV1) error {
+	bySize := make(map[uint64][]*types.LogRootV1)
+	var size uint64
+	for _, root := range roots {
+		bySize[root.TreeSize] = append(bySize[root.TreeSize], root)
+		if root.TreeSize > size {
+			size = root.TreeSize
+		}
+	}
+
+	fact := compact.RangeFactory{Hash: c.hasher.HashChildren}
+	cr := fact.NewEmptyRange(0)
+	if err := c.checkRootHashes(cr, bySize); err != nil {
+		return err
+	}
+	for cr.End() < size {
+		count := size - cr.End()
+		if count > uint64(c.batchSize) {
+			count = uint64(c.batchSize)
+		}
+		var complete bool
+		if err := c.snapshot(ctx, func(tx storage.ReadOnlyLogTreeTX) error {
+			var err error
+			complete, err = c.checkBatch(ctx, tx, cr, count, bySize)
+			return err
+		}); err != nil {
+			return err
+		}
+		if !complete {
+			// Later nodes and roots can't be recomputed without the missing
+			// leaves.
+			return nil
+		}
+	}
+	return nil
+}
+
+// checkBatch extends the compact range with up to count of the following
+// leaves, and checks them along with the subtree nodes and root hashes they
+// complete. It returns false if a leaf is missing.
+func (c *checker) checkBatch(ctx context.Context, tx storage.ReadOnlyLogTreeTX, cr *compact.Range, count uint64, bySize map[uint64][]*types.LogRootV1) (bool, error) {
+	begin := cr.End()
+	leaves, err := tx.GetLeavesByRange(ctx, int64(begin), int64(count))
+	if err != nil {
+		return false, fmt.Errorf("failed to read leaves at %d: %v", begin, err)
+	}
+
+	var ids []compact.NodeID
+	hashes := make(map[compact.NodeID][]byte)
+	visit := func(id compact.NodeID, hash []byte) {
+		ids = append(ids, id)
+		hashes[id] = hash
+	}
+	complete := true
+	for i, leaf := range leaves {
+		index := begin + uint64(i)
+		if got := leaf.LeafIndex; got != int64(index) {
+			c.report.addf(kindLeafMissing, int64(index), "leaf at index %d is missing, next stored leaf has index %d", index, got)
+			complete = false
+			break
+		}
+		if got, want := len(leaf.MerkleLeafHash), c.hasher.Size(); got != want {
+			c.report.addf(kindLeafHash, int64(index), "leaf hash size is %d, want %d", got, want)
+		} else if hash := c.hasher.HashLeaf(leaf.LeafValue); !bytes.Equal(hash, leaf.MerkleLeafHash) {
+			c.report.addf(kindLeafHash, int64(index), "leaf hash is %x, but leaf value hashes to %x", leaf.MerkleLeafHash, hash)
+		}
+		visit(compact.NewNodeID(0, index), leaf.MerkleLeafHash)
+		if err := cr.Append(leaf.MerkleLeafHash, visit); err != nil {
+			return false, fmt.Errorf("failed to append leaf %d: %v", index, err)
+		}
+		c.report.LeavesChecked++
+		if err := c.checkRootHashes(cr, bySize); err != nil {
+			return false, err
+		}
+	}
+	if len(leaves) == 0 {
+		c.report.addf(kindLeafMissing, int64(begin), "leaf at index %d is missing", begin)
+		complete = false
+	}
+	if len(ids) == 0 {
+		return complete, nil
+	}
+
+	nodes, err := tx.GetMerkleNodes(ctx, ids)
+	if err != nil {
+		return false, fmt.Errorf("failed to read tree nodes: %v", err)
+	}
+	stored := make(map[compact.NodeID][]byte, len(nodes))
+	for _, node := range nodes {
+		stored[node.ID] = node.Hash
+	}
+	for _, id := range ids {
+		// Nodes are reported against the first leaf of their subtree.
+		index := int64(id.Index << id.Level)
+		if hash, ok := stored[id]; !ok {
+			c.report.addf(kindNode, index, "node %+v is missing", id)
+		} else if !bytes.Equal(hash, hashes[id]) {
+			c.report.addf(kindNode, index, "node %+v is %x, but leaves hash to %x", id, hash, hashes[id])
+		}
+	}
+	return complete, nil
+}
+
+// checkRootHashes compares the root hash of the compact range with the
+// signed roots of the same size.
+func (c *checker) checkRootHashes(cr *compact.Range, bySize map[uint64][]*types.LogRootV1) error {
+	roots := bySize[cr.End()]
+	if len(roots) == 0 {
+		return nil
+	}
+	hash := c.hasher.EmptyRoot()
+	if cr.End() > 0 {
+		var err error
+		if hash, err = cr.GetRootHash(nil); err != nil {
+			return fmt.Errorf("failed to compute root hash at size %d: %v", cr.End(), err)
+		}
+	}
+	for _, root := range roots {
+		if !bytes.Equal(hash, root.RootHash) {
+			c.report.addf(kindRootHash, int64(root.Revision), "root hash at size %d is %x, but leaves hash to %x", root.TreeSize, root.RootHash, hash)
+		}
+	}
+	return nil
+}
+
+// snapshot runs f in a read-only transaction for the tree.
+func (c *checker) snapshot(ctx context.Context, f func(storage.ReadOnlyLogTreeTX) error) error {
+	tx, err := c.ls.SnapshotForTree(ctx, c.tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
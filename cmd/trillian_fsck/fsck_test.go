@@ -0,0 +1,269 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	gocrypto "crypto"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/merkle/compact"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+)
+
+// corruption modifies the data of a sequencing pass before it is stored.
+type corruption struct {
+	pass   int
+	leaves func([]*trillian.LogLeaf)
+	nodes  func([]tree.Node)
+	root   func(*types.LogRootV1)
+	slr    func(*trillian.SignedLogRoot)
+}
+
+func TestCheckTree(t *testing.T) {
+	// The log is built in sequencing passes, each storing a signed root.
+	sizes := []int{0, 4, 10, 10}
+	for _, tc := range []struct {
+		desc    string
+		corrupt corruption
+		// want lists the kinds and indices of the problems found.
+		want []Problem
+	}{
+		{desc: "ok"},
+		{
+			desc: "bad-leaf-hash",
+			corrupt: corruption{pass: 2, leaves: func(leaves []*trillian.LogLeaf) {
+				leaves[1].LeafValue = []byte("other")
+			}},
+			want: []Problem{{Kind: kindLeafHash, Index: 5}},
+		},
+		{
+			desc: "bad-node",
+			corrupt: corruption{pass: 1, nodes: func(nodes []tree.Node) {
+				for i := range nodes {
+					if nodes[i].ID == compact.NewNodeID(1, 1) {
+						nodes[i].Hash = make([]byte, 32)
+					}
+				}
+			}},
+			want: []Problem{{Kind: kindNode, Index: 2}},
+		},
+		{
+			desc: "missing-node",
+			corrupt: corruption{pass: 2, nodes: func(nodes []tree.Node) {
+				for i := range nodes {
+					if nodes[i].ID == compact.NewNodeID(3, 0) {
+						nodes[i].ID = compact.NewNodeID(3, 10)
+					}
+				}
+			}},
+			want: []Problem{{Kind: kindNode, Index: 0}},
+		},
+		{
+			desc: "bad-root-hash",
+			corrupt: corruption{pass: 1, root: func(root *types.LogRootV1) {
+				root.RootHash = make([]byte, 32)
+			}},
+			want: []Problem{{Kind: kindRootHash, Index: 1}},
+		},
+		{
+			desc: "bad-signature",
+			corrupt: corruption{pass: 2, slr: func(slr *trillian.SignedLogRoot) {
+				slr.LogRootSignature = []byte("not a signature")
+			}},
+			want: []Problem{{Kind: kindRootSignature, Index: 2}},
+		},
+		{
+			// The memory storage reads the root with the latest timestamp, so
+			// this only goes back on the last root, which adds no leaves.
+			desc: "timestamp-goes-back",
+			corrupt: corruption{pass: 3, root: func(root *types.LogRootV1) {
+				root.TimestampNanos = 1
+			}},
+			want: []Problem{{Kind: kindRootOrder, Index: 3}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ts := memory.NewTreeStorage()
+			as := memory.NewAdminStorage(ts)
+			ls := memory.NewLogStorage(ts, nil)
+			logTree := storeLog(ctx, t, as, ls, sizes, tc.corrupt)
+
+			for _, batchSize := range []int{1, 3, 100} {
+				report, err := checkTree(ctx, ls, logTree, batchSize)
+				if err != nil {
+					t.Fatalf("checkTree(%d): %v", batchSize, err)
+				}
+				var got []Problem
+				for _, p := range report.Problems {
+					got = append(got, Problem{Kind: p.Kind, Index: p.Index})
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("checkTree(%d): problems diff (-want +got):\n%s\nreport: %+v", batchSize, diff, report)
+				}
+				if got, want := report.OK, len(tc.want) == 0; got != want {
+					t.Errorf("checkTree(%d): OK=%v, want %v", batchSize, got, want)
+				}
+				if got, want := report.RootsChecked, len(sizes); got != want {
+					t.Errorf("checkTree(%d): RootsChecked=%d, want %d", batchSize, got, want)
+				}
+				if got, want := report.LeavesChecked, uint64(sizes[len(sizes)-1]); got != want {
+					t.Errorf("checkTree(%d): LeavesChecked=%d, want %d", batchSize, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckTreeLatestRootOnly(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	as := memory.NewAdminStorage(ts)
+	ls := memory.NewLogStorage(ts, nil)
+	logTree := storeLog(ctx, t, as, ls, []int{0, 5, 7}, corruption{})
+
+	report, err := checkTree(ctx, latestRootOnly{ls}, logTree, 2)
+	if err != nil {
+		t.Fatalf("checkTree(): %v", err)
+	}
+	if !report.OK || report.RootsChecked != 1 || report.TreeSize != 7 || report.LeavesChecked != 7 {
+		t.Errorf("checkTree(): %+v, want OK with 1 root of size 7", report)
+	}
+}
+
+// latestRootOnly hides the LogRootHistoryTX implementation of the wrapped
+// storage.
+type latestRootOnly struct {
+	storage.LogStorage
+}
+
+func (l latestRootOnly) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := l.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ storage.ReadOnlyLogTreeTX }{tx}, nil
+}
+
+// storeLog creates a log, and grows it to each of the given sizes in turn,
+// storing leaves, tree nodes and a signed root the way the sequencer would.
+// The first size must be zero.
+func storeLog(ctx context.Context, t *testing.T, as storage.AdminStorage, ls storage.LogStorage, sizes []int, corrupt corruption) *trillian.Tree {
+	t.Helper()
+	logTree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	var keyProto keyspb.PrivateKey
+	if err := ptypes.UnmarshalAny(logTree.PrivateKey, &keyProto); err != nil {
+		t.Fatalf("UnmarshalAny(): %v", err)
+	}
+	key, err := der.UnmarshalPrivateKey(keyProto.Der)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(): %v", err)
+	}
+	signer := crypto.NewSigner(logTree.TreeId, key, gocrypto.SHA256)
+
+	hasher := rfc6962.DefaultHasher
+	cr := (&compact.RangeFactory{Hash: hasher.HashChildren}).NewEmptyRange(0)
+	for pass, size := range sizes {
+		begin := int(cr.End())
+		leaves := make([]*trillian.LogLeaf, 0, size-begin)
+		for i := begin; i < size; i++ {
+			value := []byte(fmt.Sprintf("leaf-%d", i))
+			hash := hasher.HashLeaf(value)
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash, MerkleLeafHash: hash, LeafValue: value})
+		}
+		if len(leaves) > 0 {
+			if _, err := ls.QueueLeaves(ctx, logTree, leaves, time.Unix(10, 0)); err != nil {
+				t.Fatalf("QueueLeaves(): %v", err)
+			}
+		}
+
+		if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			dequeued, err := tx.DequeueLeaves(ctx, len(leaves), time.Unix(20, 0))
+			if err != nil {
+				return err
+			}
+			var nodes []tree.Node
+			store := func(id compact.NodeID, hash []byte) { nodes = append(nodes, tree.Node{ID: id, Hash: hash}) }
+			for i, leaf := range dequeued {
+				leaf.LeafIndex = int64(begin + i)
+				store(compact.NewNodeID(0, uint64(leaf.LeafIndex)), leaf.MerkleLeafHash)
+				if err := cr.Append(leaf.MerkleLeafHash, store); err != nil {
+					return err
+				}
+			}
+			rootHash := hasher.EmptyRoot()
+			if cr.End() > 0 {
+				if rootHash, err = cr.GetRootHash(nil); err != nil {
+					return err
+				}
+			}
+			// The first root initializes the tree, so there is no revision yet.
+			var rev int64
+			if pass > 0 {
+				if rev, err = tx.WriteRevision(ctx); err != nil {
+					return err
+				}
+			}
+			root := &types.LogRootV1{TreeSize: uint64(size), RootHash: rootHash, TimestampNanos: uint64(100 + pass), Revision: uint64(rev)}
+			if pass == corrupt.pass {
+				if corrupt.leaves != nil {
+					corrupt.leaves(dequeued)
+				}
+				if corrupt.nodes != nil {
+					corrupt.nodes(nodes)
+				}
+				if corrupt.root != nil {
+					corrupt.root(root)
+				}
+			}
+			if len(dequeued) > 0 {
+				if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+					return err
+				}
+				if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+					return err
+				}
+			}
+			slr, err := signer.SignLogRoot(root)
+			if err != nil {
+				return err
+			}
+			if pass == corrupt.pass && corrupt.slr != nil {
+				corrupt.slr(slr)
+			}
+			return tx.StoreSignedLogRoot(ctx, slr)
+		}); err != nil {
+			t.Fatalf("ReadWriteTransaction(): %v", err)
+		}
+	}
+	return logTree
+}
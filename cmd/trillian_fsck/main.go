@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The trillian_fsck binary checks the consistency of the stored data of a log
+// tree. It verifies that the leaves are densely indexed and hash to their
+// Merkle leaf hashes, that the stored subtree nodes match the leaves, and that
+// each stored signed root verifies, follows its predecessor and matches the
+// root hash recomputed at its size. It only reads from the storage.
+//
+// A JSON report is written to stdout, and the exit status is non-zero if any
+// problems were found.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/cmd"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+
+	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/bolt"
+	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/commitlog"
+	_ "github.com/google/trillian/storage/memory"
+	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/sqlite"
+	_ "github.com/google/trillian/storage/tiles"
+
+	// Load hashers
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+var (
+	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	treeID        = flag.Int64("tree_id", 0, "The ID of the log tree to check")
+	batchSize     = flag.Int("batch_size", 1000, "Max number of leaves or signed roots to read per transaction")
+
+	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
+)
+
+func main() {
+	flag.Parse()
+	defer glog.Flush()
+
+	if *configFile != "" {
+		if err := cmd.ParseFlagFile(*configFile); err != nil {
+			glog.Exitf("Failed to load flags from config file %q: %s", *configFile, err)
+		}
+	}
+	if *treeID == 0 {
+		glog.Exit("--tree_id must be set")
+	}
+
+	sp, err := storage.NewProvider(*storageSystem, monitoring.InertMetricFactory{})
+	if err != nil {
+		glog.Exitf("Failed to get storage provider: %v", err)
+	}
+	defer sp.Close()
+
+	ctx := context.Background()
+	tree, err := storage.GetTree(ctx, sp.AdminStorage(), *treeID)
+	if err != nil {
+		glog.Exitf("Failed to get tree %d: %v", *treeID, err)
+	}
+	report, err := checkTree(ctx, sp.LogStorage(), tree, *batchSize)
+	if err != nil {
+		glog.Exitf("Failed to check tree %d: %v", *treeID, err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		glog.Exitf("Failed to write report: %v", err)
+	}
+	if !report.OK {
+		glog.Errorf("Found %d problems in tree %d", len(report.Problems), *treeID)
+		glog.Flush()
+		os.Exit(1)
+	}
+}
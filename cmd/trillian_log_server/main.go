@@ -43,6 +43,8 @@ import (
 	"github.com/google/trillian/quota/etcd/quotapb"
 	"github.com/google/trillian/server"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/admincache"
+	"github.com/google/trillian/storage/instrumented"
 	"github.com/google/trillian/util/clock"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
@@ -53,8 +55,15 @@ import (
 	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
 
 	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/bolt"
 	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/commitlog"
+	_ "github.com/google/trillian/storage/faulty"
+	_ "github.com/google/trillian/storage/memory"
 	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/sqlite"
+	_ "github.com/google/trillian/storage/tiles"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/rfc6962"
@@ -75,7 +84,9 @@ var (
 	quotaSystem = flag.String("quota_system", "mysql", fmt.Sprintf("Quota system to use. One of: %v", quota.Providers()))
 	quotaDryRun = flag.Bool("quota_dry_run", false, "If true no requests are blocked due to lack of tokens")
 
-	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	storageSystem     = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	instrumentStorage = flag.Bool("instrument_storage", false, "If true, export the latency, errors and rows returned of every storage call, by method and tree")
+	adminCacheTTL     = flag.Duration("admin_cache_ttl", 0, "If positive, trees are cached for this long instead of being read from storage for every request; this bounds the time taken by changes made through other servers, such as freezing or deleting a tree, to take effect")
 
 	treeGCEnabled            = flag.Bool("tree_gc", true, "If true, tree garbage collection (hard-deletion) is periodically performed")
 	treeDeleteThreshold      = flag.Duration("tree_delete_threshold", serverutil.DefaultTreeDeleteThreshold, "Minimum period a tree has to remain deleted before being hard-deleted")
@@ -122,6 +133,9 @@ func main() {
 		glog.Exitf("Failed to get storage provider: %v", err)
 	}
 	defer sp.Close()
+	if *instrumentStorage {
+		sp = instrumented.NewProvider(sp, mf)
+	}
 
 	var client *clientv3.Client
 	if servers := *etcd.Servers; servers != "" {
@@ -147,8 +161,13 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	as := sp.AdminStorage()
+	if *adminCacheTTL > 0 {
+		as = admincache.NewAdminStorage(as, admincache.Options{TTL: *adminCacheTTL}, mf)
+	}
+
 	registry := extension.Registry{
-		AdminStorage:  sp.AdminStorage(),
+		AdminStorage:  as,
 		LogStorage:    sp.LogStorage(),
 		QuotaManager:  qm,
 		MetricFactory: mf,
@@ -19,6 +19,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	_ "net/http/pprof" // Register pprof HTTP handlers.
 	"os"
 	"runtime/pprof"
@@ -30,12 +31,14 @@ import (
 	"github.com/google/trillian/cmd/internal/serverutil"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/log"
+	"github.com/google/trillian/log/publisher"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/monitoring/opencensus"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/quota/etcd"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/instrumented"
 	"github.com/google/trillian/util"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
@@ -50,8 +53,15 @@ import (
 	_ "github.com/google/trillian/crypto/keys/pkcs11/proto"
 
 	// Register supported storage providers.
+	_ "github.com/google/trillian/storage/bolt"
 	_ "github.com/google/trillian/storage/cloudspanner"
+	_ "github.com/google/trillian/storage/commitlog"
+	_ "github.com/google/trillian/storage/faulty"
+	_ "github.com/google/trillian/storage/memory"
 	_ "github.com/google/trillian/storage/mysql"
+	_ "github.com/google/trillian/storage/postgres"
+	_ "github.com/google/trillian/storage/sqlite"
+	_ "github.com/google/trillian/storage/tiles"
 
 	// Load hashers
 	_ "github.com/google/trillian/merkle/rfc6962"
@@ -68,6 +78,8 @@ var (
 	sequencerIntervalFlag    = flag.Duration("sequencer_interval", 100*time.Millisecond, "Time between each sequencing pass through all logs")
 	batchSizeFlag            = flag.Int("batch_size", 1000, "Max number of leaves to process per batch")
 	numSeqFlag               = flag.Int("num_sequencers", 10, "Number of sequencer workers to run in parallel")
+	watchQueue               = flag.Bool("watch_queue", true, "If true and the storage system supports it, start a sequencing pass as soon as leaves are queued instead of waiting for --sequencer_interval")
+	sequencerMinIntervalFlag = flag.Duration("sequencer_min_interval", 10*time.Millisecond, "Minimum time between the starts of sequencing passes when they are started by queued leaves, see --watch_queue")
 	sequencerGuardWindowFlag = flag.Duration("sequencer_guard_window", 0, "If set, the time elapsed before submitted leaves are eligible for sequencing")
 	forceMaster              = flag.Bool("force_master", false, "If true, assume master for all logs")
 	etcdHTTPService          = flag.String("etcd_http_service", "trillian-logsigner-http", "Service name to announce our HTTP endpoint under")
@@ -79,11 +91,35 @@ var (
 		"Increase factor for tokens replenished by sequencing-based quotas (1 means a 1:1 relationship between sequenced leaves and replenished tokens)."+
 			"Only effective for --quota_system=etcd.")
 
-	storageSystem = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	storageSystem     = flag.String("storage_system", "mysql", fmt.Sprintf("Storage system to use. One of: %v", storage.Providers()))
+	instrumentStorage = flag.Bool("instrument_storage", false, "If true, export the latency, errors and rows returned of every storage call, by method and tree")
+
+	submitterWeights = flag.String("dequeue_submitter_weights", "", "Comma-separated submitter=weight pairs used to share dequeued leaves between submitters, for trees with FAIR_SHARE_ORDER. Unlisted submitters have weight 1")
 
 	preElectionPause   = flag.Duration("pre_election_pause", 1*time.Second, "Maximum time to wait before starting elections")
 	masterHoldInterval = flag.Duration("master_hold_interval", 60*time.Second, "Minimum interval to hold mastership for")
 	masterHoldJitter   = flag.Duration("master_hold_jitter", 120*time.Second, "Maximal random addition to --master_hold_interval")
+	rebalanceTolerance = flag.Float64("rebalance_tolerance", 0, "If positive, resign mastership of some logs when holding more than (1+tolerance) times the fair share of the load across signers; requires etcd")
+	rebalanceInterval  = flag.Duration("rebalance_interval", 60*time.Second, "Minimum interval between load rebalancing checks")
+	rebalanceHold      = flag.Duration("rebalance_hold", 10*time.Minute, "Minimum time to hold mastership of a log before resigning it to rebalance load")
+
+	auditInterval         = flag.Duration("audit_interval", 0, "If positive, the interval between integrity audits of the stored data of each log this instance is master for")
+	auditBatchSize        = flag.Int64("audit_batch_size", 1000, "Max number of leaves to read per transaction during integrity audits")
+	auditLeavesPerSecond  = flag.Float64("audit_leaves_per_second", 1000, "Max rate at which integrity audits read leaves; 0 means unlimited")
+	auditFreezeOnMismatch = flag.Bool("audit_freeze_on_mismatch", false, "If true, set logs which fail an integrity audit to the FROZEN state")
+	auditCheckpointDir    = flag.String("audit_checkpoint_dir", "", "If set, a directory where integrity audits record how far each log has been audited, so that they resume from there after a restart instead of auditing the whole log again")
+
+	compactionInterval   = flag.Duration("subtree_compaction_interval", 0, "If positive, the interval between deletions of superseded subtree revisions of each log this instance is master for; requires MySQL, PostgreSQL or SQLite storage")
+	compactionRetention  = flag.Duration("subtree_compaction_retention", 24*time.Hour, "How long signed log roots remain readable after a later root is stored; must exceed the longest read transaction")
+	compactionBatchSize  = flag.Int("subtree_compaction_batch_size", 100, "Max number of subtrees to compact per transaction")
+	compactionBatchPause = flag.Duration("subtree_compaction_batch_pause", 100*time.Millisecond, "Time to wait between subtree compaction transactions")
+
+	rootPublisher        = flag.String("root_publisher", "", "Where to publish newly signed log roots. One of: file, http; empty means disabled")
+	rootPublisherFile    = flag.String("root_publisher_file", "", "File to append signed log roots to, for --root_publisher=file")
+	rootPublisherURL     = flag.String("root_publisher_url", "", "URL to POST signed log roots to, for --root_publisher=http")
+	rootPublisherOutbox  = flag.String("root_publisher_outbox", "", "If set, directory in which signed log roots are persisted until they are published, so that every root is delivered. Otherwise, roots are published on a best-effort basis, and only the latest root of a log is retried")
+	rootPublisherTimeout = flag.Duration("root_publisher_timeout", 10*time.Second, "Timeout of the requests posting signed log roots, for --root_publisher=http")
+	rootPublisherState   = flag.String("root_publisher_state_dir", "", "If set, directory in which the latest published revision of each log is recorded, so that roots are not published again after a restart")
 
 	configFile = flag.String("config", "", "Config file containing flags, file contents can be overridden by command line flags")
 
@@ -108,11 +144,20 @@ func main() {
 	mf := prometheus.MetricFactory{}
 	monitoring.SetStartSpan(opencensus.StartSpan)
 
-	sp, err := storage.NewProvider(*storageSystem, mf)
+	weights, err := storage.ParseSubmitterWeights(*submitterWeights)
+	if err != nil {
+		glog.Exitf("Invalid --dequeue_submitter_weights: %v", err)
+	}
+	sp, err := storage.NewProviderWithOptions(*storageSystem, mf, storage.ProviderOptions{
+		SubmitterWeights: func(int64) map[string]int { return weights },
+	})
 	if err != nil {
 		glog.Exitf("Failed to get storage provider: %v", err)
 	}
 	defer sp.Close()
+	if *instrumentStorage {
+		sp = instrumented.NewProvider(sp, mf)
+	}
 
 	var client *clientv3.Client
 	if servers := *etcd.Servers; servers != "" {
@@ -147,12 +192,23 @@ func main() {
 		glog.Exitf("Error creating quota manager: %v", err)
 	}
 
+	rp, closeRP, err := newRootPublisher(ctx)
+	if err != nil {
+		glog.Exitf("Error creating root publisher: %v", err)
+	}
+	defer func() {
+		if err := closeRP(); err != nil {
+			glog.Errorf("Error closing root publisher: %v", err)
+		}
+	}()
+
 	registry := extension.Registry{
 		AdminStorage:    sp.AdminStorage(),
 		LogStorage:      sp.LogStorage(),
 		ElectionFactory: electionFactory,
 		QuotaManager:    qm,
 		MetricFactory:   mf,
+		RootPublisher:   rp,
 	}
 
 	// Start HTTP server (optional)
@@ -179,10 +235,49 @@ func main() {
 			MasterHoldJitter:   *masterHoldJitter,
 			TimeSource:         clock.System,
 		},
+		RebalanceTolerance: *rebalanceTolerance,
+		RebalanceInterval:  *rebalanceInterval,
+		RebalanceHold:      *rebalanceHold,
+	}
+	if qw, ok := sp.(storage.QueueWatcher); ok && *watchQueue {
+		notifications, err := qw.WatchQueue(ctx)
+		if err != nil {
+			glog.Exitf("Failed to watch for queued leaves: %v", err)
+		}
+		info.QueueNotifications = notifications
+		info.MinRunInterval = *sequencerMinIntervalFlag
 	}
 	sequencerTask := log.NewOperationManager(info, sequencerManager)
 	go sequencerTask.OperationLoop(ctx)
 
+	if *auditInterval > 0 {
+		var checkpoints log.AuditCheckpoints
+		if *auditCheckpointDir != "" {
+			if checkpoints, err = log.NewFileAuditCheckpoints(*auditCheckpointDir); err != nil {
+				glog.Exitf("Failed to create audit checkpoints: %v", err)
+			}
+		}
+		auditor := log.NewAuditor(registry, log.AuditOptions{
+			Interval:         *auditInterval,
+			BatchSize:        *auditBatchSize,
+			LeavesPerSecond:  *auditLeavesPerSecond,
+			FreezeOnMismatch: *auditFreezeOnMismatch,
+			Checkpoints:      checkpoints,
+			TimeSource:       clock.System,
+		})
+		go auditor.Run(ctx, sequencerTask.HeldLogIDs)
+	}
+	if *compactionInterval > 0 {
+		compactor := log.NewCompactor(registry, log.CompactionOptions{
+			Interval:   *compactionInterval,
+			Retention:  *compactionRetention,
+			BatchSize:  *compactionBatchSize,
+			BatchPause: *compactionBatchPause,
+			TimeSource: clock.System,
+		})
+		go compactor.Run(ctx, sequencerTask.HeldLogIDs)
+	}
+
 	// Enable CPU profile if requested
 	if *cpuProfile != "" {
 		f := mustCreate(*cpuProfile)
@@ -217,6 +312,55 @@ func main() {
 	time.Sleep(time.Second * 5)
 }
 
+// newRootPublisher creates the RootPublisher selected by flags, or returns nil
+// if root publishing is disabled, along with a function which releases its
+// resources. If an outbox is configured, the returned publisher delivers roots
+// in the background until ctx is done.
+func newRootPublisher(ctx context.Context) (publisher.RootPublisher, func() error, error) {
+	var rp publisher.RootPublisher
+	closeRP := func() error { return nil }
+	switch *rootPublisher {
+	case "":
+		return nil, closeRP, nil
+	case "file":
+		fp, err := publisher.NewFilePublisher(*rootPublisherFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		rp, closeRP = fp, fp.Close
+	case "http":
+		if *rootPublisherURL == "" {
+			return nil, nil, fmt.Errorf("--root_publisher_url must be set for --root_publisher=http")
+		}
+		hp, err := publisher.NewHTTPPublisher(*rootPublisherURL, &http.Client{Timeout: *rootPublisherTimeout})
+		if err != nil {
+			return nil, nil, err
+		}
+		rp = hp
+	default:
+		return nil, nil, fmt.Errorf("unknown --root_publisher %q", *rootPublisher)
+	}
+
+	if *rootPublisherOutbox != "" {
+		outbox, err := publisher.NewOutbox(*rootPublisherOutbox, rp)
+		if err != nil {
+			closeRP()
+			return nil, nil, err
+		}
+		go outbox.Run(ctx)
+		rp = outbox
+	}
+	if *rootPublisherState != "" {
+		tracker, err := publisher.NewPersistentTracker(rp, *rootPublisherState)
+		if err != nil {
+			closeRP()
+			return nil, nil, err
+		}
+		rp = tracker
+	}
+	return rp, closeRP, nil
+}
+
 func mustCreate(fileName string) *os.File {
 	f, err := os.Create(fileName)
 	if err != nil {
@@ -46,6 +46,9 @@ var (
 	treeID          = flag.Int64("tree_id", 0, "The ID of the tree to be set updated")
 	treeState       = flag.String("tree_state", "", "If set the tree state will be updated")
 	treeType        = flag.String("tree_type", "", "If set the tree type will be updated")
+	dequeueOrder    = flag.String("dequeue_order", "", "If set the dequeue order will be updated")
+	leafCompression = flag.String("leaf_compression", "", "If set the leaf compression will be updated")
+	dictionaryID    = flag.Int("leaf_compression_dictionary_id", -1, "If non-negative the leaf compression dictionary ID will be updated")
 	printTree       = flag.Bool("print", false, "Print the resulting tree")
 )
 
@@ -84,6 +87,37 @@ func updateTree(ctx context.Context) (*trillian.Tree, error) {
 		paths = append(paths, "tree_type")
 	}
 
+	if len(*dequeueOrder) > 0 {
+		m, err := protoregistry.GlobalTypes.FindEnumByName("trillian.DequeueOrder")
+		if err != nil {
+			return nil, fmt.Errorf("can't find enum value map for dequeue orders: %w", err)
+		}
+		newOrder := m.Descriptor().Values().ByName(protoreflect.Name(*dequeueOrder))
+		if newOrder == nil {
+			return nil, fmt.Errorf("invalid dequeue order: %v", *dequeueOrder)
+		}
+		tree.DequeueOrder = trillian.DequeueOrder(newOrder.Number())
+		paths = append(paths, "dequeue_order")
+	}
+
+	if len(*leafCompression) > 0 {
+		m, err := protoregistry.GlobalTypes.FindEnumByName("trillian.LeafCompression")
+		if err != nil {
+			return nil, fmt.Errorf("can't find enum value map for leaf compressions: %w", err)
+		}
+		newCompression := m.Descriptor().Values().ByName(protoreflect.Name(*leafCompression))
+		if newCompression == nil {
+			return nil, fmt.Errorf("invalid leaf compression: %v", *leafCompression)
+		}
+		tree.LeafCompression = trillian.LeafCompression(newCompression.Number())
+		paths = append(paths, "leaf_compression")
+	}
+
+	if *dictionaryID >= 0 {
+		tree.LeafCompressionDictionaryId = uint32(*dictionaryID)
+		paths = append(paths, "leaf_compression_dictionary_id")
+	}
+
 	if len(paths) == 0 {
 		return nil, errors.New("nothing to change")
 	}
@@ -18,6 +18,7 @@ package extension
 
 import (
 	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/log/publisher"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/quota"
 	"github.com/google/trillian/storage"
@@ -41,6 +42,9 @@ type Registry struct {
 	// NewKeyProto creates a new private key based on a key specification.
 	// It returns a proto that can be passed to a keys.ProtoHandler to get a crypto.Signer.
 	NewKeyProto keys.ProtoGenerator
+	// RootPublisher, if set, is notified of every new SignedLogRoot committed
+	// by the sequencer, at least once.
+	RootPublisher publisher.RootPublisher
 	// SetProcessStatus sets the current process status for diagnostic purposes.
 	SetProcessStatus func(string)
 }
@@ -17,6 +17,7 @@ package integration
 import (
 	"context"
 	"flag"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -26,8 +27,11 @@ import (
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/quota"
+	"github.com/google/trillian/storage/commitlog"
+	"github.com/google/trillian/storage/faulty"
 	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/storage/testdb"
+	"github.com/google/trillian/storage/tiles"
 	"github.com/google/trillian/testonly/integration"
 
 	_ "github.com/google/trillian/crypto/keys/der/proto" // Register PrivateKey ProtoHandler
@@ -147,3 +151,77 @@ func TestInProcessLogIntegrationDuplicateLeaves(t *testing.T) {
 		t.Fatalf("Test failed: %v", err)
 	}
 }
+
+func TestInProcessLogIntegrationFaultyStorage(t *testing.T) {
+	ctx := context.Background()
+	const numSequencers = 2
+	ts := memory.NewTreeStorage()
+	// Failed commits are retried by InitLog and by the sequencers, but
+	// errors of other calls would fail the client requests of the test.
+	inj := faulty.NewInjector(faulty.Options{
+		Seed:               1,
+		Latency:            time.Millisecond,
+		LatencyProbability: 0.5,
+		ErrorProbability:   0.2,
+		Methods:            []string{faulty.Commit},
+	}, nil)
+
+	reggie := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   faulty.NewLogStorage(memory.NewLogStorage(ts, nil), inj),
+		QuotaManager: quota.Noop(),
+	}
+
+	env, err := integration.NewLogEnvWithRegistry(ctx, numSequencers, reggie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	tree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{
+		Tree: stestonly.LogTree,
+	}, env.Admin, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	params := DefaultTestParameters(tree.TreeId)
+	if err := RunLogIntegration(env.Log, params); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
+
+func TestInProcessLogIntegrationTiles(t *testing.T) {
+	ctx := context.Background()
+	const numSequencers = 2
+	dir := t.TempDir()
+	l, err := commitlog.NewFileLog(filepath.Join(dir, "log"), 1<<20)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	defer l.Close()
+
+	reggie := extension.Registry{
+		AdminStorage: commitlog.NewAdminStorage(l),
+		LogStorage:   tiles.NewLogStorage(dir, l, nil),
+		QuotaManager: quota.Noop(),
+	}
+
+	env, err := integration.NewLogEnvWithRegistry(ctx, numSequencers, reggie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	tree, err := client.CreateAndInitTree(ctx, &trillian.CreateTreeRequest{
+		Tree: stestonly.LogTree,
+	}, env.Admin, env.Log)
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	params := DefaultTestParameters(tree.TreeId)
+	if err := RunLogIntegration(env.Log, params); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
@@ -371,6 +371,21 @@ func testGetLeavesByRangeImpl(ctx context.Context, t *testing.T, s storage.LogSt
 			if !reflect.DeepEqual(got, test.want) {
 				t.Errorf("GetLeavesByRange(%d, +%d)=%+v; want %+v", test.start, test.count, got, test.want)
 			}
+
+			if htx, ok := tx.(storage.LeafHashesTX); ok {
+				hashes, err := htx.GetLeafHashesByRange(ctx, test.start, test.count)
+				if err != nil {
+					t.Errorf("GetLeafHashesByRange(%d, +%d)=_,%v; want _,nil", test.start, test.count, err)
+					return nil
+				}
+				want := make([]*trillian.LogLeaf, len(leaves))
+				for i, leaf := range leaves {
+					want[i] = &trillian.LogLeaf{LeafIndex: leaf.LeafIndex, MerkleLeafHash: leaf.MerkleLeafHash}
+				}
+				if diff := cmp.Diff(hashes, want, protocmp.Transform()); diff != "" {
+					t.Errorf("GetLeafHashesByRange(%d, +%d) diff (-got +want):\n%s", test.start, test.count, diff)
+				}
+			}
 			return nil
 		})
 	}
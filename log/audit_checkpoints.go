@@ -0,0 +1,130 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// AuditCheckpoint records the size and root hash of a log up to which an
+// Auditor has checked it, so that the next audit only needs to check the
+// leaves added since then.
+type AuditCheckpoint struct {
+	TreeSize uint64 `json:"tree_size"`
+	RootHash []byte `json:"root_hash"`
+	// Hashes are the hashes of the compact range covering the first TreeSize
+	// leaves, which the next audit extends with the new leaves.
+	Hashes [][]byte `json:"hashes"`
+}
+
+// AuditCheckpoints stores the AuditCheckpoint of each log.
+type AuditCheckpoints interface {
+	// Get returns the checkpoint of the given log, or nil if the log has not
+	// been audited yet.
+	Get(ctx context.Context, logID int64) (*AuditCheckpoint, error)
+	// Put replaces the checkpoint of the given log.
+	Put(ctx context.Context, logID int64, cp *AuditCheckpoint) error
+}
+
+// memoryAuditCheckpoints keeps checkpoints for the lifetime of the process.
+type memoryAuditCheckpoints struct {
+	mu  sync.Mutex
+	cps map[int64]*AuditCheckpoint
+}
+
+// NewMemoryAuditCheckpoints returns AuditCheckpoints which are kept in memory,
+// so every log is audited in full once per process.
+func NewMemoryAuditCheckpoints() AuditCheckpoints {
+	return &memoryAuditCheckpoints{cps: make(map[int64]*AuditCheckpoint)}
+}
+
+func (m *memoryAuditCheckpoints) Get(_ context.Context, logID int64) (*AuditCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cps[logID], nil
+}
+
+func (m *memoryAuditCheckpoints) Put(_ context.Context, logID int64, cp *AuditCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cps[logID] = cp
+	return nil
+}
+
+// fileAuditCheckpoints keeps a JSON file per log in a local directory.
+type fileAuditCheckpoints struct {
+	dir string
+}
+
+// NewFileAuditCheckpoints returns AuditCheckpoints which are persisted in the
+// given directory, so audits carry on where they left off across restarts.
+func NewFileAuditCheckpoints(dir string) (AuditCheckpoints, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit checkpoint directory: %v", err)
+	}
+	return &fileAuditCheckpoints{dir: dir}, nil
+}
+
+func (f *fileAuditCheckpoints) path(logID int64) string {
+	return filepath.Join(f.dir, strconv.FormatInt(logID, 10)+".json")
+}
+
+func (f *fileAuditCheckpoints) Get(_ context.Context, logID int64) (*AuditCheckpoint, error) {
+	b, err := ioutil.ReadFile(f.path(logID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var cp AuditCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse audit checkpoint of log %d: %v", logID, err)
+	}
+	return &cp, nil
+}
+
+func (f *fileAuditCheckpoints) Put(_ context.Context, logID int64, cp *AuditCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that a crash can't leave a
+	// truncated checkpoint behind.
+	path := f.path(logID)
+	tmp, err := ioutil.TempFile(f.dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
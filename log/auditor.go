@@ -0,0 +1,382 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+)
+
+var (
+	auditorOnce     sync.Once
+	auditRuns       monitoring.Counter
+	auditFailures   monitoring.Counter
+	auditMismatches monitoring.Counter
+	auditedLeaves   monitoring.Counter
+	auditFreezes    monitoring.Counter
+)
+
+func createAuditorMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	auditRuns = mf.NewCounter("audit_runs", "Number of completed integrity audits", logIDLabel)
+	auditFailures = mf.NewCounter("audit_failures", "Number of integrity audits which failed to complete", logIDLabel)
+	auditMismatches = mf.NewCounter("audit_mismatches", "Number of integrity audits which found stored data inconsistent", logIDLabel)
+	auditedLeaves = mf.NewCounter("audited_leaves", "Number of leaves checked by integrity audits", logIDLabel)
+	auditFreezes = mf.NewCounter("audit_freezes", "Number of logs frozen because of failed integrity audits", logIDLabel)
+}
+
+// AuditOptions configures an Auditor.
+type AuditOptions struct {
+	// Interval is the time between the starts of consecutive audit passes over
+	// all the held logs.
+	Interval time.Duration
+	// BatchSize is the number of leaves read per storage transaction.
+	BatchSize int64
+	// LeavesPerSecond limits the rate at which leaves are read. Zero means no
+	// limit.
+	LeavesPerSecond float64
+	// FreezeOnMismatch causes logs which fail the audit to be set to the
+	// FROZEN state, so that no more leaves are integrated on top of corrupted
+	// data.
+	FreezeOnMismatch bool
+	// Checkpoints stores how far each log has been audited. If nil, they are
+	// kept in memory.
+	Checkpoints AuditCheckpoints
+	// TimeSource is used for pacing, and may be mocked out by tests.
+	TimeSource clock.TimeSource
+}
+
+// MismatchError is returned by Auditor.AuditLog when stored data of a log is
+// inconsistent with other stored data, or with its latest signed root.
+type MismatchError struct {
+	msg string
+}
+
+func (e *MismatchError) Error() string {
+	return e.msg
+}
+
+func mismatchf(format string, args ...interface{}) error {
+	return &MismatchError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Auditor periodically checks the integrity of stored logs. It reads the
+// Merkle leaf hashes of the leaves added since the last audit, extends the
+// compact range of the audited leaves with them, and compares each of the
+// resulting perfect subtree nodes with the one in the stored tiles, as well as
+// the final root hash with the latest SignedLogRoot. A consistency proof built
+// from the stored tiles checks that the previously audited part of the tree
+// has not changed since.
+type Auditor struct {
+	registry extension.Registry
+	opts     AuditOptions
+}
+
+var auditOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// NewAuditor creates a new Auditor for the logs in the given registry.
+func NewAuditor(registry extension.Registry, opts AuditOptions) *Auditor {
+	auditorOnce.Do(func() {
+		createAuditorMetrics(registry.MetricFactory)
+	})
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.Checkpoints == nil {
+		opts.Checkpoints = NewMemoryAuditCheckpoints()
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	return &Auditor{registry: registry, opts: opts}
+}
+
+// Run audits the logs returned by held, e.g. the ones that this instance is
+// the master for, every Interval. It runs until the context is canceled.
+func (a *Auditor) Run(ctx context.Context, held func() []int64) {
+	for {
+		start := a.opts.TimeSource.Now()
+		for _, logID := range held() {
+			a.auditAndReport(ctx, logID)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		wait := a.opts.Interval - a.opts.TimeSource.Now().Sub(start)
+		if err := clock.SleepSource(ctx, wait, a.opts.TimeSource); err != nil {
+			return
+		}
+	}
+}
+
+// auditAndReport audits the given log, and acts on the outcome.
+func (a *Auditor) auditAndReport(ctx context.Context, logID int64) {
+	label := strconv.FormatInt(logID, 10)
+	err := a.AuditLog(ctx, logID)
+	var mismatch *MismatchError
+	switch {
+	case err == nil:
+		glog.V(1).Infof("%v: integrity audit passed", logID)
+		auditRuns.Inc(label)
+		return
+	case errors.As(err, &mismatch):
+		glog.Errorf("%v: integrity audit failed: %v", logID, err)
+		auditRuns.Inc(label)
+		auditMismatches.Inc(label)
+	default:
+		if ctx.Err() == nil {
+			glog.Warningf("%v: integrity audit did not complete: %v", logID, err)
+			auditFailures.Inc(label)
+		}
+		return
+	}
+
+	if !a.opts.FreezeOnMismatch {
+		return
+	}
+	if _, err := storage.UpdateTree(ctx, a.registry.AdminStorage, logID, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	}); err != nil {
+		glog.Errorf("%v: failed to freeze log: %v", logID, err)
+		return
+	}
+	glog.Warningf("%v: froze log after failed integrity audit", logID)
+	auditFreezes.Inc(label)
+}
+
+// AuditLog checks the integrity of the given log up to its latest signed
+// root, starting from its audit checkpoint if there is one. It returns a
+// *MismatchError if the stored data is inconsistent. The checkpoint is moved
+// forward if the audit passes.
+func (a *Auditor) AuditLog(ctx context.Context, logID int64) error {
+	tree, err := trees.GetTree(ctx, a.registry.AdminStorage, logID, auditOpts)
+	if err != nil {
+		return fmt.Errorf("failed to get tree: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return fmt.Errorf("failed to get hasher: %v", err)
+	}
+
+	var root types.LogRootV1
+	if err := a.snapshot(ctx, tree, func(tx storage.ReadOnlyLogTreeTX) error {
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		return root.UnmarshalBinary(slr.LogRoot)
+	}); err != nil {
+		return fmt.Errorf("failed to read latest root: %v", err)
+	}
+
+	cp, err := a.opts.Checkpoints.Get(ctx, logID)
+	if err != nil {
+		return fmt.Errorf("failed to read audit checkpoint: %v", err)
+	}
+	fact := compact.RangeFactory{Hash: hasher.HashChildren}
+	cr := fact.NewEmptyRange(0)
+	if cp != nil {
+		if cr, err = a.resume(ctx, tree, hasher, fact, cp, &root); err != nil {
+			return err
+		}
+	}
+
+	for begin := cr.End(); begin < root.TreeSize; begin = cr.End() {
+		count := root.TreeSize - begin
+		if count > uint64(a.opts.BatchSize) {
+			count = uint64(a.opts.BatchSize)
+		}
+		if err := a.snapshot(ctx, tree, func(tx storage.ReadOnlyLogTreeTX) error {
+			return a.auditLeaves(ctx, tx, hasher, cr, count)
+		}); err != nil {
+			return err
+		}
+		auditedLeaves.Add(float64(cr.End()-begin), strconv.FormatInt(logID, 10))
+
+		if a.opts.LeavesPerSecond > 0 {
+			pause := time.Duration(float64(cr.End()-begin) / a.opts.LeavesPerSecond * float64(time.Second))
+			if err := clock.SleepSource(ctx, pause, a.opts.TimeSource); err != nil {
+				return err
+			}
+		}
+	}
+
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return fmt.Errorf("failed to compute root hash: %v", err)
+	}
+	if root.TreeSize == 0 {
+		hash = hasher.EmptyRoot()
+	}
+	if !bytes.Equal(hash, root.RootHash) {
+		return mismatchf("root hash at size %d is %x, but signed root has %x", root.TreeSize, hash, root.RootHash)
+	}
+	if err := a.opts.Checkpoints.Put(ctx, logID, &AuditCheckpoint{TreeSize: root.TreeSize, RootHash: root.RootHash, Hashes: cr.Hashes()}); err != nil {
+		return fmt.Errorf("failed to store audit checkpoint: %v", err)
+	}
+	return nil
+}
+
+// resume returns the compact range of the leaves covered by the checkpoint,
+// after checking that the stored tree, up to the latest signed root, is
+// consistent with the checkpoint's root.
+func (a *Auditor) resume(ctx context.Context, tree *trillian.Tree, hasher hashers.LogHasher, fact compact.RangeFactory, cp *AuditCheckpoint, root *types.LogRootV1) (*compact.Range, error) {
+	cr, err := fact.NewRange(0, cp.TreeSize, cp.Hashes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit checkpoint: %v", err)
+	}
+	hash, err := cr.GetRootHash(nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit checkpoint: %v", err)
+	}
+	if cp.TreeSize == 0 {
+		hash = hasher.EmptyRoot()
+	}
+	if !bytes.Equal(hash, cp.RootHash) {
+		return nil, fmt.Errorf("invalid audit checkpoint: range hashes to %x, want %x", hash, cp.RootHash)
+	}
+
+	switch {
+	case root.TreeSize < cp.TreeSize:
+		return nil, mismatchf("signed root has size %d, but the log was audited at size %d", root.TreeSize, cp.TreeSize)
+	case root.TreeSize == cp.TreeSize:
+		if !bytes.Equal(root.RootHash, cp.RootHash) {
+			return nil, mismatchf("signed root hash at size %d is %x, but the audited root has %x", root.TreeSize, root.RootHash, cp.RootHash)
+		}
+		return cr, nil
+	case cp.TreeSize == 0:
+		// Every tree is consistent with the empty one.
+		return cr, nil
+	}
+
+	fetches, err := merkle.CalcConsistencyProofNodeAddresses(int64(cp.TreeSize), int64(root.TreeSize), int64(root.TreeSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute consistency proof nodes: %v", err)
+	}
+	ids := make([]compact.NodeID, 0, len(fetches))
+	for _, fetch := range fetches {
+		ids = append(ids, fetch.ID)
+	}
+	var proof [][]byte
+	if err := a.snapshot(ctx, tree, func(tx storage.ReadOnlyLogTreeTX) error {
+		nodes, err := tx.GetMerkleNodes(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to read tree nodes: %v", err)
+		}
+		if got, want := len(nodes), len(ids); got != want {
+			return mismatchf("got %d consistency proof nodes, want %d", got, want)
+		}
+		hashes := make([][]byte, len(nodes))
+		for i, node := range nodes {
+			if id := ids[i]; node.ID != id {
+				return mismatchf("got tree node %+v, want %+v", node.ID, id)
+			}
+			hashes[i] = node.Hash
+		}
+		proof, err = merkle.Rehash(hashes, fetches, hasher.HashChildren)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := logverifier.New(hasher).VerifyConsistencyProof(int64(cp.TreeSize), int64(root.TreeSize), cp.RootHash, root.RootHash, proof); err != nil {
+		return nil, mismatchf("stored tree at size %d is inconsistent with the audited root at size %d: %v", root.TreeSize, cp.TreeSize, err)
+	}
+	return cr, nil
+}
+
+// auditLeaves extends the compact range with up to count of the following
+// leaves, and checks the stored tile nodes covering them.
+func (a *Auditor) auditLeaves(ctx context.Context, tx storage.ReadOnlyLogTreeTX, hasher hashers.LogHasher, cr *compact.Range, count uint64) error {
+	begin := cr.End()
+	leaves, err := tx.GetLeavesByRange(ctx, int64(begin), int64(count))
+	if err != nil {
+		return fmt.Errorf("failed to read leaves at %d: %v", begin, err)
+	}
+	if len(leaves) == 0 {
+		return mismatchf("missing leaf at index %d", begin)
+	}
+
+	var ids []compact.NodeID
+	var hashes [][]byte
+	visit := func(id compact.NodeID, hash []byte) {
+		ids = append(ids, id)
+		hashes = append(hashes, hash)
+	}
+	for i, leaf := range leaves {
+		index := begin + uint64(i)
+		if got := leaf.LeafIndex; got != int64(index) {
+			return mismatchf("leaf at index %d has index %d", index, got)
+		}
+		if got, want := len(leaf.MerkleLeafHash), hasher.Size(); got != want {
+			return mismatchf("leaf at index %d has hash size %d, want %d", index, got, want)
+		}
+		visit(compact.NewNodeID(0, index), leaf.MerkleLeafHash)
+		if err := cr.Append(leaf.MerkleLeafHash, visit); err != nil {
+			return fmt.Errorf("failed to append leaf %d: %v", index, err)
+		}
+	}
+
+	nodes, err := tx.GetMerkleNodes(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to read tree nodes: %v", err)
+	}
+	if got, want := len(nodes), len(ids); got != want {
+		return mismatchf("got %d tree nodes at %d, want %d", got, begin, want)
+	}
+	for i, node := range nodes {
+		if id := ids[i]; node.ID != id {
+			return mismatchf("got tree node %+v, want %+v", node.ID, id)
+		}
+		if !bytes.Equal(node.Hash, hashes[i]) {
+			return mismatchf("tree node %+v is %x, but leaves hash to %x", ids[i], node.Hash, hashes[i])
+		}
+	}
+	return nil
+}
+
+// snapshot runs f in a read-only transaction for the given tree.
+func (a *Auditor) snapshot(ctx context.Context, tree *trillian.Tree, f func(storage.ReadOnlyLogTreeTX) error) error {
+	tx, err := a.registry.LogStorage.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
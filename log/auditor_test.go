@@ -0,0 +1,328 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/merkle/compact"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+)
+
+func TestAuditLog(t *testing.T) {
+	const size = 10
+	for _, tc := range []struct {
+		desc string
+		// corrupt modifies the tree nodes and root before they are stored.
+		corrupt      func(nodes []tree.Node, root *types.LogRootV1)
+		freeze       bool
+		wantMismatch bool
+	}{
+		{desc: "ok"},
+		{
+			desc: "bad-root",
+			corrupt: func(nodes []tree.Node, root *types.LogRootV1) {
+				root.RootHash = make([]byte, 32)
+			},
+			wantMismatch: true,
+		},
+		{
+			desc: "bad-internal-node",
+			corrupt: func(nodes []tree.Node, root *types.LogRootV1) {
+				for i := range nodes {
+					if nodes[i].ID == compact.NewNodeID(2, 1) {
+						nodes[i].Hash = make([]byte, 32)
+					}
+				}
+			},
+			wantMismatch: true,
+		},
+		{
+			desc: "bad-leaf-node",
+			corrupt: func(nodes []tree.Node, root *types.LogRootV1) {
+				for i := range nodes {
+					if nodes[i].ID == compact.NewNodeID(0, 7) {
+						nodes[i].Hash = make([]byte, 32)
+					}
+				}
+			},
+			freeze:       true,
+			wantMismatch: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ts := memory.NewTreeStorage()
+			registry := extension.Registry{
+				AdminStorage: memory.NewAdminStorage(ts),
+				LogStorage:   memory.NewLogStorage(ts, nil),
+			}
+			logTree := storeLog(ctx, t, registry, size, tc.corrupt)
+
+			a := NewAuditor(registry, AuditOptions{BatchSize: 3, FreezeOnMismatch: tc.freeze})
+			err := a.AuditLog(ctx, logTree.TreeId)
+			var mismatch *MismatchError
+			if got := errors.As(err, &mismatch); got != tc.wantMismatch {
+				t.Errorf("AuditLog(): %v, want mismatch: %v", err, tc.wantMismatch)
+			} else if !got && err != nil {
+				t.Errorf("AuditLog(): %v", err)
+			}
+
+			a.auditAndReport(ctx, logTree.TreeId)
+			stored, err := storage.GetTree(ctx, registry.AdminStorage, logTree.TreeId)
+			if err != nil {
+				t.Fatalf("GetTree(): %v", err)
+			}
+			wantState := trillian.TreeState_ACTIVE
+			if tc.freeze && tc.wantMismatch {
+				wantState = trillian.TreeState_FROZEN
+			}
+			if got := stored.TreeState; got != wantState {
+				t.Errorf("TreeState=%v, want %v", got, wantState)
+			}
+		})
+	}
+}
+
+func TestAuditLogIncremental(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc string
+		// change modifies the log after the first audit.
+		change       func(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree)
+		wantMismatch bool
+	}{
+		{
+			desc: "unchanged",
+		},
+		{
+			desc: "grown",
+			change: func(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree) {
+				appendLeaves(ctx, t, registry, logTree, 10, 16, nil)
+			},
+		},
+		{
+			desc: "grown-with-changed-history",
+			change: func(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree) {
+				appendLeaves(ctx, t, registry, logTree, 10, 16, nil)
+				// The consistency proof from size 10 uses the node covering the
+				// first 8 leaves, which were audited before.
+				overwriteNode(ctx, t, registry, logTree, compact.NewNodeID(3, 0))
+			},
+			wantMismatch: true,
+		},
+		{
+			desc: "rolled-back",
+			change: func(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree) {
+				var root types.LogRootV1
+				if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+					rev, err := tx.WriteRevision(ctx)
+					if err != nil {
+						return err
+					}
+					root = types.LogRootV1{TreeSize: 8, RootHash: make([]byte, 32), TimestampNanos: 2, Revision: uint64(rev)}
+					logRoot, err := root.MarshalBinary()
+					if err != nil {
+						return err
+					}
+					return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+				}); err != nil {
+					t.Fatalf("StoreSignedLogRoot(): %v", err)
+				}
+			},
+			wantMismatch: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := memory.NewTreeStorage()
+			registry := extension.Registry{
+				AdminStorage: memory.NewAdminStorage(ts),
+				LogStorage:   memory.NewLogStorage(ts, nil),
+			}
+			logTree := storeLog(ctx, t, registry, 10, nil)
+
+			cps, err := NewFileAuditCheckpoints(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFileAuditCheckpoints(): %v", err)
+			}
+			opts := AuditOptions{BatchSize: 3, Checkpoints: cps}
+			if err := NewAuditor(registry, opts).AuditLog(ctx, logTree.TreeId); err != nil {
+				t.Fatalf("AuditLog(): %v", err)
+			}
+			if tc.change != nil {
+				tc.change(ctx, t, registry, logTree)
+			}
+
+			// A new Auditor, e.g. after a restart, carries on from the checkpoint.
+			err = NewAuditor(registry, opts).AuditLog(ctx, logTree.TreeId)
+			var mismatch *MismatchError
+			if got := errors.As(err, &mismatch); got != tc.wantMismatch {
+				t.Fatalf("AuditLog(): %v, want mismatch: %v", err, tc.wantMismatch)
+			} else if !got && err != nil {
+				t.Fatalf("AuditLog(): %v", err)
+			}
+
+			cp, err := cps.Get(ctx, logTree.TreeId)
+			if err != nil {
+				t.Fatalf("Get(): %v", err)
+			}
+			var root types.LogRootV1
+			if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+				slr, err := tx.LatestSignedLogRoot(ctx)
+				if err != nil {
+					return err
+				}
+				return root.UnmarshalBinary(slr.LogRoot)
+			}); err != nil {
+				t.Fatalf("LatestSignedLogRoot(): %v", err)
+			}
+			// The checkpoint only moves forward after a successful audit.
+			wantSize := root.TreeSize
+			if tc.wantMismatch {
+				wantSize = 10
+			}
+			if got := cp.TreeSize; got != wantSize {
+				t.Errorf("checkpoint TreeSize=%d, want %d", got, wantSize)
+			}
+		})
+	}
+}
+
+// storeLog creates a log with the given number of leaves, and stores its tree
+// nodes and root the way the sequencer would.
+func storeLog(ctx context.Context, t *testing.T, registry extension.Registry, size int, corrupt func([]tree.Node, *types.LogRootV1)) *trillian.Tree {
+	t.Helper()
+	logTree, err := storage.CreateTree(ctx, registry.AdminStorage, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	hasher := rfc6962.DefaultHasher
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := (&types.LogRootV1{RootHash: hasher.EmptyRoot()}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	appendLeaves(ctx, t, registry, logTree, 0, size, corrupt)
+	return logTree
+}
+
+// appendLeaves grows the log from the given size to the given size, and
+// stores the new tree nodes and root the way the sequencer would.
+func appendLeaves(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree, from, size int, corrupt func([]tree.Node, *types.LogRootV1)) {
+	t.Helper()
+	hasher := rfc6962.DefaultHasher
+	leaves := make([]*trillian.LogLeaf, 0, size-from)
+	for i := from; i < size; i++ {
+		hash := hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i)))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash, MerkleLeafHash: hash})
+	}
+	if _, err := registry.LogStorage.QueueLeaves(ctx, logTree, leaves, time.Unix(10, 0)); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, size-from, time.Unix(20, 0))
+		if err != nil {
+			return err
+		}
+		var nodes []tree.Node
+		store := func(id compact.NodeID, hash []byte) { nodes = append(nodes, tree.Node{ID: id, Hash: hash}) }
+		cr := (&compact.RangeFactory{Hash: hasher.HashChildren}).NewEmptyRange(0)
+		for i := 0; i < from; i++ {
+			if err := cr.Append(hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i))), nil); err != nil {
+				return err
+			}
+		}
+		for i, leaf := range dequeued {
+			leaf.LeafIndex = int64(from + i)
+			store(compact.NewNodeID(0, uint64(from+i)), leaf.MerkleLeafHash)
+			if err := cr.Append(leaf.MerkleLeafHash, store); err != nil {
+				return err
+			}
+		}
+		rootHash, err := cr.GetRootHash(store)
+		if err != nil {
+			return err
+		}
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		root := &types.LogRootV1{TreeSize: uint64(size), RootHash: rootHash, TimestampNanos: 1, Revision: uint64(rev)}
+		if corrupt != nil {
+			corrupt(nodes, root)
+		}
+		if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+			return err
+		}
+		if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+			return err
+		}
+		logRoot, err := root.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
+
+// overwriteNode replaces the stored hash of the given tree node, and re-stores
+// the latest root so that reads see the new hash.
+func overwriteNode(ctx context.Context, t *testing.T, registry extension.Registry, logTree *trillian.Tree, id compact.NodeID) {
+	t.Helper()
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return err
+		}
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		if err := tx.SetMerkleNodes(ctx, []tree.Node{{ID: id, Hash: make([]byte, 32)}}); err != nil {
+			return err
+		}
+		root.Revision, root.TimestampNanos = uint64(rev), root.TimestampNanos+1
+		logRoot, err := root.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}); err != nil {
+		t.Fatalf("overwriteNode(): %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	compactorOnce      sync.Once
+	compactionRuns     monitoring.Counter
+	compactionFailures monitoring.Counter
+	compactedSubtrees  monitoring.Counter
+)
+
+func createCompactorMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	compactionRuns = mf.NewCounter("subtree_compaction_runs", "Number of completed subtree compactions", logIDLabel)
+	compactionFailures = mf.NewCounter("subtree_compaction_failures", "Number of subtree compactions which failed to complete", logIDLabel)
+	compactedSubtrees = mf.NewCounter("compacted_subtree_revisions", "Number of superseded subtree revisions deleted by compactions", logIDLabel)
+}
+
+// CompactionOptions configures a Compactor.
+type CompactionOptions struct {
+	// Interval is the time between the starts of consecutive compaction
+	// passes over all the held logs.
+	Interval time.Duration
+	// Retention is how long a signed root remains readable after a later one
+	// is stored. Readers holding snapshots at a revision for longer than
+	// this may find subtrees missing.
+	Retention time.Duration
+	// BatchSize is the number of subtrees compacted per storage transaction.
+	BatchSize int
+	// BatchPause is the time to wait between transactions, to limit the load
+	// on the storage.
+	BatchPause time.Duration
+	// TimeSource is used for the retention and pacing, and may be mocked out
+	// by tests.
+	TimeSource clock.TimeSource
+}
+
+// Compactor periodically deletes the stored subtree revisions of logs which
+// are superseded by later revisions, and so can't be read at the revision of
+// any retained signed root.
+//
+// A root is retained if it was the latest root at some point in the last
+// Retention, so the revision of the latest root which is older than that is
+// the oldest one which stays readable.
+type Compactor struct {
+	registry extension.Registry
+	opts     CompactionOptions
+}
+
+// Compaction doesn't change what can be read from a tree, so frozen logs can
+// be compacted too.
+var compactOpts = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// NewCompactor creates a new Compactor for the logs in the given registry.
+func NewCompactor(registry extension.Registry, opts CompactionOptions) *Compactor {
+	compactorOnce.Do(func() {
+		createCompactorMetrics(registry.MetricFactory)
+	})
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	return &Compactor{registry: registry, opts: opts}
+}
+
+// Run compacts the logs returned by held, e.g. the ones that this instance is
+// the master for, every Interval. It runs until the context is canceled.
+func (c *Compactor) Run(ctx context.Context, held func() []int64) {
+	for {
+		start := c.opts.TimeSource.Now()
+		for _, logID := range held() {
+			c.compactAndReport(ctx, logID)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		wait := c.opts.Interval - c.opts.TimeSource.Now().Sub(start)
+		if err := clock.SleepSource(ctx, wait, c.opts.TimeSource); err != nil {
+			return
+		}
+	}
+}
+
+// compactAndReport compacts the given log, and records the outcome.
+func (c *Compactor) compactAndReport(ctx context.Context, logID int64) {
+	label := strconv.FormatInt(logID, 10)
+	n, err := c.CompactLog(ctx, logID)
+	compactedSubtrees.Add(float64(n), label)
+	if err != nil {
+		if ctx.Err() == nil {
+			glog.Warningf("%v: subtree compaction did not complete after deleting %d revisions: %v", logID, n, err)
+			compactionFailures.Inc(label)
+		}
+		return
+	}
+	glog.V(1).Infof("%v: subtree compaction deleted %d revisions", logID, n)
+	compactionRuns.Inc(label)
+}
+
+// CompactLog deletes the superseded subtree revisions of the given log which
+// are not needed to read any retained signed root. It returns the number of
+// revisions deleted, which is non-zero even on error if some transactions
+// were committed.
+func (c *Compactor) CompactLog(ctx context.Context, logID int64) (int, error) {
+	tree, err := trees.GetTree(ctx, c.registry.AdminStorage, logID, compactOpts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tree: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	cutoff := c.opts.TimeSource.Now().Add(-c.opts.Retention)
+	var revision int64
+	if err := c.transaction(ctx, tree, func(ctx context.Context, tx storage.SubtreeCompactionTX) error {
+		var err error
+		revision, err = tx.RevisionAt(ctx, cutoff)
+		return err
+	}); err != nil {
+		return 0, status.Errorf(status.Code(err), "failed to find revision at %v: %v", cutoff, err)
+	}
+	if revision < 0 {
+		// No root is old enough, so all of them are retained.
+		return 0, nil
+	}
+
+	var after []byte
+	total := 0
+	for {
+		var last []byte
+		var n int
+		if err := c.transaction(ctx, tree, func(ctx context.Context, tx storage.SubtreeCompactionTX) error {
+			var err error
+			last, n, err = tx.CompactSubtrees(ctx, revision, after, c.opts.BatchSize)
+			return err
+		}); err != nil {
+			return total, fmt.Errorf("failed to compact subtrees at revision %d: %v", revision, err)
+		}
+		total += n
+		if last == nil {
+			return total, nil
+		}
+		after = last
+
+		if c.opts.BatchPause > 0 {
+			if err := clock.SleepSource(ctx, c.opts.BatchPause, c.opts.TimeSource); err != nil {
+				return total, err
+			}
+		}
+	}
+}
+
+// transaction runs f in a read-write transaction for the given tree.
+func (c *Compactor) transaction(ctx context.Context, tree *trillian.Tree, f func(context.Context, storage.SubtreeCompactionTX) error) error {
+	return c.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		compactTX, ok := tx.(storage.SubtreeCompactionTX)
+		if !ok {
+			return status.Error(codes.Unimplemented, "storage does not support subtree compaction")
+		}
+		return f(ctx, compactTX)
+	})
+}
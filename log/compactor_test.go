@@ -0,0 +1,152 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeCompactionTX is a SubtreeCompactionTX over a sorted list of subtree
+// IDs, each of which has two superseded revisions.
+type fakeCompactionTX struct {
+	storage.LogTreeTX
+	revision int64
+	subtrees [][]byte
+
+	gotCutoff    time.Time
+	gotRevisions []int64
+	gotAfter     [][]byte
+}
+
+func (f *fakeCompactionTX) RevisionAt(ctx context.Context, ts time.Time) (int64, error) {
+	f.gotCutoff = ts
+	return f.revision, nil
+}
+
+func (f *fakeCompactionTX) CompactSubtrees(ctx context.Context, revision int64, after []byte, limit int) ([]byte, int, error) {
+	f.gotRevisions = append(f.gotRevisions, revision)
+	f.gotAfter = append(f.gotAfter, after)
+	var batch [][]byte
+	for _, id := range f.subtrees {
+		if bytes.Compare(id, after) > 0 && len(batch) < limit {
+			batch = append(batch, id)
+		}
+	}
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+	return batch[len(batch)-1], 2 * len(batch), nil
+}
+
+// fakeCompactionStorage runs all read-write transactions with its tx.
+type fakeCompactionStorage struct {
+	storage.LogStorage
+	tx storage.LogTreeTX
+}
+
+func (f fakeCompactionStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, fn storage.LogTXFunc) error {
+	return fn(ctx, f.tx)
+}
+
+func TestCompactLog(t *testing.T) {
+	now := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		desc        string
+		revision    int64
+		wantDeleted int
+		wantAfter   [][]byte
+	}{
+		{
+			desc:        "compact",
+			revision:    7,
+			wantDeleted: 10,
+			wantAfter:   [][]byte{nil, []byte("b"), []byte("d"), []byte("e")},
+		},
+		{
+			desc:     "nothing-retained-yet",
+			revision: -1,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ts := memory.NewTreeStorage()
+			as := memory.NewAdminStorage(ts)
+			logTree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+			if err != nil {
+				t.Fatalf("CreateTree(): %v", err)
+			}
+			tx := &fakeCompactionTX{
+				revision: tc.revision,
+				subtrees: [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")},
+			}
+			registry := extension.Registry{
+				AdminStorage: as,
+				LogStorage:   fakeCompactionStorage{LogStorage: memory.NewLogStorage(ts, nil), tx: tx},
+			}
+			c := NewCompactor(registry, CompactionOptions{
+				Retention:  time.Hour,
+				BatchSize:  2,
+				TimeSource: clock.NewFake(now),
+			})
+
+			n, err := c.CompactLog(ctx, logTree.TreeId)
+			if err != nil {
+				t.Fatalf("CompactLog(): %v", err)
+			}
+			if n != tc.wantDeleted {
+				t.Errorf("CompactLog()=%d, want %d", n, tc.wantDeleted)
+			}
+			if got, want := tx.gotCutoff, now.Add(-time.Hour); !got.Equal(want) {
+				t.Errorf("RevisionAt(%v), want %v", got, want)
+			}
+			if diff := cmp.Diff(tc.wantAfter, tx.gotAfter); diff != "" {
+				t.Errorf("CompactSubtrees() after diff (-want +got):\n%s", diff)
+			}
+			for _, rev := range tx.gotRevisions {
+				if rev != tc.revision {
+					t.Errorf("CompactSubtrees(%d), want revision %d", rev, tc.revision)
+				}
+			}
+		})
+	}
+}
+
+func TestCompactLogUnsupported(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	logTree := storeLog(ctx, t, registry, 3, nil)
+
+	c := NewCompactor(registry, CompactionOptions{Retention: time.Hour})
+	if _, err := c.CompactLog(ctx, logTree.TreeId); status.Code(err) != codes.Unimplemented {
+		t.Errorf("CompactLog(): %v, want %v", err, codes.Unimplemented)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election2"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -46,8 +47,14 @@ var (
 	failedSigningRuns monitoring.Counter
 	entriesAdded      monitoring.Counter
 	batchesAdded      monitoring.Counter
+	rebalances        monitoring.Counter
 )
 
+// loadDecay is the weight of the previous estimate when updating the
+// exponentially weighted moving average of the number of entries that each
+// log processes per pass.
+const loadDecay = 0.9
+
 func createMetrics(mf monitoring.MetricFactory) {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
@@ -68,6 +75,7 @@ func createMetrics(mf monitoring.MetricFactory) {
 	// entriesAdded / batchesAdded is average batch size. These can be used for
 	// tuning sequencing or evaluating performance.
 	batchesAdded = mf.NewCounter("batches_added", "Number of times a non zero number of entries was added", logIDLabel)
+	rebalances = mf.NewCounter("rebalance_resignations", "Number of resignations requested to balance load between instances", logIDLabel)
 }
 
 // Operation defines a task that operates on a log. Examples are scheduling, signing,
@@ -105,6 +113,27 @@ type OperationInfo struct {
 	// Timeout sets an optional timeout on each operation run.
 	// If unset, default to the value of DefaultTimeout.
 	Timeout time.Duration
+
+	// RebalanceTolerance is how far above its fair share of the total load an
+	// instance may go before it resigns mastership of some of its logs, as a
+	// fraction of the fair share. Zero disables rebalancing. Rebalancing also
+	// requires the election factory to implement election2.LoadBoard.
+	RebalanceTolerance float64
+	// RebalanceInterval is the minimum time between load rebalancing checks.
+	RebalanceInterval time.Duration
+	// RebalanceHold is the minimum time that an instance holds mastership of a
+	// log before it may resign it to rebalance load. This stops logs flapping
+	// between instances whose loads are close to their fair shares.
+	RebalanceHold time.Duration
+
+	// QueueNotifications optionally delivers notifications of newly queued
+	// leaves (see storage.QueueWatcher), which start the next pass without
+	// waiting for the rest of RunInterval.
+	QueueNotifications <-chan int64
+	// MinRunInterval is the minimum time between starting batches when a
+	// queue notification starts the next pass early, so that leaves queued
+	// in a steady stream are still processed in batches.
+	MinRunInterval time.Duration
 }
 
 // OperationManager controls scheduling activities for logs.
@@ -118,6 +147,10 @@ type OperationManager struct {
 	runnerWG sync.WaitGroup
 	// runnerCancels contains cancel function for each logID election Runner.
 	runnerCancels map[string]context.CancelFunc
+	// runners contains the current election Runner for each logID.
+	runners map[string]*election.Runner
+	// runnersMutex guards the runners field.
+	runnersMutex sync.Mutex
 	// pendingResignations delivers resignation requests from election Runners.
 	pendingResignations chan election.Resignation
 
@@ -129,6 +162,13 @@ type OperationManager struct {
 	lastHeld []int64
 	// idsMutex guards logNames and lastHeld fields.
 	idsMutex sync.Mutex
+
+	// Moving average of entries processed per pass, by logID.
+	load map[int64]float64
+	// The time that this instance became the master, by logID.
+	heldSince map[int64]time.Time
+	// The time of the last load rebalancing check.
+	lastRebalance time.Time
 }
 
 // NewOperationManager creates a new OperationManager instance.
@@ -150,9 +190,12 @@ func NewOperationManager(info OperationInfo, logOperation Operation) *OperationM
 		info:                info,
 		logOperation:        logOperation,
 		runnerCancels:       make(map[string]context.CancelFunc),
+		runners:             make(map[string]*election.Runner),
 		pendingResignations: make(chan election.Resignation, 100),
 		tracker:             tracker,
 		logNames:            make(map[int64]string),
+		load:                make(map[int64]float64),
+		heldSince:           make(map[int64]time.Time),
 	}
 }
 
@@ -224,13 +267,25 @@ func (o *OperationManager) masterFor(ctx context.Context, allIDs []int64) ([]int
 	}
 
 	// Synchronize the set of log IDs with those we are tracking mastership for.
+	active := make(map[string]bool, len(allStringIDs))
 	for _, logID := range allStringIDs {
+		active[logID] = true
 		knownLogs.Set(1, logID)
 		if o.runnerCancels[logID] == nil {
 			o.tracker.Set(logID, false) // Initialise tracking for this ID.
 			o.runnerCancels[logID] = o.runElectionWithRestarts(ctx, logID)
 		}
 	}
+	// Stop the elections for the logs which are no longer active, e.g. deleted
+	// or frozen ones, which also resigns mastership of them.
+	for logID, cancel := range o.runnerCancels {
+		if !active[logID] {
+			glog.Infof("%s: stop master election for inactive log", logID)
+			cancel()
+			delete(o.runnerCancels, logID)
+			knownLogs.Set(0, logID)
+		}
+	}
 
 	held := o.tracker.Held()
 	heldIDs := make([]int64, 0, len(allIDs))
@@ -270,7 +325,15 @@ func (o *OperationManager) runElectionWithRestarts(ctx context.Context, logID st
 		config := o.info.ElectionConfig
 		// TODO(pavelkalinnikov): Passing the cancel function is not needed here.
 		r := election.NewRunner(logID, &config, o.tracker, cancel, e)
+		o.runnersMutex.Lock()
+		o.runners[logID] = r
+		o.runnersMutex.Unlock()
 		r.Run(ctx, o.pendingResignations)
+		o.runnersMutex.Lock()
+		if o.runners[logID] == r {
+			delete(o.runners, logID)
+		}
+		o.runnersMutex.Unlock()
 	}
 	o.runnerWG.Add(1)
 	go func(ctx context.Context) {
@@ -308,6 +371,16 @@ func (o *OperationManager) updateHeldIDs(ctx context.Context, logIDs, activeIDs
 	}
 }
 
+// HeldLogIDs returns the IDs of the active logs that this instance was the
+// master for as of the last pass.
+func (o *OperationManager) HeldLogIDs() []int64 {
+	o.idsMutex.Lock()
+	defer o.idsMutex.Unlock()
+	ret := make([]int64, len(o.lastHeld))
+	copy(ret, o.lastHeld)
+	return ret
+}
+
 func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
 	runCtx, cancel := context.WithTimeout(ctx, o.info.Timeout)
 	defer cancel()
@@ -318,17 +391,86 @@ func (o *OperationManager) getLogsAndExecutePass(ctx context.Context) error {
 	}
 	// Find the logs we are master for, skipping those logs that are not active,
 	// e.g. deleted or FROZEN ones.
-	// TODO(pavelkalinnikov): Resign mastership for the inactive logs.
 	logIDs, err := o.masterFor(ctx, activeIDs)
 	if err != nil {
 		return fmt.Errorf("failed to determine log IDs we're master for: %v", err)
 	}
 	o.updateHeldIDs(ctx, logIDs, activeIDs)
 
-	executePassForAll(runCtx, &o.info, o.logOperation, logIDs)
+	counts := executePassForAll(runCtx, &o.info, o.logOperation, logIDs)
+	o.updateLoad(logIDs, counts)
+	o.rebalance(runCtx, logIDs)
 	return nil
 }
 
+// updateLoad folds the number of entries processed by each of the given logs
+// in the last pass into the moving averages, records when this instance became
+// the master for each of them, and forgets about the logs which this instance
+// is no longer the master for.
+func (o *OperationManager) updateLoad(logIDs []int64, counts map[int64]int) {
+	now := o.info.TimeSource.Now()
+	load := make(map[int64]float64, len(logIDs))
+	heldSince := make(map[int64]time.Time, len(logIDs))
+	for _, logID := range logIDs {
+		load[logID] = loadDecay*o.load[logID] + (1-loadDecay)*float64(counts[logID])
+		if since, ok := o.heldSince[logID]; ok {
+			heldSince[logID] = since
+		} else {
+			heldSince[logID] = now
+		}
+	}
+	o.load = load
+	o.heldSince = heldSince
+}
+
+// rebalance publishes the load of this instance, and requests resignation of
+// mastership for some of the held logs if the instance is overloaded compared
+// to the others.
+func (o *OperationManager) rebalance(ctx context.Context, logIDs []int64) {
+	board, ok := o.info.Registry.ElectionFactory.(election2.LoadBoard)
+	if !ok || o.info.RebalanceTolerance <= 0 {
+		return
+	}
+	now := o.info.TimeSource.Now()
+	if now.Sub(o.lastRebalance) < o.info.RebalanceInterval {
+		return
+	}
+	o.lastRebalance = now
+
+	// Every held log weighs at least 1, even if idle. The logs held for less
+	// than RebalanceHold are pinned to this instance.
+	held := make(map[string]float64, len(logIDs))
+	pinned := make(map[string]bool)
+	for _, logID := range logIDs {
+		id := strconv.FormatInt(logID, 10)
+		held[id] = 1 + o.load[logID]
+		if now.Sub(o.heldSince[logID]) < o.info.RebalanceHold {
+			pinned[id] = true
+		}
+	}
+	if err := board.PublishLoad(ctx, held); err != nil {
+		glog.Errorf("failed to publish load: %v", err)
+		return
+	}
+	loads, err := board.Loads(ctx)
+	if err != nil {
+		glog.Errorf("failed to read loads: %v", err)
+		return
+	}
+
+	o.runnersMutex.Lock()
+	defer o.runnersMutex.Unlock()
+	for _, logID := range election.ToResign(board.InstanceID(), loads, o.info.RebalanceTolerance, pinned) {
+		r := o.runners[logID]
+		if r == nil {
+			continue
+		}
+		glog.Infof("%s: requesting resignation to rebalance load", logID)
+		rebalances.Inc(logID)
+		r.RequestResign()
+	}
+}
+
 // OperationSingle performs a single pass of the manager.
 //
 // TODO(pavelkalinnikov): Deprecate this because it doesn't clean up any state,
@@ -410,7 +552,7 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 	wait := o.info.RunInterval - duration
 	if wait > 0 {
 		glog.V(1).Infof("Processing started at %v for %v; wait %v before next run", start, duration, wait)
-		if err := clock.SleepContext(ctx, wait); err != nil {
+		if err := o.waitForNextPass(ctx, wait, o.info.MinRunInterval-duration); err != nil {
 			return err
 		}
 	} else {
@@ -419,10 +561,41 @@ func (o *OperationManager) operateOnce(ctx context.Context) error {
 	return nil
 }
 
+// waitForNextPass waits for the given duration, or until leaves are queued if
+// queue notifications are available, but for at least minWait. Returns an
+// error only if the context is canceled.
+func (o *OperationManager) waitForNextPass(ctx context.Context, wait, minWait time.Duration) error {
+	if o.info.QueueNotifications == nil {
+		return clock.SleepContext(ctx, wait)
+	}
+	begin := time.Now()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	case treeID, ok := <-o.info.QueueNotifications:
+		if !ok {
+			// The notifications have stopped, so fall back to polling.
+			o.info.QueueNotifications = nil
+		} else {
+			glog.V(1).Infof("Woken up by queued leaves in tree %d", treeID)
+		}
+	}
+	// Don't start passes more often than MinRunInterval, however often
+	// leaves are queued.
+	return clock.SleepContext(ctx, minWait-time.Since(begin))
+}
+
 // executePassForAll runs ExecutePass of the given operation for each of the
 // passed-in logs, allowing up to a configurable number of parallel operations.
-func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, logIDs []int64) {
+// Returns the number of items processed by each log that completed its pass.
+func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, logIDs []int64) map[int64]int {
 	startBatch := info.TimeSource.Now()
+	counts := make(map[int64]int)
+	var countsMutex sync.Mutex
 
 	numWorkers := info.NumWorkers
 	if numWorkers <= 0 {
@@ -441,9 +614,14 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 		go func(logID int64) {
 			defer wg.Done()
 			defer sem.Release(1)
-			if err := executePass(ctx, info, op, logID); err != nil {
+			count, err := executePass(ctx, info, op, logID)
+			if err != nil {
 				glog.Errorf("ExecutePass(%v) failed: %v", logID, err)
+				return
 			}
+			countsMutex.Lock()
+			defer countsMutex.Unlock()
+			counts[logID] = count
 		}(logID)
 	}
 
@@ -451,16 +629,17 @@ func executePassForAll(ctx context.Context, info *OperationInfo, op Operation, l
 	wg.Wait()
 	d := clock.SecondsSince(info.TimeSource, startBatch)
 	glog.V(1).Infof("Group run completed in %.2f seconds", d)
+	return counts
 }
 
 // executePass runs ExecutePass of the given operation for the passed-in log.
-func executePass(ctx context.Context, info *OperationInfo, op Operation, logID int64) error {
+func executePass(ctx context.Context, info *OperationInfo, op Operation, logID int64) (int, error) {
 	label := strconv.FormatInt(logID, 10)
 	start := info.TimeSource.Now()
 	count, err := op.ExecutePass(ctx, logID, info)
 	if err != nil {
 		failedSigningRuns.Inc(label)
-		return err
+		return 0, err
 	}
 
 	// This indicates signing activity is proceeding on the logID.
@@ -473,5 +652,5 @@ func executePass(ctx context.Context, info *OperationInfo, op Operation, logID i
 	} else {
 		glog.V(1).Infof("%v: no items to process", logID)
 	}
-	return nil
+	return count, nil
 }
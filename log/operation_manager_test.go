@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"sync/atomic"
 	"testing"
@@ -335,6 +336,54 @@ func TestOperationManagerOperationLoopExitOnContext(t *testing.T) {
 	t.Logf("Exited operationLoop")
 }
 
+func TestOperationManagerWaitForNextPass(t *testing.T) {
+	ctx := context.Background()
+	notifications := make(chan int64, 1)
+	info := defaultOperationInfo(extension.Registry{})
+	info.QueueNotifications = notifications
+	om := &OperationManager{info: info}
+
+	// A notification ends the wait early.
+	notifications <- 451
+	if err := om.waitForNextPass(ctx, time.Hour, 0); err != nil {
+		t.Errorf("waitForNextPass() after notification = %v", err)
+	}
+
+	// A notification doesn't end the wait before the minimum.
+	notifications <- 451
+	start := time.Now()
+	if err := om.waitForNextPass(ctx, time.Hour, 50*time.Millisecond); err != nil {
+		t.Errorf("waitForNextPass() after notification = %v", err)
+	}
+	if got := time.Since(start); got < 50*time.Millisecond {
+		t.Errorf("waitForNextPass() after notification returned after %v, want at least 50ms", got)
+	}
+
+	// Without notifications, the wait lasts the full duration.
+	start = time.Now()
+	if err := om.waitForNextPass(ctx, 50*time.Millisecond, 0); err != nil {
+		t.Errorf("waitForNextPass() = %v", err)
+	}
+	if got := time.Since(start); got < 50*time.Millisecond {
+		t.Errorf("waitForNextPass() returned after %v, want at least 50ms", got)
+	}
+
+	// Closing the channel falls back to polling.
+	close(notifications)
+	if err := om.waitForNextPass(ctx, time.Hour, 0); err != nil {
+		t.Errorf("waitForNextPass() after close = %v", err)
+	}
+	if om.info.QueueNotifications != nil {
+		t.Error("QueueNotifications not reset after the channel was closed")
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := om.waitForNextPass(cctx, time.Hour, 0); err == nil {
+		t.Error("waitForNextPass() with canceled context = nil, want error")
+	}
+}
+
 func TestOperationManagerOperationLoopExecutePassError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -473,6 +522,151 @@ func TestMasterFor(t *testing.T) {
 	}
 }
 
+func TestRebalance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ids := []int64{1, 2, 3, 4}
+
+	board := &fakeLoadBoard{others: []election2.Load{{InstanceID: "other"}}}
+	info := OperationInfo{
+		Registry:           extension.Registry{ElectionFactory: board},
+		TimeSource:         clock.System,
+		RebalanceTolerance: 0.1,
+	}
+	lom := NewOperationManager(info, nil)
+	lom.masterFor(ctx, ids)
+	time.Sleep(100 * time.Millisecond)
+	held, err := lom.masterFor(ctx, ids)
+	if err != nil || !reflect.DeepEqual(held, ids) {
+		t.Fatalf("masterFor()=%v,%v; want %v,nil", held, err, ids)
+	}
+
+	// Log 4 is busier than the rest.
+	lom.updateLoad(held, map[int64]int{4: 30})
+	lom.rebalance(ctx, held)
+
+	wantHeld := map[string]float64{"1": 1, "2": 1, "3": 1, "4": 4}
+	if !reflect.DeepEqual(board.held, wantHeld) {
+		t.Errorf("published load %v; want %v", board.held, wantHeld)
+	}
+	time.Sleep(100 * time.Millisecond)
+	var got []string
+	for len(lom.pendingResignations) > 0 {
+		r := <-lom.pendingResignations
+		got = append(got, r.ID)
+		r.Execute(ctx)
+	}
+	// Fair share is 3.5, so resigning log 4 would overshoot it.
+	sort.Strings(got)
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resignations=%v; want %v", got, want)
+	}
+}
+
+func TestRebalanceHold(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ids := []int64{1, 2, 3, 4}
+
+	fakeTime := clock.NewFake(time.Now())
+	board := &fakeLoadBoard{others: []election2.Load{{InstanceID: "other"}}}
+	info := OperationInfo{
+		Registry:           extension.Registry{ElectionFactory: board},
+		TimeSource:         fakeTime,
+		RebalanceTolerance: 0.1,
+		RebalanceHold:      10 * time.Minute,
+	}
+	lom := NewOperationManager(info, nil)
+	lom.masterFor(ctx, ids)
+	time.Sleep(100 * time.Millisecond)
+	held, err := lom.masterFor(ctx, ids)
+	if err != nil || !reflect.DeepEqual(held, ids) {
+		t.Fatalf("masterFor()=%v,%v; want %v,nil", held, err, ids)
+	}
+
+	resignations := func() []string {
+		time.Sleep(100 * time.Millisecond)
+		var got []string
+		for len(lom.pendingResignations) > 0 {
+			r := <-lom.pendingResignations
+			got = append(got, r.ID)
+			r.Execute(ctx)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	// The logs were only just acquired, so none of them may be resigned.
+	lom.updateLoad(held, nil)
+	lom.rebalance(ctx, held)
+	if got := resignations(); len(got) != 0 {
+		t.Errorf("resignations=%v; want none within the hold time", got)
+	}
+
+	fakeTime.Set(fakeTime.Now().Add(11 * time.Minute))
+	lom.updateLoad(held, nil)
+	lom.rebalance(ctx, held)
+	if got, want := resignations(), []string{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resignations=%v; want %v", got, want)
+	}
+}
+
+func TestMasterForStopsInactiveLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := OperationInfo{
+		Registry:   extension.Registry{ElectionFactory: alwaysMasterFactory{}},
+		TimeSource: clock.System,
+	}
+	lom := NewOperationManager(info, nil)
+	lom.masterFor(ctx, []int64{1, 2, 3})
+	time.Sleep(100 * time.Millisecond)
+
+	// Log 2 is no longer active, e.g. it was deleted.
+	lom.masterFor(ctx, []int64{1, 3})
+	time.Sleep(100 * time.Millisecond)
+	held, err := lom.masterFor(ctx, []int64{1, 3})
+	if want := []int64{1, 3}; err != nil || !reflect.DeepEqual(held, want) {
+		t.Fatalf("masterFor()=%v,%v; want %v,nil", held, err, want)
+	}
+	if _, ok := lom.runnerCancels["2"]; ok {
+		t.Error("election of inactive log 2 is still running")
+	}
+	lom.runnersMutex.Lock()
+	defer lom.runnersMutex.Unlock()
+	if _, ok := lom.runners["2"]; ok {
+		t.Error("runner of inactive log 2 was not removed")
+	}
+	if got, want := len(lom.runners), 2; got != want {
+		t.Errorf("len(runners)=%d; want %d", got, want)
+	}
+	if held := lom.tracker.Held(); !reflect.DeepEqual(held, []string{"1", "3"}) {
+		t.Errorf("tracker.Held()=%v; want [1 3]", held)
+	}
+}
+
+// fakeLoadBoard is an election factory that is always the master, and shares
+// its load with a fixed set of other instances.
+type fakeLoadBoard struct {
+	alwaysMasterFactory
+	others []election2.Load
+	held   map[string]float64
+}
+
+func (b *fakeLoadBoard) InstanceID() string {
+	return "self"
+}
+
+func (b *fakeLoadBoard) PublishLoad(ctx context.Context, held map[string]float64) error {
+	b.held = held
+	return nil
+}
+
+func (b *fakeLoadBoard) Loads(ctx context.Context) ([]election2.Load, error) {
+	return append(b.others, election2.Load{InstanceID: "self", Held: b.held}), nil
+}
+
 type alwaysMasterFactory struct{}
 
 func (m alwaysMasterFactory) NewElection(ctx context.Context, treeID string) (election2.Election, error) {
@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/trillian"
+)
+
+// FilePublisher is a RootPublisher which appends every root to a local file,
+// one JSON-encoded Root per line.
+type FilePublisher struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFilePublisher opens (or creates) the file at path for appending.
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", path, err)
+	}
+	return &FilePublisher{f: f}, nil
+}
+
+// PublishRoot appends the root to the file, and syncs it to disk.
+func (p *FilePublisher) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	b, err := marshalRoot(treeID, root)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to append root: %v", err)
+	}
+	return p.f.Sync()
+}
+
+// Close closes the underlying file.
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.f.Close()
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/google/trillian"
+)
+
+// HTTPPublisher is a RootPublisher which POSTs every root, as a JSON-encoded
+// Root, to a fixed URL. Any non-2xx response is treated as a failure, and 4xx
+// responses other than 408 and 429 as a permanent one.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher creates a publisher which POSTs roots to url using client.
+// Roots may be published on the sequencing path, so the client must have a
+// timeout.
+func NewHTTPPublisher(url string, client *http.Client) (*HTTPPublisher, error) {
+	if client == nil || client.Timeout <= 0 {
+		return nil, errors.New("HTTP publisher requires a client with a timeout")
+	}
+	return &HTTPPublisher{url: url, client: client}, nil
+}
+
+// PublishRoot POSTs the root to the configured URL.
+func (p *HTTPPublisher) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	b, err := marshalRoot(treeID, root)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to POST root to %s: %v", p.url, err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("POST root to %s: got HTTP status %q", p.url, resp.Status)
+		if permanentStatus(resp.StatusCode) {
+			return &PermanentError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// permanentStatus returns whether a request which got the given HTTP status
+// will get it again when it is retried.
+func permanentStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return code >= 400 && code <= 499
+}
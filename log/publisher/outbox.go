@@ -0,0 +1,254 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client/backoff"
+)
+
+const (
+	entrySuffix = ".json"
+	tmpSuffix   = ".tmp"
+	badSuffix   = ".bad"
+)
+
+// Outbox is a RootPublisher which durably records roots in a local directory,
+// and delivers them to another RootPublisher in the background. Delivery is
+// retried with backoff until it succeeds, so every root accepted by the Outbox
+// is delivered at least once, including across process restarts. Roots which
+// fail with a *PermanentError are moved aside, with a .bad suffix, so that they
+// don't hold up the roots after them.
+type Outbox struct {
+	dir     string
+	pub     RootPublisher
+	backoff backoff.Backoff
+
+	mu   sync.Mutex
+	next uint64
+	wake chan struct{}
+}
+
+// NewOutbox creates an Outbox which persists roots in dir, and delivers them
+// to pub once Run is called. Roots left over in dir from a previous process
+// are delivered first.
+func NewOutbox(dir string, pub RootPublisher) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %v", err)
+	}
+	o := &Outbox{
+		dir: dir,
+		pub: pub,
+		backoff: backoff.Backoff{
+			Min:    100 * time.Millisecond,
+			Max:    time.Minute,
+			Factor: 2,
+			Jitter: true,
+		},
+		wake: make(chan struct{}, 1),
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if strings.HasSuffix(name, tmpSuffix) {
+			// A partially written entry, which was never acknowledged.
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if seq, ok := entrySeq(name); ok && seq >= o.next {
+			o.next = seq + 1
+		}
+	}
+	// Deliver any left over entries as soon as Run starts.
+	o.signal()
+	return o, nil
+}
+
+// PublishRoot persists the root in the outbox directory. It returns once the
+// root is safely on disk, and does not wait for the delivery.
+func (o *Outbox) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	b, err := marshalRoot(treeID, root)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	seq := o.next
+	o.next++
+	o.mu.Unlock()
+
+	name := filepath.Join(o.dir, entryName(seq))
+	if err := writeFileSync(name+tmpSuffix, b); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %v", err)
+	}
+	if err := os.Rename(name+tmpSuffix, name); err != nil {
+		return fmt.Errorf("failed to commit outbox entry: %v", err)
+	}
+	if err := syncDir(o.dir); err != nil {
+		return err
+	}
+	o.signal()
+	return nil
+}
+
+// Run delivers the persisted roots in order of their acceptance, until the
+// context is done.
+func (o *Outbox) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		}
+		names, err := o.pending()
+		if err != nil {
+			glog.Errorf("Outbox: failed to list pending roots: %v", err)
+			continue
+		}
+		for _, name := range names {
+			if err := o.deliver(ctx, name); err != nil {
+				glog.Warningf("Outbox: %v", err)
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pending returns the number of roots which are yet to be delivered.
+func (o *Outbox) Pending() (int, error) {
+	names, err := o.pending()
+	return len(names), err
+}
+
+// deliver publishes a single entry, retrying until success or until the
+// context is done, and then removes it from the outbox.
+func (o *Outbox) deliver(ctx context.Context, name string) error {
+	path := filepath.Join(o.dir, name)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	var r Root
+	if err := json.Unmarshal(b, &r); err != nil {
+		// This entry can never be delivered, so move it out of the way.
+		glog.Errorf("Outbox: discarding malformed entry %s: %v", name, err)
+		return os.Rename(path, path+badSuffix)
+	}
+
+	bo := o.backoff
+	if err := bo.Retry(ctx, func() error {
+		err := o.pub.PublishRoot(ctx, r.TreeID, r.SignedLogRoot())
+		var permErr *PermanentError
+		if err == nil || errors.As(err, &permErr) {
+			return err
+		}
+		glog.Warningf("Outbox: failed to publish root for tree %d (will retry): %v", r.TreeID, err)
+		return backoff.RetriableError(err.Error())
+	}); err != nil {
+		var permErr *PermanentError
+		if errors.As(err, &permErr) {
+			glog.Errorf("Outbox: discarding rejected entry %s: %v", name, err)
+			return os.Rename(path, path+badSuffix)
+		}
+		return fmt.Errorf("failed to publish %s: %v", name, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove delivered entry %s: %v", name, err)
+	}
+	return nil
+}
+
+// pending returns the names of the persisted entries, in order.
+func (o *Outbox) pending() ([]string, error) {
+	files, err := ioutil.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, fi := range files {
+		if _, ok := entrySeq(fi.Name()); ok {
+			names = append(names, fi.Name())
+		}
+	}
+	// Entry names are zero-padded, so lexicographic order is sequence order.
+	sort.Strings(names)
+	return names, nil
+}
+
+func (o *Outbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func entryName(seq uint64) string {
+	return fmt.Sprintf("%020d%s", seq, entrySuffix)
+}
+
+func entrySeq(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, entrySuffix) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(name, entrySuffix), 10, 64)
+	return seq, err == nil
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
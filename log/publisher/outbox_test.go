@@ -0,0 +1,186 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+)
+
+// fakePublisher records the published roots, and fails the first failures
+// calls to PublishRoot. The roots of the rejected tree fail permanently.
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	rejected  int64
+	published []int64
+	done      chan struct{}
+	want      int
+}
+
+func (f *fakePublisher) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rejected != 0 && treeID == f.rejected {
+		return &PermanentError{Err: errors.New("rejected")}
+	}
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("injected failure")
+	}
+	f.published = append(f.published, treeID)
+	if len(f.published) == f.want {
+		close(f.done)
+	}
+	return nil
+}
+
+func newOutbox(t *testing.T, dir string, pub RootPublisher) *Outbox {
+	t.Helper()
+	o, err := NewOutbox(dir, pub)
+	if err != nil {
+		t.Fatalf("NewOutbox(): %v", err)
+	}
+	o.backoff.Min = time.Millisecond
+	o.backoff.Max = 10 * time.Millisecond
+	return o
+}
+
+func TestOutboxDeliversInOrderWithRetries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pub := &fakePublisher{failures: 3, want: 3, done: make(chan struct{})}
+	o := newOutbox(t, dir, pub)
+	for _, id := range []int64{1, 2, 3} {
+		if err := o.PublishRoot(ctx, id, &trillian.SignedLogRoot{LogRoot: []byte{byte(id)}}); err != nil {
+			t.Fatalf("PublishRoot(%d): %v", id, err)
+		}
+	}
+	go o.Run(ctx)
+
+	select {
+	case <-pub.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+	cancel()
+
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	for i, want := range []int64{1, 2, 3} {
+		if got := pub.published[i]; got != want {
+			t.Errorf("published[%d]=%d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestOutboxReplaysAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	// Accept roots, but never deliver them, as if the process crashed.
+	o := newOutbox(t, dir, &fakePublisher{})
+	for _, id := range []int64{10, 20} {
+		if err := o.PublishRoot(ctx, id, &trillian.SignedLogRoot{}); err != nil {
+			t.Fatalf("PublishRoot(%d): %v", id, err)
+		}
+	}
+	if n, err := o.Pending(); err != nil || n != 2 {
+		t.Fatalf("Pending()=%d, %v, want 2, nil", n, err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pub := &fakePublisher{want: 3, done: make(chan struct{})}
+	o = newOutbox(t, dir, pub)
+	if err := o.PublishRoot(ctx, 30, &trillian.SignedLogRoot{}); err != nil {
+		t.Fatalf("PublishRoot(30): %v", err)
+	}
+	go o.Run(cctx)
+
+	select {
+	case <-pub.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+	cancel()
+
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	for i, want := range []int64{10, 20, 30} {
+		if got := pub.published[i]; got != want {
+			t.Errorf("published[%d]=%d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestOutboxSetsAsideRejectedRoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pub := &fakePublisher{rejected: 2, want: 2, done: make(chan struct{})}
+	o := newOutbox(t, dir, pub)
+	for _, id := range []int64{1, 2, 3} {
+		if err := o.PublishRoot(ctx, id, &trillian.SignedLogRoot{}); err != nil {
+			t.Fatalf("PublishRoot(%d): %v", id, err)
+		}
+	}
+	go o.Run(ctx)
+
+	select {
+	case <-pub.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+	cancel()
+
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	for i, want := range []int64{1, 3} {
+		if got := pub.published[i]; got != want {
+			t.Errorf("published[%d]=%d, want %d", i, got, want)
+		}
+	}
+	bad, err := filepath.Glob(filepath.Join(dir, "*"+badSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 1 {
+		t.Errorf("set aside %d entries, want 1", len(bad))
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package publisher provides a mechanism for pushing newly signed log roots
+// to systems outside of Trillian storage, e.g. gossip, witnesses or CDN
+// invalidation.
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/trillian"
+)
+
+// RootPublisher is notified of every SignedLogRoot which the sequencer has
+// successfully committed to storage.
+type RootPublisher interface {
+	// PublishRoot publishes the given root of the specified tree. Returning an
+	// error indicates that the root may not have been delivered, and that the
+	// call can be retried, unless the error is a *PermanentError.
+	PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error
+}
+
+// PermanentError is returned by a RootPublisher when retrying the publication
+// of a root can't succeed, e.g. because the receiver rejected it.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Root is the serialized form of a published root, as written by the file
+// publisher, POSTed by the HTTP publisher, and persisted in the Outbox.
+type Root struct {
+	TreeID           int64  `json:"tree_id"`
+	LogRoot          []byte `json:"log_root"`
+	LogRootSignature []byte `json:"log_root_signature"`
+}
+
+// SignedLogRoot returns the SignedLogRoot carried by r.
+func (r *Root) SignedLogRoot() *trillian.SignedLogRoot {
+	return &trillian.SignedLogRoot{LogRoot: r.LogRoot, LogRootSignature: r.LogRootSignature}
+}
+
+func marshalRoot(treeID int64, root *trillian.SignedLogRoot) ([]byte, error) {
+	return json.Marshal(&Root{
+		TreeID:           treeID,
+		LogRoot:          root.GetLogRoot(),
+		LogRootSignature: root.GetLogRootSignature(),
+	})
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+)
+
+var testRoot = &trillian.SignedLogRoot{LogRoot: []byte("root"), LogRootSignature: []byte("sig")}
+
+func TestFilePublisher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filepub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "roots")
+
+	p, err := NewFilePublisher(path)
+	if err != nil {
+		t.Fatalf("NewFilePublisher(): %v", err)
+	}
+	for _, id := range []int64{1, 2} {
+		if err := p.PublishRoot(context.Background(), id, testRoot); err != nil {
+			t.Fatalf("PublishRoot(%d): %v", id, err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []int64
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var r Root
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", s.Text(), err)
+		}
+		if got := r.SignedLogRoot(); !proto.Equal(got, testRoot) {
+			t.Errorf("root=%v, want %v", got, testRoot)
+		}
+		ids = append(ids, r.TreeID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("tree IDs=%v, want [1 2]", ids)
+	}
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	for _, tc := range []struct {
+		desc          string
+		status        int
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{desc: "ok", status: http.StatusOK},
+		{desc: "no-content", status: http.StatusNoContent},
+		{desc: "server-error", status: http.StatusInternalServerError, wantErr: true},
+		{desc: "bad-request", status: http.StatusBadRequest, wantErr: true, wantPermanent: true},
+		{desc: "too-many-requests", status: http.StatusTooManyRequests, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var got Root
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method=%s, want POST", r.Method)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("Decode(): %v", err)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			p, err := NewHTTPPublisher(ts.URL, &http.Client{Timeout: time.Second})
+			if err != nil {
+				t.Fatalf("NewHTTPPublisher(): %v", err)
+			}
+			err = p.PublishRoot(context.Background(), 42, testRoot)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("PublishRoot()=%v, wantErr %v", err, tc.wantErr)
+			}
+			var permErr *PermanentError
+			if got := errors.As(err, &permErr); got != tc.wantPermanent {
+				t.Errorf("PublishRoot()=%v, permanent: %v, want %v", err, got, tc.wantPermanent)
+			}
+			if got.TreeID != 42 || !proto.Equal(got.SignedLogRoot(), testRoot) {
+				t.Errorf("server got %+v, want tree 42 with %v", got, testRoot)
+			}
+		})
+	}
+}
+
+func TestHTTPPublisherRequiresTimeout(t *testing.T) {
+	for _, client := range []*http.Client{nil, {}} {
+		if _, err := NewHTTPPublisher("http://localhost", client); err == nil {
+			t.Errorf("NewHTTPPublisher(%v)=_,nil, want error", client)
+		}
+	}
+}
+
+// failingPublisher fails every call while err is set.
+type failingPublisher struct {
+	err error
+}
+
+func (p *failingPublisher) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	return p.err
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	root := func(rev uint64) *trillian.SignedLogRoot {
+		b, err := (&types.LogRootV1{Revision: rev}).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &trillian.SignedLogRoot{LogRoot: b}
+	}
+	pub := &failingPublisher{}
+	tr := NewTracker(pub)
+	if tr.Published(1, 0) {
+		t.Errorf("Published(1, 0)=true before publishing")
+	}
+
+	if err := tr.PublishRoot(ctx, 1, root(3)); err != nil {
+		t.Fatalf("PublishRoot(): %v", err)
+	}
+	pub.err = errors.New("publish")
+	if err := tr.PublishRoot(ctx, 1, root(4)); err == nil {
+		t.Fatal("PublishRoot()=nil, want error")
+	}
+	if err := tr.PublishRoot(ctx, 1, &trillian.SignedLogRoot{LogRoot: []byte("bad")}); err == nil {
+		t.Fatal("PublishRoot(unparseable)=nil, want error")
+	}
+	for _, tc := range []struct {
+		treeID int64
+		rev    uint64
+		want   bool
+	}{
+		{treeID: 1, rev: 2, want: true},
+		{treeID: 1, rev: 3, want: true},
+		{treeID: 1, rev: 4, want: false},
+		{treeID: 2, rev: 3, want: false},
+	} {
+		if got := tr.Published(tc.treeID, tc.rev); got != tc.want {
+			t.Errorf("Published(%d, %d)=%v, want %v", tc.treeID, tc.rev, got, tc.want)
+		}
+	}
+}
+
+func TestPersistentTracker(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "tracker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, err := (&types.LogRootV1{Revision: 3}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := NewPersistentTracker(&failingPublisher{}, dir)
+	if err != nil {
+		t.Fatalf("NewPersistentTracker(): %v", err)
+	}
+	if err := tr.PublishRoot(ctx, 1, &trillian.SignedLogRoot{LogRoot: b}); err != nil {
+		t.Fatalf("PublishRoot(): %v", err)
+	}
+
+	// A new Tracker must know about the roots published by the previous one.
+	tr, err = NewPersistentTracker(&failingPublisher{}, dir)
+	if err != nil {
+		t.Fatalf("NewPersistentTracker() after restart: %v", err)
+	}
+	for _, tc := range []struct {
+		treeID int64
+		rev    uint64
+		want   bool
+	}{
+		{treeID: 1, rev: 3, want: true},
+		{treeID: 1, rev: 4, want: false},
+		{treeID: 2, rev: 0, want: false},
+	} {
+		if got := tr.Published(tc.treeID, tc.rev); got != tc.want {
+			t.Errorf("Published(%d, %d)=%v, want %v", tc.treeID, tc.rev, got, tc.want)
+		}
+	}
+}
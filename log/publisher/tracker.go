@@ -0,0 +1,135 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+)
+
+const revisionSuffix = ".revision"
+
+// Tracker is a RootPublisher which remembers the latest revision of each tree
+// that it has published. This lets the sequencer find the roots which were
+// committed to storage but never published, e.g. because the publisher failed
+// or the process crashed right after the commit, and publish them again.
+type Tracker struct {
+	pub RootPublisher
+	// dir holds a file with the latest published revision of each tree, or
+	// is empty if the revisions are only kept in memory.
+	dir string
+
+	mu        sync.Mutex
+	revisions map[int64]uint64
+}
+
+// NewTracker creates a Tracker which publishes roots to pub. It only keeps the
+// published revisions in memory, so the latest root of each tree is published
+// again after a restart.
+func NewTracker(pub RootPublisher) *Tracker {
+	return &Tracker{pub: pub, revisions: make(map[int64]uint64)}
+}
+
+// NewPersistentTracker is like NewTracker, but records the latest published
+// revision of each tree in a file in dir, so that the roots which were
+// published before a restart are not published again.
+func NewPersistentTracker(pub RootPublisher, dir string) (*Tracker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tracker directory: %v", err)
+	}
+	t := &Tracker{pub: pub, dir: dir, revisions: make(map[int64]uint64)}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if !strings.HasSuffix(name, revisionSuffix) {
+			continue
+		}
+		treeID, err := strconv.ParseInt(strings.TrimSuffix(name, revisionSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		rev, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse published revision in %s: %v", name, err)
+		}
+		t.revisions[treeID] = rev
+	}
+	return t, nil
+}
+
+// PublishRoot publishes the root to the underlying RootPublisher, and records
+// its revision if that succeeds.
+func (t *Tracker) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.GetLogRoot()); err != nil {
+		return fmt.Errorf("failed to parse root: %v", err)
+	}
+	if err := t.pub.PublishRoot(ctx, treeID, root); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rev, ok := t.revisions[treeID]; !ok || logRoot.Revision > rev {
+		t.revisions[treeID] = logRoot.Revision
+		if err := t.persist(treeID, logRoot.Revision); err != nil {
+			// The root was published, so only its republishing after a
+			// restart is at stake.
+			glog.Warningf("Failed to record published revision %d of tree %d: %v", logRoot.Revision, treeID, err)
+		}
+	}
+	return nil
+}
+
+// persist records the latest published revision of the tree in its file, if
+// the Tracker is persistent.
+func (t *Tracker) persist(treeID int64, rev uint64) error {
+	if t.dir == "" {
+		return nil
+	}
+	name := filepath.Join(t.dir, strconv.FormatInt(treeID, 10)+revisionSuffix)
+	if err := writeFileSync(name+tmpSuffix, []byte(strconv.FormatUint(rev, 10))); err != nil {
+		return err
+	}
+	if err := os.Rename(name+tmpSuffix, name); err != nil {
+		return err
+	}
+	return syncDir(t.dir)
+}
+
+// Published returns whether the root of the given revision of the tree, or a
+// later one, has been published by this Tracker.
+func (t *Tracker) Published(treeID int64, revision uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rev, ok := t.revisions[treeID]
+	return ok && rev >= revision
+}
@@ -17,6 +17,7 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -25,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/log/publisher"
 	"github.com/google/trillian/merkle/compact"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
@@ -54,6 +56,8 @@ var (
 	seqCounter             monitoring.Counter
 	seqMergeDelay          monitoring.Histogram
 	seqTimestamp           monitoring.Gauge
+	seqPublishErrors       monitoring.Counter
+	seqQuarantined         monitoring.Counter
 
 	// QuotaIncreaseFactor is the multiplier used for the number of tokens added back to
 	// sequencing-based quotas. The resulting PutTokens call is equivalent to
@@ -93,6 +97,8 @@ func createSequencerMetrics(mf monitoring.MetricFactory) {
 	seqStoreRootLatency = mf.NewHistogram("sequencer_latency_store_root", "Latency of store-root part of sequencer batch operation in seconds", logIDLabel)
 	seqCounter = mf.NewCounter("sequencer_sequenced", "Number of leaves sequenced", logIDLabel)
 	seqMergeDelay = mf.NewHistogram("sequencer_merge_delay", "Delay between queuing and integration of leaves", logIDLabel)
+	seqPublishErrors = mf.NewCounter("sequencer_publish_errors", "Number of signed roots which failed to be published", logIDLabel)
+	seqQuarantined = mf.NewCounter("sequencer_quarantined", "Number of leaves quarantined because they could not be integrated", logIDLabel)
 }
 
 // Sequencer instances are responsible for integrating new leaves into a single log.
@@ -105,25 +111,37 @@ type Sequencer struct {
 	logStorage storage.LogStorage
 	signer     *tcrypto.Signer
 	qm         quota.Manager
+	publisher  *publisher.Tracker
 }
 
-// NewSequencer creates a new Sequencer instance for the specified inputs.
+// NewSequencer creates a new Sequencer instance for the specified inputs. The
+// RootPublisher is optional, and may be nil. If it is a publisher.Tracker, it
+// should be shared between the Sequencers of the process, so that the roots it
+// already published are not published again.
 func NewSequencer(
 	hasher hashers.LogHasher,
 	timeSource clock.TimeSource,
 	logStorage storage.LogStorage,
 	signer *tcrypto.Signer,
 	mf monitoring.MetricFactory,
-	qm quota.Manager) *Sequencer {
+	qm quota.Manager,
+	rp publisher.RootPublisher) *Sequencer {
 	sequencerOnce.Do(func() {
 		createSequencerMetrics(mf)
 	})
+	var tracker *publisher.Tracker
+	if rp != nil {
+		if tracker, _ = rp.(*publisher.Tracker); tracker == nil {
+			tracker = publisher.NewTracker(rp)
+		}
+	}
 	return &Sequencer{
 		hasher:     hasher,
 		timeSource: timeSource,
 		logStorage: logStorage,
 		signer:     signer,
 		qm:         qm,
+		publisher:  tracker,
 	}
 }
 
@@ -180,7 +198,7 @@ func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label
 	for i, leaf := range leaves {
 		// The leaf should already have the correct index before it's integrated.
 		if got, want := leaf.LeafIndex, begin+uint64(i); got < 0 || got != int64(want) {
-			return fmt.Errorf("got invalid leaf index: %v, want: %v", got, want)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid leaf index: %v, want: %v", got, want)}
 		}
 		leaf.IntegrateTimestamp = integrateAt
 
@@ -189,7 +207,7 @@ func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label
 		if leaf.QueueTimestamp != nil && leaf.QueueTimestamp.Seconds != 0 {
 			queueTS, err := ptypes.Timestamp(leaf.QueueTimestamp)
 			if err != nil {
-				return fmt.Errorf("got invalid queue timestamp: %v", queueTS)
+				return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid queue timestamp: %v", err)}
 			}
 			mergeDelay := now.Sub(queueTS)
 			seqMergeDelay.Observe(mergeDelay.Seconds(), label)
@@ -198,6 +216,65 @@ func (s Sequencer) prepareLeaves(leaves []*trillian.LogLeaf, begin uint64, label
 	return nil
 }
 
+// checkLeaf returns an error describing why the leaf can't be integrated at
+// the given index, or nil if it can.
+func (s Sequencer) checkLeaf(leaf *trillian.LogLeaf, index uint64) error {
+	if got, want := len(leaf.MerkleLeafHash), s.hasher.Size(); got != want {
+		return fmt.Errorf("Merkle leaf hash has size %d, want %d", got, want)
+	}
+	if got, want := leaf.LeafIndex, int64(index); got < want {
+		return fmt.Errorf("duplicate leaf index %d, want %d", got, want)
+	}
+	return nil
+}
+
+// quarantineLeaves sets aside the leaves of the batch which can't be
+// integrated, so that they don't fail every subsequent batch too. It returns
+// the remaining leaves, which have consecutive indices starting at begin.
+//
+// A leaf is quarantined if it fails checkLeaf, or if its identity hash is in
+// failed, which maps the leaves that failed an earlier attempt at the batch to
+// the reason.
+//
+// Leaves of LOG trees are renumbered to close the gaps left by quarantined
+// leaves. The indices of PREORDERED_LOG leaves are fixed, so the batch ends
+// before a quarantined leaf, unless that leaf duplicates an earlier index.
+func (s Sequencer) quarantineLeaves(ctx context.Context, tx storage.QuarantineLogTreeTX, treeType trillian.TreeType, leaves []*trillian.LogLeaf, begin uint64, failed map[string]error, label string) ([]*trillian.LogLeaf, error) {
+	now, err := ptypes.TimestampProto(s.timeSource.Now())
+	if err != nil {
+		return nil, fmt.Errorf("got invalid quarantine timestamp: %v", err)
+	}
+	var bad []*trillian.QuarantinedLeaf
+	good := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, leaf := range leaves {
+		next := begin + uint64(len(good))
+		if treeType == trillian.TreeType_LOG {
+			leaf.LeafIndex = int64(next)
+		}
+		err := failed[string(leaf.LeafIdentityHash)]
+		if err == nil {
+			err = s.checkLeaf(leaf, next)
+		}
+		if err == nil {
+			good = append(good, leaf)
+			continue
+		}
+		glog.Warningf("%v: quarantining leaf %x: %v", label, leaf.LeafIdentityHash, err)
+		bad = append(bad, &trillian.QuarantinedLeaf{Leaf: leaf, Reason: err.Error(), QuarantineTimestamp: now})
+		if treeType == trillian.TreeType_PREORDERED_LOG && leaf.LeafIndex >= int64(next) {
+			break
+		}
+	}
+	if len(bad) == 0 {
+		return leaves, nil
+	}
+	if err := tx.QuarantineLeaves(ctx, bad); err != nil {
+		return nil, err
+	}
+	seqQuarantined.Add(float64(len(bad)), label)
+	return good, nil
+}
+
 // updateCompactRange adds the passed in leaves to the compact range. Returns a
 // map of all updated tree nodes, and the new root hash.
 func (s Sequencer) updateCompactRange(cr *compact.Range, leaves []*trillian.LogLeaf, label string) (map[compact.NodeID][]byte, []byte, error) {
@@ -272,7 +349,7 @@ func (s *logSequencingTask) update(ctx context.Context, leaves []*trillian.LogLe
 	start := s.timeSource.Now()
 	// Write the new sequence numbers to the leaves in the DB.
 	if err := s.tx.UpdateSequencedLeaves(ctx, leaves); err != nil {
-		return fmt.Errorf("%v: Sequencer failed to update sequenced leaves: %v", s.label, err)
+		return fmt.Errorf("%v: Sequencer failed to update sequenced leaves: %w", s.label, err)
 	}
 	seqUpdateLeavesLatency.Observe(clock.SecondsSince(s.timeSource, start), s.label)
 	return nil
@@ -307,7 +384,16 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 	numLeaves := 0
 	var newLogRoot *types.LogRootV1
 	var newSLR *trillian.SignedLogRoot
-	err := s.logStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+	// latestSLR is the root which was stored before the batch, at revision
+	// latestRevision.
+	var latestSLR *trillian.SignedLogRoot
+	var latestRevision uint64
+	// failed maps the identity hashes of the leaves which made earlier
+	// attempts at the batch fail to the reason, so that they get quarantined.
+	failed := make(map[string]error)
+	canQuarantine := false
+	integrate := func(ctx context.Context, tx storage.LogTreeTX) error {
+		numLeaves, newLogRoot, newSLR, latestSLR = 0, nil, nil, nil
 		stageStart := s.timeSource.Now()
 		defer seqBatches.Inc(label)
 		defer func() { seqLatency.Observe(clock.SecondsSince(s.timeSource, start), label) }()
@@ -331,6 +417,7 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 			glog.Warningf("%v: Fresh log - no previous TreeHeads exist.", tree.TreeId)
 			return storage.ErrTreeNeedsInit
 		}
+		latestSLR, latestRevision = sth, currentRoot.Revision
 
 		taskData := &sequencingTaskData{
 			label:      label,
@@ -352,6 +439,13 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		if err != nil {
 			return fmt.Errorf("%v: Sequencer failed to load sequenced batch: %v", tree.TreeId, err)
 		}
+		if qtx, ok := tx.(storage.QuarantineLogTreeTX); ok {
+			canQuarantine = true
+			sequencedLeaves, err = s.quarantineLeaves(ctx, qtx, tree.TreeType, sequencedLeaves, currentRoot.TreeSize, failed, label)
+			if err != nil {
+				return fmt.Errorf("%v: Sequencer failed to quarantine leaves: %v", tree.TreeId, err)
+			}
+		}
 		numLeaves = len(sequencedLeaves)
 
 		// We need to create a signed root if entries were added or the latest root
@@ -445,9 +539,21 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 		}
 		seqStoreRootLatency.Observe(clock.SecondsSince(s.timeSource, stageStart), label)
 		return nil
-	})
-	if err != nil {
-		return 0, err
+	}
+	for {
+		err := s.logStorage.ReadWriteTransaction(ctx, tree, integrate)
+		// If a particular leaf failed the batch, quarantine it and try again,
+		// rather than failing every batch from now on.
+		var leafErr *storage.LeafError
+		if canQuarantine && errors.As(err, &leafErr) && failed[string(leafErr.LeafIdentityHash)] == nil {
+			glog.Warningf("%v: retrying batch without leaf %x: %v", tree.TreeId, leafErr.LeafIdentityHash, leafErr.Err)
+			failed[string(leafErr.LeafIdentityHash)] = leafErr.Err
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		break
 	}
 
 	// Let quota.Manager know about newly-sequenced entries.
@@ -457,9 +563,34 @@ func (s Sequencer) IntegrateBatch(ctx context.Context, tree *trillian.Tree, limi
 	if newSLR != nil {
 		glog.Infof("%v: sequenced %v leaves, size %v, tree-revision %v", tree.TreeId, numLeaves, newLogRoot.TreeSize, newLogRoot.Revision)
 	}
+	// The root which was stored before the batch may not have been published
+	// if publishing it failed, or if the process which committed it crashed
+	// before publishing it.
+	if latestSLR != nil && s.publisher != nil && !s.publisher.Published(tree.TreeId, latestRevision) {
+		s.publishRoot(ctx, tree.TreeId, latestSLR, label)
+	}
+	if newSLR != nil {
+		s.publishRoot(ctx, tree.TreeId, newSLR, label)
+	}
 	return numLeaves, nil
 }
 
+// publishRoot hands a committed root over to the RootPublisher, if any. This
+// is done after the transaction which stored the root, so that publishing
+// never holds it open. Publishing is best-effort: failures are logged rather
+// than failing the batch, and only the latest root is published again by the
+// next batch. The roots which must all be delivered should be published
+// through a publisher.Outbox.
+func (s Sequencer) publishRoot(ctx context.Context, treeID int64, slr *trillian.SignedLogRoot, label string) {
+	if s.publisher == nil {
+		return
+	}
+	if err := s.publisher.PublishRoot(ctx, treeID, slr); err != nil {
+		glog.Warningf("%v: failed to publish root: %v", treeID, err)
+		seqPublishErrors.Inc(label)
+	}
+}
+
 // replenishQuota replenishes all quotas, such as {Tree/Global, Read/Write},
 // that are possibly influenced by sequencing numLeaves entries for the passed
 // in tree ID. Implementations are tasked with filtering quotas that shouldn't
@@ -24,6 +24,7 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
+	"github.com/google/trillian/log/publisher"
 	"github.com/google/trillian/merkle/hashers/registry"
 	"github.com/google/trillian/trees"
 
@@ -36,6 +37,9 @@ type SequencerManager struct {
 	registry     extension.Registry
 	signers      map[int64]*tcrypto.Signer
 	signersMutex sync.Mutex
+	// publisher wraps the RootPublisher of the registry, if any, and is shared
+	// by the Sequencers so that they know which roots have been published.
+	publisher publisher.RootPublisher
 }
 
 var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
@@ -43,11 +47,17 @@ var seqOpts = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_LOG, trillia
 // NewSequencerManager creates a new SequencerManager instance based on the provided KeyManager instance
 // and guard window.
 func NewSequencerManager(registry extension.Registry, gw time.Duration) *SequencerManager {
-	return &SequencerManager{
+	s := &SequencerManager{
 		guardWindow: gw,
 		registry:    registry,
 		signers:     make(map[int64]*tcrypto.Signer),
 	}
+	if tracker, ok := registry.RootPublisher.(*publisher.Tracker); ok {
+		s.publisher = tracker
+	} else if registry.RootPublisher != nil {
+		s.publisher = publisher.NewTracker(registry.RootPublisher)
+	}
+	return s
 }
 
 // ExecutePass performs sequencing for the specified Log.
@@ -71,7 +81,7 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *O
 		return 0, fmt.Errorf("error getting signer for log %v: %v", logID, err)
 	}
 
-	sequencer := NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, signer, s.registry.MetricFactory, s.registry.QuotaManager)
+	sequencer := NewSequencer(hasher, info.TimeSource, s.registry.LogStorage, signer, s.registry.MetricFactory, s.registry.QuotaManager, s.publisher)
 
 	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
@@ -15,17 +15,24 @@
 package log
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/log/publisher"
 	"github.com/google/trillian/merkle/compact"
 	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
 	"github.com/google/trillian/quota"
@@ -35,6 +42,7 @@ import (
 	"github.com/google/trillian/util/clock"
 
 	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage/memory"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
 )
@@ -322,7 +330,7 @@ func createTestContext(ctrl *gomock.Controller, params testParameters) (testCont
 	if qm == nil {
 		qm = quota.Noop()
 	}
-	sequencer := NewSequencer(rfc6962.DefaultHasher, clock.NewFake(fakeTime), fakeStorage, signer, nil, qm)
+	sequencer := NewSequencer(rfc6962.DefaultHasher, clock.NewFake(fakeTime), fakeStorage, signer, nil, qm, nil)
 	return testContext{mockTx: mockTx, fakeStorage: fakeStorage, signer: signer, sequencer: sequencer}, context.Background()
 }
 
@@ -668,6 +676,89 @@ func TestIntegrateBatch(t *testing.T) {
 	}
 }
 
+// fakeRootPublisher records the published roots, and fails the calls for
+// which errs holds an error.
+type fakeRootPublisher struct {
+	treeIDs []int64
+	roots   []*trillian.SignedLogRoot
+	errs    []error
+}
+
+func (f *fakeRootPublisher) PublishRoot(ctx context.Context, treeID int64, root *trillian.SignedLogRoot) error {
+	var err error
+	if n := len(f.roots); n < len(f.errs) {
+		err = f.errs[n]
+	}
+	f.treeIDs = append(f.treeIDs, treeID)
+	f.roots = append(f.roots, root)
+	return err
+}
+
+func TestIntegrateBatch_PublishesRoot(t *testing.T) {
+	leaves16 := []*trillian.LogLeaf{testLeaf16}
+	for _, test := range []struct {
+		desc string
+		// published is whether the stored root has already been published.
+		published bool
+		pubErrs   []error
+		wantRoots []*trillian.SignedLogRoot
+	}{
+		{desc: "ok", published: true, wantRoots: []*trillian.SignedLogRoot{testSignedRoot}},
+		{desc: "publish-fails", published: true, pubErrs: []error{errors.New("publish")}, wantRoots: []*trillian.SignedLogRoot{testSignedRoot}},
+		{desc: "unpublished", wantRoots: []*trillian.SignedLogRoot{testSignedRoot16, testSignedRoot}},
+		{desc: "unpublished-fails", pubErrs: []error{errors.New("publish")}, wantRoots: []*trillian.SignedLogRoot{testSignedRoot16, testSignedRoot}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			params := testParameters{
+				logID:            154035,
+				writeRevision:    int64(testRoot16.Revision + 1),
+				latestSignedRoot: testSignedRoot16,
+				signer:           fixedGoSigner,
+				dequeueLimit:     1,
+				shouldCommit:     true,
+				dequeuedLeaves:   []*trillian.LogLeaf{getLeaf42()},
+				merkleNodesGet:   &compactTree16,
+				updatedLeaves:    &leaves16,
+				merkleNodesSet:   &updatedNodes,
+				storeSignedRoot:  testSignedRoot,
+			}
+			c, ctx := createTestContext(ctrl, params)
+			rp := &fakeRootPublisher{}
+			tracker := publisher.NewTracker(rp)
+			if test.published {
+				if err := tracker.PublishRoot(ctx, params.logID, testSignedRoot16); err != nil {
+					t.Fatalf("PublishRoot(): %v", err)
+				}
+				rp.treeIDs, rp.roots = nil, nil
+			}
+			rp.errs = test.pubErrs
+			c.sequencer.publisher = tracker
+			tree := &trillian.Tree{TreeId: params.logID, TreeType: trillian.TreeType_LOG}
+
+			// Roots are published after the commit, so failures to publish
+			// them must not fail the batch.
+			got, err := c.sequencer.IntegrateBatch(ctx, tree, 1, 0, 0)
+			if err != nil || got != 1 {
+				t.Fatalf("IntegrateBatch()=%v,%v; want 1,nil", got, err)
+			}
+			if len(rp.roots) != len(test.wantRoots) {
+				t.Fatalf("published %d roots, want %d", len(rp.roots), len(test.wantRoots))
+			}
+			for i, want := range test.wantRoots {
+				if got := rp.treeIDs[i]; got != params.logID {
+					t.Errorf("published tree ID %d, want %d", got, params.logID)
+				}
+				if got := rp.roots[i]; !proto.Equal(got, want) {
+					t.Errorf("published root %d: %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
 func TestIntegrateBatch_PutTokens(t *testing.T) {
 	cryptoSigner := newSignerWithFixedSig(testSignedRoot.LogRootSignature)
 
@@ -768,7 +859,7 @@ func TestIntegrateBatch_PutTokens(t *testing.T) {
 				qm.EXPECT().PutTokens(any, test.wantTokens, specs)
 			}
 
-			sequencer := NewSequencer(hasher, ts, logStorage, signer, nil /* mf */, qm)
+			sequencer := NewSequencer(hasher, ts, logStorage, signer, nil /* mf */, qm, nil /* rp */)
 			tree := &trillian.Tree{TreeId: treeID, TreeType: trillian.TreeType_LOG}
 			leaves, err := sequencer.IntegrateBatch(ctx, tree, limit, guardWindow, maxRootDuration)
 			if err != nil {
@@ -781,3 +872,176 @@ func TestIntegrateBatch_PutTokens(t *testing.T) {
 		}()
 	}
 }
+
+// fakeQuarantineTX is a storage.QuarantineLogTreeTX which records the
+// quarantined leaves. Only QuarantineLeaves may be called.
+type fakeQuarantineTX struct {
+	storage.LogTreeTX
+	quarantined []*trillian.QuarantinedLeaf
+}
+
+func (f *fakeQuarantineTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	f.quarantined = append(f.quarantined, leaves...)
+	return nil
+}
+
+func (f *fakeQuarantineTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	return f.quarantined, nil
+}
+
+func (f *fakeQuarantineTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestQuarantineLeaves(t *testing.T) {
+	s := NewSequencer(rfc6962.DefaultHasher, clock.NewFake(fakeTime), nil, nil, nil, quota.Noop(), nil)
+	leaf := func(id string, index int64, hashSize int) *trillian.LogLeaf {
+		return &trillian.LogLeaf{LeafIdentityHash: []byte(id), MerkleLeafHash: make([]byte, hashSize), LeafIndex: index}
+	}
+	const begin = 10
+	for _, tc := range []struct {
+		desc           string
+		treeType       trillian.TreeType
+		leaves         []*trillian.LogLeaf
+		wantIDs        []string
+		wantIndices    []int64
+		wantQuarantine []string
+	}{
+		{
+			desc:        "all-good",
+			treeType:    trillian.TreeType_LOG,
+			leaves:      []*trillian.LogLeaf{leaf("a", 10, 32), leaf("b", 11, 32)},
+			wantIDs:     []string{"a", "b"},
+			wantIndices: []int64{10, 11},
+		},
+		{
+			desc:           "log-bad-hash-renumbers",
+			treeType:       trillian.TreeType_LOG,
+			leaves:         []*trillian.LogLeaf{leaf("a", 10, 32), leaf("b", 11, 3), leaf("c", 12, 32)},
+			wantIDs:        []string{"a", "c"},
+			wantIndices:    []int64{10, 11},
+			wantQuarantine: []string{"b"},
+		},
+		{
+			desc:           "preordered-duplicate-index-skipped",
+			treeType:       trillian.TreeType_PREORDERED_LOG,
+			leaves:         []*trillian.LogLeaf{leaf("a", 10, 32), leaf("b", 10, 32), leaf("c", 11, 32)},
+			wantIDs:        []string{"a", "c"},
+			wantIndices:    []int64{10, 11},
+			wantQuarantine: []string{"b"},
+		},
+		{
+			desc:           "preordered-bad-hash-ends-batch",
+			treeType:       trillian.TreeType_PREORDERED_LOG,
+			leaves:         []*trillian.LogLeaf{leaf("a", 10, 32), leaf("b", 11, 3), leaf("c", 12, 32)},
+			wantIDs:        []string{"a"},
+			wantIndices:    []int64{10},
+			wantQuarantine: []string{"b"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tx := &fakeQuarantineTX{}
+			got, err := s.quarantineLeaves(context.Background(), tx, tc.treeType, tc.leaves, begin, nil, "test")
+			if err != nil {
+				t.Fatalf("quarantineLeaves(): %v", err)
+			}
+			var gotIDs []string
+			var gotIndices []int64
+			for _, l := range got {
+				gotIDs = append(gotIDs, string(l.LeafIdentityHash))
+				gotIndices = append(gotIndices, l.LeafIndex)
+			}
+			var gotQuarantine []string
+			for _, ql := range tx.quarantined {
+				gotQuarantine = append(gotQuarantine, string(ql.Leaf.LeafIdentityHash))
+				if ql.Reason == "" || ql.QuarantineTimestamp == nil {
+					t.Errorf("quarantined leaf %s has no reason or timestamp", ql.Leaf.LeafIdentityHash)
+				}
+			}
+			if !reflect.DeepEqual(gotIDs, tc.wantIDs) || !reflect.DeepEqual(gotIndices, tc.wantIndices) {
+				t.Errorf("quarantineLeaves() returned %v at %v, want %v at %v", gotIDs, gotIndices, tc.wantIDs, tc.wantIndices)
+			}
+			if !reflect.DeepEqual(gotQuarantine, tc.wantQuarantine) {
+				t.Errorf("quarantined %v, want %v", gotQuarantine, tc.wantQuarantine)
+			}
+		})
+	}
+}
+
+// failingLeafStorage is a storage.LogStorage whose transactions fail to
+// update the sequenced leaves if the batch contains the bad leaf.
+type failingLeafStorage struct {
+	storage.LogStorage
+	bad []byte
+}
+
+func (f failingLeafStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, fn storage.LogTXFunc) error {
+	return f.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return fn(ctx, failingLeafTX{QuarantineLogTreeTX: tx.(storage.QuarantineLogTreeTX), bad: f.bad})
+	})
+}
+
+type failingLeafTX struct {
+	storage.QuarantineLogTreeTX
+	bad []byte
+}
+
+func (f failingLeafTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	for _, leaf := range leaves {
+		if bytes.Equal(leaf.LeafIdentityHash, f.bad) {
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: errors.New("bad leaf")}
+		}
+	}
+	return f.QuarantineLogTreeTX.UpdateSequencedLeaves(ctx, leaves)
+}
+
+func TestIntegrateBatch_QuarantinesFailedLeaf(t *testing.T) {
+	ctx := context.Background()
+	hasher := rfc6962.DefaultHasher
+	// Other tests in this package unregister the handler of the test tree key.
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return der.FromProto(pb.(*keyspb.PrivateKey))
+	})
+	defer keys.UnregisterHandler(&keyspb.PrivateKey{})
+	ts := memory.NewTreeStorage()
+	as := memory.NewAdminStorage(ts)
+	tree, err := storage.CreateTree(ctx, as, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	leaves := make([]*trillian.LogLeaf, 0, 3)
+	for i := 0; i < 3; i++ {
+		hash := hasher.HashLeaf([]byte(fmt.Sprintf("leaf-%d", i)))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash, MerkleLeafHash: hash})
+	}
+	ls := failingLeafStorage{LogStorage: memory.NewLogStorage(ts, nil), bad: leaves[1].LeafIdentityHash}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := (&types.LogRootV1{RootHash: hasher.EmptyRoot()}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	if _, err := ls.QueueLeaves(ctx, tree, leaves, fakeTime.Add(-time.Minute)); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+
+	s := NewSequencer(hasher, clock.NewFake(fakeTime), ls, fixedSigner, nil, quota.Noop(), nil)
+	if got, err := s.IntegrateBatch(ctx, tree, 10, 0, 0); err != nil || got != 2 {
+		t.Fatalf("IntegrateBatch()=%v,%v; want 2,nil", got, err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		quarantined, err := tx.(storage.QuarantineLogTreeTX).ListQuarantinedLeaves(ctx)
+		if err != nil {
+			return err
+		}
+		if len(quarantined) != 1 || !bytes.Equal(quarantined[0].Leaf.LeafIdentityHash, ls.bad) || quarantined[0].Reason != "bad leaf" {
+			t.Errorf("ListQuarantinedLeaves()=%v, want the bad leaf", quarantined)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ListQuarantinedLeaves(): %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/trees"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TieringOptions configures a Tierer.
+type TieringOptions struct {
+	// BatchSize is the number of leaves, or of subtrees, visited per storage
+	// transaction.
+	BatchSize int
+	// BatchPause is the time to wait between transactions, to limit the load
+	// on the storage.
+	BatchPause time.Duration
+	// TimeSource is used for the pacing, and may be mocked out by tests.
+	TimeSource clock.TimeSource
+}
+
+// Tierer moves the data of the old leaves and complete tiles of logs out of
+// the database to a cold object store, through storage.TieringLogTreeTX. The
+// data can still be read through the storage as before.
+type Tierer struct {
+	registry extension.Registry
+	opts     TieringOptions
+}
+
+// Tiering doesn't change what can be read from a tree, so frozen logs can be
+// tiered too.
+var tierOpts = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+
+// NewTierer creates a new Tierer for the logs in the given registry.
+func NewTierer(registry extension.Registry, opts TieringOptions) *Tierer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	return &Tierer{registry: registry, opts: opts}
+}
+
+// TierLog moves the data of the leaves of the given log with indices below
+// treeSize, and of the tiles which only cover such leaves, to the cold store.
+// The tree size is capped at the size of the latest signed root. It returns
+// the number of leaves and tiles moved, which are non-zero even on error if
+// some transactions were committed.
+func (t *Tierer) TierLog(ctx context.Context, logID, treeSize int64) (int, int, error) {
+	tree, err := trees.GetTree(ctx, t.registry.AdminStorage, logID, tierOpts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get tree: %v", err)
+	}
+	ctx = trees.NewContext(ctx, tree)
+
+	if err := t.transaction(ctx, tree, func(ctx context.Context, tx storage.TieringLogTreeTX) error {
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return err
+		}
+		if size := int64(root.TreeSize); treeSize > size {
+			treeSize = size
+		}
+		return nil
+	}); err != nil {
+		return 0, 0, status.Errorf(status.Code(err), "failed to read latest root: %v", err)
+	}
+
+	leaves := 0
+	for start := int64(0); start < treeSize; start += int64(t.opts.BatchSize) {
+		end := start + int64(t.opts.BatchSize)
+		if end > treeSize {
+			end = treeSize
+		}
+		var n int
+		if err := t.transaction(ctx, tree, func(ctx context.Context, tx storage.TieringLogTreeTX) error {
+			var err error
+			n, err = tx.TierLeaves(ctx, start, end)
+			return err
+		}); err != nil {
+			return leaves, 0, fmt.Errorf("failed to tier leaves [%d, %d): %v", start, end, err)
+		}
+		leaves += n
+		if err := t.pause(ctx); err != nil {
+			return leaves, 0, err
+		}
+	}
+
+	var after []byte
+	tiles := 0
+	for {
+		var last []byte
+		var n int
+		if err := t.transaction(ctx, tree, func(ctx context.Context, tx storage.TieringLogTreeTX) error {
+			var err error
+			last, n, err = tx.TierTiles(ctx, treeSize, after, t.opts.BatchSize)
+			return err
+		}); err != nil {
+			return leaves, tiles, fmt.Errorf("failed to tier tiles below tree size %d: %v", treeSize, err)
+		}
+		tiles += n
+		if last == nil {
+			return leaves, tiles, nil
+		}
+		after = last
+		if err := t.pause(ctx); err != nil {
+			return leaves, tiles, err
+		}
+	}
+}
+
+// pause waits for BatchPause between transactions.
+func (t *Tierer) pause(ctx context.Context) error {
+	if t.opts.BatchPause <= 0 {
+		return nil
+	}
+	return clock.SleepSource(ctx, t.opts.BatchPause, t.opts.TimeSource)
+}
+
+// transaction runs f in a read-write transaction for the given tree.
+func (t *Tierer) transaction(ctx context.Context, tree *trillian.Tree, f func(context.Context, storage.TieringLogTreeTX) error) error {
+	return t.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		tierTX, ok := tx.(storage.TieringLogTreeTX)
+		if !ok {
+			return status.Error(codes.Unimplemented, "storage does not support tiering")
+		}
+		return f(ctx, tierTX)
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/extension"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTieringTX is a TieringLogTreeTX over a log of the given size, and a
+// sorted list of subtree IDs of which each batch has one tile to move.
+type fakeTieringTX struct {
+	storage.LogTreeTX
+	treeSize uint64
+	subtrees [][]byte
+
+	gotLeafRanges [][2]int64
+	gotTreeSizes  []int64
+	gotAfter      [][]byte
+}
+
+func (f *fakeTieringTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	root, err := (&types.LogRootV1{TreeSize: f.treeSize}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.SignedLogRoot{LogRoot: root}, nil
+}
+
+func (f *fakeTieringTX) TierLeaves(ctx context.Context, start, end int64) (int, error) {
+	f.gotLeafRanges = append(f.gotLeafRanges, [2]int64{start, end})
+	return int(end - start), nil
+}
+
+func (f *fakeTieringTX) TierTiles(ctx context.Context, treeSize int64, after []byte, limit int) ([]byte, int, error) {
+	f.gotTreeSizes = append(f.gotTreeSizes, treeSize)
+	f.gotAfter = append(f.gotAfter, after)
+	var batch [][]byte
+	for _, id := range f.subtrees {
+		if bytes.Compare(id, after) > 0 && len(batch) < limit {
+			batch = append(batch, id)
+		}
+	}
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+	return batch[len(batch)-1], 1, nil
+}
+
+// registerDERKeyHandler registers the handler for the private key of
+// testonly.LogTree, which the tests in sequencer_manager_test.go unregister.
+func registerDERKeyHandler() {
+	keys.RegisterHandler(&keyspb.PrivateKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		if pb, ok := pb.(*keyspb.PrivateKey); ok {
+			return der.FromProto(pb)
+		}
+		return nil, fmt.Errorf("der: got %T, want *keyspb.PrivateKey", pb)
+	})
+}
+
+func TestTierLog(t *testing.T) {
+	registerDERKeyHandler()
+	for _, tc := range []struct {
+		desc           string
+		treeSize       int64
+		wantLeaves     int
+		wantLeafRanges [][2]int64
+		wantTreeSize   int64
+	}{
+		{
+			desc:           "below-root",
+			treeSize:       5,
+			wantLeaves:     5,
+			wantLeafRanges: [][2]int64{{0, 2}, {2, 4}, {4, 5}},
+			wantTreeSize:   5,
+		},
+		{
+			desc:           "capped-at-root",
+			treeSize:       100,
+			wantLeaves:     7,
+			wantLeafRanges: [][2]int64{{0, 2}, {2, 4}, {4, 6}, {6, 7}},
+			wantTreeSize:   7,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ts := memory.NewTreeStorage()
+			as := memory.NewAdminStorage(ts)
+			logTree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+			if err != nil {
+				t.Fatalf("CreateTree(): %v", err)
+			}
+			tx := &fakeTieringTX{
+				treeSize: 7,
+				subtrees: [][]byte{[]byte("a"), []byte("b"), []byte("c")},
+			}
+			registry := extension.Registry{
+				AdminStorage: as,
+				LogStorage:   fakeCompactionStorage{LogStorage: memory.NewLogStorage(ts, nil), tx: tx},
+			}
+			tierer := NewTierer(registry, TieringOptions{BatchSize: 2})
+
+			leaves, tiles, err := tierer.TierLog(ctx, logTree.TreeId, tc.treeSize)
+			if err != nil {
+				t.Fatalf("TierLog(): %v", err)
+			}
+			if leaves != tc.wantLeaves || tiles != 2 {
+				t.Errorf("TierLog()=%d, %d, want %d, 2", leaves, tiles, tc.wantLeaves)
+			}
+			if diff := cmp.Diff(tc.wantLeafRanges, tx.gotLeafRanges); diff != "" {
+				t.Errorf("TierLeaves() ranges diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([][]byte{nil, []byte("b"), []byte("c")}, tx.gotAfter); diff != "" {
+				t.Errorf("TierTiles() after diff (-want +got):\n%s", diff)
+			}
+			for _, size := range tx.gotTreeSizes {
+				if size != tc.wantTreeSize {
+					t.Errorf("TierTiles(%d), want tree size %d", size, tc.wantTreeSize)
+				}
+			}
+		})
+	}
+}
+
+func TestTierLogUnsupported(t *testing.T) {
+	registerDERKeyHandler()
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	logTree := storeLog(ctx, t, registry, 3, nil)
+
+	tierer := NewTierer(registry, TieringOptions{})
+	if _, _, err := tierer.TierLog(ctx, logTree.TreeId, 3); status.Code(err) != codes.Unimplemented {
+		t.Errorf("TierLog(): %v, want %v", err, codes.Unimplemented)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
@@ -221,6 +222,12 @@ func applyUpdateMask(from, to *trillian.Tree, mask *field_mask.FieldMask) error
 			to.MaxRootDuration = from.MaxRootDuration
 		case "private_key":
 			to.PrivateKey = from.PrivateKey
+		case "dequeue_order":
+			to.DequeueOrder = from.DequeueOrder
+		case "leaf_compression":
+			to.LeafCompression = from.LeafCompression
+		case "leaf_compression_dictionary_id":
+			to.LeafCompressionDictionaryId = from.LeafCompressionDictionaryId
 		default:
 			return status.Errorf(codes.InvalidArgument, "invalid update_mask path: %q", path)
 		}
@@ -246,6 +253,56 @@ func (s *Server) UndeleteTree(ctx context.Context, req *trillian.UndeleteTreeReq
 	return redact(tree), nil
 }
 
+// ListQuarantinedLeaves implements trillian.TrillianAdminServer.ListQuarantinedLeaves.
+func (s *Server) ListQuarantinedLeaves(ctx context.Context, req *trillian.ListQuarantinedLeavesRequest) (*trillian.ListQuarantinedLeavesResponse, error) {
+	var leaves []*trillian.QuarantinedLeaf
+	err := s.quarantineTransaction(ctx, req.GetTreeId(), func(ctx context.Context, tx storage.QuarantineLogTreeTX) error {
+		var err error
+		leaves, err = tx.ListQuarantinedLeaves(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.ListQuarantinedLeavesResponse{Leaves: leaves}, nil
+}
+
+// RequeueQuarantinedLeaves implements trillian.TrillianAdminServer.RequeueQuarantinedLeaves.
+func (s *Server) RequeueQuarantinedLeaves(ctx context.Context, req *trillian.RequeueQuarantinedLeavesRequest) (*trillian.RequeueQuarantinedLeavesResponse, error) {
+	if len(req.GetLeafIdentityHash()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no leaf_identity_hash specified")
+	}
+	var requeued [][]byte
+	err := s.quarantineTransaction(ctx, req.GetTreeId(), func(ctx context.Context, tx storage.QuarantineLogTreeTX) error {
+		var err error
+		requeued, err = tx.RequeueQuarantinedLeaves(ctx, req.GetLeafIdentityHash(), time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.RequeueQuarantinedLeavesResponse{LeafIdentityHash: requeued}, nil
+}
+
+// quarantineTransaction runs f in a read-write transaction on the given log,
+// provided that its storage supports quarantining leaves.
+func (s *Server) quarantineTransaction(ctx context.Context, treeID int64, f func(context.Context, storage.QuarantineLogTreeTX) error) error {
+	if s.registry.LogStorage == nil {
+		return status.Errorf(codes.Unimplemented, "log storage is not available")
+	}
+	tree, err := trees.GetTree(ctx, s.registry.AdminStorage, treeID, trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG))
+	if err != nil {
+		return err
+	}
+	return s.registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		qtx, ok := tx.(storage.QuarantineLogTreeTX)
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "log storage does not support quarantined leaves")
+		}
+		return f(ctx, qtx)
+	})
+}
+
 // redact removes sensitive information from t. Returns t for convenience.
 func redact(t *trillian.Tree) *trillian.Tree {
 	t.PrivateKey = nil
@@ -40,7 +40,9 @@ import (
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -612,9 +614,10 @@ func TestServer_UpdateTree(t *testing.T) {
 		StorageSettings: settings,
 		MaxRootDuration: ptypes.DurationProto(2 * time.Nanosecond),
 		PrivateKey:      ttestonly.MustMarshalAny(t, &empty.Empty{}),
+		DequeueOrder:    trillian.DequeueOrder_FAIR_SHARE_ORDER,
 	}
 	successMask := &field_mask.FieldMask{
-		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key"},
+		Paths: []string{"tree_state", "display_name", "description", "storage_settings", "max_root_duration", "private_key", "dequeue_order"},
 	}
 
 	successWant := proto.Clone(existingTree).(*trillian.Tree)
@@ -624,6 +627,7 @@ func TestServer_UpdateTree(t *testing.T) {
 	successWant.StorageSettings = successTree.StorageSettings
 	successWant.PrivateKey = nil // redacted on responses
 	successWant.MaxRootDuration = successTree.MaxRootDuration
+	successWant.DequeueOrder = successTree.DequeueOrder
 
 	tests := []struct {
 		desc                           string
@@ -955,3 +959,88 @@ func fakeKeyProtoGenerator(wantKeySpec *keyspb.Specification, keyProto proto.Mes
 		return keyProto, nil
 	}
 }
+
+func TestServer_QuarantinedLeaves(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	tree, err := storage.CreateTree(ctx, registry.AdminStorage, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := (&types.LogRootV1{RootHash: make([]byte, 32)}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	// Queue two leaves, and quarantine one of them as the sequencer would.
+	leaves := []*trillian.LogLeaf{
+		{LeafIdentityHash: make([]byte, 32), MerkleLeafHash: []byte("bad")},
+		{LeafIdentityHash: append(make([]byte, 31), 1), MerkleLeafHash: make([]byte, 32)},
+	}
+	if _, err := registry.LogStorage.QueueLeaves(ctx, tree, leaves, time.Unix(10, 0)); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 1, time.Unix(20, 0))
+		if err != nil {
+			return err
+		}
+		return tx.(storage.QuarantineLogTreeTX).QuarantineLeaves(ctx, []*trillian.QuarantinedLeaf{
+			{Leaf: dequeued[0], Reason: "bad hash", QuarantineTimestamp: ptypes.TimestampNow()},
+		})
+	}); err != nil {
+		t.Fatalf("QuarantineLeaves(): %v", err)
+	}
+
+	s := New(registry, nil)
+	list, err := s.ListQuarantinedLeaves(ctx, &trillian.ListQuarantinedLeavesRequest{TreeId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("ListQuarantinedLeaves(): %v", err)
+	}
+	if got := len(list.Leaves); got != 1 || list.Leaves[0].Reason != "bad hash" {
+		t.Fatalf("ListQuarantinedLeaves() returned %v, want the quarantined leaf", list.Leaves)
+	}
+
+	if _, err := s.RequeueQuarantinedLeaves(ctx, &trillian.RequeueQuarantinedLeavesRequest{TreeId: tree.TreeId}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RequeueQuarantinedLeaves(no hashes) returned err = %v, want code %v", err, codes.InvalidArgument)
+	}
+	resp, err := s.RequeueQuarantinedLeaves(ctx, &trillian.RequeueQuarantinedLeavesRequest{
+		TreeId:           tree.TreeId,
+		LeafIdentityHash: [][]byte{leaves[0].LeafIdentityHash, []byte("unknown")},
+	})
+	if err != nil {
+		t.Fatalf("RequeueQuarantinedLeaves(): %v", err)
+	}
+	if diff := cmp.Diff(resp.LeafIdentityHash, [][]byte{leaves[0].LeafIdentityHash}); diff != "" {
+		t.Errorf("RequeueQuarantinedLeaves() diff (-got +want):\n%s", diff)
+	}
+
+	list, err = s.ListQuarantinedLeaves(ctx, &trillian.ListQuarantinedLeavesRequest{TreeId: tree.TreeId})
+	if err != nil {
+		t.Fatalf("ListQuarantinedLeaves(): %v", err)
+	}
+	if got := len(list.Leaves); got != 0 {
+		t.Errorf("ListQuarantinedLeaves() returned %d leaves after requeue, want 0", got)
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 10, time.Now())
+		if err != nil {
+			return err
+		}
+		if got := len(dequeued); got != 2 {
+			t.Errorf("DequeueLeaves() returned %d leaves after requeue, want 2", got)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("DequeueLeaves(): %v", err)
+	}
+}
@@ -380,12 +380,22 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 		info.getTree = false // Read-modify-write done within RPC handler
 		info.readonly = false
 
+	// Admin (Log + Pre-ordered Log) / readonly
+	case *trillian.ListQuarantinedLeavesRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+
+	// Admin (Log + Pre-ordered Log) / readwrite
+	case *trillian.RequeueQuarantinedLeavesRequest:
+		info.readonly = false
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
+
 	// (Log + Pre-ordered Log) / readonly
 	case *trillian.GetConsistencyProofRequest,
 		*trillian.GetEntryAndProofRequest,
 		*trillian.GetInclusionProofByHashRequest,
 		*trillian.GetInclusionProofRequest,
-		*trillian.GetLatestSignedLogRootRequest:
+		*trillian.GetLatestSignedLogRootRequest,
+		*trillian.GetSignedLogRootsRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 		info.tokens = 1
 	case *trillian.GetLeavesByHashRequest:
@@ -403,6 +413,11 @@ func newRPCInfoForRequest(req interface{}) (*rpcInfo, error) {
 	case *trillian.GetSequencedLeafCountRequest:
 		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG}
 
+	// Log / readonly
+	case *trillian.GetLeafIndexRangeByTimeRequest:
+		info.treeTypes = []trillian.TreeType{trillian.TreeType_LOG}
+		info.tokens = 1
+
 	// Log / readwrite
 	case *trillian.QueueLeafRequest:
 		info.readonly = false
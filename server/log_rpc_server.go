@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/merkle"
@@ -37,11 +39,18 @@ import (
 // TODO: There is no access control in the server yet and clients could easily modify
 // any tree.
 
-const traceSpanRoot = "/trillian"
+const (
+	traceSpanRoot = "/trillian"
+
+	// maxRootsPageSize is the maximum number of roots returned by one call
+	// to GetSignedLogRoots, and the number returned if none is requested.
+	maxRootsPageSize = 1000
+)
 
 var (
 	optsLogInit            = trees.NewGetOpts(trees.Admin, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
 	optsLogRead            = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG)
+	optsLogTimeRead        = trees.NewGetOpts(trees.Query, trillian.TreeType_LOG)
 	optsLogWrite           = trees.NewGetOpts(trees.QueueLog, trillian.TreeType_LOG)
 	optsPreorderedLogWrite = trees.NewGetOpts(trees.SequenceLog, trillian.TreeType_PREORDERED_LOG)
 )
@@ -97,8 +106,9 @@ func (t *TrillianLogRPCServer) QueueLeaf(ctx context.Context, req *trillian.Queu
 	}
 
 	queueReq := &trillian.QueueLeavesRequest{
-		LogId:  req.LogId,
-		Leaves: []*trillian.LogLeaf{req.Leaf},
+		LogId:    req.LogId,
+		Leaves:   []*trillian.LogLeaf{req.Leaf},
+		ChargeTo: req.ChargeTo,
 	}
 	queueRsp, err := t.QueueLeaves(ctx, queueReq)
 	if err != nil {
@@ -137,6 +147,9 @@ func (t *TrillianLogRPCServer) QueueLeaves(ctx context.Context, req *trillian.Qu
 	}
 
 	ctx = trees.NewContext(ctx, tree)
+	if users := req.GetChargeTo().GetUser(); len(users) > 0 {
+		ctx = storage.NewSubmitterContext(ctx, users[0])
+	}
 
 	hashLeaves(req.Leaves, hasher)
 
@@ -237,6 +250,9 @@ func (t *TrillianLogRPCServer) GetInclusionProof(ctx context.Context, req *trill
 
 	// Next we need to make sure the requested tree size corresponds to an STH, so that we
 	// have a usable tree revision
+	// Replicas which haven't caught up with the requested tree size can't
+	// serve the proof.
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.TreeSize))
 	tx, err := t.snapshotForTree(ctx, tree, "GetInclusionProof")
 	if err != nil {
 		return nil, err
@@ -291,6 +307,7 @@ func (t *TrillianLogRPCServer) GetInclusionProofByHash(ctx context.Context, req
 
 	// Next we need to make sure the requested tree size corresponds to an STH, so that we
 	// have a usable tree revision
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.TreeSize))
 	tx, err := t.snapshotForTree(ctx, tree, "GetInclusionProofByHash")
 	if err != nil {
 		return nil, err
@@ -360,6 +377,7 @@ func (t *TrillianLogRPCServer) GetConsistencyProof(ctx context.Context, req *tri
 	}
 	ctx = trees.NewContext(ctx, tree)
 
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.SecondTreeSize))
 	tx, err := t.snapshotForTree(ctx, tree, "GetConsistencyProof")
 	if err != nil {
 		return nil, err
@@ -404,6 +422,7 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 		return nil, err
 	}
 	ctx = trees.NewContext(ctx, tree)
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.FirstTreeSize))
 	tx, err := t.registry.LogStorage.SnapshotForTree(ctx, tree)
 	if err != nil {
 		return nil, err
@@ -422,8 +441,10 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 
 	r := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: slr}
 
-	if req.FirstTreeSize == 0 {
-		// no need to get consistency proof in this case
+	// There is no need for a consistency proof if none was requested, and
+	// there can be none if the tree is smaller than the requested size, which
+	// the client must treat as a rollback of the log.
+	if req.FirstTreeSize == 0 || req.FirstTreeSize > int64(root.TreeSize) {
 		if err := t.commitAndLog(ctx, req.LogId, tx, "GetLatestSignedLogRoot"); err != nil {
 			return nil, err
 		}
@@ -451,6 +472,109 @@ func (t *TrillianLogRPCServer) GetLatestSignedLogRoot(ctx context.Context, req *
 	return r, nil
 }
 
+// GetSignedLogRoots returns the stored signed log roots of a tree which match
+// the request, in order of revision.
+func (t *TrillianLogRPCServer) GetSignedLogRoots(ctx context.Context, req *trillian.GetSignedLogRootsRequest) (*trillian.GetSignedLogRootsResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetSignedLogRoots")
+	defer spanEnd()
+	filter, err := signedLogRootsFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := int(req.PageSize)
+	if pageSize == 0 || pageSize > maxRootsPageSize {
+		pageSize = maxRootsPageSize
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetSignedLogRoots")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetSignedLogRoots")
+
+	htx, ok := tx.(storage.LogRootHistoryTX)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "log storage does not support reading earlier signed log roots")
+	}
+	// Read one more root than requested, to tell whether there is another page.
+	roots, err := htx.GetSignedLogRoots(ctx, filter, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetSignedLogRoots"); err != nil {
+		return nil, err
+	}
+
+	r := &trillian.GetSignedLogRootsResponse{SignedLogRoots: roots}
+	if len(roots) > pageSize {
+		r.SignedLogRoots = roots[:pageSize]
+		var last types.LogRootV1
+		if err := last.UnmarshalBinary(roots[pageSize-1].LogRoot); err != nil {
+			return nil, status.Errorf(codes.Internal, "Could not read log root: %v", err)
+		}
+		r.NextPageToken = strconv.FormatUint(last.Revision+1, 10)
+	}
+	return r, nil
+}
+
+// signedLogRootsFilter returns the storage filter selecting the roots for the
+// given request.
+func signedLogRootsFilter(req *trillian.GetSignedLogRootsRequest) (storage.LogRootFilter, error) {
+	var f storage.LogRootFilter
+	if req.PageSize < 0 {
+		return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.PageSize: %v, want >= 0", req.PageSize)
+	}
+	switch sel := req.Selector.(type) {
+	case nil:
+	case *trillian.GetSignedLogRootsRequest_TreeSizeRange_:
+		r := sel.TreeSizeRange
+		if r.GetBegin() < 0 || r.GetEnd() < 0 || (r.GetEnd() != 0 && r.GetEnd() <= r.GetBegin()) {
+			return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.TreeSizeRange: [%v, %v) is not a valid range", r.GetBegin(), r.GetEnd())
+		}
+		f.TreeSizeBegin, f.TreeSizeEnd = r.GetBegin(), r.GetEnd()
+	case *trillian.GetSignedLogRootsRequest_TimeRange_:
+		r := sel.TimeRange
+		if r.GetBegin() != nil {
+			begin, err := ptypes.Timestamp(r.GetBegin())
+			if err != nil {
+				return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.TimeRange.Begin: %v", err)
+			}
+			f.TimestampBegin = begin.UnixNano()
+		}
+		if r.GetEnd() != nil {
+			end, err := ptypes.Timestamp(r.GetEnd())
+			if err != nil {
+				return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.TimeRange.End: %v", err)
+			}
+			if f.TimestampEnd = end.UnixNano(); f.TimestampEnd <= f.TimestampBegin {
+				return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.TimeRange: end %v is not after begin", end)
+			}
+		}
+	case *trillian.GetSignedLogRootsRequest_Revision:
+		if sel.Revision < 0 {
+			return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.Revision: %v, want >= 0", sel.Revision)
+		}
+		f.RevisionBegin, f.RevisionEnd = sel.Revision, sel.Revision+1
+	default:
+		return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.Selector: unknown type %T", sel)
+	}
+
+	if req.PageToken != "" {
+		next, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || next < 0 {
+			return f, status.Errorf(codes.InvalidArgument, "GetSignedLogRootsRequest.PageToken: %q is not valid", req.PageToken)
+		}
+		if next > f.RevisionBegin {
+			f.RevisionBegin = next
+		}
+	}
+	return f, nil
+}
+
 func tryGetConsistencyProof(ctx context.Context, firstTreeSize, secondTreeSize, rootTreeSize int64, tx storage.ReadOnlyLogTreeTX, hasher hashers.LogHasher) (*trillian.Proof, error) {
 	nodeFetches, err := merkle.CalcConsistencyProofNodeAddresses(firstTreeSize, secondTreeSize, rootTreeSize)
 	if err != nil {
@@ -505,13 +629,22 @@ func (t *TrillianLogRPCServer) GetLeavesByIndex(ctx context.Context, req *trilli
 	if err != nil {
 		return nil, err
 	}
+	// Replicas which haven't caught up with the requested leaves can't serve
+	// them.
+	var minTreeSize uint64
+	for _, index := range req.LeafIndex {
+		if size := uint64(index) + 1; size > minTreeSize {
+			minTreeSize = size
+		}
+	}
+	ctx = storage.NewMinTreeSizeContext(ctx, minTreeSize)
 	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByIndex")
 	if err != nil {
 		return nil, err
 	}
 	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeavesByIndex")
 
-	leaves, err := tx.GetLeavesByIndex(ctx, req.LeafIndex)
+	leaves, err := getLeavesByIndex(ctx, tx, req.LeafIndex, req.HashesOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -547,6 +680,9 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	if err != nil {
 		return nil, err
 	}
+	// Replicas which haven't caught up with the requested range would return
+	// a truncated one.
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.StartIndex)+uint64(req.Count))
 	tx, err := t.snapshotForTree(ctx, tree, "GetLeavesByRange")
 	if err != nil {
 		return nil, err
@@ -565,7 +701,7 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	r := &trillian.GetLeavesByRangeResponse{SignedLogRoot: slr}
 
 	if req.StartIndex < int64(root.TreeSize) {
-		leaves, err := tx.GetLeavesByRange(ctx, req.StartIndex, req.Count)
+		leaves, err := getLeavesByRange(ctx, tx, req.StartIndex, req.Count, req.HashesOnly)
 		if err != nil {
 			return nil, err
 		}
@@ -580,6 +716,48 @@ func (t *TrillianLogRPCServer) GetLeavesByRange(ctx context.Context, req *trilli
 	return r, nil
 }
 
+// getLeavesByIndex reads the leaves with the given indices. If hashesOnly is
+// set, only their Merkle leaf hashes and indices are returned, which are read
+// without the leaf data if tx supports it.
+func getLeavesByIndex(ctx context.Context, tx storage.ReadOnlyLogTreeTX, indices []int64, hashesOnly bool) ([]*trillian.LogLeaf, error) {
+	if !hashesOnly {
+		return tx.GetLeavesByIndex(ctx, indices)
+	}
+	if htx, ok := tx.(storage.LeafHashesTX); ok {
+		return htx.GetLeafHashesByIndex(ctx, indices)
+	}
+	leaves, err := tx.GetLeavesByIndex(ctx, indices)
+	if err != nil {
+		return nil, err
+	}
+	return leafHashes(leaves), nil
+}
+
+// getLeavesByRange is like getLeavesByIndex, for a range of leaves.
+func getLeavesByRange(ctx context.Context, tx storage.ReadOnlyLogTreeTX, start, count int64, hashesOnly bool) ([]*trillian.LogLeaf, error) {
+	if !hashesOnly {
+		return tx.GetLeavesByRange(ctx, start, count)
+	}
+	if htx, ok := tx.(storage.LeafHashesTX); ok {
+		return htx.GetLeafHashesByRange(ctx, start, count)
+	}
+	leaves, err := tx.GetLeavesByRange(ctx, start, count)
+	if err != nil {
+		return nil, err
+	}
+	return leafHashes(leaves), nil
+}
+
+// leafHashes returns copies of the given leaves with only their Merkle leaf
+// hashes and indices.
+func leafHashes(leaves []*trillian.LogLeaf) []*trillian.LogLeaf {
+	ret := make([]*trillian.LogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		ret[i] = &trillian.LogLeaf{MerkleLeafHash: leaf.MerkleLeafHash, LeafIndex: leaf.LeafIndex}
+	}
+	return ret
+}
+
 // GetLeavesByHash obtains one or more leaves based on their tree hash. It is not possible
 // to fetch leaves that have been queued but not yet integrated. Logs may accept duplicate
 // entries so this may return more results than the number of hashes in the request.
@@ -627,6 +805,161 @@ func (t *TrillianLogRPCServer) GetLeavesByHash(ctx context.Context, req *trillia
 	}, nil
 }
 
+// GetLeafIndexRangeByTime returns the range of indices of the leaves which were
+// integrated within the requested time window, relative to the latest signed
+// log root.
+func (t *TrillianLogRPCServer) GetLeafIndexRangeByTime(ctx context.Context, req *trillian.GetLeafIndexRangeByTimeRequest) (*trillian.GetLeafIndexRangeByTimeResponse, error) {
+	ctx, spanEnd := spanFor(ctx, "GetLeafIndexRangeByTime")
+	defer spanEnd()
+	begin, end, err := leafTimeWindow(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, ctx, err := t.getTreeAndContext(ctx, req.LogId, optsLogTimeRead)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.snapshotForTree(ctx, tree, "GetLeafIndexRangeByTime")
+	if err != nil {
+		return nil, err
+	}
+	defer t.closeAndLog(ctx, tree.TreeId, tx, "GetLeafIndexRangeByTime")
+
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not read current log root: %v", err)
+	}
+
+	treeSize := int64(root.TreeSize)
+	r := &trillian.GetLeafIndexRangeByTimeResponse{EndIndex: treeSize, SignedLogRoot: slr}
+	if !begin.IsZero() {
+		if r.BeginIndex, err = leafIndexAt(ctx, tx, begin, treeSize); err != nil {
+			return nil, err
+		}
+	}
+	if !end.IsZero() {
+		if r.EndIndex, err = leafIndexAt(ctx, tx, end, treeSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.commitAndLog(ctx, req.LogId, tx, "GetLeafIndexRangeByTime"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// leafTimeWindow returns the bounds of the time window of the given request.
+// An unset bound is returned as the zero time.
+func leafTimeWindow(req *trillian.GetLeafIndexRangeByTimeRequest) (time.Time, time.Time, error) {
+	var begin, end time.Time
+	if req.BeginTime != nil {
+		var err error
+		if begin, err = ptypes.Timestamp(req.BeginTime); err != nil {
+			return begin, end, status.Errorf(codes.InvalidArgument, "GetLeafIndexRangeByTimeRequest.BeginTime: %v", err)
+		}
+	}
+	if req.EndTime != nil {
+		var err error
+		if end, err = ptypes.Timestamp(req.EndTime); err != nil {
+			return begin, end, status.Errorf(codes.InvalidArgument, "GetLeafIndexRangeByTimeRequest.EndTime: %v", err)
+		}
+		if !end.After(begin) {
+			return begin, end, status.Errorf(codes.InvalidArgument, "GetLeafIndexRangeByTimeRequest: end time %v is not after begin time", end)
+		}
+	}
+	return begin, end, nil
+}
+
+// leafIndexAt returns the lowest index below treeSize of a leaf which was
+// integrated at or after ts, or treeSize if there is no such leaf.
+//
+// Storage which indexes leaves by time answers this directly. Otherwise, as
+// each signed root is timestamped after all of its leaves were integrated, the
+// roots on either side of ts bound the index, and the leaves between them are
+// binary searched.
+func leafIndexAt(ctx context.Context, tx storage.ReadOnlyLogTreeTX, ts time.Time, treeSize int64) (int64, error) {
+	if itx, ok := tx.(storage.LeafTimeIndexTX); ok {
+		return itx.GetLeafIndexByTime(ctx, ts, treeSize)
+	}
+
+	lo, hi := int64(0), treeSize
+	if htx, ok := tx.(storage.LogRootHistoryTX); ok {
+		var err error
+		if lo, hi, err = leafIndexBounds(ctx, htx, ts, treeSize); err != nil {
+			return 0, err
+		}
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		leaves, err := tx.GetLeavesByRange(ctx, mid, 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(leaves) != 1 {
+			return 0, status.Errorf(codes.Internal, "leaf %d is missing", mid)
+		}
+		integrated, err := ptypes.Timestamp(leaves[0].IntegrateTimestamp)
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "Could not read integrate timestamp of leaf %d: %v", mid, err)
+		}
+		if integrated.Before(ts) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// leafIndexBounds returns the range of indices below treeSize which contains
+// the lowest index of a leaf integrated at or after ts, using the sizes of the
+// last root signed before ts and the first one signed at or after it.
+func leafIndexBounds(ctx context.Context, tx storage.LogRootHistoryTX, ts time.Time, treeSize int64) (int64, int64, error) {
+	after, err := tx.GetSignedLogRoots(ctx, storage.LogRootFilter{TimestampBegin: ts.UnixNano()}, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(after) == 0 {
+		// Every root, including the latest one, was signed before ts.
+		return treeSize, treeSize, nil
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(after[0].LogRoot); err != nil {
+		return 0, 0, status.Errorf(codes.Internal, "Could not read log root: %v", err)
+	}
+	hi := int64(root.TreeSize)
+	if hi > treeSize {
+		hi = treeSize
+	}
+	if root.Revision == 0 {
+		return 0, hi, nil
+	}
+
+	rev := int64(root.Revision)
+	before, err := tx.GetSignedLogRoots(ctx, storage.LogRootFilter{RevisionBegin: rev - 1, RevisionEnd: rev}, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(before) == 0 {
+		// Earlier roots may have been removed; fall back to the whole range.
+		return 0, hi, nil
+	}
+	if err := root.UnmarshalBinary(before[0].LogRoot); err != nil {
+		return 0, 0, status.Errorf(codes.Internal, "Could not read log root: %v", err)
+	}
+	lo := int64(root.TreeSize)
+	if lo > hi {
+		lo = hi
+	}
+	return lo, hi, nil
+}
+
 // GetEntryAndProof returns both a Merkle Leaf entry and an inclusion proof for a given index
 // and tree size.
 func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trillian.GetEntryAndProofRequest) (*trillian.GetEntryAndProofResponse, error) {
@@ -645,6 +978,9 @@ func (t *TrillianLogRPCServer) GetEntryAndProof(ctx context.Context, req *trilli
 
 	// Next we need to make sure the requested tree size corresponds to an STH, so that we
 	// have a usable tree revision
+	// Replicas which haven't caught up with the requested tree size can't
+	// serve the proof.
+	ctx = storage.NewMinTreeSizeContext(ctx, uint64(req.TreeSize))
 	tx, err := t.snapshotForTree(ctx, tree, "GetEntryAndProof")
 	if err != nil {
 		return nil, err
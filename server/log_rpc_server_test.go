@@ -17,6 +17,7 @@ package server
 import (
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strings"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/trillian"
 	tcrypto "github.com/google/trillian/crypto"
@@ -35,6 +37,7 @@ import (
 	_ "github.com/google/trillian/merkle/rfc6962" // Register the hasher.
 	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
 	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/testonly"
@@ -57,6 +60,18 @@ func (m cmpMatcher) String() string {
 	return fmt.Sprintf("equals %v", m.want)
 }
 
+// minTreeSizeMatcher matches contexts carrying the given minimum tree size.
+type minTreeSizeMatcher uint64
+
+func (m minTreeSizeMatcher) Matches(got interface{}) bool {
+	ctx, ok := got.(context.Context)
+	return ok && storage.MinTreeSizeFromContext(ctx) == uint64(m)
+}
+
+func (m minTreeSizeMatcher) String() string {
+	return fmt.Sprintf("context with minimum tree size %d", uint64(m))
+}
+
 func newTestLeaf(data []byte, extra []byte, index int64) *trillian.LogLeaf {
 	hash := th.HashLeaf(data)
 	return &trillian.LogLeaf{
@@ -243,7 +258,7 @@ func TestGetLeavesByIndex(t *testing.T) {
 			name: "ok multiple",
 			setupStorage: func(c *gomock.Controller, s *storage.MockLogStorage) {
 				tx := storage.NewMockLogTreeTX(c)
-				s.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).Return(tx, nil)
+				s.EXPECT().SnapshotForTree(minTreeSizeMatcher(4), cmpMatcher{tree1}).Return(tx, nil)
 				tx.EXPECT().GetLeavesByIndex(gomock.Any(), []int64{0, 3}).Return([]*trillian.LogLeaf{leaf1, leaf3}, nil)
 				tx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(signedRoot1, nil)
 				tx.EXPECT().Commit(gomock.Any()).Return(nil)
@@ -383,7 +398,7 @@ func TestGetLeavesByRange(t *testing.T) {
 					if root == nil {
 						root = signedRoot1
 					}
-					fakeStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree}).Return(mockTX, nil)
+					fakeStorage.EXPECT().SnapshotForTree(minTreeSizeMatcher(test.start+test.count), cmpMatcher{tree}).Return(mockTX, nil)
 					mockTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(root, test.slrErr)
 
 					if test.root == nil {
@@ -642,6 +657,14 @@ func TestGetLatestSignedLogRoot(t *testing.T) {
 			wantRoot:    &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: signedRoot1},
 			storageRoot: signedRoot1,
 		},
+		{
+			desc: "first_size_beyond_tree",
+			// Test that a tree smaller than the requested size is returned
+			// without a consistency proof, for the client to reject.
+			req:         &trillian.GetLatestSignedLogRootRequest{LogId: logID1, FirstTreeSize: 8},
+			wantRoot:    &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: signedRoot1},
+			storageRoot: signedRoot1,
+		},
 	}
 
 	for _, test := range tests {
@@ -680,8 +703,8 @@ func TestGetLatestSignedLogRoot(t *testing.T) {
 					return
 				}
 				// Ensure we got the expected root back.
-				if !proto.Equal(got.SignedLogRoot, test.wantRoot.SignedLogRoot) {
-					t.Errorf("GetConsistencyProof(%+v)=%v,nil, want: %v,nil", test.req, got, test.wantRoot)
+				if !proto.Equal(got, test.wantRoot) {
+					t.Errorf("GetLatestSignedLogRoot(%+v)=%v,nil, want: %v,nil", test.req, got, test.wantRoot)
 				}
 			}
 		})
@@ -1311,7 +1334,7 @@ func TestGetEntryAndProof(t *testing.T) {
 			name: "storage error",
 			setupStorage: func(c *gomock.Controller, s *storage.MockLogStorage) {
 				tx := storage.NewMockLogTreeTX(c)
-				s.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).Return(tx, nil)
+				s.EXPECT().SnapshotForTree(minTreeSizeMatcher(7), cmpMatcher{tree1}).Return(tx, nil)
 				tx.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(signedRoot1, nil)
 				tx.EXPECT().GetMerkleNodes(gomock.Any(), nodeIdsInclusionSize7Index2).Return([]tree.Node{
 					{ID: nodeIdsInclusionSize7Index2[0], Hash: []byte("nodehash0")},
@@ -2327,3 +2350,349 @@ func newSignerWithFixedSig(sig []byte) crypto.Signer {
 	}
 	return testonly.NewSignerWithFixedSig(key, sig)
 }
+
+func TestGetSignedLogRoots(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	logTree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	// Store roots at revisions 0 to 4, with sizes 0 to 40 and timestamps
+	// 100s to 104s.
+	for i := 0; i < 5; i++ {
+		if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			var rev int64
+			if i > 0 {
+				var err error
+				if rev, err = tx.WriteRevision(ctx); err != nil {
+					return err
+				}
+			}
+			root := &types.LogRootV1{TreeSize: uint64(10 * i), TimestampNanos: uint64(time.Duration(100+i) * time.Second), Revision: uint64(rev)}
+			logRoot, err := root.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+		}); err != nil {
+			t.Fatalf("StoreSignedLogRoot(%d): %v", i, err)
+		}
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	for _, tc := range []struct {
+		desc string
+		req  *trillian.GetSignedLogRootsRequest
+		// want holds the revisions of the pages of roots returned.
+		want     [][]uint64
+		wantCode codes.Code
+	}{
+		{
+			desc: "all",
+			req:  &trillian.GetSignedLogRootsRequest{},
+			want: [][]uint64{{0, 1, 2, 3, 4}},
+		},
+		{
+			desc: "paged",
+			req:  &trillian.GetSignedLogRootsRequest{PageSize: 2},
+			want: [][]uint64{{0, 1}, {2, 3}, {4}},
+		},
+		{
+			desc: "revision",
+			req:  &trillian.GetSignedLogRootsRequest{Selector: &trillian.GetSignedLogRootsRequest_Revision{Revision: 3}},
+			want: [][]uint64{{3}},
+		},
+		{
+			desc: "tree-size-range",
+			req: &trillian.GetSignedLogRootsRequest{
+				Selector: &trillian.GetSignedLogRootsRequest_TreeSizeRange_{TreeSizeRange: &trillian.GetSignedLogRootsRequest_TreeSizeRange{Begin: 5, End: 31}},
+				PageSize: 2,
+			},
+			want: [][]uint64{{1, 2}, {3}},
+		},
+		{
+			desc: "time-range",
+			req: &trillian.GetSignedLogRootsRequest{
+				Selector: &trillian.GetSignedLogRootsRequest_TimeRange_{TimeRange: &trillian.GetSignedLogRootsRequest_TimeRange{
+					Begin: &timestamp.Timestamp{Seconds: 102},
+				}},
+			},
+			want: [][]uint64{{2, 3, 4}},
+		},
+		{
+			desc: "no-match",
+			req:  &trillian.GetSignedLogRootsRequest{Selector: &trillian.GetSignedLogRootsRequest_Revision{Revision: 5}},
+			want: [][]uint64{nil},
+		},
+		{
+			desc:     "bad-page-size",
+			req:      &trillian.GetSignedLogRootsRequest{PageSize: -1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc:     "bad-page-token",
+			req:      &trillian.GetSignedLogRootsRequest{PageToken: "x"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc: "bad-tree-size-range",
+			req: &trillian.GetSignedLogRootsRequest{
+				Selector: &trillian.GetSignedLogRootsRequest_TreeSizeRange_{TreeSizeRange: &trillian.GetSignedLogRootsRequest_TreeSizeRange{Begin: 5, End: 5}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			desc: "bad-time-range",
+			req: &trillian.GetSignedLogRootsRequest{
+				Selector: &trillian.GetSignedLogRootsRequest_TimeRange_{TimeRange: &trillian.GetSignedLogRootsRequest_TimeRange{
+					Begin: &timestamp.Timestamp{Seconds: 102},
+					End:   &timestamp.Timestamp{Seconds: 101},
+				}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := proto.Clone(tc.req).(*trillian.GetSignedLogRootsRequest)
+			req.LogId = logTree.TreeId
+			var got [][]uint64
+			for {
+				rsp, err := server.GetSignedLogRoots(ctx, req)
+				if status.Code(err) != tc.wantCode {
+					t.Fatalf("GetSignedLogRoots(): %v, want code %v", err, tc.wantCode)
+				}
+				if err != nil {
+					return
+				}
+				var page []uint64
+				for _, slr := range rsp.SignedLogRoots {
+					var root types.LogRootV1
+					if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+						t.Fatalf("UnmarshalBinary(): %v", err)
+					}
+					page = append(page, root.Revision)
+				}
+				got = append(got, page)
+				if rsp.NextPageToken == "" {
+					break
+				}
+				req.PageToken = rsp.NextPageToken
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("GetSignedLogRoots(): pages diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetSignedLogRootsUnimplemented(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storage.NewMockLogStorage(ctrl)
+	mockTX := storage.NewMockLogTreeTX(ctrl)
+	mockStorage.EXPECT().SnapshotForTree(gomock.Any(), cmpMatcher{tree1}).Return(mockTX, nil)
+	mockTX.EXPECT().Close().Return(nil)
+	registry := extension.Registry{
+		AdminStorage: fakeAdminStorage(ctrl, storageParams{treeID: tree1.TreeId, numSnapshots: 1}),
+		LogStorage:   mockStorage,
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	_, err := server.GetSignedLogRoots(context.Background(), &trillian.GetSignedLogRootsRequest{LogId: tree1.TreeId})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("GetSignedLogRoots(): %v, want code %v", err, codes.Unimplemented)
+	}
+}
+
+func TestGetLeafIndexRangeByTime(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	logTree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	storeRoot := func(tx storage.LogTreeTX, root *types.LogRootV1) error {
+		logRoot, err := root.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(tx, &types.LogRootV1{TimestampNanos: uint64(100 * time.Second)})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	// Integrate batches 1 to 4 of two leaves each at 101s to 104s, and sign a
+	// root for each batch half a second later.
+	for b := 1; b <= 4; b++ {
+		var leaves []*trillian.LogLeaf
+		for i := 2 * (b - 1); i < 2*b; i++ {
+			hash := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafIndex: int64(i)})
+		}
+		if _, err := registry.LogStorage.QueueLeaves(ctx, logTree, leaves, fakeTime); err != nil {
+			t.Fatalf("QueueLeaves(): %v", err)
+		}
+		integrated := time.Duration(100+b) * time.Second
+		if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			for _, leaf := range leaves {
+				leaf.IntegrateTimestamp = &timestamp.Timestamp{Seconds: int64(integrated / time.Second)}
+			}
+			if err := tx.UpdateSequencedLeaves(ctx, leaves); err != nil {
+				return err
+			}
+			rev, err := tx.WriteRevision(ctx)
+			if err != nil {
+				return err
+			}
+			return storeRoot(tx, &types.LogRootV1{TreeSize: uint64(2 * b), TimestampNanos: uint64(integrated + time.Second/2), Revision: uint64(rev)})
+		}); err != nil {
+			t.Fatalf("Failed to integrate batch %d: %v", b, err)
+		}
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	at := func(secs, nanos int64) *timestamp.Timestamp {
+		return &timestamp.Timestamp{Seconds: secs, Nanos: int32(nanos)}
+	}
+	for _, tc := range []struct {
+		desc       string
+		begin, end *timestamp.Timestamp
+		wantBegin  int64
+		wantEnd    int64
+		wantCode   codes.Code
+	}{
+		{desc: "all", wantBegin: 0, wantEnd: 8},
+		{desc: "from-batch", begin: at(102, 0), wantBegin: 2, wantEnd: 8},
+		{desc: "one-batch", begin: at(102, 0), end: at(103, 0), wantBegin: 2, wantEnd: 4},
+		{desc: "between-roots", begin: at(101, 7e8), end: at(103, 2e8), wantBegin: 2, wantEnd: 6},
+		{desc: "until-batch", end: at(102, 1), wantBegin: 0, wantEnd: 4},
+		{desc: "after-all", begin: at(105, 0), wantBegin: 8, wantEnd: 8},
+		{desc: "before-all", end: at(100, 0), wantBegin: 0, wantEnd: 0},
+		{desc: "empty-window", begin: at(102, 0), end: at(101, 0), wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			rsp, err := server.GetLeafIndexRangeByTime(ctx, &trillian.GetLeafIndexRangeByTimeRequest{LogId: logTree.TreeId, BeginTime: tc.begin, EndTime: tc.end})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("GetLeafIndexRangeByTime(): %v, want code %v", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if rsp.BeginIndex != tc.wantBegin || rsp.EndIndex != tc.wantEnd {
+				t.Errorf("GetLeafIndexRangeByTime(): [%d, %d), want [%d, %d)", rsp.BeginIndex, rsp.EndIndex, tc.wantBegin, tc.wantEnd)
+			}
+		})
+	}
+}
+
+func TestGetLeavesHashesOnly(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	registry := extension.Registry{
+		AdminStorage: memory.NewAdminStorage(ts),
+		LogStorage:   memory.NewLogStorage(ts, nil),
+	}
+	logTree, err := storage.CreateTree(ctx, registry.AdminStorage, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	storeRoot := func(tx storage.LogTreeTX, root *types.LogRootV1) error {
+		logRoot, err := root.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: logRoot})
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(tx, &types.LogRootV1{})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	var leaves, want []*trillian.LogLeaf
+	for i := 0; i < 3; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafIndex: int64(i), LeafValue: []byte("value"), ExtraData: []byte("extra")})
+		want = append(want, &trillian.LogLeaf{MerkleLeafHash: hash[:], LeafIndex: int64(i)})
+	}
+	if _, err := registry.LogStorage.QueueLeaves(ctx, logTree, leaves, fakeTime); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if err := registry.LogStorage.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := tx.UpdateSequencedLeaves(ctx, leaves); err != nil {
+			return err
+		}
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		return storeRoot(tx, &types.LogRootV1{TreeSize: 3, TimestampNanos: 1, Revision: uint64(rev)})
+	}); err != nil {
+		t.Fatalf("Failed to integrate leaves: %v", err)
+	}
+	server := NewTrillianLogRPCServer(registry, fakeTimeSource)
+
+	// The memory storage doesn't implement storage.LeafHashesTX, so the leaf
+	// data is stripped by the server.
+	rangeRsp, err := server.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: logTree.TreeId, Count: 3, HashesOnly: true})
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	if diff := cmp.Diff(want, rangeRsp.Leaves, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("GetLeavesByRange(): diff (-want +got):\n%s", diff)
+	}
+	indexRsp, err := server.GetLeavesByIndex(ctx, &trillian.GetLeavesByIndexRequest{LogId: logTree.TreeId, LeafIndex: []int64{2, 0}, HashesOnly: true})
+	if err != nil {
+		t.Fatalf("GetLeavesByIndex(): %v", err)
+	}
+	if diff := cmp.Diff([]*trillian.LogLeaf{want[2], want[0]}, indexRsp.Leaves, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("GetLeavesByIndex(): diff (-want +got):\n%s", diff)
+	}
+
+	// Storage which implements storage.LeafHashesTX is used instead.
+	htx := fakeLeafHashesTX{leaves: want}
+	got, err := getLeavesByRange(ctx, htx, 0, 3, true)
+	if err != nil {
+		t.Fatalf("getLeavesByRange(): %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("getLeavesByRange(): diff (-want +got):\n%s", diff)
+	}
+	got, err = getLeavesByIndex(ctx, htx, []int64{1}, true)
+	if err != nil {
+		t.Fatalf("getLeavesByIndex(): %v", err)
+	}
+	if diff := cmp.Diff(want[1:2], got, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("getLeavesByIndex(): diff (-want +got):\n%s", diff)
+	}
+}
+
+// fakeLeafHashesTX is a storage.LeafHashesTX which only implements the
+// methods of that interface, returning leaves.
+type fakeLeafHashesTX struct {
+	storage.ReadOnlyLogTreeTX
+	leaves []*trillian.LogLeaf
+}
+
+func (f fakeLeafHashesTX) GetLeafHashesByIndex(_ context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	ret := make([]*trillian.LogLeaf, len(indices))
+	for i, index := range indices {
+		ret[i] = f.leaves[index]
+	}
+	return ret, nil
+}
+
+func (f fakeLeafHashesTX) GetLeafHashesByRange(_ context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	return f.leaves[start : start+count], nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admincache provides a storage.AdminStorage which caches the trees
+// read from another AdminStorage, so that looking up the tree of every
+// request doesn't hit the database.
+package admincache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/util/clock"
+)
+
+var (
+	once          sync.Once
+	hitsCounter   monitoring.Counter
+	missesCounter monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	hitsCounter = mf.NewCounter("admin_cache_hits", "Number of trees read from the admin storage cache")
+	missesCounter = mf.NewCounter("admin_cache_misses", "Number of trees read from the admin storage because they weren't cached")
+}
+
+// Options configures an AdminStorage.
+type Options struct {
+	// TTL is the time for which a tree read from the underlying storage is
+	// served from the cache. It bounds the time it takes for the changes
+	// made to a tree by other processes, such as freezing or deleting it, to
+	// be seen through the cache.
+	TTL time.Duration
+	// TimeSource is used to expire the cached trees, and may be mocked out by
+	// tests.
+	TimeSource clock.TimeSource
+}
+
+// entry is a cached tree.
+type entry struct {
+	tree    *trillian.Tree
+	expires time.Time
+}
+
+// AdminStorage is a storage.AdminStorage which serves GetTree in snapshots
+// from a cache of the trees read from the underlying AdminStorage. Other
+// reads, and everything in read-write transactions, go to the underlying
+// storage. The trees written through the AdminStorage are removed from the
+// cache, while the changes made by other processes are seen when the cached
+// trees expire, or when they are passed to Invalidate.
+type AdminStorage struct {
+	storage.AdminStorage
+	opts Options
+
+	mu    sync.Mutex
+	trees map[int64]entry
+	// gen is incremented by every invalidation, so that trees read before it
+	// aren't cached after it.
+	gen uint64
+}
+
+// NewAdminStorage returns an AdminStorage which caches the trees read from
+// as.
+func NewAdminStorage(as storage.AdminStorage, opts Options, mf monitoring.MetricFactory) *AdminStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	once.Do(func() {
+		createMetrics(mf)
+	})
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	return &AdminStorage{
+		AdminStorage: as,
+		opts:         opts,
+		trees:        make(map[int64]entry),
+	}
+}
+
+// get returns a copy of the cached tree with the given ID, or nil if it isn't
+// cached or has expired.
+func (s *AdminStorage) get(treeID int64) *trillian.Tree {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.trees[treeID]
+	if !ok {
+		return nil
+	}
+	if !s.opts.TimeSource.Now().Before(e.expires) {
+		delete(s.trees, treeID)
+		return nil
+	}
+	return proto.Clone(e.tree).(*trillian.Tree)
+}
+
+// generation returns the current generation of the cache.
+func (s *AdminStorage) generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen
+}
+
+// put caches a copy of the given trees, which were read from the underlying
+// storage at the given generation of the cache, unless that's changed since.
+func (s *AdminStorage) put(trees []*trillian.Tree, gen uint64) {
+	expires := s.opts.TimeSource.Now().Add(s.opts.TTL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen {
+		return
+	}
+	for _, tree := range trees {
+		s.trees[tree.TreeId] = entry{tree: proto.Clone(tree).(*trillian.Tree), expires: expires}
+	}
+}
+
+// Invalidate removes the tree with the given ID from the cache, or all trees
+// if treeID is 0.
+func (s *AdminStorage) Invalidate(treeID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	if treeID == 0 {
+		s.trees = make(map[int64]entry)
+		return
+	}
+	delete(s.trees, treeID)
+}
+
+// Watch invalidates the trees whose IDs are received from changes, as per
+// Invalidate, until ctx is done or changes is closed. It can be used to see
+// the changes made by other processes before the cached trees expire, given
+// a source of notifications of the changes.
+func (s *AdminStorage) Watch(ctx context.Context, changes <-chan int64) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id, ok := <-changes:
+			if !ok {
+				return
+			}
+			s.Invalidate(id)
+		}
+	}
+}
+
+// Snapshot implements storage.AdminStorage. The underlying snapshot is only
+// started when something not in the cache is read.
+func (s *AdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return &snapshotTX{s: s}, nil
+}
+
+// ReadWriteTransaction implements storage.AdminStorage. The trees written by
+// f are invalidated once the transaction is done, whether it succeeded or not.
+func (s *AdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	written := make(map[int64]bool)
+	defer func() {
+		for id := range written {
+			s.Invalidate(id)
+		}
+	}()
+	return s.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		return f(ctx, &writeTX{AdminTX: tx, written: written})
+	})
+}
+
+// snapshotTX is a storage.ReadOnlyAdminTX which reads trees from the cache,
+// or from an underlying snapshot started on demand.
+type snapshotTX struct {
+	s      *AdminStorage
+	tx     storage.ReadOnlyAdminTX
+	closed bool
+	// read are the trees read from the underlying snapshot, which are cached
+	// when it's committed, and gen the generation of the cache when it was
+	// started.
+	read []*trillian.Tree
+	gen  uint64
+}
+
+// snapshot returns the underlying snapshot, starting it if needed.
+func (t *snapshotTX) snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	if t.tx == nil {
+		t.gen = t.s.generation()
+		tx, err := t.s.AdminStorage.Snapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.tx = tx
+	}
+	return t.tx, nil
+}
+
+func (t *snapshotTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	if tree := t.s.get(treeID); tree != nil {
+		hitsCounter.Inc()
+		return tree, nil
+	}
+	missesCounter.Inc()
+	tx, err := t.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := tx.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	t.read = append(t.read, tree)
+	return tree, nil
+}
+
+func (t *snapshotTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	tx, err := t.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ListTreeIDs(ctx, includeDeleted)
+}
+
+func (t *snapshotTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	tx, err := t.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ListTrees(ctx, includeDeleted)
+}
+
+func (t *snapshotTX) Commit() error {
+	t.closed = true
+	if t.tx == nil {
+		return nil
+	}
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	t.s.put(t.read, t.gen)
+	return nil
+}
+
+func (t *snapshotTX) Rollback() error {
+	t.closed = true
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Rollback()
+}
+
+func (t *snapshotTX) IsClosed() bool {
+	return t.closed
+}
+
+func (t *snapshotTX) Close() error {
+	if t.closed {
+		return nil
+	}
+	return t.Rollback()
+}
+
+// writeTX is a storage.AdminTX which records the IDs of the trees written
+// through it.
+type writeTX struct {
+	storage.AdminTX
+	written map[int64]bool
+}
+
+func (t *writeTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	ret, err := t.AdminTX.CreateTree(ctx, tree)
+	if ret != nil {
+		t.written[ret.TreeId] = true
+	}
+	return ret, err
+}
+
+func (t *writeTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	t.written[treeID] = true
+	return t.AdminTX.UpdateTree(ctx, treeID, updateFunc)
+}
+
+func (t *writeTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	t.written[treeID] = true
+	return t.AdminTX.SoftDeleteTree(ctx, treeID)
+}
+
+func (t *writeTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	t.written[treeID] = true
+	return t.AdminTX.HardDeleteTree(ctx, treeID)
+}
+
+func (t *writeTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	t.written[treeID] = true
+	return t.AdminTX.UndeleteTree(ctx, treeID)
+}
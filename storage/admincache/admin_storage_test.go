@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admincache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/util/clock"
+)
+
+const ttl = time.Minute
+
+// countingStorage is an AdminStorage which counts the snapshots started.
+type countingStorage struct {
+	storage.AdminStorage
+	snapshots int
+}
+
+func (s *countingStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	s.snapshots++
+	return s.AdminStorage.Snapshot(ctx)
+}
+
+// setup returns a cache over a memory AdminStorage with a log in it, and the
+// log.
+func setup(ctx context.Context, t *testing.T) (*AdminStorage, *countingStorage, *clock.FakeTimeSource, *trillian.Tree) {
+	t.Helper()
+	base := &countingStorage{AdminStorage: memory.NewAdminStorage(memory.NewTreeStorage())}
+	ts := clock.NewFake(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+	s := NewAdminStorage(base, Options{TTL: ttl, TimeSource: ts}, nil)
+	tree, err := storage.CreateTree(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	return s, base, ts, tree
+}
+
+// checkState checks the state of the tree read through s, and the number of
+// snapshots of the underlying storage so far.
+func checkState(ctx context.Context, t *testing.T, s *AdminStorage, base *countingStorage, treeID int64, want trillian.TreeState, wantSnapshots int) {
+	t.Helper()
+	tree, err := storage.GetTree(ctx, s, treeID)
+	if err != nil {
+		t.Fatalf("GetTree(): %v", err)
+	}
+	if tree.TreeState != want {
+		t.Errorf("GetTree() state=%v, want %v", tree.TreeState, want)
+	}
+	if base.snapshots != wantSnapshots {
+		t.Errorf("%d underlying snapshots, want %d", base.snapshots, wantSnapshots)
+	}
+}
+
+func freeze(tree *trillian.Tree) {
+	tree.TreeState = trillian.TreeState_FROZEN
+}
+
+func TestGetTreeExpiry(t *testing.T) {
+	ctx := context.Background()
+	s, base, ts, tree := setup(ctx, t)
+
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_ACTIVE, 1)
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_ACTIVE, 1)
+
+	// A change by another process is only seen once the cached tree expires.
+	if _, err := storage.UpdateTree(ctx, base, tree.TreeId, freeze); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	ts.Set(ts.Now().Add(ttl - time.Second))
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_ACTIVE, 1)
+	ts.Set(ts.Now().Add(time.Second))
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_FROZEN, 2)
+}
+
+func TestWriteInvalidates(t *testing.T) {
+	ctx := context.Background()
+	s, base, _, tree := setup(ctx, t)
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_ACTIVE, 1)
+
+	if _, err := storage.UpdateTree(ctx, s, tree.TreeId, freeze); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_FROZEN, 2)
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, base, _, tree := setup(ctx, t)
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_ACTIVE, 1)
+
+	if _, err := storage.UpdateTree(ctx, base, tree.TreeId, freeze); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	changes := make(chan int64)
+	done := make(chan struct{})
+	go func() {
+		s.Watch(ctx, changes)
+		close(done)
+	}()
+	changes <- tree.TreeId
+	close(changes)
+	<-done
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_FROZEN, 2)
+}
+
+func TestStaleReadNotCached(t *testing.T) {
+	ctx := context.Background()
+	s, base, _, tree := setup(ctx, t)
+
+	// The tree is changed while a snapshot which read it is still open.
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot(): %v", err)
+	}
+	if _, err := tx.GetTree(ctx, tree.TreeId); err != nil {
+		t.Fatalf("GetTree(): %v", err)
+	}
+	if _, err := storage.UpdateTree(ctx, s, tree.TreeId, freeze); err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+	checkState(ctx, t, s, base, tree.TreeId, trillian.TreeState_FROZEN, 2)
+}
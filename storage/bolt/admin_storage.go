@@ -0,0 +1,309 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewAdminStorage returns a Bolt storage.AdminStorage implementation backed by DB.
+func NewAdminStorage(db *bbolt.DB) storage.AdminStorage {
+	return &boltAdminStorage{db}
+}
+
+// boltAdminStorage implements storage.AdminStorage
+type boltAdminStorage struct {
+	db *bbolt.DB
+}
+
+func (s *boltAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return s.beginInternal(ctx, false /* writable */)
+}
+
+func (s *boltAdminStorage) beginInternal(ctx context.Context, writable bool) (storage.AdminTX, error) {
+	tx, err := s.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{tx: tx}, nil
+}
+
+func (s *boltAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	tx, err := s.beginInternal(ctx, true /* writable */)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *boltAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return checkDatabaseAccessible(s.db)
+}
+
+func checkDatabaseAccessible(db *bbolt.DB) error {
+	return db.View(func(tx *bbolt.Tx) error {
+		if tx.Bucket(treesBucket) == nil {
+			return errors.New("trees bucket not found")
+		}
+		return nil
+	})
+}
+
+type adminTX struct {
+	tx *bbolt.Tx
+
+	// mu guards *direct* reads/writes on closed, which happen only on
+	// Commit/Rollback/IsClosed/Close methods.
+	// We don't check closed on *all* methods (apart from the ones above),
+	// as we trust tx to keep tabs on its state (and consequently fail to do
+	// queries after closed).
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if !t.tx.Writable() {
+		// Bolt doesn't commit read-only transactions.
+		return t.tx.Rollback()
+	}
+	return t.tx.Commit()
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return t.tx.Rollback()
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	// Acquire and release read lock manually, without defer, as if the txn
+	// is not closed Rollback() will attempt to acquire the rw lock.
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if !closed {
+		err := t.Rollback()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return getTree(t.tx, treeID)
+}
+
+// getTree reads the given tree from the trees bucket.
+func getTree(tx *bbolt.Tx, treeID int64) (*trillian.Tree, error) {
+	v := tx.Bucket(treesBucket).Get(treeKey(treeID))
+	if v == nil {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	var tree trillian.Tree
+	if err := proto.Unmarshal(v, &tree); err != nil {
+		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
+	}
+	return &tree, nil
+}
+
+// listTrees calls f for each of the stored trees, until it returns an error.
+func listTrees(tx *bbolt.Tx, f func(*trillian.Tree) error) error {
+	return tx.Bucket(treesBucket).ForEach(func(k, v []byte) error {
+		var tree trillian.Tree
+		if err := proto.Unmarshal(v, &tree); err != nil {
+			return fmt.Errorf("error reading tree %x: %v", k, err)
+		}
+		return f(&tree)
+	})
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	treeIDs := []int64{}
+	err := listTrees(t.tx, func(tree *trillian.Tree) error {
+		if includeDeleted || !tree.Deleted {
+			treeIDs = append(treeIDs, tree.TreeId)
+		}
+		return nil
+	})
+	return treeIDs, err
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	trees := []*trillian.Tree{}
+	err := listTrees(t.tx, func(tree *trillian.Tree) error {
+		if includeDeleted || !tree.Deleted {
+			trees = append(trees, tree)
+		}
+		return nil
+	})
+	return trees, err
+}
+
+// putTree stores the given tree in the trees bucket.
+func (t *adminTX) putTree(tree *trillian.Tree) error {
+	v, err := proto.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return t.tx.Bucket(treesBucket).Put(treeKey(tree.TreeId), v)
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.NewTreeID()
+	if err != nil {
+		return nil, err
+	}
+	if t.tx.Bucket(treesBucket).Get(treeKey(id)) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	}
+
+	now := time.Now()
+	newTree := proto.Clone(tree).(*trillian.Tree)
+	newTree.TreeId = id
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+
+	if err := t.putTree(newTree); err != nil {
+		return nil, err
+	}
+	return newTree, nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	tree, err := t.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	tree.UpdateTime, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, true /* deleted */)
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(ctx, treeID, false /* deleted */)
+}
+
+// updateDeleted updates the Deleted and DeleteTime fields of the specified tree.
+func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool) (*trillian.Tree, error) {
+	tree, err := t.validateDeleted(treeID, !deleted)
+	if err != nil {
+		return nil, err
+	}
+	tree.Deleted = deleted
+	tree.DeleteTime = nil
+	if deleted {
+		if tree.DeleteTime, err = ptypes.TimestampProto(time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to build delete time: %v", err)
+		}
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if _, err := t.validateDeleted(treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+	if err := t.tx.Bucket(dataBucket).DeleteBucket(treeKey(treeID)); err != nil && err != bbolt.ErrBucketNotFound {
+		return err
+	}
+	return t.tx.Bucket(treesBucket).Delete(treeKey(treeID))
+}
+
+// validateDeleted returns the given tree if its soft deletion status is as
+// wanted, or an error otherwise.
+func (t *adminTX) validateDeleted(treeID int64, wantDeleted bool) (*trillian.Tree, error) {
+	tree, err := getTree(t.tx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	switch deleted := tree.Deleted; {
+	case wantDeleted && !deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return tree, nil
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
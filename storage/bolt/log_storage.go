@@ -0,0 +1,809 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const logIDLabel = "logid"
+
+var (
+	defaultLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8}
+
+	once            sync.Once
+	queuedCounter   monitoring.Counter
+	dequeuedCounter monitoring.Counter
+
+	leafDataPrefix   = []byte("leaf/")
+	unseqPrefix      = []byte("unseq/")
+	seqLeafPrefix    = []byte("seq/")
+	hashToSeqPrefix  = []byte("h2s/")
+	sthPrefix        = []byte("sth/")
+	quarantinePrefix = []byte("quarantine/")
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("bolt_queued_leaves", "Number of leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("bolt_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+// leafDataKey formats a key for use in a tree's bucket.
+// The associated value will be the LogLeaf with the given identity hash, with
+// its LeafValue, ExtraData and QueueTimestamp, but none of the fields which
+// are set when it is sequenced.
+func leafDataKey(leafIDHash []byte) []byte {
+	return key(leafDataPrefix, leafIDHash)
+}
+
+// unseqKey formats a key for use in a tree's bucket.
+// The associated value will be the queuedEntry of the leaf with the given
+// identity hash, queued at the given time.
+func unseqKey(queueTimestampNanos int64, leafIDHash []byte) []byte {
+	return key(unseqPrefix, uint64Bytes(uint64(queueTimestampNanos)), leafIDHash)
+}
+
+// seqLeafKey formats a key for use in a tree's bucket.
+// The associated value will be the LogLeaf at the given sequence number, with
+// only the fields which are set when it is sequenced.
+func seqLeafKey(seq int64) []byte {
+	return key(seqLeafPrefix, uint64Bytes(uint64(seq)))
+}
+
+// hashToSeqKey formats a key for use in a tree's bucket.
+// The key maps the given Merkle leaf hash to one of the sequence numbers of
+// leaves with that hash; there is no associated value.
+func hashToSeqKey(merkleLeafHash []byte, seq int64) []byte {
+	return key(hashToSeqPrefix, merkleLeafHash, uint64Bytes(uint64(seq)))
+}
+
+// sthKey formats a key for use in a tree's bucket.
+// The associated value will be the SignedLogRoot with the given timestamp.
+func sthKey(timestamp uint64) []byte {
+	return key(sthPrefix, uint64Bytes(timestamp))
+}
+
+// quarantineKey formats a key for use in a tree's bucket.
+// The associated value will be the QuarantinedLeaf with the given identity
+// hash.
+func quarantineKey(leafIDHash []byte) []byte {
+	return key(quarantinePrefix, leafIDHash)
+}
+
+// queuedEntry is the value of an entry of a tree's unsequenced queue.
+type queuedEntry struct {
+	MerkleLeafHash []byte
+	Submitter      string
+}
+
+// getActiveLogIDs returns the IDs of all logs that are currently in a state
+// that requires sequencing (e.g. ACTIVE, DRAINING).
+func getActiveLogIDs(tx *bbolt.Tx) ([]int64, error) {
+	ids := []int64{}
+	err := listTrees(tx, func(tree *trillian.Tree) error {
+		if tree.Deleted {
+			return nil
+		}
+		switch tree.TreeType {
+		case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+			switch tree.TreeState {
+			case trillian.TreeState_ACTIVE, trillian.TreeState_DRAINING:
+				ids = append(ids, tree.TreeId)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+type boltLogStorage struct {
+	*boltTreeStorage
+	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
+}
+
+// NewLogStorage creates a storage.LogStorage instance for the given Bolt
+// database. It assumes storage.AdminStorage is backed by the same database.
+func NewLogStorage(db *bbolt.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return newLogStorage(db, mf, nil)
+}
+
+func newLogStorage(db *bbolt.DB, mf monitoring.MetricFactory, weights storage.SubmitterWeights) *boltLogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &boltLogStorage{
+		boltTreeStorage:  &boltTreeStorage{db: db},
+		metricFactory:    mf,
+		submitterWeights: weights,
+	}
+}
+
+func (m *boltLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	return checkDatabaseAccessible(m.db)
+}
+
+// readOnlyLogTX implements storage.ReadOnlyLogTX
+type readOnlyLogTX struct {
+	tx *bbolt.Tx
+}
+
+func (m *boltLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	tx, err := m.db.Begin(false /* writable */)
+	if err != nil {
+		glog.Warningf("Could not start ReadOnlyLogTX: %s", err)
+		return nil, err
+	}
+	return &readOnlyLogTX{tx}, nil
+}
+
+func (t *readOnlyLogTX) Commit(context.Context) error {
+	return t.Rollback()
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *readOnlyLogTX) Close() error {
+	if err := t.Rollback(); err != nil && err != bbolt.ErrTxClosed {
+		glog.Warningf("Rollback error on Close(): %v", err)
+		return err
+	}
+	return nil
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	return getActiveLogIDs(t.tx)
+}
+
+func (m *boltLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree, writable bool) (*logTreeTX, error) {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	stCache := cache.NewLogSubtreeCache(defaultLogStrata, hasher)
+	ttx, err := m.beginTreeTx(ctx, tree.TreeId, hasher.Size(), stCache, writable)
+	if err != nil {
+		return nil, err
+	}
+
+	ltx := &logTreeTX{
+		treeTX:   ttx,
+		treeType: tree.TreeType,
+		dequeued: make(map[string][]byte),
+		order:    tree.DequeueOrder,
+		weights:  m.submitterWeights.ForTree(tree.TreeId),
+	}
+	ltx.slr, err = ltx.fetchLatestRoot()
+	if err == storage.ErrTreeNeedsInit {
+		ltx.treeTX.writeRevision = 0
+		return ltx, err
+	} else if err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	if err := ltx.root.UnmarshalBinary(ltx.slr.LogRoot); err != nil {
+		ttx.Rollback()
+		return nil, err
+	}
+
+	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *boltLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *boltLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if AddSequencedLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.AddSequencedLeaves(ctx, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *boltLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	return tx, err
+}
+
+func (m *boltLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if QueueLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tx.QueueLeaves(ctx, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+type logTreeTX struct {
+	treeTX
+	treeType trillian.TreeType
+	root     types.LogRootV1
+	slr      *trillian.SignedLogRoot
+	// dequeued maps the identity hashes of the leaves dequeued in this
+	// transaction to their keys in the unsequenced queue.
+	dequeued map[string][]byte
+	order    trillian.DequeueOrder
+	// weights are the submitter weights of FAIR_SHARE_ORDER trees.
+	weights map[string]int
+}
+
+func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	return int64(t.root.Revision), nil
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeTX.writeRevision < 0 {
+		return t.treeTX.writeRevision, errors.New("logTreeTX write revision not populated")
+	}
+	return t.treeTX.writeRevision, nil
+}
+
+// GetMerkleNodes returns the requested nodes at the read revision.
+func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]stree.Node, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+	rev := int64(t.root.Revision)
+	return t.subtreeCache.GetNodes(ids, t.getSubtreesAtRev(ctx, rev))
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		return t.getLeavesByRangeInternal(int64(t.root.TreeSize), int64(limit))
+	}
+
+	var leaves []*trillian.LogLeaf
+	var err error
+	if t.order == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		// The queue is in queue order, so each submitter's queue will be too.
+		queues := make(map[string][]*trillian.LogLeaf)
+		err = t.scanQueue(cutoffTime, func(leaf *trillian.LogLeaf, submitter string) bool {
+			if len(queues[submitter]) < limit {
+				queues[submitter] = append(queues[submitter], leaf)
+			}
+			return true
+		})
+		leaves = storage.FairShare(queues, t.weights, limit)
+	} else {
+		leaves = make([]*trillian.LogLeaf, 0, limit)
+		err = t.scanQueue(cutoffTime, func(leaf *trillian.LogLeaf, _ string) bool {
+			leaves = append(leaves, leaf)
+			return len(leaves) < limit
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leaf := range leaves {
+		qTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		t.dequeued[string(leaf.LeafIdentityHash)] = unseqKey(qTimestamp.UnixNano(), leaf.LeafIdentityHash)
+	}
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+// scanQueue calls f with the leaves of the unsequenced queue which were
+// queued at or before cutoffTime, and haven't been dequeued by this
+// transaction yet, in queue order, until f returns false.
+func (t *logTreeTX) scanQueue(cutoffTime time.Time, f func(leaf *trillian.LogLeaf, submitter string) bool) error {
+	c := t.cursor()
+	if c == nil {
+		return nil
+	}
+	end := unseqKey(cutoffTime.UnixNano()+1, nil)
+	for k, v := c.Seek(unseqPrefix); k != nil && bytes.HasPrefix(k, unseqPrefix) && bytes.Compare(k, end) < 0; k, v = c.Next() {
+		rest := k[len(unseqPrefix):]
+		queueTimestamp := int64(binary.BigEndian.Uint64(rest))
+		leafIDHash := append([]byte(nil), rest[8:]...)
+		if _, ok := t.dequeued[string(leafIDHash)]; ok {
+			continue
+		}
+		var e queuedEntry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return fmt.Errorf("failed to read queued leaf: %v", err)
+		}
+		queueTimestampProto, err := ptypes.TimestampProto(time.Unix(0, queueTimestamp))
+		if err != nil {
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		// Note: the LeafData and ExtraData being nil here is OK as this is only
+		// used by the sequencer.
+		leaf := &trillian.LogLeaf{
+			LeafIdentityHash: leafIDHash,
+			MerkleLeafHash:   e.MerkleLeafHash,
+			QueueTimestamp:   queueTimestampProto,
+		}
+		if !f(leaf, e.Submitter) {
+			break
+		}
+	}
+	return nil
+}
+
+// enqueue adds the leaf with the given hashes to the unsequenced queue.
+func (t *logTreeTX) enqueue(leafIDHash, merkleLeafHash []byte, submitter string, queueTimestamp time.Time) error {
+	v, err := json.Marshal(queuedEntry{MerkleLeafHash: merkleLeafHash, Submitter: submitter})
+	if err != nil {
+		return err
+	}
+	return t.put(unseqKey(queueTimestamp.UnixNano(), leafIDHash), v)
+}
+
+// getLeafData reads the leaf with the given identity hash from the leaf data
+// entries, or returns nil if there is none.
+func (t *logTreeTX) getLeafData(leafIDHash []byte) (*trillian.LogLeaf, error) {
+	v := t.get(leafDataKey(leafIDHash))
+	if v == nil {
+		return nil, nil
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(v, &leaf); err != nil {
+		return nil, fmt.Errorf("failed to read leaf data: %v", err)
+	}
+	return &leaf, nil
+}
+
+func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	submitter := storage.SubmitterFromContext(ctx)
+
+	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+	for i, leaf := range leaves {
+		existing, err := t.getLeafData(leaf.LeafIdentityHash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existingLeaves[i] = existing
+			continue
+		}
+
+		if err := t.putProto(leafDataKey(leaf.LeafIdentityHash), &trillian.LogLeaf{
+			LeafIdentityHash: leaf.LeafIdentityHash,
+			MerkleLeafHash:   leaf.MerkleLeafHash,
+			LeafValue:        leaf.LeafValue,
+			ExtraData:        leaf.ExtraData,
+			QueueTimestamp:   leaf.QueueTimestamp,
+		}); err != nil {
+			glog.Warningf("Error storing leaf data %d: %s", i, err)
+			return nil, err
+		}
+		if err := t.enqueue(leaf.LeafIdentityHash, leaf.MerkleLeafHash, submitter, queueTimestamp); err != nil {
+			glog.Warningf("Error queueing leaf %d: %s", i, err)
+			return nil, err
+		}
+	}
+	queuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return existingLeaves, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	queueTimestamp, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	integrateTimestamp, err := ptypes.TimestampProto(time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+		// TODO(pavelkalinnikov): Support opting out from duplicates detection.
+		if t.get(leafDataKey(leaf.LeafIdentityHash)) != nil {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+			continue
+		}
+		if t.get(seqLeafKey(leaf.LeafIndex)) != nil {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+			continue
+		}
+
+		if err := t.putProto(leafDataKey(leaf.LeafIdentityHash), &trillian.LogLeaf{
+			LeafIdentityHash: leaf.LeafIdentityHash,
+			MerkleLeafHash:   leaf.MerkleLeafHash,
+			LeafValue:        leaf.LeafValue,
+			ExtraData:        leaf.ExtraData,
+			QueueTimestamp:   queueTimestamp,
+		}); err != nil {
+			glog.Errorf("Error storing leaves[%d] data: %s", i, err)
+			return nil, err
+		}
+		// TODO(pavelkalinnikov): Update IntegrateTimestamp on integrating the leaf.
+		if err := t.putSequenced(leaf, integrateTimestamp); err != nil {
+			glog.Errorf("Error storing sequenced leaves[%d]: %s", i, err)
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// putSequenced stores the given leaf at its index, along with its Merkle leaf
+// hash to index mapping.
+func (t *logTreeTX) putSequenced(leaf *trillian.LogLeaf, integrateTimestamp *timestamp.Timestamp) error {
+	if err := t.putProto(seqLeafKey(leaf.LeafIndex), &trillian.LogLeaf{
+		LeafIdentityHash:   leaf.LeafIdentityHash,
+		MerkleLeafHash:     leaf.MerkleLeafHash,
+		LeafIndex:          leaf.LeafIndex,
+		IntegrateTimestamp: integrateTimestamp,
+	}); err != nil {
+		return err
+	}
+	return t.put(hashToSeqKey(leaf.MerkleLeafHash, leaf.LeafIndex), []byte{})
+}
+
+// getSequenced reads the leaf at the given index, joined with its leaf data,
+// or returns nil if there is none.
+func (t *logTreeTX) getSequenced(seq int64) (*trillian.LogLeaf, error) {
+	v := t.get(seqLeafKey(seq))
+	if v == nil {
+		return nil, nil
+	}
+	return t.joinLeafData(v)
+}
+
+// joinLeafData returns the sequenced leaf in v, with the fields stored in its
+// leaf data entry filled in.
+func (t *logTreeTX) joinLeafData(v []byte) (*trillian.LogLeaf, error) {
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(v, &leaf); err != nil {
+		return nil, fmt.Errorf("failed to read sequenced leaf: %v", err)
+	}
+	data, err := t.getLeafData(leaf.LeafIdentityHash)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("missing data of leaf %d", leaf.LeafIndex)
+	}
+	leaf.LeafValue = data.LeafValue
+	leaf.ExtraData = data.ExtraData
+	leaf.QueueTimestamp = data.QueueTimestamp
+	return &leaf, nil
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	c := t.cursor()
+	if c == nil {
+		return 0, nil
+	}
+	k, _ := lastWithPrefix(c, seqLeafPrefix)
+	if k == nil {
+		return 0, nil
+	}
+	return int64(binary.BigEndian.Uint64(k[len(seqLeafPrefix):])) + 1, nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, seq := range leaves {
+		leaf, err := t.getSequenced(seq)
+		if err != nil {
+			return nil, err
+		}
+		if leaf != nil {
+			ret = append(ret, leaf)
+		}
+	}
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+	return t.getLeavesByRangeInternal(start, count)
+}
+
+func (t *logTreeTX) getLeavesByRangeInternal(start, count int64) ([]*trillian.LogLeaf, error) {
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, count)
+	c := t.cursor()
+	if c == nil {
+		return ret, nil
+	}
+	end := seqLeafKey(start + count)
+	wantIndex := start
+	for k, v := c.Seek(seqLeafKey(start)); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+		leaf, err := t.joinLeafData(v)
+		if err != nil {
+			return nil, err
+		}
+		if leaf.LeafIndex != wantIndex {
+			if wantIndex < int64(t.root.TreeSize) {
+				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
+			}
+			break
+		}
+		ret = append(ret, leaf)
+		wantIndex++
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var ret []*trillian.LogLeaf
+	c := t.cursor()
+	if c == nil {
+		return ret, nil
+	}
+	for _, hash := range leafHashes {
+		prefix := key(hashToSeqPrefix, hash)
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if len(k) != len(prefix)+8 {
+				continue
+			}
+			seq := int64(binary.BigEndian.Uint64(k[len(prefix):]))
+			leaf, err := t.getSequenced(seq)
+			if err != nil {
+				return nil, err
+			}
+			if leaf == nil {
+				return nil, fmt.Errorf("missing leaf %d with hash %x", seq, hash)
+			}
+			ret = append(ret, leaf)
+		}
+	}
+	if orderBySequence {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].LeafIndex < ret[j].LeafIndex })
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	if t.slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+
+	return t.slr, nil
+}
+
+// fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
+func (t *logTreeTX) fetchLatestRoot() (*trillian.SignedLogRoot, error) {
+	c := t.cursor()
+	if c == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	k, v := lastWithPrefix(c, sthPrefix)
+	if k == nil {
+		// It's possible there are no roots for this tree yet
+		return nil, storage.ErrTreeNeedsInit
+	}
+	var slr trillian.SignedLogRoot
+	if err := proto.Unmarshal(v, &slr); err != nil {
+		return nil, fmt.Errorf("failed to read signed log root: %v", err)
+	}
+	return &slr, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+	if got, want := int64(logRoot.Revision), t.treeTX.writeRevision; got != want {
+		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
+	}
+	k := sthKey(logRoot.TimestampNanos)
+	if t.get(k) != nil {
+		return status.Errorf(codes.AlreadyExists, "root with timestamp %d already exists", logRoot.TimestampNanos)
+	}
+	return t.putProto(k, root)
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: errors.New("sequenced leaf has incorrect hash size")}
+		}
+		if t.get(seqLeafKey(leaf.LeafIndex)) != nil {
+			return fmt.Errorf("leaf index %d is already sequenced", leaf.LeafIndex)
+		}
+		qk, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to update leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		if err := t.putSequenced(leaf, leaf.IntegrateTimestamp); err != nil {
+			glog.Warningf("Failed to update sequenced leaves: %s", err)
+			return err
+		}
+		if err := t.del(qk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	return getActiveLogIDs(t.tx)
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/storage"
+)
+
+func TestLogSuite(t *testing.T) {
+	storageFactory := func(_ context.Context, t *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		db := openTestDB(t)
+		return NewLogStorage(db, nil), NewAdminStorage(db)
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
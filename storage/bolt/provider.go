@@ -0,0 +1,82 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	boltFile           = flag.String("bolt_file", "trillian.bolt", "Path of the Bolt database file, which is created if it does not exist")
+	boltInitialMmapMiB = flag.Int("bolt_initial_mmap_mib", 1024, "Initial size of the memory mapping of the Bolt database file in MiB; should exceed the expected size of the file")
+
+	boltOnce            sync.Once
+	boltOnceErr         error
+	boltStorageInstance *boltProvider
+)
+
+func init() {
+	if err := storage.RegisterProviderWithOptions("bolt", newBoltProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider bolt: %v", err)
+	}
+}
+
+type boltProvider struct {
+	db      *bbolt.DB
+	mf      monitoring.MetricFactory
+	weights storage.SubmitterWeights
+}
+
+func newBoltProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
+	boltOnce.Do(func() {
+		var db *bbolt.DB
+		db, boltOnceErr = OpenDB(*boltFile, &bbolt.Options{
+			Timeout:         time.Second,
+			InitialMmapSize: *boltInitialMmapMiB << 20,
+		})
+		if boltOnceErr != nil {
+			return
+		}
+
+		boltStorageInstance = &boltProvider{
+			db:      db,
+			mf:      mf,
+			weights: opts.SubmitterWeights,
+		}
+	})
+	if boltOnceErr != nil {
+		return nil, boltOnceErr
+	}
+	return boltStorageInstance, nil
+}
+
+func (s *boltProvider) LogStorage() storage.LogStorage {
+	return newLogStorage(s.db, s.mf, s.weights)
+}
+
+func (s *boltProvider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(s.db)
+}
+
+func (s *boltProvider) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+func (t *logTreeTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	for _, ql := range leaves {
+		leaf := ql.Leaf
+		if err := t.putProto(quarantineKey(leaf.LeafIdentityHash), &trillian.QuarantinedLeaf{
+			Leaf: &trillian.LogLeaf{
+				LeafIdentityHash: leaf.LeafIdentityHash,
+				MerkleLeafHash:   leaf.MerkleLeafHash,
+				QueueTimestamp:   leaf.QueueTimestamp,
+				LeafIndex:        leaf.LeafIndex,
+			},
+			Reason:              ql.Reason,
+			QuarantineTimestamp: ql.QuarantineTimestamp,
+		}); err != nil {
+			glog.Warningf("Failed to quarantine leaf: %s", err)
+			return err
+		}
+
+		if t.treeType == trillian.TreeType_PREORDERED_LOG {
+			continue
+		}
+		k := string(leaf.LeafIdentityHash)
+		qk, ok := t.dequeued[k]
+		if !ok {
+			return fmt.Errorf("attempting to quarantine leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		delete(t.dequeued, k)
+		if err := t.del(qk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var ret []*trillian.QuarantinedLeaf
+	c := t.cursor()
+	if c == nil {
+		return ret, nil
+	}
+	for k, v := c.Seek(quarantinePrefix); k != nil && bytes.HasPrefix(k, quarantinePrefix); k, v = c.Next() {
+		var ql trillian.QuarantinedLeaf
+		if err := proto.Unmarshal(v, &ql); err != nil {
+			return nil, fmt.Errorf("failed to read quarantined leaf: %v", err)
+		}
+		ret = append(ret, &ql)
+	}
+	// Match the order of the SQL storages, oldest quarantines first.
+	sort.SliceStable(ret, func(i, j int) bool {
+		ti, tj := ret[i].QuarantineTimestamp, ret[j].QuarantineTimestamp
+		if ti.GetSeconds() != tj.GetSeconds() {
+			return ti.GetSeconds() < tj.GetSeconds()
+		}
+		return ti.GetNanos() < tj.GetNanos()
+	})
+	return ret, nil
+}
+
+func (t *logTreeTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var ret [][]byte
+	for _, id := range leafIdentityHashes {
+		qk := quarantineKey(id)
+		v := t.get(qk)
+		if v == nil {
+			continue
+		}
+		var ql trillian.QuarantinedLeaf
+		if err := proto.Unmarshal(v, &ql); err != nil {
+			return nil, fmt.Errorf("failed to read quarantined leaf: %v", err)
+		}
+		if err := t.del(qk); err != nil {
+			glog.Warningf("Failed to delete quarantined leaf: %s", err)
+			return nil, err
+		}
+
+		if t.treeType != trillian.TreeType_PREORDERED_LOG {
+			if err := t.enqueue(id, ql.Leaf.GetMerkleLeafHash(), "", queueTimestamp); err != nil {
+				glog.Warningf("Error queueing leaf: %s", err)
+				return nil, err
+			}
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
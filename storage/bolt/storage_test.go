@@ -0,0 +1,90 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"go.etcd.io/bbolt"
+)
+
+// openTestDB opens a new Bolt database in a temporary directory, which is
+// removed at the end of the test.
+func openTestDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	db, err := OpenDB(filepath.Join(t.TempDir(), "trillian.bolt"), nil)
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReopenDB(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "trillian.bolt")
+
+	db, err := OpenDB(path, nil)
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(db), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	root, err := (&types.LogRootV1{TimestampNanos: 1, RootHash: make([]byte, 32)}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	if err := NewLogStorage(db, nil).ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	db, err = OpenDB(path, nil)
+	if err != nil {
+		t.Fatalf("OpenDB() after Close(): %v", err)
+	}
+	defer db.Close()
+	if _, err := storage.GetTree(ctx, NewAdminStorage(db), tree.TreeId); err != nil {
+		t.Errorf("GetTree() after reopening: %v", err)
+	}
+	tx, err := NewLogStorage(db, nil).SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree() after reopening: %v", err)
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot() after reopening: %v", err)
+	}
+	var got types.LogRootV1
+	if err := got.UnmarshalBinary(slr.LogRoot); err != nil {
+		t.Fatalf("UnmarshalBinary(): %v", err)
+	}
+	if got.TimestampNanos != 1 {
+		t.Errorf("LatestSignedLogRoot() after reopening: TimestampNanos=%d, want 1", got.TimestampNanos)
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bolt provides a storage layer implementation on top of Bolt, an
+// embedded ordered key-value store, for single-node deployments which do not
+// want to run a database server.
+//
+// Tree metadata is kept in one bucket, keyed by tree ID. All other data of a
+// tree is kept in a bucket of its own, with keys laid out much like those of
+// the memory storage: each kind of entry has a key prefix, followed by the
+// fields it is ordered by. Every ReadWriteTransaction is a single Bolt
+// read-write transaction, so its writes are committed atomically, and synced
+// to disk once.
+//
+// Bolt locks the database file, so only one process can use it at a time.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
+	stree "github.com/google/trillian/storage/tree"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// treesBucket holds the trillian.Tree protos of all trees, keyed by tree ID.
+	treesBucket = []byte("trees")
+	// dataBucket holds a bucket with the data of each tree, keyed by tree ID.
+	dataBucket = []byte("data")
+
+	subtreePrefix = []byte("subtree/")
+)
+
+// OpenDB opens the Bolt database in the given file, creating the file and
+// the top-level buckets in it if necessary.
+func OpenDB(path string, opts *bbolt.Options) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0600, opts)
+	if err != nil {
+		glog.Warningf("Could not open Bolt database %q: %s", path, err)
+		return nil, err
+	}
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{treesBucket, dataBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		glog.Warningf("Failed to create buckets in Bolt database %q: %s", path, err)
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// treeKey returns the key of the given tree in the trees and data buckets.
+func treeKey(treeID int64) []byte {
+	return uint64Bytes(uint64(treeID))
+}
+
+// uint64Bytes returns the big-endian encoding of v, which sorts in the same
+// order as v.
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// key concatenates the given parts into a key.
+func key(parts ...[]byte) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		b.Write(p)
+	}
+	return b.Bytes()
+}
+
+// subtreeKey formats a key for use in a tree's bucket.
+// The associated value will be the SubtreeProto with the given ID, written at
+// the given revision. The ID is prefixed with its length, so that all the
+// revisions of a subtree are adjacent.
+func subtreeKey(id []byte, rev int64) []byte {
+	return key(subtreeKeyPrefix(id), uint64Bytes(uint64(rev)))
+}
+
+// subtreeKeyPrefix returns the prefix of the keys of all revisions of the
+// subtree with the given ID.
+func subtreeKeyPrefix(id []byte) []byte {
+	return key(subtreePrefix, []byte{byte(len(id))}, id)
+}
+
+// subtreeID returns a []byte suitable for use in subtreeKey for the subtree
+// rooted at the passed-in node ID. Returns an error if the ID is not aligned
+// to bytes.
+func subtreeID(id stree.NodeID) ([]byte, error) {
+	if id.PrefixLenBits%8 != 0 {
+		return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", id.PrefixLenBits)
+	}
+	if bytes := id.Path; bytes != nil {
+		return bytes[:id.PrefixLenBits/8], nil
+	}
+	return []byte{}, nil
+}
+
+// lastWithPrefix returns the last entry of the bucket whose key starts with
+// the given prefix, or nil if there is no such entry.
+func lastWithPrefix(c *bbolt.Cursor, prefix []byte) ([]byte, []byte) {
+	k, v := c.Seek(prefixEnd(prefix))
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return nil, nil
+	}
+	return k, v
+}
+
+// prefixEnd returns the smallest key which is greater than all the keys with
+// the given prefix. The prefix must not consist of 0xff bytes only.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i]++; end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	panic(fmt.Sprintf("no end for prefix %x", prefix))
+}
+
+// boltTreeStorage contains the functionality of boltLogStorage which is not
+// specific to logs.
+type boltTreeStorage struct {
+	db *bbolt.DB
+}
+
+func (m *boltTreeStorage) beginTreeTx(ctx context.Context, treeID int64, hashSizeBytes int, subtreeCache *cache.SubtreeCache, writable bool) (treeTX, error) {
+	t, err := m.db.Begin(writable)
+	if err != nil {
+		glog.Warningf("Could not start tree TX: %s", err)
+		return treeTX{}, err
+	}
+	return treeTX{
+		tx:            t,
+		b:             t.Bucket(dataBucket).Bucket(treeKey(treeID)),
+		mu:            &sync.Mutex{},
+		treeID:        treeID,
+		hashSizeBytes: hashSizeBytes,
+		subtreeCache:  subtreeCache,
+		writeRevision: -1,
+	}, nil
+}
+
+type treeTX struct {
+	// mu ensures that tx can only be used for one operation at a time.
+	mu     *sync.Mutex
+	closed bool
+	tx     *bbolt.Tx
+	// b is the bucket of the tree, which is nil until the first write if the
+	// tree has no data yet.
+	b             *bbolt.Bucket
+	treeID        int64
+	hashSizeBytes int
+	subtreeCache  *cache.SubtreeCache
+	writeRevision int64
+}
+
+// get returns the value of the given key in the tree's bucket, or nil if
+// there is none. The value is only valid for the life of the transaction.
+func (t *treeTX) get(k []byte) []byte {
+	if t.b == nil {
+		return nil
+	}
+	return t.b.Get(k)
+}
+
+// cursor returns a cursor over the tree's bucket, or nil if the tree has no
+// data.
+func (t *treeTX) cursor() *bbolt.Cursor {
+	if t.b == nil {
+		return nil
+	}
+	return t.b.Cursor()
+}
+
+// put sets the given key in the tree's bucket, creating the bucket on the
+// first write.
+func (t *treeTX) put(k, v []byte) error {
+	if t.b == nil {
+		if t.tx.Bucket(treesBucket).Get(treeKey(t.treeID)) == nil {
+			return status.Errorf(codes.NotFound, "tree %v not found", t.treeID)
+		}
+		b, err := t.tx.Bucket(dataBucket).CreateBucketIfNotExists(treeKey(t.treeID))
+		if err != nil {
+			return err
+		}
+		t.b = b
+	}
+	return t.b.Put(k, v)
+}
+
+// putProto sets the given key in the tree's bucket to the marshaled message.
+func (t *treeTX) putProto(k []byte, m proto.Message) error {
+	v, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return t.put(k, v)
+}
+
+// del removes the given key from the tree's bucket.
+func (t *treeTX) del(k []byte) error {
+	if t.b == nil {
+		return nil
+	}
+	return t.b.Delete(k)
+}
+
+func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stree.NodeID) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []stree.NodeID{nodeID})
+	if err != nil {
+		return nil, err
+	}
+	switch len(s) {
+	case 0:
+		return nil, nil
+	case 1:
+		return s[0], nil
+	default:
+		return nil, fmt.Errorf("got %d subtrees, but expected 1", len(s))
+	}
+}
+
+// getSubtrees returns the latest revisions at or below treeRevision of the
+// requested subtrees which exist.
+func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+	c := t.cursor()
+	if c == nil || len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	ret := make([]*storagepb.SubtreeProto, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		id, err := subtreeID(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		// Find the last key at or before the one for the requested revision.
+		want := subtreeKey(id, treeRevision)
+		k, v := c.Seek(want)
+		if k == nil {
+			k, v = c.Last()
+		} else if !bytes.Equal(k, want) {
+			k, v = c.Prev()
+		}
+		if k == nil || !bytes.HasPrefix(k, subtreeKeyPrefix(id)) {
+			continue
+		}
+
+		var subtree storagepb.SubtreeProto
+		if err := proto.Unmarshal(v, &subtree); err != nil {
+			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
+			return nil, err
+		}
+		if subtree.Prefix == nil {
+			subtree.Prefix = []byte{}
+		}
+		ret = append(ret, &subtree)
+	}
+
+	// The InternalNodes cache is possibly nil here, but the SubtreeCache (which called
+	// this method) will re-populate it.
+	return ret, nil
+}
+
+func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.SubtreeProto) error {
+	for _, s := range subtrees {
+		if s.Prefix == nil {
+			panic(fmt.Errorf("nil prefix on %v", s))
+		}
+		if err := t.putProto(subtreeKey(s.Prefix, t.writeRevision), s); err != nil {
+			glog.Warningf("Failed to set merkle subtrees: %s", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+func (t *treeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
+	return func(ids []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+		return t.getSubtrees(ctx, rev, ids)
+	}
+}
+
+func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, n := range nodes {
+		err := t.subtreeCache.SetNodeHash(n.ID, n.Hash,
+			func(nID stree.NodeID) (*storagepb.SubtreeProto, error) {
+				return t.getSubtree(ctx, t.writeRevision, nID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeTX) Commit(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.tx.Writable() {
+		// Bolt doesn't commit read-only transactions.
+		return t.rollbackInternal()
+	}
+	if t.writeRevision > -1 {
+		if err := t.subtreeCache.Flush(ctx, func(ctx context.Context, st []*storagepb.SubtreeProto) error {
+			return t.storeSubtrees(ctx, st)
+		}); err != nil {
+			glog.Warningf("TX commit flush error: %v", err)
+			return err
+		}
+	}
+	t.closed = true
+	if err := t.tx.Commit(); err != nil {
+		glog.Warningf("TX commit error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rollbackInternal()
+}
+
+func (t *treeTX) rollbackInternal() error {
+	t.closed = true
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, bbolt.ErrTxClosed) {
+		glog.Warningf("TX rollback error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.closed {
+		err := t.rollbackInternal()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *treeTX) IsOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return !t.closed
+}
@@ -70,6 +70,14 @@ type SubtreeCache struct {
 
 	// populateConcurrency sets the amount of concurrency when repopulating subtrees.
 	populateConcurrency int
+
+	// tiles, if not nil, is the shared cache of complete tiles consulted
+	// before reading subtrees from storage.
+	tiles *TileCache
+	// treeID and readRevision identify the tree and revision the subtrees are
+	// read at, for use with the shared tile cache.
+	treeID       int64
+	readRevision int64
 }
 
 // NewLogSubtreeCache creates and returns a SubtreeCache appropriate for use with a log
@@ -96,6 +104,16 @@ func NewLogSubtreeCache(strataDepths []int, hasher hashers.LogHasher) *SubtreeCa
 	}
 }
 
+// UseTileCache makes the SubtreeCache consult the given shared cache of
+// complete tiles before reading subtrees of the given tree from storage, and
+// fill it in with the complete tiles that it reads at readRevision. It must be
+// called before any of the other methods. A nil tc is a no-op.
+func (s *SubtreeCache) UseTileCache(tc *TileCache, treeID, readRevision int64) {
+	s.tiles = tc
+	s.treeID = treeID
+	s.readRevision = readRevision
+}
+
 // preload calculates the set of subtrees required to know the hashes of the
 // passed in node IDs, uses getSubtrees to retrieve them, and finally populates
 // the cache structures with the data.
@@ -109,9 +127,18 @@ func (s *SubtreeCache) preload(ids []tree.NodeID, getSubtrees GetSubtreesFunc) e
 			// No need to check s.subtrees map twice.
 			continue
 		}
-		if _, ok := s.subtrees.Load(subKey); !ok {
-			want[subKey] = subID
+		if _, ok := s.subtrees.Load(subKey); ok {
+			continue
+		}
+		if s.tiles != nil {
+			if t := s.tiles.Get(s.treeID, subKey, s.readRevision); t != nil {
+				if err := s.cacheSubtree(t); err != nil {
+					return err
+				}
+				continue
+			}
 		}
+		want[subKey] = subID
 	}
 	// Note: At this point multiple parallel preload invocations can happen to
 	// getSubtrees with overlapping sets of IDs. It's okay because we collapse
@@ -164,6 +191,9 @@ func (s *SubtreeCache) preload(ids []tree.NodeID, getSubtrees GetSubtreesFunc) e
 	}()
 
 	for t := range ch {
+		if s.tiles != nil {
+			s.tiles.Put(s.treeID, t, s.readRevision)
+		}
 		if err := s.cacheSubtree(t); err != nil {
 			return err
 		}
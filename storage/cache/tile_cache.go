@@ -0,0 +1,147 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"flag"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage/storagepb"
+)
+
+var tileCacheSize = flag.Int("tile_cache_size", 4096, "Max number of complete log tiles kept in the process-wide tile cache, 0 disables the cache")
+
+var (
+	sharedTileCacheOnce sync.Once
+	sharedTileCache     *TileCache
+)
+
+// SharedTileCache returns the process-wide TileCache, sized according to the
+// --tile_cache_size flag, or nil if the cache is disabled. The metric factory
+// passed in by the first caller is used to create the cache metrics.
+func SharedTileCache(mf monitoring.MetricFactory) *TileCache {
+	sharedTileCacheOnce.Do(func() {
+		if *tileCacheSize > 0 {
+			sharedTileCache = NewTileCache(*tileCacheSize, mf)
+		}
+	})
+	return sharedTileCache
+}
+
+// tileKey identifies a tile across all the trees served by the process.
+type tileKey struct {
+	treeID int64
+	tile   string
+}
+
+// tileEntry is an element of the TileCache LRU list.
+type tileEntry struct {
+	key  tileKey
+	tile *storagepb.SubtreeProto
+	// rev is the lowest tree revision at which the tile is known to be
+	// complete.
+	rev int64
+}
+
+// TileCache is a size-bounded LRU cache of complete log tiles, shared across
+// transactions. A log tile never changes once all its leaves are set, so it
+// can be served to any reader at or above the revision at which it was seen
+// complete. Partial tiles are never stored in the cache.
+type TileCache struct {
+	size int
+
+	mu      sync.Mutex
+	lru     *list.List // Of *tileEntry, most recently used at the front.
+	entries map[tileKey]*list.Element
+
+	hits   monitoring.Counter
+	misses monitoring.Counter
+}
+
+// NewTileCache returns a TileCache which holds up to size tiles.
+func NewTileCache(size int, mf monitoring.MetricFactory) *TileCache {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &TileCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[tileKey]*list.Element),
+		hits:    mf.NewCounter("tile_cache_hits", "Number of log tiles served from the shared tile cache"),
+		misses:  mf.NewCounter("tile_cache_misses", "Number of log tiles not found in the shared tile cache"),
+	}
+}
+
+// Get returns a copy of the complete tile with the given prefix, if it is cached
+// and was complete at or below the given read revision. Otherwise it returns
+// nil.
+func (c *TileCache) Get(treeID int64, prefix string, readRev int64) *storagepb.SubtreeProto {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[keyOf(treeID, prefix)]
+	if !ok || el.Value.(*tileEntry).rev > readRev {
+		c.misses.Inc()
+		return nil
+	}
+	c.hits.Inc()
+	c.lru.MoveToFront(el)
+	return proto.Clone(el.Value.(*tileEntry).tile).(*storagepb.SubtreeProto)
+}
+
+// Put stores a copy of the given tile, read at the given revision, if the tile
+// is complete. Partial tiles are ignored.
+func (c *TileCache) Put(treeID int64, tile *storagepb.SubtreeProto, readRev int64) {
+	if !isCompleteTile(tile) {
+		return
+	}
+	key := keyOf(treeID, string(tile.Prefix))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		if e := el.Value.(*tileEntry); readRev < e.rev {
+			e.rev = readRev
+		}
+		c.lru.MoveToFront(el)
+		return
+	}
+	e := &tileEntry{key: key, tile: proto.Clone(tile).(*storagepb.SubtreeProto), rev: readRev}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*tileEntry).key)
+	}
+}
+
+// Len returns the number of tiles in the cache.
+func (c *TileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func keyOf(treeID int64, tile string) tileKey {
+	return tileKey{treeID: treeID, tile: tile}
+}
+
+// isCompleteTile returns whether all the leaves of the given populated log
+// tile are set.
+func isCompleteTile(t *storagepb.SubtreeProto) bool {
+	return t.Depth > 0 && len(t.Leaves) == 1<<uint(t.Depth)
+}
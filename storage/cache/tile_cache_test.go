@@ -0,0 +1,162 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian/merkle/compact"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tree"
+)
+
+// buildLogTile returns the stored form of the bottom-most log tile with the
+// given number of leaves set.
+func buildLogTile(t *testing.T, leaves int) *storagepb.SubtreeProto {
+	t.Helper()
+	c := NewLogSubtreeCache(defaultLogStrata, rfc6962.DefaultHasher)
+	missing := func(tree.NodeID) (*storagepb.SubtreeProto, error) { return nil, nil }
+	for i := 0; i < leaves; i++ {
+		id := compact.NewNodeID(0, uint64(i))
+		if err := c.SetNodeHash(id, []byte(fmt.Sprintf("leaf-%d", i)), missing); err != nil {
+			t.Fatalf("SetNodeHash(%v): %v", id, err)
+		}
+	}
+	var tile *storagepb.SubtreeProto
+	if err := c.Flush(context.Background(), func(_ context.Context, st []*storagepb.SubtreeProto) error {
+		if got := len(st); got != 1 {
+			return fmt.Errorf("flushed %d tiles, want 1", got)
+		}
+		tile = st[0]
+		return nil
+	}); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return tile
+}
+
+func TestTileCacheRevisions(t *testing.T) {
+	full := buildLogTile(t, 256)
+	partial := buildLogTile(t, 255)
+
+	for _, tc := range []struct {
+		desc    string
+		tile    *storagepb.SubtreeProto
+		putRevs []int64
+		treeID  int64
+		getRev  int64
+		wantHit bool
+	}{
+		{desc: "same-rev", tile: full, putRevs: []int64{5}, treeID: 1, getRev: 5, wantHit: true},
+		{desc: "later-rev", tile: full, putRevs: []int64{5}, treeID: 1, getRev: 10, wantHit: true},
+		{desc: "earlier-rev", tile: full, putRevs: []int64{5}, treeID: 1, getRev: 4},
+		{desc: "lowered-rev", tile: full, putRevs: []int64{5, 3}, treeID: 1, getRev: 4, wantHit: true},
+		{desc: "other-tree", tile: full, putRevs: []int64{5}, treeID: 2, getRev: 5},
+		{desc: "partial", tile: partial, putRevs: []int64{5}, treeID: 1, getRev: 5},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := NewTileCache(10, nil)
+			for _, rev := range tc.putRevs {
+				c.Put(1, tc.tile, rev)
+			}
+			got := c.Get(tc.treeID, string(tc.tile.Prefix), tc.getRev)
+			if gotHit := got != nil; gotHit != tc.wantHit {
+				t.Fatalf("Get(): hit=%v, want %v", gotHit, tc.wantHit)
+			}
+			if got != nil && !proto.Equal(got, tc.tile) {
+				t.Errorf("Get(): returned a different tile")
+			}
+		})
+	}
+}
+
+func TestTileCacheEviction(t *testing.T) {
+	c := NewTileCache(2, nil)
+	tile := buildLogTile(t, 256)
+	for _, id := range []int64{1, 2} {
+		c.Put(id, tile, 1)
+	}
+	// Use tree 1 so that tree 2 becomes the least recently used.
+	if c.Get(1, string(tile.Prefix), 1) == nil {
+		t.Fatal("Get(1): miss, want hit")
+	}
+	c.Put(3, tile, 1)
+
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len(): %d, want %d", got, want)
+	}
+	for _, tc := range []struct {
+		treeID  int64
+		wantHit bool
+	}{{1, true}, {2, false}, {3, true}} {
+		if gotHit := c.Get(tc.treeID, string(tile.Prefix), 1) != nil; gotHit != tc.wantHit {
+			t.Errorf("Get(%d): hit=%v, want %v", tc.treeID, gotHit, tc.wantHit)
+		}
+	}
+}
+
+func TestTileCacheReturnsCopies(t *testing.T) {
+	c := NewTileCache(1, nil)
+	tile := buildLogTile(t, 256)
+	c.Put(1, tile, 1)
+	want := proto.Clone(tile)
+
+	tile.Leaves["modified"] = []byte("put")
+	got := c.Get(1, string(tile.Prefix), 1)
+	got.Leaves["modified"] = []byte("get")
+	if got := c.Get(1, string(tile.Prefix), 1); !proto.Equal(got, want) {
+		t.Errorf("Get(): cached tile was modified")
+	}
+}
+
+func TestSubtreeCacheUsesTileCache(t *testing.T) {
+	full := buildLogTile(t, 256)
+	tc := NewTileCache(10, nil)
+	ids := []compact.NodeID{compact.NewNodeID(7, 1), compact.NewNodeID(0, 7)}
+
+	for _, step := range []struct {
+		desc      string
+		rev       int64
+		wantReads int
+	}{
+		{desc: "cold", rev: 5, wantReads: 1},
+		{desc: "warm", rev: 5, wantReads: 0},
+		{desc: "newer", rev: 6, wantReads: 0},
+		{desc: "older", rev: 4, wantReads: 1},
+	} {
+		t.Run(step.desc, func(t *testing.T) {
+			c := NewLogSubtreeCache(defaultLogStrata, rfc6962.DefaultHasher)
+			c.UseTileCache(tc, 1, step.rev)
+			reads := 0
+			nodes, err := c.GetNodes(ids, func(ids []tree.NodeID) ([]*storagepb.SubtreeProto, error) {
+				reads++
+				return []*storagepb.SubtreeProto{proto.Clone(full).(*storagepb.SubtreeProto)}, nil
+			})
+			if err != nil {
+				t.Fatalf("GetNodes: %v", err)
+			}
+			if got, want := len(nodes), len(ids); got != want {
+				t.Errorf("GetNodes: got %d nodes, want %d", got, want)
+			}
+			if reads != step.wantReads {
+				t.Errorf("GetNodes: read storage %d times, want %d", reads, step.wantReads)
+			}
+		})
+	}
+}
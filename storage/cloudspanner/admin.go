@@ -67,12 +67,17 @@ var (
 		sigpb.DigitallySigned_RSA:   spannerpb.SignatureAlgorithm_RSA,
 		sigpb.DigitallySigned_ECDSA: spannerpb.SignatureAlgorithm_ECDSA,
 	}
+	leafCompressionMap = map[trillian.LeafCompression]spannerpb.LeafCompression{
+		trillian.LeafCompression_NO_LEAF_COMPRESSION:   spannerpb.LeafCompression_NO_LEAF_COMPRESSION,
+		trillian.LeafCompression_ZSTD_LEAF_COMPRESSION: spannerpb.LeafCompression_ZSTD_LEAF_COMPRESSION,
+	}
 
-	treeStateReverseMap    = reverseTreeStateMap(treeStateMap)
-	treeTypeReverseMap     = reverseTreeTypeMap(treeTypeMap)
-	hashStrategyReverseMap = reverseHashStrategyMap(hashStrategyMap)
-	hashAlgReverseMap      = reverseHashAlgMap(hashAlgMap)
-	signatureAlgReverseMap = reverseSignatureAlgMap(signatureAlgMap)
+	treeStateReverseMap       = reverseTreeStateMap(treeStateMap)
+	treeTypeReverseMap        = reverseTreeTypeMap(treeTypeMap)
+	hashStrategyReverseMap    = reverseHashStrategyMap(hashStrategyMap)
+	hashAlgReverseMap         = reverseHashAlgMap(hashAlgMap)
+	signatureAlgReverseMap    = reverseSignatureAlgMap(signatureAlgMap)
+	leafCompressionReverseMap = reverseLeafCompressionMap(leafCompressionMap)
 )
 
 const nanosPerMilli = int64(time.Millisecond / time.Nanosecond)
@@ -132,6 +137,17 @@ func reverseSignatureAlgMap(m map[sigpb.DigitallySigned_SignatureAlgorithm]spann
 	return reverse
 }
 
+func reverseLeafCompressionMap(m map[trillian.LeafCompression]spannerpb.LeafCompression) map[spannerpb.LeafCompression]trillian.LeafCompression {
+	reverse := make(map[spannerpb.LeafCompression]trillian.LeafCompression)
+	for k, v := range m {
+		if x, ok := reverse[v]; ok {
+			glog.Fatalf("Duplicate values for key %v: %v and %v", v, x, k)
+		}
+		reverse[v] = k
+	}
+	return reverse
+}
+
 // adminTX implements both storage.ReadOnlyAdminTX and storage.AdminTX.
 type adminTX struct {
 	client *spanner.Client
@@ -424,6 +440,11 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		return nil, status.Errorf(codes.Internal, "unexpected SignatureAlgorithm: %s", tree.SignatureAlgorithm)
 	}
 
+	lc, ok := leafCompressionMap[tree.LeafCompression]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unexpected LeafCompression: %s", tree.LeafCompression)
+	}
+
 	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "malformed MaxRootDuration: %v", err)
@@ -443,6 +464,9 @@ func newTreeInfo(tree *trillian.Tree, treeID int64, now time.Time) (*spannerpb.T
 		PrivateKey:            tree.GetPrivateKey(),
 		PublicKeyDer:          tree.GetPublicKey().GetDer(),
 		MaxRootDurationMillis: int64(maxRootDuration / time.Millisecond),
+
+		LeafCompression:             lc,
+		LeafCompressionDictionaryId: tree.LeafCompressionDictionaryId,
 	}
 
 	switch tt := tree.TreeType; tt {
@@ -529,6 +553,11 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		return nil, status.Errorf(codes.Internal, "unexpected TreeState: %s", tree.TreeState)
 	}
 
+	lc, ok := leafCompressionMap[tree.LeafCompression]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unexpected LeafCompression: %s", tree.LeafCompression)
+	}
+
 	maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "malformed MaxRootDuration: %v", err)
@@ -542,6 +571,8 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	info.UpdateTimeNanos = now.UnixNano()
 	info.MaxRootDurationMillis = int64(maxRootDuration / time.Millisecond)
 	info.PrivateKey = tree.PrivateKey
+	info.LeafCompression = lc
+	info.LeafCompressionDictionaryId = tree.LeafCompressionDictionaryId
 
 	if err := t.updateTreeInfo(ctx, info); err != nil {
 		return nil, err
@@ -698,6 +729,13 @@ func toTrillianTree(info *spannerpb.TreeInfo) (*trillian.Tree, error) {
 	}
 	tree.SignatureAlgorithm = sa
 
+	lc, ok := leafCompressionReverseMap[info.LeafCompression]
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "unexpected LeafCompression: %s", info.LeafCompression)
+	}
+	tree.LeafCompression = lc
+	tree.LeafCompressionDictionaryId = info.LeafCompressionDictionaryId
+
 	var config proto.Message
 	switch tt := info.TreeType; tt {
 	case spannerpb.TreeType_PREORDERED_LOG:
@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	"github.com/google/trillian"
+)
+
+// leafHashCols are the columns of SequencedLeafData read for leaf hashes. The
+// LeafData table isn't read at all.
+var leafHashCols = []string{colSequenceNumber, colMerkleLeafHash}
+
+// GetLeafHashesByIndex implements storage.LeafHashesTX.
+func (tx *logTX) GetLeafHashesByIndex(ctx context.Context, indices []int64) ([]*trillian.LogLeaf, error) {
+	// We need the latest root to validate the indices are within range.
+	currentSTH, err := tx.currentSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateIndices(indices, currentSTH.TreeSize); err != nil {
+		return nil, err
+	}
+
+	keySet := make([]spanner.KeySet, 0, len(indices))
+	for _, i := range indices {
+		keySet = append(keySet, spanner.Key{tx.treeID, i})
+	}
+	leaves := make(map[int64]*trillian.LogLeaf, len(indices))
+	rows := tx.stx.Read(ctx, seqDataTbl, spanner.KeySets(keySet...), leafHashCols)
+	if err := rows.Do(func(r *spanner.Row) error {
+		leaf := &trillian.LogLeaf{}
+		if err := r.Columns(&leaf.LeafIndex, &leaf.MerkleLeafHash); err != nil {
+			return err
+		}
+		leaves[leaf.LeafIndex] = leaf
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Return the leaves in the same order as the indices.
+	ret := make([]*trillian.LogLeaf, 0, len(indices))
+	for _, i := range indices {
+		l, ok := leaves[i]
+		if !ok {
+			return nil, fmt.Errorf("inconsistency: missing data for index %d", i)
+		}
+		ret = append(ret, l)
+	}
+	return ret, nil
+}
+
+// GetLeafHashesByRange implements storage.LeafHashesTX.
+func (tx *logTX) GetLeafHashesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	// We need the latest root to validate the indices are within range.
+	currentSTH, err := tx.currentSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	xsize := currentSTH.TreeSize
+	if tx.treeType == trillian.TreeType_PREORDERED_LOG {
+		xsize = -1 // Allow requesting entries beyond the tree size.
+	}
+	if err := validateRange(start, count, xsize); err != nil {
+		return nil, err
+	}
+	xend := start + count
+	if tx.treeType != trillian.TreeType_PREORDERED_LOG && xend > xsize {
+		xend = xsize
+	}
+
+	// Rows are read in primary key order, i.e. by sequence number.
+	keyRange := spanner.KeyRange{Start: spanner.Key{tx.treeID, start}, End: spanner.Key{tx.treeID, xend}, Kind: spanner.ClosedOpen}
+	ret := make([]*trillian.LogLeaf, 0, xend-start)
+	rows := tx.stx.Read(ctx, seqDataTbl, keyRange, leafHashCols)
+	if err := rows.Do(func(r *spanner.Row) error {
+		leaf := &trillian.LogLeaf{}
+		if err := r.Columns(&leaf.LeafIndex, &leaf.MerkleLeafHash); err != nil {
+			return err
+		}
+		ret = append(ret, leaf)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Pre-ordered logs may have gaps above the tree size, which end the range.
+	for i, leaf := range ret {
+		if want := start + int64(i); leaf.LeafIndex != want {
+			if want < currentSTH.TreeSize {
+				return nil, fmt.Errorf("missing expected index %d", want)
+			}
+			return ret[:i], nil
+		}
+	}
+	if end := start + int64(len(ret)); end < xend && end < currentSTH.TreeSize {
+		return nil, fmt.Errorf("missing expected index %d", end)
+	}
+	return ret, nil
+}
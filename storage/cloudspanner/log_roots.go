@@ -0,0 +1,77 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudspanner
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+// GetSignedLogRoots implements storage.LogRootHistoryTX.
+func (tx *logTX) GetSignedLogRoots(ctx context.Context, filter storage.LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error) {
+	f := filter.Bounded()
+	query := spanner.NewStatement(
+		"SELECT TimestampNanos, TreeSize, RootHash, RootSignature, TreeRevision, TreeMetadata FROM TreeHeads" +
+			"   WHERE TreeID = @tree_id" +
+			"   AND TreeRevision >= @revision_begin AND TreeRevision < @revision_end" +
+			"   AND TreeSize >= @tree_size_begin AND TreeSize < @tree_size_end" +
+			"   AND TimestampNanos >= @timestamp_begin AND TimestampNanos < @timestamp_end" +
+			"   ORDER BY TreeRevision" +
+			"   LIMIT @limit")
+	query.Params["tree_id"] = tx.treeID
+	query.Params["revision_begin"] = f.RevisionBegin
+	query.Params["revision_end"] = f.RevisionEnd
+	query.Params["tree_size_begin"] = f.TreeSizeBegin
+	query.Params["tree_size_end"] = f.TreeSizeEnd
+	query.Params["timestamp_begin"] = f.TimestampBegin
+	query.Params["timestamp_end"] = f.TimestampEnd
+	query.Params["limit"] = int64(limit)
+
+	var ret []*trillian.SignedLogRoot
+	rows := tx.stx.Query(ctx, query)
+	defer rows.Stop()
+	if err := rows.Do(func(r *spanner.Row) error {
+		var tsNanos, treeSize, treeRevision int64
+		var rootHash, signature, metadata []byte
+		if err := r.Columns(&tsNanos, &treeSize, &rootHash, &signature, &treeRevision, &metadata); err != nil {
+			return err
+		}
+		// LogRoot has a deterministic serialization, so this reproduces the
+		// signed bytes.
+		logRoot, err := (&types.LogRootV1{
+			TimestampNanos: uint64(tsNanos),
+			RootHash:       rootHash,
+			TreeSize:       uint64(treeSize),
+			Revision:       uint64(treeRevision),
+			Metadata:       metadata,
+		}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		ret = append(ret, &trillian.SignedLogRoot{
+			KeyHint:          types.SerializeKeyHint(tx.treeID),
+			LogRoot:          logRoot,
+			LogRootSignature: signature,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
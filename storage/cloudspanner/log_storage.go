@@ -33,6 +33,7 @@ import (
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/cloudspanner/spannerpb"
+	"github.com/google/trillian/storage/compression"
 	"github.com/google/trillian/types"
 	"go.opencensus.io/trace"
 	"golang.org/x/sync/semaphore"
@@ -76,6 +77,7 @@ var (
 	colMerkleLeafHash      = "MerkleLeafHash"
 	colSequenceNumber      = "SequenceNumber"
 	colQueueTimestampNanos = "QueueTimestampNanos"
+	colCompression         = "Compression"
 )
 
 type leafDataCols struct {
@@ -84,6 +86,9 @@ type leafDataCols struct {
 	LeafValue           []byte
 	ExtraData           []byte
 	QueueTimestampNanos int64
+	// Compression is the trillian.LeafCompression format of LeafValue and
+	// ExtraData, and is NULL for leaves stored before compression support.
+	Compression spanner.NullInt64
 }
 
 type sequencedLeafDataCols struct {
@@ -171,10 +176,16 @@ func (ls *logStorage) begin(ctx context.Context, tree *trillian.Tree, readonly b
 		return nil, err
 	}
 
+	codec, err := compression.NewCodec(tree)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	ltx := &logTX{
 		ls:       ls,
 		dequeued: make(map[string]*QueuedEntry),
 		treeTX:   tx,
+		codec:    codec,
 	}
 
 	// Needed to generate ErrTreeNeedsInit in SnapshotForTree and other methods.
@@ -218,6 +229,10 @@ func (ls *logStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leav
 	if !ok {
 		return nil, status.Errorf(codes.Internal, "got unexpected config type for Log operation: %T", treeConfig)
 	}
+	codec, err := compression.NewCodec(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now().UTC().Unix()
 	bucketPrefix := (now % config.NumUnseqBuckets) << 8
@@ -236,12 +251,14 @@ func (ls *logStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leav
 			defer wg.Done()
 
 			// The insert of the leafdata and the unsequenced work item must happen atomically.
+			value, extra, format := codec.Encode(l.LeafValue, l.ExtraData)
 			m1, err := spanner.InsertStruct(leafDataTbl, leafDataCols{
 				TreeID:              tree.TreeId,
 				LeafIdentityHash:    l.LeafIdentityHash,
-				LeafValue:           l.LeafValue,
-				ExtraData:           l.ExtraData,
+				LeafValue:           value,
+				ExtraData:           extra,
 				QueueTimestampNanos: qTS,
+				Compression:         spanner.NullInt64{Int64: int64(format), Valid: true},
 			})
 			if err != nil {
 				results[i] = &trillian.QueuedLogLeaf{Status: status.Convert(err).Proto()}
@@ -289,6 +306,10 @@ func (ls *logStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tre
 	defer span.End()
 
 	okProto := status.New(codes.OK, "OK").Proto()
+	codec, err := compression.NewCodec(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	_, span = trace.StartSpan(ctx, "insert")
 	defer span.End()
@@ -308,12 +329,14 @@ func (ls *logStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tre
 
 		wg.Add(1)
 		// The insert of the LeafData and SequencedLeafData must happen atomically.
+		value, extra, format := codec.Encode(l.LeafValue, l.ExtraData)
 		m1, err := spanner.InsertStruct(leafDataTbl, leafDataCols{
 			TreeID:              tree.TreeId,
 			LeafIdentityHash:    l.LeafIdentityHash,
-			LeafValue:           l.LeafValue,
-			ExtraData:           l.ExtraData,
+			LeafValue:           value,
+			ExtraData:           extra,
 			QueueTimestampNanos: ts.UnixNano(),
+			Compression:         spanner.NullInt64{Int64: int64(format), Valid: true},
 		})
 		if err != nil {
 			return nil, err
@@ -434,6 +457,9 @@ type logTX struct {
 	// This is required to recover the primary key for the unsequenced entry in
 	// UpdateSequencedLeaves.
 	dequeued map[string]*QueuedEntry
+
+	// codec compresses leaf data according to the settings of the tree.
+	codec *compression.Codec
 }
 
 func (tx *logTX) getLogStorageConfig() *spannerpb.LogStorageConfig {
@@ -528,7 +554,7 @@ func (tx *logTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLo
 
 func readLeaves(ctx context.Context, stx *spanner.ReadOnlyTransaction, logID int64, ids [][]byte, f func(*trillian.LogLeaf)) error {
 	leafTable := leafDataTbl
-	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colQueueTimestampNanos}
+	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colQueueTimestampNanos, colCompression}
 	keys := make([]spanner.KeySet, 0)
 	for _, l := range ids {
 		keys = append(keys, spanner.Key{logID, l})
@@ -538,7 +564,11 @@ func readLeaves(ctx context.Context, stx *spanner.ReadOnlyTransaction, logID int
 	return rows.Do(func(r *spanner.Row) error {
 		var l trillian.LogLeaf
 		var qTimestamp int64
-		if err := r.Columns(&l.LeafIdentityHash, &l.LeafValue, &l.ExtraData, &qTimestamp); err != nil {
+		var format spanner.NullInt64
+		if err := r.Columns(&l.LeafIdentityHash, &l.LeafValue, &l.ExtraData, &qTimestamp, &format); err != nil {
+			return err
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format.Int64), &l); err != nil {
 			return err
 		}
 		var err error
@@ -660,6 +690,49 @@ func (tx *logTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time)
 	return ret, nil
 }
 
+// RecompressLeaves re-encodes the data of up to limit leaves, ordered by
+// identity hash after the given one, with the leaf compression of the tree.
+// It implements storage.RecompressLogTreeTX.
+func (tx *logTX) RecompressLeaves(ctx context.Context, after []byte, limit int) ([]byte, int, error) {
+	stx, ok := tx.stx.(*spanner.ReadWriteTransaction)
+	if !ok {
+		return nil, 0, ErrWrongTXType
+	}
+
+	keys := spanner.KeyRange{Start: spanner.Key{tx.treeID}, End: spanner.Key{tx.treeID}, Kind: spanner.ClosedClosed}
+	if after != nil {
+		keys = spanner.KeyRange{Start: spanner.Key{tx.treeID, after}, End: spanner.Key{tx.treeID}, Kind: spanner.OpenClosed}
+	}
+	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colCompression}
+	var last []byte
+	var muts []*spanner.Mutation
+	err := stx.ReadWithOptions(ctx, leafDataTbl, keys, cols, &spanner.ReadOptions{Limit: limit}).Do(func(r *spanner.Row) error {
+		var id, value, extra []byte
+		var format spanner.NullInt64
+		if err := r.Columns(&id, &value, &extra, &format); err != nil {
+			return err
+		}
+		last = id
+		value, extra, newFormat, changed, err := tx.codec.Recode(trillian.LeafCompression(format.Int64), value, extra)
+		if err != nil || !changed {
+			return err
+		}
+		muts = append(muts, spanner.Update(leafDataTbl,
+			[]string{colTreeID, colLeafIdentityHash, colLeafValue, colExtraData, colCompression},
+			[]interface{}{tx.treeID, id, value, extra, int64(newFormat)}))
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(muts) > 0 {
+		if err := stx.BufferWrite(muts); err != nil {
+			return nil, 0, fmt.Errorf("bufferwrite(): %v", err)
+		}
+	}
+	return last, len(muts), nil
+}
+
 // UpdateSequencedLeaves stores the sequence numbers assigned to the leaves,
 // and integrates them into the tree.
 func (tx *logTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
@@ -745,6 +818,9 @@ func (l leafmap) addFullRow(seqLeaves map[string]sequencedLeafDataCols) func(r *
 			LeafIndex:        seqLeaf.SequenceNumber,
 			LeafIdentityHash: leafData.LeafIdentityHash,
 		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(leafData.Compression.Int64), leaf); err != nil {
+			return err
+		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, leafData.QueueTimestampNanos))
 		if err != nil {
@@ -770,7 +846,12 @@ func (b leavesByHash) addRow(r *spanner.Row) error {
 	var v []byte
 	var ed []byte
 	var qTimestamp int64
-	if err := r.Columns(&h, &v, &ed, &qTimestamp); err != nil {
+	var format spanner.NullInt64
+	if err := r.Columns(&h, &v, &ed, &qTimestamp, &format); err != nil {
+		return err
+	}
+	v, ed, err := compression.Decode(trillian.LeafCompression(format.Int64), v, ed)
+	if err != nil {
 		return err
 	}
 	queueTimestamp, err := ptypes.TimestampProto(time.Unix(0, qTimestamp))
@@ -803,7 +884,7 @@ func (tx *logTX) populateLeafData(ctx context.Context, byHash leavesByHash) erro
 	for k := range byHash {
 		keySet = append(keySet, spanner.Key{tx.treeID, []byte(k)})
 	}
-	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colQueueTimestampNanos}
+	cols := []string{colLeafIdentityHash, colLeafValue, colExtraData, colQueueTimestampNanos, colCompression}
 	rows := tx.stx.Read(ctx, leafDataTbl, spanner.KeySets(keySet...), cols)
 	return rows.Do(byHash.addRow)
 }
@@ -873,7 +954,8 @@ func (tx *logTX) GetLeavesByIndex(ctx context.Context, indices []int64) ([]*tril
 		   LeafIdentityHash, 
 		   LeafValue, 
 		   ExtraData, 
-		   QueueTimestampNanos
+		   QueueTimestampNanos,
+		   Compression
 		 FROM 
 		   SequencedLeafData
 		 WHERE 
@@ -979,7 +1061,8 @@ func (tx *logTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*t
 		   LeafIdentityHash, 
 		   LeafValue, 
 		   ExtraData, 
-		   QueueTimestampNanos
+		   QueueTimestampNanos,
+		   Compression
 		 FROM 
 		   LeafData
 		 WHERE 
@@ -34,19 +34,19 @@ WCkgTk9UIE5VTEwKKSBQUklNQVJZIEtFWShUcmVlSUQsIFN1YnRyZWVJRCwgUmV2aXNpb24gREVT
 Qyk7CgpDUkVBVEUgVEFCTEUgTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICBJTlQ2NCBO
 T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgTGVh
 ZlZhbHVlICAgICAgICAgICBCWVRFUyhNQVgpIE5PVCBOVUxMLAogIEV4dHJhRGF0YSAgICAgICAg
-ICAgQllURVMoTUFYKSwKICBRdWV1ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBOVUxMLAopIFBS
-SU1BUlkgS0VZKFRyZWVJRCwgTGVhZklkZW50aXR5SGFzaCk7CgpDUkVBVEUgVEFCTEUgU2VxdWVu
-Y2VkTGVhZkRhdGEoCiAgVHJlZUlEICAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAg
-U2VxdWVuY2VOdW1iZXIgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgTGVhZklkZW50aXR5SGFz
-aCAgICAgICAgQllURVMoMjU2KSBOT1QgTlVMTCwKICBNZXJrbGVMZWFmSGFzaCAgICAgICAgICBC
-WVRFUygyNTYpIE5PVCBOVUxMLAogIEludGVncmF0ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBO
-VUxMLAopIFBSSU1BUlkgS0VZKFRyZWVJRCwgU2VxdWVuY2VOdW1iZXIpOwoKQ1JFQVRFIElOREVY
-IFNlcXVlbmNlQnlNZXJrbGVIYXNoCiAgT04gU2VxdWVuY2VkTGVhZkRhdGEoVHJlZUlELCBNZXJr
-bGVMZWFmSGFzaCkKICBTVE9SSU5HKExlYWZJZGVudGl0eUhhc2gpOwoKQ1JFQVRFIFRBQkxFIFVu
-c2VxdWVuY2VkKAogIFRyZWVJRCAgICAgICAgICAgICAgICAgSU5UNjQgTk9UIE5VTEwsCiAgQnVj
-a2V0ICAgICAgICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBRdWV1ZVRpbWVzdGFtcE5hbm9z
-ICAgIElOVDY0IE5PVCBOVUxMLAogIE1lcmtsZUxlYWZIYXNoICAgICAgICAgQllURVMoMjU2KSBO
-T1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCikg
-UFJJTUFSWSBLRVkgKFRyZWVJRCwgQnVja2V0LCBRdWV1ZVRpbWVzdGFtcE5hbm9zLCBNZXJrbGVM
-ZWFmSGFzaCk7Cg==
+ICAgQllURVMoTUFYKSwKICBRdWV1ZVRpbWVzdGFtcE5hbm9zIElOVDY0IE5PVCBOVUxMLAogIENv
+bXByZXNzaW9uICAgICAgICAgSU5UNjQsCikgUFJJTUFSWSBLRVkoVHJlZUlELCBMZWFmSWRlbnRp
+dHlIYXNoKTsKCkNSRUFURSBUQUJMRSBTZXF1ZW5jZWRMZWFmRGF0YSgKICBUcmVlSUQgICAgICAg
+ICAgICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBTZXF1ZW5jZU51bWJlciAgICAgICAgICBJTlQ2
+NCBOT1QgTlVMTCwKICBMZWFmSWRlbnRpdHlIYXNoICAgICAgICBCWVRFUygyNTYpIE5PVCBOVUxM
+LAogIE1lcmtsZUxlYWZIYXNoICAgICAgICAgIEJZVEVTKDI1NikgTk9UIE5VTEwsCiAgSW50ZWdy
+YXRlVGltZXN0YW1wTmFub3MgSU5UNjQgTk9UIE5VTEwsCikgUFJJTUFSWSBLRVkoVHJlZUlELCBT
+ZXF1ZW5jZU51bWJlcik7CgpDUkVBVEUgSU5ERVggU2VxdWVuY2VCeU1lcmtsZUhhc2gKICBPTiBT
+ZXF1ZW5jZWRMZWFmRGF0YShUcmVlSUQsIE1lcmtsZUxlYWZIYXNoKQogIFNUT1JJTkcoTGVhZklk
+ZW50aXR5SGFzaCk7CgpDUkVBVEUgVEFCTEUgVW5zZXF1ZW5jZWQoCiAgVHJlZUlEICAgICAgICAg
+ICAgICAgICBJTlQ2NCBOT1QgTlVMTCwKICBCdWNrZXQgICAgICAgICAgICAgICAgIElOVDY0IE5P
+VCBOVUxMLAogIFF1ZXVlVGltZXN0YW1wTmFub3MgICAgSU5UNjQgTk9UIE5VTEwsCiAgTWVya2xl
+TGVhZkhhc2ggICAgICAgICBCWVRFUygyNTYpIE5PVCBOVUxMLAogIExlYWZJZGVudGl0eUhhc2gg
+ICAgICAgQllURVMoMjU2KSBOT1QgTlVMTCwKKSBQUklNQVJZIEtFWSAoVHJlZUlELCBCdWNrZXQs
+IFF1ZXVlVGltZXN0YW1wTmFub3MsIE1lcmtsZUxlYWZIYXNoKTsK
 `
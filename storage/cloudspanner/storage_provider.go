@@ -74,6 +74,7 @@ func warn() {
 
 type cloudSpannerProvider struct {
 	client *spanner.Client
+	mf     monitoring.MetricFactory
 }
 
 func configFromFlags() spanner.ClientConfig {
@@ -97,7 +98,7 @@ func optionsFromFlags() []option.ClientOption {
 	return opts
 }
 
-func newCloudSpannerStorageProvider(_ monitoring.MetricFactory) (storage.Provider, error) {
+func newCloudSpannerStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
 	csMu.Lock()
 	defer csMu.Unlock()
 
@@ -111,6 +112,7 @@ func newCloudSpannerStorageProvider(_ monitoring.MetricFactory) (storage.Provide
 	}
 	csStorageInstance = &cloudSpannerProvider{
 		client: client,
+		mf:     mf,
 	}
 	return csStorageInstance, nil
 }
@@ -119,6 +121,7 @@ func newCloudSpannerStorageProvider(_ monitoring.MetricFactory) (storage.Provide
 func (s *cloudSpannerProvider) LogStorage() storage.LogStorage {
 	warn()
 	opts := LogStorageOptions{}
+	opts.MetricFactory = s.mf
 	frac := *csDequeueAcrossMerkleBucketsFraction
 	if frac > 1.0 {
 		frac = 1.0
@@ -27,6 +27,7 @@ import (
 	"github.com/golang/protobuf/proto" //nolint:staticcheck
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/cloudspanner/spannerpb"
@@ -81,6 +82,9 @@ type TreeStorageOptions struct {
 	// to help with performance.
 	// See https://cloud.google.com/spanner/docs/timestamp-bounds for more details.
 	ReadOnlyStaleness time.Duration
+	// MetricFactory is used to create the metrics of the shared tile cache,
+	// if this storage is the first to use it. Nil means no metrics.
+	MetricFactory monitoring.MetricFactory
 }
 
 func newTreeStorageWithOpts(client *spanner.Client, opts TreeStorageOptions) *treeStorage {
@@ -166,6 +170,7 @@ func (t *treeTX) getLatestRoot(ctx context.Context) error {
 		t._currentSTH, t._currentSTHErr = t.ts.latestSTH(ctx, t.stx, t.treeID)
 		if t._currentSTH != nil {
 			t._writeRev = t._currentSTH.TreeRevision + 1
+			t.cache.UseTileCache(cache.SharedTileCache(t.ts.opts.MetricFactory), t.treeID, t._currentSTH.TreeRevision)
 		}
 	})
 
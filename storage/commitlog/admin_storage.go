@@ -0,0 +1,397 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminEntry is an entry of the trees topic. It holds either the new state
+// of a tree, or the ID of a tree which was hard deleted.
+type adminEntry struct {
+	// Tree is the marshaled trillian.Tree.
+	Tree        []byte `json:",omitempty"`
+	HardDeleted int64  `json:",omitempty"`
+}
+
+// treeSet is the set of trees materialised from the trees topic of a Log.
+type treeSet struct {
+	log Log
+
+	mu sync.Mutex
+	// offset is the offset of the next entry of the trees topic to apply.
+	offset int64
+	trees  map[int64]*trillian.Tree
+}
+
+func newTreeSet(l Log) *treeSet {
+	return &treeSet{log: l, trees: make(map[int64]*trillian.Tree)}
+}
+
+// snapshot catches up with the trees topic, and returns a copy of the set of
+// trees. The trees themselves are shared, and must not be modified.
+func (s *treeSet) snapshot(ctx context.Context) (map[int64]*trillian.Tree, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		entries, err := s.log.Read(ctx, treesTopic, s.offset, catchUpBatch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trees: %v", err)
+		}
+		for _, e := range entries {
+			var entry adminEntry
+			if err := json.Unmarshal(e, &entry); err != nil {
+				return nil, fmt.Errorf("failed to read tree entry %d: %v", s.offset, err)
+			}
+			if entry.Tree != nil {
+				var tree trillian.Tree
+				if err := proto.Unmarshal(entry.Tree, &tree); err != nil {
+					return nil, fmt.Errorf("failed to read tree entry %d: %v", s.offset, err)
+				}
+				s.trees[tree.TreeId] = &tree
+			} else {
+				delete(s.trees, entry.HardDeleted)
+			}
+			s.offset++
+		}
+		if len(entries) < catchUpBatch {
+			break
+		}
+	}
+
+	trees := make(map[int64]*trillian.Tree, len(s.trees))
+	for id, tree := range s.trees {
+		trees[id] = tree
+	}
+	return trees, nil
+}
+
+// getActiveLogIDs returns the IDs of all logs that are currently in a state
+// that requires sequencing (e.g. ACTIVE, DRAINING).
+func (s *treeSet) getActiveLogIDs(ctx context.Context) ([]int64, error) {
+	trees, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int64{}
+	for id, tree := range trees {
+		if tree.Deleted {
+			continue
+		}
+		switch tree.TreeType {
+		case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+			switch tree.TreeState {
+			case trillian.TreeState_ACTIVE, trillian.TreeState_DRAINING:
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// NewAdminStorage returns a storage.AdminStorage implementation which keeps
+// the trees in the given commit log.
+//
+// Transactions don't conflict with each other: if several of them update the
+// same tree concurrently, the last one to commit wins.
+func NewAdminStorage(l Log) storage.AdminStorage {
+	return &commitLogAdminStorage{trees: newTreeSet(l)}
+}
+
+// commitLogAdminStorage implements storage.AdminStorage
+type commitLogAdminStorage struct {
+	trees *treeSet
+}
+
+func (s *commitLogAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	return s.beginInternal(ctx)
+}
+
+func (s *commitLogAdminStorage) beginInternal(ctx context.Context) (*adminTX, error) {
+	trees, err := s.trees.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTX{ctx: ctx, log: s.trees.log, trees: trees}, nil
+}
+
+func (s *commitLogAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	tx, err := s.beginInternal(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *commitLogAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	_, err := s.trees.snapshot(ctx)
+	return err
+}
+
+// adminTX buffers the changes made to the trees until it is committed, when
+// they are appended to the trees topic.
+type adminTX struct {
+	ctx context.Context
+	log Log
+
+	mu      sync.Mutex
+	closed  bool
+	trees   map[int64]*trillian.Tree
+	pending [][]byte
+}
+
+func (t *adminTX) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if len(t.pending) == 0 {
+		return nil
+	}
+	if _, err := t.log.Append(t.ctx, treesTopic, t.pending...); err != nil {
+		glog.Warningf("Failed to append to the trees topic: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (t *adminTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
+
+func (t *adminTX) IsClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+func (t *adminTX) Close() error {
+	return t.Rollback()
+}
+
+func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.getTree(treeID)
+}
+
+// getTree returns a copy of the given tree, which the caller may modify.
+func (t *adminTX) getTree(treeID int64) (*trillian.Tree, error) {
+	tree, ok := t.trees[treeID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	return proto.Clone(tree).(*trillian.Tree), nil
+}
+
+// sortedTrees returns copies of the trees of the transaction, ordered by ID.
+func (t *adminTX) sortedTrees(includeDeleted bool) []*trillian.Tree {
+	trees := []*trillian.Tree{}
+	for _, tree := range t.trees {
+		if includeDeleted || !tree.Deleted {
+			trees = append(trees, proto.Clone(tree).(*trillian.Tree))
+		}
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+	return trees
+}
+
+func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	treeIDs := []int64{}
+	for _, tree := range t.sortedTrees(includeDeleted) {
+		treeIDs = append(treeIDs, tree.TreeId)
+	}
+	return treeIDs, nil
+}
+
+func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sortedTrees(includeDeleted), nil
+}
+
+// putTree records the new state of the given tree, which is written to the
+// trees topic on commit.
+func (t *adminTX) putTree(tree *trillian.Tree) error {
+	v, err := proto.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	e, err := json.Marshal(adminEntry{Tree: v})
+	if err != nil {
+		return err
+	}
+	t.trees[tree.TreeId] = tree
+	t.pending = append(t.pending, e)
+	return nil
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	id, err := storage.NewTreeID()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := t.trees[id]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "tree %v already exists", id)
+	}
+
+	now := time.Now()
+	newTree := proto.Clone(tree).(*trillian.Tree)
+	newTree.TreeId = id
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+
+	if err := t.putTree(newTree); err != nil {
+		return nil, err
+	}
+	return proto.Clone(newTree).(*trillian.Tree), nil
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tree, err := t.getTree(treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	updateFunc(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, beforeUpdate, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	tree.UpdateTime, err = ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return proto.Clone(tree).(*trillian.Tree), nil
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(treeID, true /* deleted */)
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.updateDeleted(treeID, false /* deleted */)
+}
+
+// updateDeleted updates the Deleted and DeleteTime fields of the specified tree.
+func (t *adminTX) updateDeleted(treeID int64, deleted bool) (*trillian.Tree, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tree, err := t.validateDeleted(treeID, !deleted)
+	if err != nil {
+		return nil, err
+	}
+	tree.Deleted = deleted
+	tree.DeleteTime = nil
+	if deleted {
+		if tree.DeleteTime, err = ptypes.TimestampProto(time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to build delete time: %v", err)
+		}
+	}
+	if err := t.putTree(tree); err != nil {
+		return nil, err
+	}
+	return proto.Clone(tree).(*trillian.Tree), nil
+}
+
+// HardDeleteTree removes the tree from the set of trees. The entries of its
+// leaves and commits topics are kept, as the commit log is append-only.
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.validateDeleted(treeID, true /* wantDeleted */); err != nil {
+		return err
+	}
+	e, err := json.Marshal(adminEntry{HardDeleted: treeID})
+	if err != nil {
+		return err
+	}
+	delete(t.trees, treeID)
+	t.pending = append(t.pending, e)
+	return nil
+}
+
+// validateDeleted returns a copy of the given tree if its soft deletion
+// status is as wanted, or an error otherwise.
+func (t *adminTX) validateDeleted(treeID int64, wantDeleted bool) (*trillian.Tree, error) {
+	tree, err := t.getTree(treeID)
+	if err != nil {
+		return nil, err
+	}
+	switch deleted := tree.Deleted; {
+	case wantDeleted && !deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v is not soft deleted", treeID)
+	case !wantDeleted && deleted:
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %v already soft deleted", treeID)
+	}
+	return tree, nil
+}
+
+func validateStorageSettings(tree *trillian.Tree) error {
+	if tree.StorageSettings != nil {
+		return fmt.Errorf("storage_settings not supported, but got %v", tree.StorageSettings)
+	}
+	return nil
+}
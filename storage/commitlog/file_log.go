@@ -0,0 +1,374 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// segmentSuffix is the file name suffix of the segments of a topic, which
+	// are named by the offset of their first entry.
+	segmentSuffix = ".seg"
+	// entryHeaderSize is the size of the length and checksum which precede
+	// each entry in a segment.
+	entryHeaderSize = 8
+)
+
+var errLogClosed = errors.New("commit log is closed")
+
+// FileLog is a Log kept in a local directory, which may be shared by the
+// processes of one machine, or by several machines through a file system
+// with working advisory locks.
+//
+// Each topic is a subdirectory holding a sequence of segment files. A segment
+// is a series of entries, each framed by its length and CRC-32 checksum, and
+// a new segment is started once the last one reaches the configured size.
+// Appends hold an exclusive lock on the topic, and are synced before they
+// return; a partially written entry left by a failed append is discarded by
+// the next one.
+type FileLog struct {
+	dir          string
+	segmentBytes int64
+
+	mu     sync.Mutex
+	closed bool
+	topics map[string]*fileTopic
+}
+
+// NewFileLog returns a FileLog in the given directory, which is created if
+// it does not exist. Segments are rolled over once they reach segmentBytes.
+func NewFileLog(dir string, segmentBytes int64) (*FileLog, error) {
+	if segmentBytes <= 0 {
+		return nil, fmt.Errorf("segment size must be positive, got %d", segmentBytes)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileLog{
+		dir:          dir,
+		segmentBytes: segmentBytes,
+		topics:       make(map[string]*fileTopic),
+	}, nil
+}
+
+// topic returns the named topic, creating its directory if necessary.
+func (l *FileLog) topic(name string) (*fileTopic, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, errLogClosed
+	}
+	if t, ok := l.topics[name]; ok {
+		return t, nil
+	}
+	dir := filepath.Join(l.dir, url.PathEscape(name))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, "lock"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	t := &fileTopic{dir: dir, lock: lock}
+	l.topics[name] = t
+	return t, nil
+}
+
+// Append implements Log.
+func (l *FileLog) Append(ctx context.Context, topic string, entries ...[]byte) (int64, error) {
+	t, err := l.topic(topic)
+	if err != nil {
+		return 0, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := lockFile(t.lock); err != nil {
+		return 0, fmt.Errorf("failed to lock topic %q: %v", topic, err)
+	}
+	defer unlockFile(t.lock) // nolint: errcheck
+
+	if err := t.refresh(); err != nil {
+		return 0, err
+	}
+	first := t.end()
+	s := t.last()
+	if s == nil || s.size >= l.segmentBytes {
+		if s, err = t.newSegment(first); err != nil {
+			return 0, err
+		}
+	} else if err := s.f.Truncate(s.size); err != nil {
+		// Drop anything after the last complete entry, which was left behind
+		// by an append that failed half-way.
+		return 0, err
+	}
+
+	var buf []byte
+	positions := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		positions = append(positions, s.size+int64(len(buf)))
+		var hdr [entryHeaderSize]byte
+		binary.BigEndian.PutUint32(hdr[:4], uint32(len(e)))
+		binary.BigEndian.PutUint32(hdr[4:], crc32.ChecksumIEEE(e))
+		buf = append(append(buf, hdr[:]...), e...)
+	}
+	if _, err := s.f.WriteAt(buf, s.size); err != nil {
+		return 0, err
+	}
+	if err := s.f.Sync(); err != nil {
+		return 0, err
+	}
+	s.positions = append(s.positions, positions...)
+	s.size += int64(len(buf))
+	return first, nil
+}
+
+// Read implements Log.
+func (l *FileLog) Read(ctx context.Context, topic string, offset int64, limit int) ([][]byte, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d, want >= 0", offset)
+	}
+	t, err := l.topic(topic)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Readers don't take the lock of the topic: an entry which is being
+	// written fails its checksum or is short, and is not indexed until the
+	// next refresh.
+	if err := t.refresh(); err != nil {
+		return nil, err
+	}
+	var ret [][]byte
+	i := sort.Search(len(t.segments), func(i int) bool { return t.segments[i].base > offset }) - 1
+	for ; i >= 0 && i < len(t.segments) && len(ret) < limit; i++ {
+		s := t.segments[i]
+		for j := offset - s.base; j < int64(len(s.positions)) && len(ret) < limit; j++ {
+			e, err := s.read(j)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+			offset++
+		}
+	}
+	return ret, nil
+}
+
+// Close implements Log.
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	var firstErr error
+	for _, t := range l.topics {
+		if err := t.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// fileTopic is a topic of a FileLog.
+type fileTopic struct {
+	dir  string
+	lock *os.File
+
+	// mu guards the index of the segments, which is shared by all the users
+	// of the topic in this process.
+	mu       sync.Mutex
+	segments []*segment
+}
+
+// segment is a file holding consecutive entries of a topic.
+type segment struct {
+	f *os.File
+	// base is the offset of the first entry of the segment.
+	base int64
+	// positions holds the position in the file of each complete entry.
+	positions []int64
+	// size is the position just after the last complete entry.
+	size int64
+}
+
+func segmentName(base int64) string {
+	return fmt.Sprintf("%020d%s", base, segmentSuffix)
+}
+
+// last returns the last segment of the topic, or nil if there is none.
+func (t *fileTopic) last() *segment {
+	if len(t.segments) == 0 {
+		return nil
+	}
+	return t.segments[len(t.segments)-1]
+}
+
+// end returns the offset following the last indexed entry of the topic.
+func (t *fileTopic) end() int64 {
+	s := t.last()
+	if s == nil {
+		return 0
+	}
+	return s.base + int64(len(s.positions))
+}
+
+// refresh brings the index of the topic up to date with the segment files,
+// which may have been written by another process.
+func (t *fileTopic) refresh() error {
+	names, err := filepath.Glob(filepath.Join(t.dir, "*"+segmentSuffix))
+	if err != nil {
+		return err
+	}
+	// The zero-padded names sort in offset order.
+	sort.Strings(names)
+	for _, name := range names {
+		base, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(name), segmentSuffix), 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected segment file %q: %v", name, err)
+		}
+		if s := t.last(); s != nil && base <= s.base {
+			continue
+		}
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		t.segments = append(t.segments, &segment{f: f, base: base})
+	}
+	// Only the last two segments can have grown since the last refresh: no
+	// segment is started before the previous one is complete.
+	for i := len(t.segments) - 2; i < len(t.segments); i++ {
+		if i < 0 {
+			continue
+		}
+		if err := t.segments[i].scan(); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < len(t.segments); i++ {
+		prev, s := t.segments[i-1], t.segments[i]
+		if want := prev.base + int64(len(prev.positions)); s.base != want {
+			return fmt.Errorf("segment %s of %s starts at offset %d, want %d", segmentName(s.base), t.dir, s.base, want)
+		}
+	}
+	return nil
+}
+
+// newSegment creates an empty segment starting at the given offset, and adds
+// it to the index.
+func (t *fileTopic) newSegment(base int64) (*segment, error) {
+	f, err := os.OpenFile(filepath.Join(t.dir, segmentName(base)), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncDir(t.dir); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &segment{f: f, base: base}
+	t.segments = append(t.segments, s)
+	return s, nil
+}
+
+func (t *fileTopic) close() error {
+	var firstErr error
+	for _, s := range t.segments {
+		if err := s.f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := t.lock.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// scan indexes the complete entries written to the segment after the ones
+// already indexed.
+func (s *segment) scan() error {
+	fi, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	var hdr [entryHeaderSize]byte
+	for {
+		if _, err := s.f.ReadAt(hdr[:], s.size); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		n := int64(binary.BigEndian.Uint32(hdr[:4]))
+		if s.size+entryHeaderSize+n > fi.Size() {
+			// The entry is incomplete.
+			return nil
+		}
+		e := make([]byte, n)
+		if _, err := s.f.ReadAt(e, s.size+entryHeaderSize); err != nil {
+			return err
+		}
+		if crc32.ChecksumIEEE(e) != binary.BigEndian.Uint32(hdr[4:]) {
+			// This is either an entry which is still being written, or one
+			// which will be overwritten by the next append.
+			return nil
+		}
+		s.positions = append(s.positions, s.size)
+		s.size += entryHeaderSize + int64(len(e))
+	}
+}
+
+// read returns the entry with the given index in the segment.
+func (s *segment) read(i int64) ([]byte, error) {
+	start := s.positions[i] + entryHeaderSize
+	end := s.size
+	if i+1 < int64(len(s.positions)) {
+		end = s.positions[i+1]
+	}
+	e := make([]byte, end-start)
+	if _, err := s.f.ReadAt(e, start); err != nil {
+		return nil, fmt.Errorf("failed to read entry %d of segment %d: %v", s.base+i, s.base, err)
+	}
+	return e, nil
+}
+
+// syncDir syncs the given directory, so that the files created in it are
+// durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
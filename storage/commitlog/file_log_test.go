@@ -0,0 +1,160 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestLog returns a FileLog in a temporary directory, which is closed and
+// removed at the end of the test.
+func newTestLog(t *testing.T, segmentBytes int64) *FileLog {
+	t.Helper()
+	l, err := NewFileLog(t.TempDir(), segmentBytes)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func entries(first, count int) [][]byte {
+	ret := make([][]byte, count)
+	for i := range ret {
+		ret[i] = []byte(fmt.Sprintf("entry %d", first+i))
+	}
+	return ret
+}
+
+func TestFileLogAppendRead(t *testing.T) {
+	ctx := context.Background()
+	for _, segmentBytes := range []int64{1, 40, 1 << 20} {
+		t.Run(fmt.Sprintf("segment%d", segmentBytes), func(t *testing.T) {
+			l := newTestLog(t, segmentBytes)
+			for _, batch := range []int{1, 3, 2, 4} {
+				want, err := l.Read(ctx, "topic", 0, 100)
+				if err != nil {
+					t.Fatalf("Read(): %v", err)
+				}
+				first, err := l.Append(ctx, "topic", entries(len(want), batch)...)
+				if err != nil {
+					t.Fatalf("Append(): %v", err)
+				}
+				if got, want := first, int64(len(want)); got != want {
+					t.Errorf("Append(): %d, want %d", got, want)
+				}
+			}
+			for _, tc := range []struct {
+				offset int64
+				limit  int
+				want   [][]byte
+			}{
+				{offset: 0, limit: 100, want: entries(0, 10)},
+				{offset: 3, limit: 4, want: entries(3, 4)},
+				{offset: 9, limit: 4, want: entries(9, 1)},
+				{offset: 10, limit: 4},
+				{offset: 20, limit: 4},
+			} {
+				got, err := l.Read(ctx, "topic", tc.offset, tc.limit)
+				if err != nil {
+					t.Fatalf("Read(%d, %d): %v", tc.offset, tc.limit, err)
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("Read(%d, %d): diff (-want +got):\n%s", tc.offset, tc.limit, diff)
+				}
+			}
+			if got, err := l.Read(ctx, "other/topic", 0, 100); err != nil || len(got) != 0 {
+				t.Errorf("Read(other topic): %v, %v, want no entries", got, err)
+			}
+		})
+	}
+}
+
+func TestFileLogShared(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	var logs []*FileLog
+	for i := 0; i < 2; i++ {
+		l, err := NewFileLog(dir, 64)
+		if err != nil {
+			t.Fatalf("NewFileLog(): %v", err)
+		}
+		defer l.Close()
+		logs = append(logs, l)
+	}
+
+	// Each FileLog must see the entries appended by the other.
+	for i := 0; i < 10; i++ {
+		first, err := logs[i%2].Append(ctx, "topic", entries(i, 1)...)
+		if err != nil {
+			t.Fatalf("Append(): %v", err)
+		}
+		if first != int64(i) {
+			t.Errorf("Append() #%d: %d, want %d", i, first, i)
+		}
+	}
+	for _, l := range logs {
+		got, err := l.Read(ctx, "topic", 0, 100)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if diff := cmp.Diff(entries(0, 10), got); diff != "" {
+			t.Errorf("Read(): diff (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestFileLogTornEntry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	l, err := NewFileLog(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	defer l.Close()
+	if _, err := l.Append(ctx, "topic", entries(0, 2)...); err != nil {
+		t.Fatalf("Append(): %v", err)
+	}
+
+	// Simulate an append which failed half-way.
+	f, err := os.OpenFile(filepath.Join(dir, "topic", segmentName(0)), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(): %v", err)
+	}
+	if _, err := f.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	f.Close()
+
+	if got, err := l.Read(ctx, "topic", 0, 100); err != nil || len(got) != 2 {
+		t.Fatalf("Read() with torn entry: %d entries, %v; want 2 entries", len(got), err)
+	}
+	if first, err := l.Append(ctx, "topic", entries(2, 1)...); err != nil || first != 2 {
+		t.Fatalf("Append() after torn entry: %d, %v; want 2", first, err)
+	}
+	got, err := l.Read(ctx, "topic", 0, 100)
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	if diff := cmp.Diff(entries(0, 3), got); diff != "" {
+		t.Errorf("Read(): diff (-want +got):\n%s", diff)
+	}
+}
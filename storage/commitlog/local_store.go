@@ -0,0 +1,340 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage/storagepb"
+	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// treesTopic is the topic holding the metadata of all trees.
+	treesTopic = "trees"
+
+	// catchUpBatch is the number of commit entries applied to the local
+	// database in each of its transactions.
+	catchUpBatch = 256
+)
+
+var (
+	// localBucket holds a bucket with the materialised state of each log,
+	// keyed by tree ID.
+	localBucket = []byte("logs")
+
+	// followKey maps to the followState of a log.
+	followKey = []byte("follow")
+
+	seqLeafPrefix   = []byte("seq/")
+	hashToSeqPrefix = []byte("h2s/")
+	idToSeqPrefix   = []byte("id/")
+	sthPrefix       = []byte("sth/")
+	subtreePrefix   = []byte("subtree/")
+)
+
+// OpenLocalDB opens the Bolt database in the given file, in which the state
+// of the logs is materialised from the commit log. The file is created if it
+// does not exist; it can be deleted at any time the database is closed, and
+// is then rebuilt from the commit log.
+func OpenLocalDB(path string, opts *bbolt.Options) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0600, opts)
+	if err != nil {
+		glog.Warningf("Could not open local database %q: %s", path, err)
+		return nil, err
+	}
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(localBucket)
+		return err
+	}); err != nil {
+		glog.Warningf("Failed to create buckets in local database %q: %s", path, err)
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func leavesTopic(treeID int64) string {
+	return "leaves/" + strconv.FormatInt(treeID, 10)
+}
+
+func commitsTopic(treeID int64) string {
+	return "commits/" + strconv.FormatInt(treeID, 10)
+}
+
+// queuedLeaf is an entry of the leaves topic of a log.
+type queuedLeaf struct {
+	// Leaf is the marshaled LogLeaf, with its QueueTimestamp set.
+	Leaf      []byte
+	Submitter string `json:",omitempty"`
+}
+
+// commitEntry is an entry of the commits topic of a log, which records the
+// changes made by one transaction.
+type commitEntry struct {
+	// Offset is the offset in the commits topic at which the entry was meant
+	// to be appended. An entry found at any other offset was written by a
+	// transaction which lost a race with another writer, and is ignored.
+	Offset int64
+	// QueueOffset is the offset in the leaves topic from which the next
+	// transaction dequeues leaves.
+	QueueOffset int64
+	// Revision is the revision the subtrees were written at.
+	Revision int64
+	// Leaves holds the marshaled LogLeaf protos which were sequenced, with
+	// all their fields set.
+	Leaves [][]byte `json:",omitempty"`
+	// Subtrees holds the marshaled SubtreeProtos which were updated.
+	Subtrees [][]byte `json:",omitempty"`
+	// Root holds the marshaled SignedLogRoot which was stored, if any.
+	Root []byte `json:",omitempty"`
+}
+
+// followState records how far a log has been materialised.
+type followState struct {
+	// commitOffset is the offset of the next entry of the commits topic to
+	// apply.
+	commitOffset int64
+	// queueOffset is the QueueOffset of the last commit entry applied.
+	queueOffset int64
+}
+
+func (s followState) marshal() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(s.commitOffset))
+	binary.BigEndian.PutUint64(b[8:], uint64(s.queueOffset))
+	return b
+}
+
+// readFollowState returns the followState stored in b, which may be nil if
+// nothing has been applied yet.
+func readFollowState(b *bbolt.Bucket) (followState, error) {
+	if b == nil {
+		return followState{}, nil
+	}
+	v := b.Get(followKey)
+	if v == nil {
+		return followState{}, nil
+	}
+	if len(v) != 16 {
+		return followState{}, fmt.Errorf("follow state has %d bytes, want 16", len(v))
+	}
+	return followState{
+		commitOffset: int64(binary.BigEndian.Uint64(v)),
+		queueOffset:  int64(binary.BigEndian.Uint64(v[8:])),
+	}, nil
+}
+
+// logBucket returns the bucket of the given log in the local database, or nil
+// if nothing has been applied to it yet.
+func logBucket(tx *bbolt.Tx, treeID int64) *bbolt.Bucket {
+	return tx.Bucket(localBucket).Bucket(uint64Bytes(uint64(treeID)))
+}
+
+// catchUp applies the entries appended to the commits topic of the given log
+// since it was last followed.
+func catchUp(ctx context.Context, l Log, db *bbolt.DB, treeID int64) error {
+	for {
+		var state followState
+		if err := db.View(func(tx *bbolt.Tx) error {
+			var err error
+			state, err = readFollowState(logBucket(tx, treeID))
+			return err
+		}); err != nil {
+			return err
+		}
+		entries, err := l.Read(ctx, commitsTopic(treeID), state.commitOffset, catchUpBatch)
+		if err != nil {
+			return fmt.Errorf("failed to read commits of log %d: %v", treeID, err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := db.Update(func(tx *bbolt.Tx) error {
+			b, err := tx.Bucket(localBucket).CreateBucketIfNotExists(uint64Bytes(uint64(treeID)))
+			if err != nil {
+				return err
+			}
+			cur, err := readFollowState(b)
+			if err != nil {
+				return err
+			}
+			for i, e := range entries {
+				// Another transaction of this process may have applied some
+				// of the entries meanwhile.
+				if offset := state.commitOffset + int64(i); offset == cur.commitOffset {
+					if cur, err = applyCommit(b, cur, e); err != nil {
+						return fmt.Errorf("failed to apply commit %d of log %d: %v", offset, treeID, err)
+					}
+				}
+			}
+			return b.Put(followKey, cur.marshal())
+		}); err != nil {
+			return err
+		}
+		if len(entries) < catchUpBatch {
+			return nil
+		}
+	}
+}
+
+// applyCommit applies the commit entry e, found at offset state.commitOffset,
+// to the bucket of a log, and returns the new followState of the log.
+func applyCommit(b *bbolt.Bucket, state followState, e []byte) (followState, error) {
+	var entry commitEntry
+	if err := json.Unmarshal(e, &entry); err != nil {
+		return state, err
+	}
+	offset := state.commitOffset
+	state.commitOffset++
+	if entry.Offset != offset {
+		glog.V(1).Infof("Ignoring commit entry at offset %d, written for offset %d", offset, entry.Offset)
+		return state, nil
+	}
+
+	for _, v := range entry.Leaves {
+		var leaf trillian.LogLeaf
+		if err := proto.Unmarshal(v, &leaf); err != nil {
+			return state, err
+		}
+		seq := uint64Bytes(uint64(leaf.LeafIndex))
+		if err := b.Put(seqLeafKey(leaf.LeafIndex), v); err != nil {
+			return state, err
+		}
+		if err := b.Put(key(hashToSeqPrefix, leaf.MerkleLeafHash, seq), []byte{}); err != nil {
+			return state, err
+		}
+		if err := b.Put(key(idToSeqPrefix, leaf.LeafIdentityHash), seq); err != nil {
+			return state, err
+		}
+	}
+	for _, v := range entry.Subtrees {
+		var st storagepb.SubtreeProto
+		if err := proto.Unmarshal(v, &st); err != nil {
+			return state, err
+		}
+		if err := b.Put(subtreeKey(st.Prefix, entry.Revision), v); err != nil {
+			return state, err
+		}
+	}
+	if entry.Root != nil {
+		var slr trillian.SignedLogRoot
+		if err := proto.Unmarshal(entry.Root, &slr); err != nil {
+			return state, err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return state, err
+		}
+		if err := b.Put(sthKey(root.TimestampNanos), entry.Root); err != nil {
+			return state, err
+		}
+	}
+	state.queueOffset = entry.QueueOffset
+	return state, nil
+}
+
+// uint64Bytes returns the big-endian encoding of v, which sorts in the same
+// order as v.
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// key concatenates the given parts into a key.
+func key(parts ...[]byte) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		b.Write(p)
+	}
+	return b.Bytes()
+}
+
+// seqLeafKey formats a key for use in a log's bucket. The associated value
+// will be the LogLeaf at the given sequence number, with all its fields set.
+func seqLeafKey(seq int64) []byte {
+	return key(seqLeafPrefix, uint64Bytes(uint64(seq)))
+}
+
+// sthKey formats a key for use in a log's bucket. The associated value will
+// be the SignedLogRoot with the given timestamp.
+func sthKey(timestamp uint64) []byte {
+	return key(sthPrefix, uint64Bytes(timestamp))
+}
+
+// subtreeKey formats a key for use in a log's bucket. The associated value
+// will be the SubtreeProto with the given ID, written at the given revision.
+// The ID is prefixed with its length, so that all the revisions of a subtree
+// are adjacent.
+func subtreeKey(id []byte, rev int64) []byte {
+	return key(subtreeKeyPrefix(id), uint64Bytes(uint64(rev)))
+}
+
+// subtreeKeyPrefix returns the prefix of the keys of all revisions of the
+// subtree with the given ID.
+func subtreeKeyPrefix(id []byte) []byte {
+	return key(subtreePrefix, []byte{byte(len(id))}, id)
+}
+
+// subtreeID returns a []byte suitable for use in subtreeKey for the subtree
+// rooted at the passed-in node ID. Returns an error if the ID is not aligned
+// to bytes.
+func subtreeID(id stree.NodeID) ([]byte, error) {
+	if id.PrefixLenBits%8 != 0 {
+		return nil, fmt.Errorf("invalid subtree ID - not multiple of 8: %d", id.PrefixLenBits)
+	}
+	if bytes := id.Path; bytes != nil {
+		return bytes[:id.PrefixLenBits/8], nil
+	}
+	return []byte{}, nil
+}
+
+// lastWithPrefix returns the last entry of the bucket whose key starts with
+// the given prefix, or nil if there is no such entry.
+func lastWithPrefix(c *bbolt.Cursor, prefix []byte) ([]byte, []byte) {
+	k, v := c.Seek(prefixEnd(prefix))
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return nil, nil
+	}
+	return k, v
+}
+
+// prefixEnd returns the smallest key which is greater than all the keys with
+// the given prefix. The prefix must not consist of 0xff bytes only.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i]++; end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	panic(fmt.Sprintf("no end for prefix %x", prefix))
+}
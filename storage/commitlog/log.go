@@ -0,0 +1,65 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package commitlog provides a storage layer implementation in which the
+// sequencing decisions of every log are appended to an ordered commit log,
+// following docs/storage/commit_log/commit_log_based_storage_design.md.
+//
+// The commit log is the source of truth. It is made up of named topics:
+//
+//   - "trees" holds the tree metadata written through the AdminStorage.
+//   - "leaves/<tree ID>" holds the leaves queued to a log, in queue order.
+//   - "commits/<tree ID>" holds the sequencing decisions made for a log: the
+//     leaves integrated at each revision, along with the updated subtrees and
+//     the new signed log root.
+//
+// Each signer replica materialises the state of the logs it serves into a
+// local Bolt database, by following the commits topics from the offset it
+// last applied. Replicas sharing a commit log therefore converge to the same
+// state, and a lost local database can be rebuilt by replaying the log.
+//
+// Writers coordinate optimistically, as in the design: every commit entry
+// records the offset it expects to be appended at, which is the end of the
+// commits topic as seen by the writer when its transaction began. If another
+// replica appended an entry first, the new entry lands at a later offset, and
+// is ignored by every follower; its transaction fails with codes.Aborted.
+//
+// Only leaves which have been sequenced are de-duplicated when queued.
+// Duplicates which are still in the queue are skipped when dequeued.
+package commitlog
+
+import (
+	"context"
+)
+
+// Log is an ordered, append-only log of opaque entries, split into topics.
+// The entries of a topic are numbered by consecutive offsets starting at 0.
+//
+// Implementations must be safe for concurrent use, including by several
+// processes sharing the same underlying log: the entries appended by any of
+// them must be seen in the same order by all readers.
+type Log interface {
+	// Append adds the given entries to the end of the topic, contiguously,
+	// and returns the offset of the first of them. It either appends all the
+	// entries, or none of them.
+	Append(ctx context.Context, topic string, entries ...[]byte) (int64, error)
+
+	// Read returns up to limit entries of the topic, starting at the given
+	// offset. It returns fewer entries, or none, if the end of the topic is
+	// reached.
+	Read(ctx context.Context, topic string, offset int64, limit int) ([][]byte, error)
+
+	// Close releases the resources held by the Log.
+	Close() error
+}
@@ -0,0 +1,1084 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/storagepb"
+	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	logIDLabel = "logid"
+
+	// dequeueBatch is the number of entries of the leaves topic read at a
+	// time by DequeueLeaves.
+	dequeueBatch = 256
+)
+
+var (
+	defaultLogStrata = []int{8, 8, 8, 8, 8, 8, 8, 8}
+
+	once                 sync.Once
+	queuedCounter        monitoring.Counter
+	dequeuedCounter      monitoring.Counter
+	abortedCommitCounter monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("commitlog_queued_leaves", "Number of leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("commitlog_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+	abortedCommitCounter = mf.NewCounter("commitlog_aborted_commits", "Number of commits which lost a race with another writer", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+type commitLogStorage struct {
+	log           Log
+	db            *bbolt.DB
+	trees         *treeSet
+	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
+
+	// mu guards writers, which holds a lock per log serialising the
+	// read-write transactions of this storage, so that they don't abort each
+	// other.
+	mu      sync.Mutex
+	writers map[int64]*sync.Mutex
+}
+
+// NewLogStorage creates a storage.LogStorage instance which appends to the
+// given commit log, and materialises the logs into the given local database.
+// Several instances, each with its own local database, can share a commit
+// log.
+func NewLogStorage(l Log, db *bbolt.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return newLogStorage(l, db, mf, nil)
+}
+
+func newLogStorage(l Log, db *bbolt.DB, mf monitoring.MetricFactory, weights storage.SubmitterWeights) *commitLogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &commitLogStorage{
+		log:              l,
+		db:               db,
+		trees:            newTreeSet(l),
+		metricFactory:    mf,
+		submitterWeights: weights,
+		writers:          make(map[int64]*sync.Mutex),
+	}
+}
+
+func (m *commitLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	if err := m.db.View(func(tx *bbolt.Tx) error {
+		if tx.Bucket(localBucket) == nil {
+			return errors.New("logs bucket not found")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	_, err := m.trees.snapshot(ctx)
+	return err
+}
+
+// lockWriter takes the writer lock of the given log, and returns a function
+// which releases it.
+func (m *commitLogStorage) lockWriter(treeID int64) func() {
+	m.mu.Lock()
+	mu, ok := m.writers[treeID]
+	if !ok {
+		mu = &sync.Mutex{}
+		m.writers[treeID] = mu
+	}
+	m.mu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// readOnlyLogTX implements storage.ReadOnlyLogTX
+type readOnlyLogTX struct {
+	trees *treeSet
+}
+
+func (m *commitLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	return &readOnlyLogTX{m.trees}, nil
+}
+
+func (t *readOnlyLogTX) Commit(context.Context) error {
+	return nil
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	return nil
+}
+
+func (t *readOnlyLogTX) Close() error {
+	return nil
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	return t.trees.getActiveLogIDs(ctx)
+}
+
+func (m *commitLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree, writable bool) (*logTreeTX, error) {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := func() {}
+	if writable {
+		unlock = m.lockWriter(tree.TreeId)
+	}
+	if err := catchUp(ctx, m.log, m.db, tree.TreeId); err != nil {
+		unlock()
+		return nil, err
+	}
+	tx, err := m.db.Begin(false /* writable */)
+	if err != nil {
+		unlock()
+		glog.Warningf("Could not start tree TX: %s", err)
+		return nil, err
+	}
+	b := logBucket(tx, tree.TreeId)
+	state, err := readFollowState(b)
+	if err != nil {
+		tx.Rollback()
+		unlock()
+		return nil, err
+	}
+
+	ltx := &logTreeTX{
+		ls:            m,
+		tx:            tx,
+		b:             b,
+		unlock:        unlock,
+		writable:      writable,
+		treeID:        tree.TreeId,
+		treeType:      tree.TreeType,
+		order:         tree.DequeueOrder,
+		hashSizeBytes: hasher.Size(),
+		subtreeCache:  cache.NewLogSubtreeCache(defaultLogStrata, hasher),
+		writeRevision: -1,
+		state:         state,
+		nextQueue:     state.queueOffset,
+		held:          math.MaxInt64,
+		dequeued:      make(map[string]dequeuedLeaf),
+		seqIndices:    make(map[int64]bool),
+		seqIDs:        make(map[string]bool),
+	}
+	ltx.slr, err = ltx.fetchLatestRoot()
+	if err == storage.ErrTreeNeedsInit {
+		ltx.writeRevision = 0
+		return ltx, err
+	} else if err != nil {
+		ltx.rollbackInternal()
+		return nil, err
+	}
+
+	if err := ltx.root.UnmarshalBinary(ltx.slr.LogRoot); err != nil {
+		ltx.rollbackInternal()
+		return nil, err
+	}
+
+	ltx.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *commitLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *commitLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if AddSequencedLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.AddSequencedLeaves(ctx, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *commitLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	return tx, err
+}
+
+// QueueLeaves appends the leaves to the leaves topic of the log. Leaves which
+// have already been sequenced are returned as duplicates; duplicates of
+// leaves which are still queued are only detected when dequeued.
+func (m *commitLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if QueueLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tx.queueLeaves(ctx, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+// dequeuedLeaf is a leaf returned by DequeueLeaves, along with its offset in
+// the leaves topic.
+type dequeuedLeaf struct {
+	offset int64
+	leaf   *trillian.LogLeaf
+}
+
+// logTreeTX reads from a snapshot of the local database, and buffers its
+// writes until it is committed, when they are appended to the commits topic
+// of the log as a single entry.
+type logTreeTX struct {
+	// mu ensures that the transaction can only be used for one operation at
+	// a time.
+	mu     sync.Mutex
+	closed bool
+
+	ls *commitLogStorage
+	tx *bbolt.Tx
+	// b is the bucket of the log, which is nil if nothing has been applied
+	// to it yet.
+	b *bbolt.Bucket
+	// unlock releases the writer lock held by a read-write transaction.
+	unlock   func()
+	writable bool
+
+	treeID        int64
+	treeType      trillian.TreeType
+	order         trillian.DequeueOrder
+	hashSizeBytes int
+	subtreeCache  *cache.SubtreeCache
+	writeRevision int64
+	state         followState
+	root          types.LogRootV1
+	slr           *trillian.SignedLogRoot
+
+	// nextQueue is the offset in the leaves topic from which the next call
+	// to DequeueLeaves reads.
+	nextQueue int64
+	// held is the lowest offset of a leaf which was read by DequeueLeaves,
+	// but left in the queue, or math.MaxInt64 if there is none.
+	held int64
+	// dequeued holds the leaves which have been dequeued but not sequenced
+	// yet, keyed by identity hash.
+	dequeued map[string]dequeuedLeaf
+
+	// sequenced holds the leaves to be added to the log, whose indices and
+	// identity hashes are in seqIndices and seqIDs.
+	sequenced  []*trillian.LogLeaf
+	seqIndices map[int64]bool
+	seqIDs     map[string]bool
+	newRoot    *trillian.SignedLogRoot
+}
+
+// get returns the value of the given key in the log's bucket, or nil if there
+// is none. The value is only valid for the life of the transaction.
+func (t *logTreeTX) get(k []byte) []byte {
+	if t.b == nil {
+		return nil
+	}
+	return t.b.Get(k)
+}
+
+// cursor returns a cursor over the log's bucket, or nil if it has no data.
+func (t *logTreeTX) cursor() *bbolt.Cursor {
+	if t.b == nil {
+		return nil
+	}
+	return t.b.Cursor()
+}
+
+// hasLeafID returns whether a leaf with the given identity hash is in the
+// log, or is being added to it by this transaction.
+func (t *logTreeTX) hasLeafID(leafIDHash []byte) bool {
+	return t.seqIDs[string(leafIDHash)] || t.get(key(idToSeqPrefix, leafIDHash)) != nil
+}
+
+// hasLeafIndex returns whether the log has a leaf at the given index, or one
+// is being added by this transaction.
+func (t *logTreeTX) hasLeafIndex(seq int64) bool {
+	return t.seqIndices[seq] || t.get(seqLeafKey(seq)) != nil
+}
+
+// addSequenced buffers the given leaf to be added to the log on commit.
+func (t *logTreeTX) addSequenced(leaf *trillian.LogLeaf) {
+	t.sequenced = append(t.sequenced, leaf)
+	t.seqIndices[leaf.LeafIndex] = true
+	t.seqIDs[string(leaf.LeafIdentityHash)] = true
+}
+
+func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return int64(t.root.Revision), nil
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.writeRevision < 0 {
+		return t.writeRevision, errors.New("logTreeTX write revision not populated")
+	}
+	return t.writeRevision, nil
+}
+
+func (t *logTreeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stree.NodeID) (*storagepb.SubtreeProto, error) {
+	s, err := t.getSubtrees(ctx, treeRevision, []stree.NodeID{nodeID})
+	if err != nil {
+		return nil, err
+	}
+	switch len(s) {
+	case 0:
+		return nil, nil
+	case 1:
+		return s[0], nil
+	default:
+		return nil, fmt.Errorf("got %d subtrees, but expected 1", len(s))
+	}
+}
+
+// getSubtrees returns the latest revisions at or below treeRevision of the
+// requested subtrees which exist.
+func (t *logTreeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+	c := t.cursor()
+	if c == nil || len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	ret := make([]*storagepb.SubtreeProto, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		id, err := subtreeID(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		// Find the last key at or before the one for the requested revision.
+		want := subtreeKey(id, treeRevision)
+		k, v := c.Seek(want)
+		if k == nil {
+			k, v = c.Last()
+		} else if !bytes.Equal(k, want) {
+			k, v = c.Prev()
+		}
+		if k == nil || !bytes.HasPrefix(k, subtreeKeyPrefix(id)) {
+			continue
+		}
+
+		var subtree storagepb.SubtreeProto
+		if err := proto.Unmarshal(v, &subtree); err != nil {
+			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
+			return nil, err
+		}
+		if subtree.Prefix == nil {
+			subtree.Prefix = []byte{}
+		}
+		ret = append(ret, &subtree)
+	}
+
+	// The InternalNodes cache is possibly nil here, but the SubtreeCache (which called
+	// this method) will re-populate it.
+	return ret, nil
+}
+
+// getSubtreesAtRev returns a GetSubtreesFunc which reads at the passed in rev.
+func (t *logTreeTX) getSubtreesAtRev(ctx context.Context, rev int64) cache.GetSubtreesFunc {
+	return func(ids []stree.NodeID) ([]*storagepb.SubtreeProto, error) {
+		return t.getSubtrees(ctx, rev, ids)
+	}
+}
+
+// GetMerkleNodes returns the requested nodes at the read revision.
+func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]stree.Node, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rev := int64(t.root.Revision)
+	return t.subtreeCache.GetNodes(ids, t.getSubtreesAtRev(ctx, rev))
+}
+
+func (t *logTreeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, n := range nodes {
+		err := t.subtreeCache.SetNodeHash(n.ID, n.Hash,
+			func(nID stree.NodeID) (*storagepb.SubtreeProto, error) {
+				return t.getSubtree(ctx, t.writeRevision, nID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		return t.getLeavesByRangeInternal(int64(t.root.TreeSize), int64(limit))
+	}
+
+	var leaves []*trillian.LogLeaf
+	var err error
+	offsets := make(map[*trillian.LogLeaf]int64)
+	if t.order == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		// The queue is in queue order, so each submitter's queue will be too.
+		queues := make(map[string][]*trillian.LogLeaf)
+		err = t.scanQueue(ctx, cutoffTime, func(offset int64, leaf *trillian.LogLeaf, submitter string) bool {
+			if len(queues[submitter]) < limit {
+				queues[submitter] = append(queues[submitter], leaf)
+				offsets[leaf] = offset
+			} else if offset < t.held {
+				t.held = offset
+			}
+			return true
+		})
+		leaves = storage.FairShare(queues, t.ls.submitterWeights.ForTree(t.treeID), limit)
+		chosen := make(map[*trillian.LogLeaf]bool, len(leaves))
+		for _, leaf := range leaves {
+			chosen[leaf] = true
+		}
+		for leaf, offset := range offsets {
+			if !chosen[leaf] && offset < t.held {
+				t.held = offset
+			}
+		}
+	} else {
+		leaves = make([]*trillian.LogLeaf, 0, limit)
+		err = t.scanQueue(ctx, cutoffTime, func(offset int64, leaf *trillian.LogLeaf, _ string) bool {
+			leaves = append(leaves, leaf)
+			offsets[leaf] = offset
+			return len(leaves) < limit
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leaf := range leaves {
+		t.dequeued[string(leaf.LeafIdentityHash)] = dequeuedLeaf{offset: offsets[leaf], leaf: leaf}
+	}
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+// scanQueue calls f with the leaves of the leaves topic from nextQueue on,
+// in queue order, until f returns false or a leaf queued after cutoffTime is
+// reached. Leaves which are already in the log, or have been dequeued by this
+// transaction, are skipped. nextQueue is advanced past the leaves passed to f.
+func (t *logTreeTX) scanQueue(ctx context.Context, cutoffTime time.Time, f func(offset int64, leaf *trillian.LogLeaf, submitter string) bool) error {
+	cutoff := cutoffTime.UnixNano()
+	for {
+		entries, err := t.ls.log.Read(ctx, leavesTopic(t.treeID), t.nextQueue, dequeueBatch)
+		if err != nil {
+			return fmt.Errorf("failed to read queued leaves: %v", err)
+		}
+		for _, e := range entries {
+			var q queuedLeaf
+			if err := json.Unmarshal(e, &q); err != nil {
+				return fmt.Errorf("failed to read queued leaf %d: %v", t.nextQueue, err)
+			}
+			var leaf trillian.LogLeaf
+			if err := proto.Unmarshal(q.Leaf, &leaf); err != nil {
+				return fmt.Errorf("failed to read queued leaf %d: %v", t.nextQueue, err)
+			}
+			queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+			if err != nil {
+				return fmt.Errorf("got invalid queue timestamp: %v", err)
+			}
+			if queueTimestamp.UnixNano() > cutoff {
+				return nil
+			}
+			offset := t.nextQueue
+			t.nextQueue++
+			if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+				return errors.New("dequeued a leaf with incorrect hash size")
+			}
+			if _, ok := t.dequeued[string(leaf.LeafIdentityHash)]; ok || t.hasLeafID(leaf.LeafIdentityHash) {
+				continue
+			}
+			if !f(offset, &leaf, q.Submitter) {
+				return nil
+			}
+		}
+		if len(entries) < dequeueBatch {
+			return nil
+		}
+	}
+}
+
+// queueLeaves appends the leaves which are not in the log yet to its leaves
+// topic, and returns the existing leaves for the others.
+func (t *logTreeTX) queueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	submitter := storage.SubmitterFromContext(ctx)
+
+	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+	var entries [][]byte
+	for i, leaf := range leaves {
+		existing, err := t.getSequencedByID(leaf.LeafIdentityHash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existingLeaves[i] = existing
+			continue
+		}
+
+		v, err := proto.Marshal(&trillian.LogLeaf{
+			LeafIdentityHash: leaf.LeafIdentityHash,
+			MerkleLeafHash:   leaf.MerkleLeafHash,
+			LeafValue:        leaf.LeafValue,
+			ExtraData:        leaf.ExtraData,
+			QueueTimestamp:   leaf.QueueTimestamp,
+		})
+		if err != nil {
+			return nil, err
+		}
+		e, err := json.Marshal(queuedLeaf{Leaf: v, Submitter: submitter})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) > 0 {
+		if _, err := t.ls.log.Append(ctx, leavesTopic(t.treeID), entries...); err != nil {
+			glog.Warningf("Error queueing leaves: %s", err)
+			return nil, err
+		}
+	}
+	queuedCounter.Add(float64(len(entries)), labelForTX(t))
+	return existingLeaves, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	queueTimestamp, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	integrateTimestamp, err := ptypes.TimestampProto(time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+		// TODO(pavelkalinnikov): Support opting out from duplicates detection.
+		if t.hasLeafID(leaf.LeafIdentityHash) {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+			continue
+		}
+		if t.hasLeafIndex(leaf.LeafIndex) {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+			continue
+		}
+
+		// TODO(pavelkalinnikov): Update IntegrateTimestamp on integrating the leaf.
+		t.addSequenced(&trillian.LogLeaf{
+			LeafIdentityHash:   leaf.LeafIdentityHash,
+			MerkleLeafHash:     leaf.MerkleLeafHash,
+			LeafValue:          leaf.LeafValue,
+			ExtraData:          leaf.ExtraData,
+			LeafIndex:          leaf.LeafIndex,
+			QueueTimestamp:     queueTimestamp,
+			IntegrateTimestamp: integrateTimestamp,
+		})
+	}
+	return res, nil
+}
+
+// getSequenced reads the leaf at the given index, or returns nil if there is
+// none.
+func (t *logTreeTX) getSequenced(seq int64) (*trillian.LogLeaf, error) {
+	v := t.get(seqLeafKey(seq))
+	if v == nil {
+		return nil, nil
+	}
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(v, &leaf); err != nil {
+		return nil, fmt.Errorf("failed to read sequenced leaf: %v", err)
+	}
+	return &leaf, nil
+}
+
+// getSequencedByID reads the leaf with the given identity hash, or returns
+// nil if there is none.
+func (t *logTreeTX) getSequencedByID(leafIDHash []byte) (*trillian.LogLeaf, error) {
+	v := t.get(key(idToSeqPrefix, leafIDHash))
+	if v == nil {
+		return nil, nil
+	}
+	leaf, err := t.getSequenced(int64(binary.BigEndian.Uint64(v)))
+	if err != nil {
+		return nil, err
+	}
+	if leaf == nil {
+		return nil, fmt.Errorf("missing leaf with identity hash %x", leafIDHash)
+	}
+	return leaf, nil
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.cursor()
+	if c == nil {
+		return 0, nil
+	}
+	k, _ := lastWithPrefix(c, seqLeafPrefix)
+	if k == nil {
+		return 0, nil
+	}
+	return int64(binary.BigEndian.Uint64(k[len(seqLeafPrefix):])) + 1, nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, seq := range leaves {
+		leaf, err := t.getSequenced(seq)
+		if err != nil {
+			return nil, err
+		}
+		if leaf != nil {
+			ret = append(ret, leaf)
+		}
+	}
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.getLeavesByRangeInternal(start, count)
+}
+
+func (t *logTreeTX) getLeavesByRangeInternal(start, count int64) ([]*trillian.LogLeaf, error) {
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, count)
+	c := t.cursor()
+	if c == nil {
+		return ret, nil
+	}
+	end := seqLeafKey(start + count)
+	wantIndex := start
+	for k, v := c.Seek(seqLeafKey(start)); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+		var leaf trillian.LogLeaf
+		if err := proto.Unmarshal(v, &leaf); err != nil {
+			return nil, fmt.Errorf("failed to read sequenced leaf: %v", err)
+		}
+		if leaf.LeafIndex != wantIndex {
+			if wantIndex < int64(t.root.TreeSize) {
+				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
+			}
+			break
+		}
+		ret = append(ret, &leaf)
+		wantIndex++
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ret []*trillian.LogLeaf
+	c := t.cursor()
+	if c == nil {
+		return ret, nil
+	}
+	for _, hash := range leafHashes {
+		prefix := key(hashToSeqPrefix, hash)
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if len(k) != len(prefix)+8 {
+				continue
+			}
+			seq := int64(binary.BigEndian.Uint64(k[len(prefix):]))
+			leaf, err := t.getSequenced(seq)
+			if err != nil {
+				return nil, err
+			}
+			if leaf == nil {
+				return nil, fmt.Errorf("missing leaf %d with hash %x", seq, hash)
+			}
+			ret = append(ret, leaf)
+		}
+	}
+	if orderBySequence {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].LeafIndex < ret[j].LeafIndex })
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+
+	return t.slr, nil
+}
+
+// fetchLatestRoot reads the latest SignedLogRoot from the local database.
+func (t *logTreeTX) fetchLatestRoot() (*trillian.SignedLogRoot, error) {
+	c := t.cursor()
+	if c == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+	k, v := lastWithPrefix(c, sthPrefix)
+	if k == nil {
+		// It's possible there are no roots for this tree yet
+		return nil, storage.ErrTreeNeedsInit
+	}
+	var slr trillian.SignedLogRoot
+	if err := proto.Unmarshal(v, &slr); err != nil {
+		return nil, fmt.Errorf("failed to read signed log root: %v", err)
+	}
+	return &slr, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+	if got, want := int64(logRoot.Revision), t.writeRevision; got != want {
+		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
+	}
+	if t.newRoot != nil || t.get(sthKey(logRoot.TimestampNanos)) != nil {
+		return status.Errorf(codes.AlreadyExists, "root with timestamp %d already exists", logRoot.TimestampNanos)
+	}
+	t.newRoot = root
+	return nil
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return errors.New("sequenced leaf has incorrect hash size")
+		}
+		if t.hasLeafIndex(leaf.LeafIndex) {
+			return fmt.Errorf("leaf index %d is already sequenced", leaf.LeafIndex)
+		}
+		d, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to update leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		delete(t.dequeued, string(leaf.LeafIdentityHash))
+		t.addSequenced(&trillian.LogLeaf{
+			LeafIdentityHash:   leaf.LeafIdentityHash,
+			MerkleLeafHash:     leaf.MerkleLeafHash,
+			LeafValue:          d.leaf.LeafValue,
+			ExtraData:          d.leaf.ExtraData,
+			LeafIndex:          leaf.LeafIndex,
+			QueueTimestamp:     d.leaf.QueueTimestamp,
+			IntegrateTimestamp: leaf.IntegrateTimestamp,
+		})
+	}
+	return nil
+}
+
+func (t *logTreeTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ls.trees.getActiveLogIDs(ctx)
+}
+
+// queueOffset returns the offset in the leaves topic from which the next
+// transaction should dequeue: the first leaf which this transaction read but
+// did not sequence, if any.
+func (t *logTreeTX) queueOffset() int64 {
+	offset := t.nextQueue
+	if t.held < offset {
+		offset = t.held
+	}
+	for _, d := range t.dequeued {
+		if d.offset < offset {
+			offset = d.offset
+		}
+	}
+	return offset
+}
+
+// commitEntry returns the entry recording the changes made by the
+// transaction, or nil if it made none.
+func (t *logTreeTX) commitEntry(ctx context.Context) ([]byte, error) {
+	entry := commitEntry{
+		Offset:      t.state.commitOffset,
+		QueueOffset: t.queueOffset(),
+		Revision:    t.writeRevision,
+	}
+	for _, leaf := range t.sequenced {
+		v, err := proto.Marshal(leaf)
+		if err != nil {
+			return nil, err
+		}
+		entry.Leaves = append(entry.Leaves, v)
+	}
+	if err := t.subtreeCache.Flush(ctx, func(ctx context.Context, st []*storagepb.SubtreeProto) error {
+		for _, s := range st {
+			if s.Prefix == nil {
+				panic(fmt.Errorf("nil prefix on %v", s))
+			}
+			v, err := proto.Marshal(s)
+			if err != nil {
+				return err
+			}
+			entry.Subtrees = append(entry.Subtrees, v)
+		}
+		return nil
+	}); err != nil {
+		glog.Warningf("TX commit flush error: %v", err)
+		return nil, err
+	}
+	if t.newRoot != nil {
+		v, err := proto.Marshal(t.newRoot)
+		if err != nil {
+			return nil, err
+		}
+		entry.Root = v
+	}
+	if len(entry.Leaves) == 0 && len(entry.Subtrees) == 0 && entry.Root == nil && entry.QueueOffset == t.state.queueOffset {
+		return nil, nil
+	}
+	return json.Marshal(entry)
+}
+
+func (t *logTreeTX) Commit(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.writable {
+		return t.rollbackInternal()
+	}
+	// Keep the writer lock until the entry has been applied, so that the
+	// next transaction of this storage sees it.
+	defer t.rollbackInternal() // nolint: errcheck
+
+	e, err := t.commitEntry(ctx)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return nil
+	}
+	// The snapshot of the local database must be released before catching
+	// up, which writes to it.
+	if err := t.tx.Rollback(); err != nil {
+		return err
+	}
+	offset, err := t.ls.log.Append(ctx, commitsTopic(t.treeID), e)
+	if err != nil {
+		glog.Warningf("TX commit error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	if err := catchUp(ctx, t.ls.log, t.ls.db, t.treeID); err != nil {
+		// The entry is in the commit log, so the transaction has committed;
+		// the next one will apply it.
+		glog.Warningf("Failed to apply commit of log %d: %v", t.treeID, err)
+	}
+	if offset != t.state.commitOffset {
+		abortedCommitCounter.Inc(labelForTX(t))
+		return status.Errorf(codes.Aborted, "log %d was updated concurrently: commit appended at offset %d, want %d", t.treeID, offset, t.state.commitOffset)
+	}
+	return nil
+}
+
+func (t *logTreeTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rollbackInternal()
+}
+
+func (t *logTreeTX) rollbackInternal() error {
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	defer t.unlock()
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, bbolt.ErrTxClosed) {
+		glog.Warningf("TX rollback error: %s, stack:\n%s", err, string(debug.Stack()))
+		return err
+	}
+	return nil
+}
+
+func (t *logTreeTX) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.closed {
+		err := t.rollbackInternal()
+		if err != nil {
+			glog.Warningf("Rollback error on Close(): %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *logTreeTX) IsOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return !t.closed
+}
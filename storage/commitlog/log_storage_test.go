@@ -0,0 +1,177 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"go.etcd.io/bbolt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/google/trillian/merkle/rfc6962" // Register the hasher.
+)
+
+// openTestDB opens a new local database in a temporary directory, which is
+// closed and removed at the end of the test.
+func openTestDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	db, err := OpenLocalDB(filepath.Join(t.TempDir(), "local.bolt"), nil)
+	if err != nil {
+		t.Fatalf("OpenLocalDB(): %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestLogSuite(t *testing.T) {
+	storageFactory := func(_ context.Context, t *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		l := newTestLog(t, 1<<20)
+		return NewLogStorage(l, openTestDB(t), nil), NewAdminStorage(l)
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
+
+// storeRoot stores a root of the given size at the write revision of a new
+// transaction, and returns the error of the transaction.
+func storeRoot(ctx context.Context, s storage.LogStorage, tree *trillian.Tree, size uint64, leaves ...*trillian.LogLeaf) error {
+	return s.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if len(leaves) > 0 {
+			dequeued, err := tx.DequeueLeaves(ctx, len(leaves), time.Now())
+			if err != nil {
+				return err
+			}
+			for i, leaf := range dequeued {
+				leaf.LeafIndex = int64(size) - int64(len(dequeued)) + int64(i)
+			}
+			if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+				return err
+			}
+		}
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		root, err := (&types.LogRootV1{
+			TreeSize:       size,
+			RootHash:       make([]byte, 32),
+			TimestampNanos: uint64(rev) + 1,
+			Revision:       uint64(rev),
+		}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	})
+}
+
+func TestReplicasFollowLog(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(t, 1<<10)
+	as := NewAdminStorage(l)
+	primary := NewLogStorage(l, openTestDB(t), nil)
+	replica := NewLogStorage(l, openTestDB(t), nil)
+
+	tree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if err := storeRoot(ctx, primary, tree, 0); err != nil {
+		t.Fatalf("storeRoot(0): %v", err)
+	}
+	leaves := make([]*trillian.LogLeaf, 3)
+	for i := range leaves {
+		h := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+		leaves[i] = &trillian.LogLeaf{LeafIdentityHash: h[:], MerkleLeafHash: h[:], LeafValue: []byte{byte(i)}}
+	}
+	// Leaves queued through either storage go to the same queue.
+	if _, err := replica.QueueLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if err := storeRoot(ctx, primary, tree, 3, leaves...); err != nil {
+		t.Fatalf("storeRoot(3): %v", err)
+	}
+
+	// The replica applies the commit made by the primary.
+	tx, err := replica.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	got, err := tx.GetLeavesByRange(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	tx.Close()
+	if len(got) != 3 {
+		t.Fatalf("GetLeavesByRange(): got %d leaves, want 3", len(got))
+	}
+	for i, leaf := range got {
+		if leaf.LeafValue[0] != byte(i) {
+			t.Errorf("leaf %d has value %x, want %x", i, leaf.LeafValue, []byte{byte(i)})
+		}
+	}
+	if dup, err := replica.QueueLeaves(ctx, tree, leaves[:1], time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(dup): %v", err)
+	} else if got, want := status.FromProto(dup[0].Status).Code(), codes.AlreadyExists; got != want {
+		t.Errorf("QueueLeaves(dup): status %v, want %v", got, want)
+	}
+
+	// A writer which started before another one committed is aborted, and
+	// its entry is ignored by every replica.
+	err = replica.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := storeRoot(ctx, primary, tree, 3); err != nil {
+			t.Fatalf("storeRoot() on primary: %v", err)
+		}
+		root, err := (&types.LogRootV1{TreeSize: 3, RootHash: make([]byte, 32), TimestampNanos: 100, Revision: 2}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	})
+	if got, want := status.Code(err), codes.Aborted; got != want {
+		t.Fatalf("ReadWriteTransaction() racing another writer: %v, want code %v", err, want)
+	}
+	for _, s := range []storage.LogStorage{primary, replica} {
+		tx, err := s.SnapshotForTree(ctx, tree)
+		if err != nil {
+			t.Fatalf("SnapshotForTree(): %v", err)
+		}
+		rev, err := tx.ReadRevision(ctx)
+		tx.Close()
+		if err != nil {
+			t.Fatalf("ReadRevision(): %v", err)
+		}
+		if rev != 2 {
+			t.Errorf("ReadRevision(): %d, want 2", rev)
+		}
+	}
+
+	// A new local database is rebuilt from the log.
+	rebuilt := NewLogStorage(l, openTestDB(t), nil)
+	if err := storeRoot(ctx, rebuilt, tree, 3); err != nil {
+		t.Fatalf("storeRoot() on rebuilt replica: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commitlog
+
+import (
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	commitLogDir          = flag.String("commitlog_dir", "trillian-commitlog", "Directory of the commit log, which may be shared by several signers")
+	commitLogSegmentBytes = flag.Int64("commitlog_segment_bytes", 64<<20, "Size in bytes at which the segment files of the commit log are rolled over")
+	commitLogLocalFile    = flag.String("commitlog_local_file", "trillian-commitlog-local.bolt", "Path of the local Bolt database materialised from the commit log, which must not be shared")
+	commitLogInitialMmap  = flag.Int("commitlog_initial_mmap_mib", 1024, "Initial size of the memory mapping of the local database file in MiB; should exceed the expected size of the file")
+
+	commitLogOnce            sync.Once
+	commitLogOnceErr         error
+	commitLogStorageInstance *commitLogProvider
+)
+
+func init() {
+	if err := storage.RegisterProviderWithOptions("commitlog", newCommitLogProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider commitlog: %v", err)
+	}
+}
+
+type commitLogProvider struct {
+	log     Log
+	db      *bbolt.DB
+	mf      monitoring.MetricFactory
+	weights storage.SubmitterWeights
+}
+
+func newCommitLogProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
+	commitLogOnce.Do(func() {
+		var l *FileLog
+		l, commitLogOnceErr = NewFileLog(*commitLogDir, *commitLogSegmentBytes)
+		if commitLogOnceErr != nil {
+			return
+		}
+		var db *bbolt.DB
+		db, commitLogOnceErr = OpenLocalDB(*commitLogLocalFile, &bbolt.Options{
+			Timeout:         time.Second,
+			InitialMmapSize: *commitLogInitialMmap << 20,
+		})
+		if commitLogOnceErr != nil {
+			l.Close()
+			return
+		}
+
+		commitLogStorageInstance = &commitLogProvider{
+			log:     l,
+			db:      db,
+			mf:      mf,
+			weights: opts.SubmitterWeights,
+		}
+	})
+	if commitLogOnceErr != nil {
+		return nil, commitLogOnceErr
+	}
+	return commitLogStorageInstance, nil
+}
+
+func (s *commitLogProvider) LogStorage() storage.LogStorage {
+	return newLogStorage(s.log, s.db, s.mf, s.weights)
+}
+
+func (s *commitLogProvider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(s.log)
+}
+
+func (s *commitLogProvider) Close() error {
+	if err := s.db.Close(); err != nil {
+		s.log.Close()
+		return err
+	}
+	return s.log.Close()
+}
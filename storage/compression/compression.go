@@ -0,0 +1,238 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression implements the compression of leaf data in storage,
+// according to the LeafCompression settings of trees.
+//
+// Storage records the trillian.LeafCompression format of each stored leaf
+// alongside its data, so that leaves stored with different settings (or before
+// compression was enabled) stay readable. Compressed leaf data is
+// self-describing beyond the format: zstd frames record the ID of the
+// dictionary they were compressed with, which must be loaded in order to
+// decompress them.
+package compression
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var dictionaryFiles = flag.String("leaf_compression_dictionaries", "", "Comma-separated list of zstd dictionary files to load for compressing and decompressing leaf data")
+
+var (
+	loadOnce sync.Once
+	loadErr  error
+
+	mu sync.Mutex
+	// dicts holds the registered zstd dictionaries, by ID.
+	dicts = make(map[uint32][]byte)
+	// encoders holds zstd encoders, by dictionary ID. Zero is no dictionary.
+	encoders = make(map[uint32]*zstd.Encoder)
+	// decoder decompresses zstd frames using any of the registered
+	// dictionaries. It is reset whenever a dictionary is registered.
+	decoder *zstd.Decoder
+)
+
+// RegisterDictionary makes the given zstd dictionary available for use by
+// trees which refer to its ID. Dictionaries are normally loaded from the files
+// given by the --leaf_compression_dictionaries flag.
+func RegisterDictionary(dict []byte) error {
+	// See https://github.com/facebook/zstd/blob/dev/doc/zstd_compression_format.md#dictionary-format
+	if len(dict) < 8 || string(dict[:4]) != "\x37\xa4\x30\xec" {
+		return fmt.Errorf("not a zstd dictionary")
+	}
+	id := uint32(dict[4]) | uint32(dict[5])<<8 | uint32(dict[6])<<16 | uint32(dict[7])<<24
+	if id == 0 {
+		return fmt.Errorf("zstd dictionaries can't have ID 0")
+	}
+	// Check that the dictionary is usable before registering it.
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDict(dict))
+	if err != nil {
+		return fmt.Errorf("invalid zstd dictionary %d: %v", id, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := dicts[id]; ok {
+		return fmt.Errorf("zstd dictionary %d already registered", id)
+	}
+	dicts[id] = dict
+	encoders[id] = enc
+	// The current decoder may still be in use, so leave it to the GC rather
+	// than closing it.
+	decoder = nil
+	return nil
+}
+
+// loadDictionaries registers the dictionaries given by the
+// --leaf_compression_dictionaries flag, the first time it is called.
+func loadDictionaries() error {
+	loadOnce.Do(func() {
+		if *dictionaryFiles == "" {
+			return
+		}
+		for _, path := range strings.Split(*dictionaryFiles, ",") {
+			dict, err := ioutil.ReadFile(path)
+			if err != nil {
+				loadErr = fmt.Errorf("failed to read zstd dictionary: %v", err)
+				return
+			}
+			if err := RegisterDictionary(dict); err != nil {
+				loadErr = fmt.Errorf("%s: %v", path, err)
+				return
+			}
+		}
+	})
+	return loadErr
+}
+
+// Codec compresses leaf data according to the settings of a tree.
+type Codec struct {
+	compression trillian.LeafCompression
+	enc         *zstd.Encoder
+}
+
+// NewCodec returns a Codec for the LeafCompression settings of the given tree.
+// It fails if the tree refers to a dictionary which isn't registered.
+func NewCodec(tree *trillian.Tree) (*Codec, error) {
+	if err := loadDictionaries(); err != nil {
+		return nil, err
+	}
+	switch tree.LeafCompression {
+	case trillian.LeafCompression_NO_LEAF_COMPRESSION:
+		return &Codec{compression: tree.LeafCompression}, nil
+	case trillian.LeafCompression_ZSTD_LEAF_COMPRESSION:
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "unsupported leaf_compression: %v", tree.LeafCompression)
+	}
+
+	id := tree.LeafCompressionDictionaryId
+	mu.Lock()
+	defer mu.Unlock()
+	enc, ok := encoders[id]
+	if !ok {
+		if id != 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "zstd dictionary %d not loaded", id)
+		}
+		var err error
+		if enc, err = zstd.NewWriter(nil); err != nil {
+			return nil, err
+		}
+		encoders[id] = enc
+	}
+	return &Codec{compression: tree.LeafCompression, enc: enc}, nil
+}
+
+// Encode returns the stored form of the given leaf value and extra data, and
+// the format they are stored in. Leaf data which doesn't get any smaller is
+// stored as is, and nil slices stay nil.
+func (c *Codec) Encode(value, extra []byte) ([]byte, []byte, trillian.LeafCompression) {
+	if c.enc == nil {
+		return value, extra, trillian.LeafCompression_NO_LEAF_COMPRESSION
+	}
+	cValue, cExtra := c.compress(value), c.compress(extra)
+	if len(cValue)+len(cExtra) >= len(value)+len(extra) {
+		return value, extra, trillian.LeafCompression_NO_LEAF_COMPRESSION
+	}
+	return cValue, cExtra, c.compression
+}
+
+func (c *Codec) compress(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+	return c.enc.EncodeAll(data, nil)
+}
+
+// Decode returns the original leaf value and extra data from their stored
+// form in the given format.
+func Decode(format trillian.LeafCompression, value, extra []byte) ([]byte, []byte, error) {
+	switch format {
+	case trillian.LeafCompression_NO_LEAF_COMPRESSION:
+		return value, extra, nil
+	case trillian.LeafCompression_ZSTD_LEAF_COMPRESSION:
+	default:
+		return nil, nil, fmt.Errorf("unknown leaf compression format %d", format)
+	}
+	if err := loadDictionaries(); err != nil {
+		return nil, nil, err
+	}
+	dec, err := zstdDecoder()
+	if err != nil {
+		return nil, nil, err
+	}
+	if value, err = decompress(dec, value); err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress leaf value: %v", err)
+	}
+	if extra, err = decompress(dec, extra); err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress extra data: %v", err)
+	}
+	return value, extra, nil
+}
+
+// Recode returns the form in which the given stored leaf data would be stored
+// with the settings of the Codec, and whether it differs from the stored form.
+func (c *Codec) Recode(format trillian.LeafCompression, value, extra []byte) ([]byte, []byte, trillian.LeafCompression, bool, error) {
+	origValue, origExtra, err := Decode(format, value, extra)
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	newValue, newExtra, newFormat := c.Encode(origValue, origExtra)
+	changed := newFormat != format || !bytes.Equal(newValue, value) || !bytes.Equal(newExtra, extra)
+	return newValue, newExtra, newFormat, changed, nil
+}
+
+// DecodeLeaf replaces the LeafValue and ExtraData of the given leaf, which
+// are stored in the given format, with their original form.
+func DecodeLeaf(format trillian.LeafCompression, leaf *trillian.LogLeaf) error {
+	value, extra, err := Decode(format, leaf.LeafValue, leaf.ExtraData)
+	if err != nil {
+		return err
+	}
+	leaf.LeafValue, leaf.ExtraData = value, extra
+	return nil
+}
+
+func decompress(dec *zstd.Decoder, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	return dec.DecodeAll(data, nil)
+}
+
+// zstdDecoder returns a decoder which knows of all registered dictionaries.
+func zstdDecoder() (*zstd.Decoder, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if decoder == nil {
+		all := make([][]byte, 0, len(dicts))
+		for _, d := range dicts {
+			all = append(all, d)
+		}
+		var err error
+		if decoder, err = zstd.NewReader(nil, zstd.WithDecoderDicts(all...)); err != nil {
+			return nil, err
+		}
+	}
+	return decoder, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/google/trillian"
+)
+
+const testDictionaryID = 42
+
+var registerOnce sync.Once
+
+// registerTestDictionary registers testdata/leaves.dict, which was trained
+// with zstd on JSON leaves similar to the ones returned by testLeaf.
+func registerTestDictionary(t *testing.T) {
+	t.Helper()
+	registerOnce.Do(func() {
+		dict, err := ioutil.ReadFile("testdata/leaves.dict")
+		if err != nil {
+			t.Fatalf("ReadFile(): %v", err)
+		}
+		if err := RegisterDictionary(dict); err != nil {
+			t.Fatalf("RegisterDictionary(): %v", err)
+		}
+	})
+}
+
+func testLeaf(i int) []byte {
+	return []byte(fmt.Sprintf(`{"subject":"CN=host%d.example.com,O=Example Org,C=US","issuer":"CN=Example Intermediate CA %d,O=Example Org,C=US","serial":"%x","not_before":"2021-03-01T00:00:00Z","sans":["host%d.example.com","www.host%d.example.com"]}`, i, i%3, i*7919, i, i))
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	registerTestDictionary(t)
+	long := bytes.Repeat(testLeaf(1), 10)
+	for _, tc := range []struct {
+		desc       string
+		tree       *trillian.Tree
+		value      []byte
+		extra      []byte
+		wantFormat trillian.LeafCompression
+	}{
+		{
+			desc:       "no-compression",
+			tree:       &trillian.Tree{},
+			value:      long,
+			extra:      long,
+			wantFormat: trillian.LeafCompression_NO_LEAF_COMPRESSION,
+		},
+		{
+			desc:       "zstd",
+			tree:       &trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION},
+			value:      long,
+			extra:      []byte("extra"),
+			wantFormat: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION,
+		},
+		{
+			desc:       "zstd-nil-extra",
+			tree:       &trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION},
+			value:      long,
+			wantFormat: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION,
+		},
+		{
+			desc:       "zstd-incompressible",
+			tree:       &trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION},
+			value:      []byte("short"),
+			extra:      []byte("data"),
+			wantFormat: trillian.LeafCompression_NO_LEAF_COMPRESSION,
+		},
+		{
+			desc: "zstd-dictionary",
+			tree: &trillian.Tree{
+				LeafCompression:             trillian.LeafCompression_ZSTD_LEAF_COMPRESSION,
+				LeafCompressionDictionaryId: testDictionaryID,
+			},
+			value:      testLeaf(500),
+			wantFormat: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := NewCodec(tc.tree)
+			if err != nil {
+				t.Fatalf("NewCodec(): %v", err)
+			}
+			value, extra, format := c.Encode(tc.value, tc.extra)
+			if format != tc.wantFormat {
+				t.Errorf("Encode(): format %v, want %v", format, tc.wantFormat)
+			}
+			if format == trillian.LeafCompression_ZSTD_LEAF_COMPRESSION && len(value)+len(extra) >= len(tc.value)+len(tc.extra) {
+				t.Errorf("Encode(): %d bytes, want < %d", len(value)+len(extra), len(tc.value)+len(tc.extra))
+			}
+			if tc.extra == nil && extra != nil {
+				t.Errorf("Encode(): extra data %x, want nil", extra)
+			}
+
+			gotValue, gotExtra, err := Decode(format, value, extra)
+			if err != nil {
+				t.Fatalf("Decode(): %v", err)
+			}
+			if !bytes.Equal(gotValue, tc.value) || !bytes.Equal(gotExtra, tc.extra) {
+				t.Errorf("Decode(): got (%q, %q), want (%q, %q)", gotValue, gotExtra, tc.value, tc.extra)
+			}
+		})
+	}
+}
+
+func TestDictionaryImprovesCompression(t *testing.T) {
+	registerTestDictionary(t)
+	plain, err := NewCodec(&trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION})
+	if err != nil {
+		t.Fatalf("NewCodec(): %v", err)
+	}
+	dict, err := NewCodec(&trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, LeafCompressionDictionaryId: testDictionaryID})
+	if err != nil {
+		t.Fatalf("NewCodec(): %v", err)
+	}
+	leaf := testLeaf(1000)
+	withoutDict, _, _ := plain.Encode(leaf, nil)
+	withDict, _, _ := dict.Encode(leaf, nil)
+	if len(withDict) >= len(withoutDict) {
+		t.Errorf("Encode() with dictionary: %d bytes, want < %d", len(withDict), len(withoutDict))
+	}
+}
+
+func TestNewCodecErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		tree *trillian.Tree
+	}{
+		{desc: "unknown-compression", tree: &trillian.Tree{LeafCompression: 100}},
+		{desc: "unknown-dictionary", tree: &trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, LeafCompressionDictionaryId: 7}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := NewCodec(tc.tree); err == nil {
+				t.Error("NewCodec(): got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, _, err := Decode(100, []byte("value"), nil); err == nil {
+		t.Error("Decode(unknown format): got nil error, want error")
+	}
+	if _, _, err := Decode(trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, []byte("not zstd"), nil); err == nil {
+		t.Error("Decode(corrupt data): got nil error, want error")
+	}
+}
+
+func TestRecode(t *testing.T) {
+	zstdCodec, err := NewCodec(&trillian.Tree{LeafCompression: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION})
+	if err != nil {
+		t.Fatalf("NewCodec(): %v", err)
+	}
+	noneCodec, err := NewCodec(&trillian.Tree{})
+	if err != nil {
+		t.Fatalf("NewCodec(): %v", err)
+	}
+	leaf := bytes.Repeat(testLeaf(1), 10)
+	cLeaf, _, _ := zstdCodec.Encode(leaf, nil)
+
+	for _, tc := range []struct {
+		desc        string
+		codec       *Codec
+		format      trillian.LeafCompression
+		value       []byte
+		wantFormat  trillian.LeafCompression
+		wantChanged bool
+	}{
+		{desc: "compress", codec: zstdCodec, value: leaf, wantFormat: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, wantChanged: true},
+		{desc: "compressed", codec: zstdCodec, format: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, value: cLeaf, wantFormat: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION},
+		{desc: "decompress", codec: noneCodec, format: trillian.LeafCompression_ZSTD_LEAF_COMPRESSION, value: cLeaf, wantChanged: true},
+		{desc: "uncompressed", codec: noneCodec, value: leaf},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			value, extra, format, changed, err := tc.codec.Recode(tc.format, tc.value, nil)
+			if err != nil {
+				t.Fatalf("Recode(): %v", err)
+			}
+			if format != tc.wantFormat || changed != tc.wantChanged {
+				t.Errorf("Recode(): format %v, changed %v, want %v, %v", format, changed, tc.wantFormat, tc.wantChanged)
+			}
+			if got, _, err := Decode(format, value, extra); err != nil {
+				t.Errorf("Decode(): %v", err)
+			} else if !bytes.Equal(got, leaf) {
+				t.Errorf("Decode(): got %q, want %q", got, leaf)
+			}
+		})
+	}
+}
+
+func TestRegisterDictionaryErrors(t *testing.T) {
+	registerTestDictionary(t)
+	dict, err := ioutil.ReadFile("testdata/leaves.dict")
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc string
+		dict []byte
+	}{
+		{desc: "not-a-dictionary", dict: []byte("some data which isn't a dictionary")},
+		{desc: "zero-id", dict: append([]byte("\x37\xa4\x30\xec\x00\x00\x00\x00"), dict[8:]...)},
+		{desc: "duplicate", dict: dict},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if err := RegisterDictionary(tc.dict); err == nil {
+				t.Error("RegisterDictionary(): got nil error, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/trillian"
+)
+
+// MaxSubmitterLength is the maximum length of a submitter identity stored
+// alongside queued leaves. Longer identities are truncated.
+const MaxSubmitterLength = 255
+
+// SubmitterWeights returns the relative weights of the submitters of leaves to
+// the given tree, used by FAIR_SHARE_ORDER dequeueing. Submitters which are not
+// listed have weight 1.
+type SubmitterWeights func(treeID int64) map[string]int
+
+// ForTree returns the weights of the submitters to the given tree, which are
+// all 1 if w is nil.
+func (w SubmitterWeights) ForTree(treeID int64) map[string]int {
+	if w == nil {
+		return nil
+	}
+	return w(treeID)
+}
+
+type submitterKey struct{}
+
+// NewSubmitterContext returns a context carrying the identity of the submitter
+// of leaves passed to LogStorage.QueueLeaves. Storage implementations which
+// support FAIR_SHARE_ORDER record it alongside the queued leaves.
+func NewSubmitterContext(ctx context.Context, submitter string) context.Context {
+	return context.WithValue(ctx, submitterKey{}, submitter)
+}
+
+// SubmitterFromContext returns the submitter identity carried by ctx, truncated
+// to MaxSubmitterLength bytes, or the empty string if there is none.
+func SubmitterFromContext(ctx context.Context) string {
+	submitter, _ := ctx.Value(submitterKey{}).(string)
+	if len(submitter) > MaxSubmitterLength {
+		submitter = submitter[:MaxSubmitterLength]
+	}
+	return submitter
+}
+
+// ParseSubmitterWeights parses a comma-separated list of submitter=weight
+// pairs, e.g. "alice=3,bob=1".
+func ParseSubmitterWeights(s string) (map[string]int, error) {
+	weights := make(map[string]int)
+	if s == "" {
+		return weights, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("malformed submitter weight %q, want submitter=weight", pair)
+		}
+		w, err := strconv.Atoi(parts[1])
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("invalid weight for submitter %q: %q", parts[0], parts[1])
+		}
+		weights[parts[0]] = w
+	}
+	return weights, nil
+}
+
+// FairShare selects up to limit leaves from the per-submitter queues in
+// weighted round-robin order: in every round, each submitter with queued
+// leaves has up to its weight of leaves selected. Each queue must be ordered
+// by queue timestamp. Within a round, submitters are visited in order of their
+// oldest queued leaf, so that ties are broken in favour of older leaves.
+func FairShare(queues map[string][]*trillian.LogLeaf, weights map[string]int, limit int) []*trillian.LogLeaf {
+	submitters := make([]string, 0, len(queues))
+	total := 0
+	for s, q := range queues {
+		if len(q) > 0 {
+			submitters = append(submitters, s)
+			total += len(q)
+		}
+	}
+	sort.Slice(submitters, func(i, j int) bool {
+		ti, tj := queues[submitters[i]][0].QueueTimestamp, queues[submitters[j]][0].QueueTimestamp
+		if ti.GetSeconds() != tj.GetSeconds() {
+			return ti.GetSeconds() < tj.GetSeconds()
+		}
+		if ti.GetNanos() != tj.GetNanos() {
+			return ti.GetNanos() < tj.GetNanos()
+		}
+		return submitters[i] < submitters[j]
+	})
+	if total < limit {
+		limit = total
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, limit)
+	next := make(map[string]int, len(submitters))
+	for len(ret) < limit {
+		for _, s := range submitters {
+			w := weights[s]
+			if w < 1 {
+				w = 1
+			}
+			q := queues[s]
+			for i := 0; i < w && next[s] < len(q) && len(ret) < limit; i++ {
+				ret = append(ret, q[next[s]])
+				next[s]++
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+)
+
+// queue returns leaves of the given submitter, identified by their
+// LeafIdentityHash of submitter+index, queued at the given times.
+func queue(submitter string, times ...int64) []*trillian.LogLeaf {
+	leaves := make([]*trillian.LogLeaf, len(times))
+	for i, ts := range times {
+		leaves[i] = &trillian.LogLeaf{
+			LeafIdentityHash: []byte(submitter + string(rune('0'+i))),
+			QueueTimestamp:   &timestamp.Timestamp{Seconds: ts},
+		}
+	}
+	return leaves
+}
+
+func ids(leaves []*trillian.LogLeaf) []string {
+	ret := make([]string, len(leaves))
+	for i, l := range leaves {
+		ret[i] = string(l.LeafIdentityHash)
+	}
+	return ret
+}
+
+func TestFairShare(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		queues  map[string][]*trillian.LogLeaf
+		weights map[string]int
+		limit   int
+		want    []string
+	}{
+		{
+			desc:  "empty",
+			limit: 10,
+			want:  []string{},
+		},
+		{
+			desc:   "single",
+			queues: map[string][]*trillian.LogLeaf{"a": queue("a", 1, 2, 3)},
+			limit:  2,
+			want:   []string{"a0", "a1"},
+		},
+		{
+			desc: "noisy-submitter-does-not-starve",
+			queues: map[string][]*trillian.LogLeaf{
+				"noisy": queue("noisy", 1, 2, 3, 4, 5, 6),
+				"quiet": queue("quiet", 10),
+			},
+			limit: 3,
+			want:  []string{"noisy0", "quiet0", "noisy1"},
+		},
+		{
+			desc: "oldest-first-within-round",
+			queues: map[string][]*trillian.LogLeaf{
+				"a": queue("a", 5, 6),
+				"b": queue("b", 1, 2),
+			},
+			limit: 10,
+			want:  []string{"b0", "a0", "b1", "a1"},
+		},
+		{
+			desc: "weighted",
+			queues: map[string][]*trillian.LogLeaf{
+				"a": queue("a", 1, 2, 3, 4, 5),
+				"b": queue("b", 1, 2, 3, 4, 5),
+			},
+			weights: map[string]int{"a": 3},
+			limit:   6,
+			want:    []string{"a0", "a1", "a2", "b0", "a3", "a4"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := ids(FairShare(tc.queues, tc.weights, tc.limit))
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("FairShare() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseSubmitterWeights(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    map[string]int
+		wantErr bool
+	}{
+		{in: "", want: map[string]int{}},
+		{in: "a=1", want: map[string]int{"a": 1}},
+		{in: "a=3,b=2", want: map[string]int{"a": 3, "b": 2}},
+		{in: "a", wantErr: true},
+		{in: "=3", wantErr: true},
+		{in: "a=0", wantErr: true},
+		{in: "a=x", wantErr: true},
+	} {
+		got, err := ParseSubmitterWeights(tc.in)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("ParseSubmitterWeights(%q)=%v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(got, tc.want); !tc.wantErr && diff != "" {
+			t.Errorf("ParseSubmitterWeights(%q) diff (-got +want):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestSubmitterContext(t *testing.T) {
+	ctx := context.Background()
+	if got := SubmitterFromContext(ctx); got != "" {
+		t.Errorf("SubmitterFromContext(empty)=%q, want empty", got)
+	}
+	if got := SubmitterFromContext(NewSubmitterContext(ctx, "alice")); got != "alice" {
+		t.Errorf("SubmitterFromContext()=%q, want alice", got)
+	}
+	long := strings.Repeat("x", MaxSubmitterLength+10)
+	if got := SubmitterFromContext(NewSubmitterContext(ctx, long)); len(got) != MaxSubmitterLength {
+		t.Errorf("SubmitterFromContext(long) has length %d, want %d", len(got), MaxSubmitterLength)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faulty
+
+import (
+	"context"
+
+	"github.com/google/trillian/storage"
+)
+
+// AdminStorage is a storage.AdminStorage which injects faults into the calls
+// to the underlying AdminStorage.
+type AdminStorage struct {
+	storage.AdminStorage
+	inj *Injector
+}
+
+// NewAdminStorage returns an AdminStorage which injects the faults chosen by
+// inj into the calls to as.
+func NewAdminStorage(as storage.AdminStorage, inj *Injector) *AdminStorage {
+	return &AdminStorage{AdminStorage: as, inj: inj}
+}
+
+// CheckDatabaseAccessible implements storage.AdminStorage.
+func (s *AdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	if err := s.inj.before(ctx, "CheckDatabaseAccessible"); err != nil {
+		return err
+	}
+	return s.AdminStorage.CheckDatabaseAccessible(ctx)
+}
+
+// Snapshot implements storage.AdminStorage.
+func (s *AdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	if err := s.inj.before(ctx, "Snapshot"); err != nil {
+		return nil, err
+	}
+	return s.AdminStorage.Snapshot(ctx)
+}
+
+// ReadWriteTransaction implements storage.AdminStorage.
+func (s *AdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	if err := s.inj.before(ctx, "ReadWriteTransaction"); err != nil {
+		return err
+	}
+	if err := s.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return s.inj.before(ctx, Commit)
+	}); err != nil {
+		return err
+	}
+	return s.inj.after(Commit)
+}
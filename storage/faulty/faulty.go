@@ -0,0 +1,179 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faulty provides storage.LogStorage and storage.AdminStorage
+// decorators which inject latency and failures into the calls to the
+// underlying storage, for testing the servers against a flaky database.
+//
+// Faults are injected at the entry points of the storage, and at the commit
+// of read-write transactions. The transactions themselves are passed to the
+// callers unwrapped, so that the optional transaction interfaces of the
+// underlying storage, such as storage.QuarantineLogTreeTX, remain available.
+package faulty
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/util/clock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Names of the fault injection points, as used in Options.Methods, other than
+// the names of the storage methods.
+const (
+	// Commit is the commit of a read-write transaction, after its function
+	// returns successfully. A failure here rolls the transaction back.
+	Commit = "Commit"
+)
+
+const (
+	methodLabel = "method"
+	faultLabel  = "fault"
+
+	faultLatency = "latency"
+	faultError   = "error"
+	faultTorn    = "torn_commit"
+)
+
+var (
+	once          sync.Once
+	faultsCounter monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	faultsCounter = mf.NewCounter("faulty_storage_faults", "Number of faults injected into storage calls", methodLabel, faultLabel)
+}
+
+// ErrInjected is the default error returned by the calls failed by an
+// Injector.
+var ErrInjected = status.Error(codes.Unavailable, "faulty: injected storage failure")
+
+// Options configures the faults injected by an Injector.
+type Options struct {
+	// Seed seeds the pseudo-random choice of the faults, so that the same
+	// sequence of calls gets the same faults.
+	Seed int64
+	// Latency is added to a call with probability LatencyProbability.
+	Latency            time.Duration
+	LatencyProbability float64
+	// ErrorProbability is the probability that a call fails with Err, without
+	// reaching the underlying storage.
+	ErrorProbability float64
+	// TornCommitProbability is the probability that a successful write is
+	// reported to the caller as failed with Err. This applies to the Commit of
+	// read-write transactions, and to QueueLeaves and AddSequencedLeaves.
+	TornCommitProbability float64
+	// Err is the error of failed calls. Defaults to ErrInjected.
+	Err error
+	// Methods are the names of the storage methods, or Commit, at which the
+	// faults are injected. Empty means all of them.
+	Methods []string
+	// TimeSource is used for the latency, and may be mocked out by tests.
+	TimeSource clock.TimeSource
+}
+
+// Injector decides which faults to inject into each storage call. It is safe
+// for concurrent use, but the faults only follow deterministically from the
+// seed if the calls are made in a deterministic order.
+type Injector struct {
+	opts    Options
+	methods map[string]bool
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewInjector creates an Injector with the given options.
+func NewInjector(opts Options, mf monitoring.MetricFactory) *Injector {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	once.Do(func() {
+		createMetrics(mf)
+	})
+	if opts.Err == nil {
+		opts.Err = ErrInjected
+	}
+	if opts.TimeSource == nil {
+		opts.TimeSource = clock.System
+	}
+	var methods map[string]bool
+	if len(opts.Methods) > 0 {
+		methods = make(map[string]bool)
+		for _, m := range opts.Methods {
+			methods[m] = true
+		}
+	}
+	return &Injector{
+		opts:    opts,
+		methods: methods,
+		rnd:     rand.New(rand.NewSource(opts.Seed)),
+	}
+}
+
+// selected returns whether faults are injected into the given method.
+func (i *Injector) selected(method string) bool {
+	return i.methods == nil || i.methods[method]
+}
+
+// draw returns whether each of the given probabilities comes true. The same
+// number of random values is drawn whatever the probabilities are, so that
+// the faults of later calls don't depend on them.
+func (i *Injector) draw(probs ...float64) []bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	ret := make([]bool, len(probs))
+	for j, p := range probs {
+		ret[j] = i.rnd.Float64() < p
+	}
+	return ret
+}
+
+// before delays a call of the given method, and returns the error with which
+// it should fail, if any.
+func (i *Injector) before(ctx context.Context, method string) error {
+	if !i.selected(method) {
+		return nil
+	}
+	faults := i.draw(i.opts.LatencyProbability, i.opts.ErrorProbability)
+	if faults[0] && i.opts.Latency > 0 {
+		faultsCounter.Inc(method, faultLatency)
+		if err := clock.SleepSource(ctx, i.opts.Latency, i.opts.TimeSource); err != nil {
+			return err
+		}
+	}
+	if faults[1] {
+		faultsCounter.Inc(method, faultError)
+		return i.opts.Err
+	}
+	return nil
+}
+
+// after returns the error with which a successful write by the given method
+// should be reported, if any.
+func (i *Injector) after(method string) error {
+	if !i.selected(method) {
+		return nil
+	}
+	if i.draw(i.opts.TornCommitProbability)[0] {
+		faultsCounter.Inc(method, faultTorn)
+		return i.opts.Err
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faulty
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+)
+
+// outcomes returns whether each of n calls of method fails.
+func outcomes(ctx context.Context, inj *Injector, method string, n int) []bool {
+	ret := make([]bool, n)
+	for i := range ret {
+		ret[i] = inj.before(ctx, method) != nil
+	}
+	return ret
+}
+
+func TestInjectorDeterministic(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Seed: 42, ErrorProbability: 0.5}
+	first := outcomes(ctx, NewInjector(opts, nil), "QueueLeaves", 100)
+	second := outcomes(ctx, NewInjector(opts, nil), "QueueLeaves", 100)
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("Faults differ with the same seed (-first +second):\n%s", diff)
+	}
+	failed := 0
+	for _, f := range first {
+		if f {
+			failed++
+		}
+	}
+	if failed == 0 || failed == len(first) {
+		t.Errorf("%d of %d calls failed, want some", failed, len(first))
+	}
+	opts.Seed = 43
+	if third := outcomes(ctx, NewInjector(opts, nil), "QueueLeaves", 100); cmp.Equal(first, third) {
+		t.Error("Faults are the same with another seed")
+	}
+}
+
+func TestInjectorMethods(t *testing.T) {
+	ctx := context.Background()
+	wantErr := errors.New("boom")
+	inj := NewInjector(Options{ErrorProbability: 1, Err: wantErr, Methods: []string{"QueueLeaves"}}, nil)
+	if err := inj.before(ctx, "QueueLeaves"); err != wantErr {
+		t.Errorf("before(QueueLeaves): %v, want %v", err, wantErr)
+	}
+	if err := inj.before(ctx, "SnapshotForTree"); err != nil {
+		t.Errorf("before(SnapshotForTree): %v, want nil", err)
+	}
+}
+
+func TestInjectorLatency(t *testing.T) {
+	const latency = 20 * time.Millisecond
+	inj := NewInjector(Options{Latency: latency, LatencyProbability: 1}, nil)
+	start := time.Now()
+	if err := inj.before(context.Background(), "Snapshot"); err != nil {
+		t.Fatalf("before(Snapshot): %v", err)
+	}
+	if got := time.Since(start); got < latency {
+		t.Errorf("before(Snapshot) took %v, want at least %v", got, latency)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inj = NewInjector(Options{Latency: time.Hour, LatencyProbability: 1}, nil)
+	if err := inj.before(ctx, "Snapshot"); err != context.Canceled {
+		t.Errorf("before(Snapshot) with cancelled context: %v, want %v", err, context.Canceled)
+	}
+}
+
+// storeRoot stores a root with the given timestamp in the given log, through
+// ls.
+func storeRoot(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree, timestamp uint64) error {
+	root, err := (&types.LogRootV1{TimestampNanos: timestamp}).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	})
+}
+
+// latestTimestamp returns the timestamp of the latest root of the given log
+// in ls.
+func latestTimestamp(ctx context.Context, ls storage.LogStorage, tree *trillian.Tree) (uint64, error) {
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		return 0, err
+	}
+	return root.TimestampNanos, nil
+}
+
+func TestLogStorageCommit(t *testing.T) {
+	for _, tc := range []struct {
+		desc          string
+		opts          Options
+		wantErr       bool
+		wantTimestamp uint64
+	}{
+		{desc: "no-faults", wantTimestamp: 2},
+		{desc: "commit-failure", opts: Options{ErrorProbability: 1, Methods: []string{Commit}}, wantErr: true, wantTimestamp: 1},
+		{desc: "begin-failure", opts: Options{ErrorProbability: 1, Methods: []string{"ReadWriteTransaction"}}, wantErr: true, wantTimestamp: 1},
+		{desc: "torn-commit", opts: Options{TornCommitProbability: 1}, wantErr: true, wantTimestamp: 2},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			ts := memory.NewTreeStorage()
+			as := memory.NewAdminStorage(ts)
+			tree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+			if err != nil {
+				t.Fatalf("CreateTree(): %v", err)
+			}
+			base := memory.NewLogStorage(ts, nil)
+			if err := storeRoot(ctx, base, tree, 1); err != nil {
+				t.Fatalf("Failed to store first root: %v", err)
+			}
+			ls := NewLogStorage(base, NewInjector(tc.opts, nil))
+
+			err = storeRoot(ctx, ls, tree, 2)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("ReadWriteTransaction(): %v, want error: %v", err, tc.wantErr)
+			}
+			got, err := latestTimestamp(ctx, base, tree)
+			if err != nil {
+				t.Fatalf("Failed to read latest root: %v", err)
+			}
+			if got != tc.wantTimestamp {
+				t.Errorf("Latest root has timestamp %d, want %d", got, tc.wantTimestamp)
+			}
+		})
+	}
+}
+
+func TestAdminStorage(t *testing.T) {
+	ctx := context.Background()
+	base := memory.NewAdminStorage(memory.NewTreeStorage())
+	as := NewAdminStorage(base, NewInjector(Options{TornCommitProbability: 1}, nil))
+	if _, err := storage.CreateTree(ctx, as, testonly.LogTree); err != ErrInjected {
+		t.Errorf("CreateTree(): %v, want %v", err, ErrInjected)
+	}
+	// The tree was created despite the error.
+	trees, err := storage.ListTrees(ctx, base, false)
+	if err != nil {
+		t.Fatalf("ListTrees(): %v", err)
+	}
+	if len(trees) != 1 {
+		t.Errorf("ListTrees() returned %d trees, want 1", len(trees))
+	}
+}
+
+func TestProvider(t *testing.T) {
+	sp, err := storage.NewProvider("faulty", nil)
+	if err != nil {
+		t.Fatalf("NewProvider(faulty): %v", err)
+	}
+	defer sp.Close()
+	if _, ok := sp.LogStorage().(*LogStorage); !ok {
+		t.Errorf("LogStorage() is %T, want %T", sp.LogStorage(), &LogStorage{})
+	}
+	if _, ok := sp.AdminStorage().(*AdminStorage); !ok {
+		t.Errorf("AdminStorage() is %T, want %T", sp.AdminStorage(), &AdminStorage{})
+	}
+}
+
+// fakeWatchingProvider is a storage.Provider which implements
+// storage.QueueWatcher.
+type fakeWatchingProvider struct {
+	storage.Provider
+}
+
+func (fakeWatchingProvider) WatchQueue(context.Context) (<-chan int64, error) {
+	return nil, nil
+}
+
+func TestProviderQueueWatcher(t *testing.T) {
+	inj := NewInjector(Options{}, nil)
+	if _, ok := NewProvider(fakeWatchingProvider{}, inj).(storage.QueueWatcher); !ok {
+		t.Error("NewProvider() of a QueueWatcher is not a QueueWatcher")
+	}
+	sp, err := storage.NewProvider("memory", nil)
+	if err != nil {
+		t.Fatalf("NewProvider(memory): %v", err)
+	}
+	defer sp.Close()
+	fp := NewProvider(sp, inj)
+	if _, ok := fp.(storage.QueueWatcher); ok {
+		t.Error("NewProvider() of memory is a QueueWatcher")
+	}
+	if _, ok := fp.LogStorage().(*LogStorage); !ok {
+		t.Errorf("LogStorage() is %T, want %T", fp.LogStorage(), &LogStorage{})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faulty
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+)
+
+// LogStorage is a storage.LogStorage which injects faults into the calls to
+// the underlying LogStorage.
+type LogStorage struct {
+	storage.LogStorage
+	inj *Injector
+}
+
+// NewLogStorage returns a LogStorage which injects the faults chosen by inj
+// into the calls to ls.
+func NewLogStorage(ls storage.LogStorage, inj *Injector) *LogStorage {
+	return &LogStorage{LogStorage: ls, inj: inj}
+}
+
+// CheckDatabaseAccessible implements storage.LogStorage.
+func (s *LogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	if err := s.inj.before(ctx, "CheckDatabaseAccessible"); err != nil {
+		return err
+	}
+	return s.LogStorage.CheckDatabaseAccessible(ctx)
+}
+
+// Snapshot implements storage.LogStorage.
+func (s *LogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	if err := s.inj.before(ctx, "Snapshot"); err != nil {
+		return nil, err
+	}
+	return s.LogStorage.Snapshot(ctx)
+}
+
+// SnapshotForTree implements storage.LogStorage.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	if err := s.inj.before(ctx, "SnapshotForTree"); err != nil {
+		return nil, err
+	}
+	return s.LogStorage.SnapshotForTree(ctx, tree)
+}
+
+// ReadWriteTransaction implements storage.LogStorage.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	if err := s.inj.before(ctx, "ReadWriteTransaction"); err != nil {
+		return err
+	}
+	if err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+		return s.inj.before(ctx, Commit)
+	}); err != nil {
+		return err
+	}
+	return s.inj.after(Commit)
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	if err := s.inj.before(ctx, "QueueLeaves"); err != nil {
+		return nil, err
+	}
+	ret, err := s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.inj.after("QueueLeaves"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	if err := s.inj.before(ctx, "AddSequencedLeaves"); err != nil {
+		return nil, err
+	}
+	ret, err := s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.inj.after("AddSequencedLeaves"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
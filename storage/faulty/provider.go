@@ -0,0 +1,98 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faulty
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+var (
+	baseProvider         = flag.String("faulty_storage_provider", "memory", "Storage provider wrapped by the faulty storage provider")
+	faultySeed           = flag.Int64("faulty_seed", 1, "Seed of the pseudo-random faults injected by the faulty storage provider")
+	faultyLatency        = flag.Duration("faulty_latency", 0, "Latency added to storage calls by the faulty storage provider")
+	faultyLatencyProb    = flag.Float64("faulty_latency_probability", 1, "Probability that the faulty storage provider adds --faulty_latency to a storage call")
+	faultyErrorProb      = flag.Float64("faulty_error_probability", 0, "Probability that the faulty storage provider fails a storage call")
+	faultyTornCommitProb = flag.Float64("faulty_torn_commit_probability", 0, "Probability that the faulty storage provider reports a successful write as failed")
+	faultyMethods        = flag.String("faulty_methods", "", "Comma-separated names of the storage methods into which the faulty storage provider injects faults, e.g. QueueLeaves,Commit; empty means all")
+)
+
+func init() {
+	if err := storage.RegisterProviderWithOptions("faulty", newFaultyProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider faulty: %v", err)
+	}
+}
+
+// Provider is a storage.Provider which injects faults into the storage of
+// another Provider.
+type Provider struct {
+	storage.Provider
+	inj *Injector
+}
+
+// watchingProvider is a Provider which keeps the storage.QueueWatcher of the
+// underlying Provider.
+type watchingProvider struct {
+	*Provider
+	storage.QueueWatcher
+}
+
+// NewProvider returns a storage.Provider which injects the faults chosen by
+// inj into the storage of p. It implements storage.QueueWatcher if p does,
+// without injecting faults into the notifications.
+func NewProvider(p storage.Provider, inj *Injector) storage.Provider {
+	fp := &Provider{Provider: p, inj: inj}
+	if qw, ok := p.(storage.QueueWatcher); ok {
+		return &watchingProvider{Provider: fp, QueueWatcher: qw}
+	}
+	return fp
+}
+
+func newFaultyProvider(mf monitoring.MetricFactory, popts storage.ProviderOptions) (storage.Provider, error) {
+	if *baseProvider == "faulty" {
+		return nil, fmt.Errorf("faulty storage provider can't wrap itself")
+	}
+	p, err := storage.NewProviderWithOptions(*baseProvider, mf, popts)
+	if err != nil {
+		return nil, err
+	}
+	opts := Options{
+		Seed:                  *faultySeed,
+		Latency:               *faultyLatency,
+		LatencyProbability:    *faultyLatencyProb,
+		ErrorProbability:      *faultyErrorProb,
+		TornCommitProbability: *faultyTornCommitProb,
+	}
+	if *faultyMethods != "" {
+		opts.Methods = strings.Split(*faultyMethods, ",")
+	}
+	glog.Warningf("Injecting faults into storage provider %s: %+v", *baseProvider, opts)
+	return NewProvider(p, NewInjector(opts, mf)), nil
+}
+
+// LogStorage implements storage.Provider.
+func (p *Provider) LogStorage() storage.LogStorage {
+	return NewLogStorage(p.Provider.LogStorage(), p.inj)
+}
+
+// AdminStorage implements storage.Provider.
+func (p *Provider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(p.Provider.AdminStorage(), p.inj)
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+// AdminStorage is a storage.AdminStorage which records metrics of the calls
+// to the underlying AdminStorage and its transactions.
+type AdminStorage struct {
+	storage.AdminStorage
+}
+
+// NewAdminStorage returns an AdminStorage which instruments as, recording
+// metrics through mf, as per NewLogStorage.
+func NewAdminStorage(as storage.AdminStorage, mf monitoring.MetricFactory) *AdminStorage {
+	initMetrics(mf)
+	return &AdminStorage{AdminStorage: as}
+}
+
+// CheckDatabaseAccessible implements storage.AdminStorage.
+func (s *AdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	begin := time.Now()
+	err := s.AdminStorage.CheckDatabaseAccessible(ctx)
+	recorder{}.done("AdminStorage.CheckDatabaseAccessible", begin, err)
+	return err
+}
+
+// Snapshot implements storage.AdminStorage.
+func (s *AdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	begin := time.Now()
+	tx, err := s.AdminStorage.Snapshot(ctx)
+	recorder{}.done("AdminStorage.Snapshot", begin, err)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyAdminTX{tx: tx}, nil
+}
+
+// ReadWriteTransaction implements storage.AdminStorage. The latency recorded
+// for it includes the time taken by f.
+func (s *AdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+	begin := time.Now()
+	err := s.AdminStorage.ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+		return f(ctx, &adminTX{readOnlyAdminTX: &readOnlyAdminTX{tx: tx}, wtx: tx})
+	})
+	recorder{}.done("AdminStorage.ReadWriteTransaction", begin, err)
+	return err
+}
+
+// readOnlyAdminTX is an instrumented storage.ReadOnlyAdminTX.
+type readOnlyAdminTX struct {
+	tx storage.ReadOnlyAdminTX
+}
+
+func (t *readOnlyAdminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	begin := time.Now()
+	tree, err := t.tx.GetTree(ctx, treeID)
+	treeRecorder(treeID).done("AdminTX.GetTree", begin, err)
+	return tree, err
+}
+
+func (t *readOnlyAdminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	begin := time.Now()
+	ids, err := t.tx.ListTreeIDs(ctx, includeDeleted)
+	recorder{}.doneRows("AdminTX.ListTreeIDs", begin, len(ids), err)
+	return ids, err
+}
+
+func (t *readOnlyAdminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	begin := time.Now()
+	trees, err := t.tx.ListTrees(ctx, includeDeleted)
+	recorder{}.doneRows("AdminTX.ListTrees", begin, len(trees), err)
+	return trees, err
+}
+
+func (t *readOnlyAdminTX) Commit() error {
+	begin := time.Now()
+	err := t.tx.Commit()
+	recorder{}.done("AdminTX.Commit", begin, err)
+	return err
+}
+
+func (t *readOnlyAdminTX) Rollback() error {
+	begin := time.Now()
+	err := t.tx.Rollback()
+	recorder{}.done("AdminTX.Rollback", begin, err)
+	return err
+}
+
+func (t *readOnlyAdminTX) IsClosed() bool {
+	return t.tx.IsClosed()
+}
+
+func (t *readOnlyAdminTX) Close() error {
+	return t.tx.Close()
+}
+
+// adminTX is an instrumented storage.AdminTX.
+type adminTX struct {
+	*readOnlyAdminTX
+	wtx storage.AdminTX
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	begin := time.Now()
+	ret, err := t.wtx.CreateTree(ctx, tree)
+	recorder{}.done("AdminTX.CreateTree", begin, err)
+	return ret, err
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	begin := time.Now()
+	ret, err := t.wtx.UpdateTree(ctx, treeID, updateFunc)
+	treeRecorder(treeID).done("AdminTX.UpdateTree", begin, err)
+	return ret, err
+}
+
+func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	begin := time.Now()
+	ret, err := t.wtx.SoftDeleteTree(ctx, treeID)
+	treeRecorder(treeID).done("AdminTX.SoftDeleteTree", begin, err)
+	return ret, err
+}
+
+func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	begin := time.Now()
+	err := t.wtx.HardDeleteTree(ctx, treeID)
+	treeRecorder(treeID).done("AdminTX.HardDeleteTree", begin, err)
+	return err
+}
+
+func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	begin := time.Now()
+	ret, err := t.wtx.UndeleteTree(ctx, treeID)
+	treeRecorder(treeID).done("AdminTX.UndeleteTree", begin, err)
+	return ret, err
+}
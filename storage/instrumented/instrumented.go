@@ -0,0 +1,119 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instrumented provides decorators for storage.LogStorage and
+// storage.AdminStorage, and for their transactions, which record the latency,
+// errors and number of rows returned of the calls to every method, so that
+// all storage implementations get the same metrics.
+//
+// The metrics are labelled by method, as "<interface>.<method>" (for example
+// "LogTreeTX.GetLeavesByRange"), and by tree ID where there is one.
+package instrumented
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	methodLabel = "method"
+	treeLabel   = "tree_id"
+	codeLabel   = "code"
+)
+
+var (
+	once          sync.Once
+	latencyHist   monitoring.Histogram
+	errorsCounter monitoring.Counter
+	rowsCounter   monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	latencyHist = mf.NewHistogram("storage_call_latency", "Latency of storage calls in seconds", methodLabel, treeLabel)
+	errorsCounter = mf.NewCounter("storage_call_errors", "Number of failed storage calls, by gRPC code", methodLabel, treeLabel, codeLabel)
+	rowsCounter = mf.NewCounter("storage_rows_returned", "Number of leaves, nodes, roots or trees returned by storage calls", methodLabel, treeLabel)
+}
+
+func initMetrics(mf monitoring.MetricFactory) {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	once.Do(func() {
+		createMetrics(mf)
+	})
+}
+
+// recorder records the metrics of calls relating to a tree, or to no tree in
+// particular if tree is empty.
+type recorder struct {
+	tree string
+}
+
+func treeRecorder(treeID int64) recorder {
+	return recorder{tree: strconv.FormatInt(treeID, 10)}
+}
+
+// done records a call of method which began at begin and returned err.
+func (r recorder) done(method string, begin time.Time, err error) {
+	latencyHist.Observe(time.Since(begin).Seconds(), method, r.tree)
+	if err != nil {
+		errorsCounter.Inc(method, r.tree, status.Code(err).String())
+	}
+}
+
+// doneRows is like done, for a call which returned the given number of rows.
+func (r recorder) doneRows(method string, begin time.Time, rows int, err error) {
+	r.done(method, begin, err)
+	if err == nil {
+		rowsCounter.Add(float64(rows), method, r.tree)
+	}
+}
+
+// Provider is a storage.Provider whose storage is instrumented.
+type Provider struct {
+	storage.Provider
+}
+
+// watchingProvider is a Provider which keeps the storage.QueueWatcher of the
+// underlying Provider.
+type watchingProvider struct {
+	*Provider
+	storage.QueueWatcher
+}
+
+// NewProvider returns a storage.Provider which instruments the storage of p,
+// recording metrics through mf. It implements storage.QueueWatcher if p does.
+func NewProvider(p storage.Provider, mf monitoring.MetricFactory) storage.Provider {
+	initMetrics(mf)
+	ip := &Provider{Provider: p}
+	if qw, ok := p.(storage.QueueWatcher); ok {
+		return &watchingProvider{Provider: ip, QueueWatcher: qw}
+	}
+	return ip
+}
+
+// LogStorage implements storage.Provider.
+func (p *Provider) LogStorage() storage.LogStorage {
+	return NewLogStorage(p.Provider.LogStorage(), nil)
+}
+
+// AdminStorage implements storage.Provider.
+func (p *Provider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(p.Provider.AdminStorage(), nil)
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumented
+
+import (
+	"context"
+	"crypto/sha256"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+)
+
+// calls returns the number of recorded calls of method.
+func calls(method, tree string) uint64 {
+	n, _ := latencyHist.Info(method, tree)
+	return n
+}
+
+func TestLogStorage(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	as := NewAdminStorage(memory.NewAdminStorage(ts), nil)
+	tree, err := storage.CreateTree(ctx, as, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	id := strconv.FormatInt(tree.TreeId, 10)
+	ls := NewLogStorage(memory.NewLogStorage(ts, nil), nil)
+	root, err := (&types.LogRootV1{}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	var leaves []*trillian.LogLeaf
+	for _, data := range []string{"a", "b", "c"} {
+		hash := sha256.Sum256([]byte(data))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafValue: []byte(data)})
+	}
+	if _, err := ls.QueueLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		_, err := tx.DequeueLeaves(ctx, 2, time.Now())
+		return err
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		method    string
+		wantCalls uint64
+		wantRows  float64
+	}{
+		{method: "LogStorage.QueueLeaves", wantCalls: 1, wantRows: 3},
+		{method: "LogStorage.ReadWriteTransaction", wantCalls: 2},
+		{method: "LogTreeTX.StoreSignedLogRoot", wantCalls: 1},
+		{method: "LogTreeTX.DequeueLeaves", wantCalls: 1, wantRows: 2},
+	} {
+		if got := calls(tc.method, id); got != tc.wantCalls {
+			t.Errorf("%s: %d calls recorded, want %d", tc.method, got, tc.wantCalls)
+		}
+		if got := rowsCounter.Value(tc.method, id); got != tc.wantRows {
+			t.Errorf("%s: %v rows recorded, want %v", tc.method, got, tc.wantRows)
+		}
+	}
+}
+
+func TestAdminStorageErrors(t *testing.T) {
+	ctx := context.Background()
+	as := NewAdminStorage(memory.NewAdminStorage(memory.NewTreeStorage()), nil)
+	const treeID = 12345
+	if _, err := storage.GetTree(ctx, as, treeID); err == nil {
+		t.Fatal("GetTree() of missing tree succeeded")
+	}
+	if got := errorsCounter.Value("AdminTX.GetTree", "12345", "Unknown"); got != 1 {
+		t.Errorf("%v errors recorded, want 1", got)
+	}
+}
+
+// optionalTX is a LogTreeTX which implements all of the optional interfaces.
+type optionalTX struct {
+	storage.LogTreeTX
+}
+
+func (optionalTX) QuarantineLeaves(context.Context, []*trillian.QuarantinedLeaf) error {
+	return nil
+}
+
+func (optionalTX) ListQuarantinedLeaves(context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	return nil, nil
+}
+
+func (optionalTX) RequeueQuarantinedLeaves(context.Context, [][]byte, time.Time) ([][]byte, error) {
+	return nil, nil
+}
+
+func (optionalTX) GetSignedLogRoots(context.Context, storage.LogRootFilter, int) ([]*trillian.SignedLogRoot, error) {
+	return nil, nil
+}
+
+func (optionalTX) RecompressLeaves(context.Context, []byte, int) ([]byte, int, error) {
+	return nil, 0, nil
+}
+
+func (optionalTX) RevisionAt(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (optionalTX) CompactSubtrees(context.Context, int64, []byte, int) ([]byte, int, error) {
+	return nil, 0, nil
+}
+
+func (optionalTX) GetLeafIndexByTime(context.Context, time.Time, int64) (int64, error) {
+	return 0, nil
+}
+
+func (optionalTX) TierLeaves(context.Context, int64, int64) (int, error) {
+	return 0, nil
+}
+
+func (optionalTX) TierTiles(context.Context, int64, []byte, int) ([]byte, int, error) {
+	return nil, 0, nil
+}
+
+func (optionalTX) GetLeafHashesByIndex(context.Context, []int64) ([]*trillian.LogLeaf, error) {
+	return nil, nil
+}
+
+func (optionalTX) GetLeafHashesByRange(context.Context, int64, int64) ([]*trillian.LogLeaf, error) {
+	return nil, nil
+}
+
+// baseTX is a LogTreeTX which implements none of the optional interfaces.
+type baseTX struct {
+	storage.LogTreeTX
+}
+
+type interfaces struct {
+	Quarantine, History, Recompress, Compaction, Index, Tiering, Hashes bool
+}
+
+func implemented(tx storage.ReadOnlyLogTreeTX) interfaces {
+	var ret interfaces
+	_, ret.Quarantine = tx.(storage.QuarantineLogTreeTX)
+	_, ret.History = tx.(storage.LogRootHistoryTX)
+	_, ret.Recompress = tx.(storage.RecompressLogTreeTX)
+	_, ret.Compaction = tx.(storage.SubtreeCompactionTX)
+	_, ret.Index = tx.(storage.LeafTimeIndexTX)
+	_, ret.Tiering = tx.(storage.TieringLogTreeTX)
+	_, ret.Hashes = tx.(storage.LeafHashesTX)
+	return ret
+}
+
+func TestOptionalInterfaces(t *testing.T) {
+	ctx := context.Background()
+	ts := memory.NewTreeStorage()
+	tree, err := storage.CreateTree(ctx, memory.NewAdminStorage(ts), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	var memoryTX storage.LogTreeTX
+	if err := memory.NewLogStorage(ts, nil).ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		memoryTX = tx
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+
+	all := interfaces{true, true, true, true, true, true, true}
+	for _, tc := range []struct {
+		desc string
+		tx   storage.LogTreeTX
+		want interfaces
+	}{
+		{desc: "none", tx: baseTX{}},
+		{desc: "memory", tx: memoryTX, want: interfaces{Quarantine: true, History: true}},
+		{desc: "all", tx: optionalTX{}, want: all},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := implemented(tc.tx); got != tc.want {
+				t.Fatalf("Underlying TX implements %+v, want %+v", got, tc.want)
+			}
+			if got := implemented(wrapTX(tc.tx, recorder{})); got != tc.want {
+				t.Errorf("wrapTX() implements %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	snapshot := implemented(wrapSnapshot(optionalTX{}, recorder{}))
+	if want := (interfaces{History: true, Index: true, Hashes: true}); snapshot != want {
+		t.Errorf("wrapSnapshot() implements %+v, want %+v", snapshot, want)
+	}
+}
+
+// fakeWatchingProvider is a storage.Provider which implements
+// storage.QueueWatcher.
+type fakeWatchingProvider struct {
+	storage.Provider
+}
+
+func (fakeWatchingProvider) WatchQueue(context.Context) (<-chan int64, error) {
+	return nil, nil
+}
+
+func TestProviderQueueWatcher(t *testing.T) {
+	if _, ok := NewProvider(fakeWatchingProvider{}, nil).(storage.QueueWatcher); !ok {
+		t.Error("NewProvider() of a QueueWatcher is not a QueueWatcher")
+	}
+	sp, err := storage.NewProvider("memory", nil)
+	if err != nil {
+		t.Fatalf("NewProvider(memory): %v", err)
+	}
+	defer sp.Close()
+	ip := NewProvider(sp, nil)
+	if _, ok := ip.(storage.QueueWatcher); ok {
+		t.Error("NewProvider() of memory is a QueueWatcher")
+	}
+	if _, ok := ip.LogStorage().(*LogStorage); !ok {
+		t.Errorf("LogStorage() is %T, want %T", ip.LogStorage(), &LogStorage{})
+	}
+	if _, ok := ip.AdminStorage().(*AdminStorage); !ok {
+		t.Errorf("AdminStorage() is %T, want %T", ip.AdminStorage(), &AdminStorage{})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+)
+
+// LogStorage is a storage.LogStorage which records metrics of the calls to
+// the underlying LogStorage and its transactions.
+type LogStorage struct {
+	storage.LogStorage
+}
+
+// NewLogStorage returns a LogStorage which instruments ls, recording metrics
+// through mf. The metrics are only created once per process, so mf is
+// ignored after the first call to any of the constructors of this package.
+func NewLogStorage(ls storage.LogStorage, mf monitoring.MetricFactory) *LogStorage {
+	initMetrics(mf)
+	return &LogStorage{LogStorage: ls}
+}
+
+// CheckDatabaseAccessible implements storage.LogStorage.
+func (s *LogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	begin := time.Now()
+	err := s.LogStorage.CheckDatabaseAccessible(ctx)
+	recorder{}.done("LogStorage.CheckDatabaseAccessible", begin, err)
+	return err
+}
+
+// Snapshot implements storage.LogStorage.
+func (s *LogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	begin := time.Now()
+	tx, err := s.LogStorage.Snapshot(ctx)
+	recorder{}.done("LogStorage.Snapshot", begin, err)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyLogTX{tx: tx}, nil
+}
+
+// SnapshotForTree implements storage.LogStorage. Like the underlying storage,
+// it may return a transaction along with storage.ErrTreeNeedsInit.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	r := treeRecorder(tree.TreeId)
+	begin := time.Now()
+	tx, err := s.LogStorage.SnapshotForTree(ctx, tree)
+	r.done("LogStorage.SnapshotForTree", begin, err)
+	if tx == nil {
+		return nil, err
+	}
+	return wrapSnapshot(tx, r), err
+}
+
+// ReadWriteTransaction implements storage.LogStorage. The latency recorded
+// for it includes the time taken by f.
+func (s *LogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	r := treeRecorder(tree.TreeId)
+	begin := time.Now()
+	err := s.LogStorage.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return f(ctx, wrapTX(tx, r))
+	})
+	r.done("LogStorage.ReadWriteTransaction", begin, err)
+	return err
+}
+
+// QueueLeaves implements storage.LogStorage.
+func (s *LogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	begin := time.Now()
+	ret, err := s.LogStorage.QueueLeaves(ctx, tree, leaves, queueTimestamp)
+	treeRecorder(tree.TreeId).doneRows("LogStorage.QueueLeaves", begin, len(ret), err)
+	return ret, err
+}
+
+// AddSequencedLeaves implements storage.LogStorage.
+func (s *LogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	begin := time.Now()
+	ret, err := s.LogStorage.AddSequencedLeaves(ctx, tree, leaves, timestamp)
+	treeRecorder(tree.TreeId).doneRows("LogStorage.AddSequencedLeaves", begin, len(ret), err)
+	return ret, err
+}
+
+// readOnlyLogTX is an instrumented storage.ReadOnlyLogTX.
+type readOnlyLogTX struct {
+	tx storage.ReadOnlyLogTX
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	begin := time.Now()
+	ids, err := t.tx.GetActiveLogIDs(ctx)
+	recorder{}.doneRows("LogTX.GetActiveLogIDs", begin, len(ids), err)
+	return ids, err
+}
+
+func (t *readOnlyLogTX) Commit(ctx context.Context) error {
+	begin := time.Now()
+	err := t.tx.Commit(ctx)
+	recorder{}.done("LogTX.Commit", begin, err)
+	return err
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	begin := time.Now()
+	err := t.tx.Rollback()
+	recorder{}.done("LogTX.Rollback", begin, err)
+	return err
+}
+
+func (t *readOnlyLogTX) Close() error {
+	return t.tx.Close()
+}
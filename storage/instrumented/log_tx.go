@@ -0,0 +1,389 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tree"
+)
+
+// Callers detect the optional interfaces of transactions, such as
+// storage.QuarantineLogTreeTX, by type assertions. So the instrumented
+// transactions implement the same optional interfaces as the underlying ones,
+// for each combination of them implemented by the storages in this
+// repository. Underlying transactions with other combinations only keep the
+// largest of these which they implement.
+
+// wrapSnapshot returns an instrumented tx.
+func wrapSnapshot(tx storage.ReadOnlyLogTreeTX, r recorder) storage.ReadOnlyLogTreeTX {
+	ro := &readOnlyLogTreeTX{tx: tx, r: r}
+	htx, history := tx.(storage.LogRootHistoryTX)
+	itx, index := tx.(storage.LeafTimeIndexTX)
+	ltx, hashes := tx.(storage.LeafHashesTX)
+	switch {
+	case history && index && hashes:
+		return &fullSnapshotTX{ro, historyTX{htx, r}, timeIndexTX{itx, r}, leafHashesTX{ltx, r}}
+	case history && index:
+		return &indexedSnapshotTX{ro, historyTX{htx, r}, timeIndexTX{itx, r}}
+	case history && hashes:
+		return &hashesSnapshotTX{ro, historyTX{htx, r}, leafHashesTX{ltx, r}}
+	case history:
+		return &historySnapshotTX{ro, historyTX{htx, r}}
+	}
+	return ro
+}
+
+// wrapTX returns an instrumented tx.
+func wrapTX(tx storage.LogTreeTX, r recorder) storage.LogTreeTX {
+	ltx := &logTreeTX{readOnlyLogTreeTX: &readOnlyLogTreeTX{tx: tx, r: r}, wtx: tx}
+	qtx, quarantine := tx.(storage.QuarantineLogTreeTX)
+	htx, history := tx.(storage.LogRootHistoryTX)
+	rtx, recompress := tx.(storage.RecompressLogTreeTX)
+	ctx, compaction := tx.(storage.SubtreeCompactionTX)
+	itx, index := tx.(storage.LeafTimeIndexTX)
+	ttx, tiering := tx.(storage.TieringLogTreeTX)
+	lhtx, hashes := tx.(storage.LeafHashesTX)
+	switch {
+	case quarantine && history && recompress && compaction && index && tiering && hashes:
+		return &fullLogTreeTX{ltx, quarantineTX{qtx, r}, historyTX{htx, r}, recompressTX{rtx, r}, compactionTX{ctx, r}, timeIndexTX{itx, r}, tieringTX{ttx, r}, leafHashesTX{lhtx, r}}
+	case quarantine && history && recompress && compaction && index && tiering:
+		return &indexedLogTreeTX{ltx, quarantineTX{qtx, r}, historyTX{htx, r}, recompressTX{rtx, r}, compactionTX{ctx, r}, timeIndexTX{itx, r}, tieringTX{ttx, r}}
+	case quarantine && history:
+		return &quarantineHistoryLogTreeTX{ltx, quarantineTX{qtx, r}, historyTX{htx, r}}
+	case quarantine:
+		return &quarantineLogTreeTX{ltx, quarantineTX{qtx, r}}
+	case history && hashes:
+		return &hashesLogTreeTX{ltx, historyTX{htx, r}, leafHashesTX{lhtx, r}}
+	case history:
+		return &historyLogTreeTX{ltx, historyTX{htx, r}}
+	}
+	return ltx
+}
+
+type historySnapshotTX struct {
+	*readOnlyLogTreeTX
+	historyTX
+}
+
+type indexedSnapshotTX struct {
+	*readOnlyLogTreeTX
+	historyTX
+	timeIndexTX
+}
+
+type hashesSnapshotTX struct {
+	*readOnlyLogTreeTX
+	historyTX
+	leafHashesTX
+}
+
+type fullSnapshotTX struct {
+	*readOnlyLogTreeTX
+	historyTX
+	timeIndexTX
+	leafHashesTX
+}
+
+type quarantineLogTreeTX struct {
+	*logTreeTX
+	quarantineTX
+}
+
+type historyLogTreeTX struct {
+	*logTreeTX
+	historyTX
+}
+
+type hashesLogTreeTX struct {
+	*logTreeTX
+	historyTX
+	leafHashesTX
+}
+
+type quarantineHistoryLogTreeTX struct {
+	*logTreeTX
+	quarantineTX
+	historyTX
+}
+
+type indexedLogTreeTX struct {
+	*logTreeTX
+	quarantineTX
+	historyTX
+	recompressTX
+	compactionTX
+	timeIndexTX
+	tieringTX
+}
+
+type fullLogTreeTX struct {
+	*logTreeTX
+	quarantineTX
+	historyTX
+	recompressTX
+	compactionTX
+	timeIndexTX
+	tieringTX
+	leafHashesTX
+}
+
+// readOnlyLogTreeTX is an instrumented storage.ReadOnlyLogTreeTX.
+type readOnlyLogTreeTX struct {
+	tx storage.ReadOnlyLogTreeTX
+	r  recorder
+}
+
+func (t *readOnlyLogTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]tree.Node, error) {
+	begin := time.Now()
+	nodes, err := t.tx.GetMerkleNodes(ctx, ids)
+	t.r.doneRows("LogTreeTX.GetMerkleNodes", begin, len(nodes), err)
+	return nodes, err
+}
+
+func (t *readOnlyLogTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	begin := time.Now()
+	rev, err := t.tx.ReadRevision(ctx)
+	t.r.done("LogTreeTX.ReadRevision", begin, err)
+	return rev, err
+}
+
+func (t *readOnlyLogTreeTX) Commit(ctx context.Context) error {
+	begin := time.Now()
+	err := t.tx.Commit(ctx)
+	t.r.done("LogTreeTX.Commit", begin, err)
+	return err
+}
+
+func (t *readOnlyLogTreeTX) Rollback() error {
+	begin := time.Now()
+	err := t.tx.Rollback()
+	t.r.done("LogTreeTX.Rollback", begin, err)
+	return err
+}
+
+func (t *readOnlyLogTreeTX) Close() error {
+	return t.tx.Close()
+}
+
+func (t *readOnlyLogTreeTX) IsOpen() bool {
+	return t.tx.IsOpen()
+}
+
+func (t *readOnlyLogTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	begin := time.Now()
+	count, err := t.tx.GetSequencedLeafCount(ctx)
+	t.r.done("LogTreeTX.GetSequencedLeafCount", begin, err)
+	return count, err
+}
+
+func (t *readOnlyLogTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetLeavesByIndex(ctx, leaves)
+	t.r.doneRows("LogTreeTX.GetLeavesByIndex", begin, len(ret), err)
+	return ret, err
+}
+
+func (t *readOnlyLogTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetLeavesByRange(ctx, start, count)
+	t.r.doneRows("LogTreeTX.GetLeavesByRange", begin, len(ret), err)
+	return ret, err
+}
+
+func (t *readOnlyLogTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetLeavesByHash(ctx, leafHashes, orderBySequence)
+	t.r.doneRows("LogTreeTX.GetLeavesByHash", begin, len(ret), err)
+	return ret, err
+}
+
+func (t *readOnlyLogTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	begin := time.Now()
+	root, err := t.tx.LatestSignedLogRoot(ctx)
+	t.r.done("LogTreeTX.LatestSignedLogRoot", begin, err)
+	return root, err
+}
+
+// logTreeTX is an instrumented storage.LogTreeTX.
+type logTreeTX struct {
+	*readOnlyLogTreeTX
+	wtx storage.LogTreeTX
+}
+
+func (t *logTreeTX) SetMerkleNodes(ctx context.Context, nodes []tree.Node) error {
+	begin := time.Now()
+	err := t.wtx.SetMerkleNodes(ctx, nodes)
+	t.r.done("LogTreeTX.SetMerkleNodes", begin, err)
+	return err
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	begin := time.Now()
+	rev, err := t.wtx.WriteRevision(ctx)
+	t.r.done("LogTreeTX.WriteRevision", begin, err)
+	return rev, err
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	begin := time.Now()
+	err := t.wtx.StoreSignedLogRoot(ctx, root)
+	t.r.done("LogTreeTX.StoreSignedLogRoot", begin, err)
+	return err
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.wtx.DequeueLeaves(ctx, limit, cutoff)
+	t.r.doneRows("LogTreeTX.DequeueLeaves", begin, len(ret), err)
+	return ret, err
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	begin := time.Now()
+	err := t.wtx.UpdateSequencedLeaves(ctx, leaves)
+	t.r.done("LogTreeTX.UpdateSequencedLeaves", begin, err)
+	return err
+}
+
+// quarantineTX instruments the methods of storage.QuarantineLogTreeTX.
+type quarantineTX struct {
+	tx storage.QuarantineLogTreeTX
+	r  recorder
+}
+
+func (t quarantineTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	begin := time.Now()
+	err := t.tx.QuarantineLeaves(ctx, leaves)
+	t.r.done("LogTreeTX.QuarantineLeaves", begin, err)
+	return err
+}
+
+func (t quarantineTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.ListQuarantinedLeaves(ctx)
+	t.r.doneRows("LogTreeTX.ListQuarantinedLeaves", begin, len(ret), err)
+	return ret, err
+}
+
+func (t quarantineTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	begin := time.Now()
+	ret, err := t.tx.RequeueQuarantinedLeaves(ctx, leafIdentityHashes, queueTimestamp)
+	t.r.doneRows("LogTreeTX.RequeueQuarantinedLeaves", begin, len(ret), err)
+	return ret, err
+}
+
+// historyTX instruments the methods of storage.LogRootHistoryTX.
+type historyTX struct {
+	tx storage.LogRootHistoryTX
+	r  recorder
+}
+
+func (t historyTX) GetSignedLogRoots(ctx context.Context, filter storage.LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetSignedLogRoots(ctx, filter, limit)
+	t.r.doneRows("LogTreeTX.GetSignedLogRoots", begin, len(ret), err)
+	return ret, err
+}
+
+// recompressTX instruments the methods of storage.RecompressLogTreeTX.
+type recompressTX struct {
+	tx storage.RecompressLogTreeTX
+	r  recorder
+}
+
+func (t recompressTX) RecompressLeaves(ctx context.Context, after []byte, limit int) ([]byte, int, error) {
+	begin := time.Now()
+	last, n, err := t.tx.RecompressLeaves(ctx, after, limit)
+	t.r.done("LogTreeTX.RecompressLeaves", begin, err)
+	return last, n, err
+}
+
+// compactionTX instruments the methods of storage.SubtreeCompactionTX.
+type compactionTX struct {
+	tx storage.SubtreeCompactionTX
+	r  recorder
+}
+
+func (t compactionTX) RevisionAt(ctx context.Context, ts time.Time) (int64, error) {
+	begin := time.Now()
+	rev, err := t.tx.RevisionAt(ctx, ts)
+	t.r.done("LogTreeTX.RevisionAt", begin, err)
+	return rev, err
+}
+
+func (t compactionTX) CompactSubtrees(ctx context.Context, revision int64, after []byte, limit int) ([]byte, int, error) {
+	begin := time.Now()
+	last, n, err := t.tx.CompactSubtrees(ctx, revision, after, limit)
+	t.r.done("LogTreeTX.CompactSubtrees", begin, err)
+	return last, n, err
+}
+
+// timeIndexTX instruments the methods of storage.LeafTimeIndexTX.
+type timeIndexTX struct {
+	tx storage.LeafTimeIndexTX
+	r  recorder
+}
+
+func (t timeIndexTX) GetLeafIndexByTime(ctx context.Context, ts time.Time, treeSize int64) (int64, error) {
+	begin := time.Now()
+	index, err := t.tx.GetLeafIndexByTime(ctx, ts, treeSize)
+	t.r.done("LogTreeTX.GetLeafIndexByTime", begin, err)
+	return index, err
+}
+
+// tieringTX instruments the methods of storage.TieringLogTreeTX.
+type tieringTX struct {
+	tx storage.TieringLogTreeTX
+	r  recorder
+}
+
+func (t tieringTX) TierLeaves(ctx context.Context, start, end int64) (int, error) {
+	begin := time.Now()
+	n, err := t.tx.TierLeaves(ctx, start, end)
+	t.r.done("LogTreeTX.TierLeaves", begin, err)
+	return n, err
+}
+
+func (t tieringTX) TierTiles(ctx context.Context, treeSize int64, after []byte, limit int) ([]byte, int, error) {
+	begin := time.Now()
+	last, n, err := t.tx.TierTiles(ctx, treeSize, after, limit)
+	t.r.done("LogTreeTX.TierTiles", begin, err)
+	return last, n, err
+}
+
+// leafHashesTX instruments the methods of storage.LeafHashesTX.
+type leafHashesTX struct {
+	tx storage.LeafHashesTX
+	r  recorder
+}
+
+func (t leafHashesTX) GetLeafHashesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetLeafHashesByIndex(ctx, leaves)
+	t.r.doneRows("LogTreeTX.GetLeafHashesByIndex", begin, len(ret), err)
+	return ret, err
+}
+
+func (t leafHashesTX) GetLeafHashesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	begin := time.Now()
+	ret, err := t.tx.GetLeafHashesByRange(ctx, start, count)
+	t.r.doneRows("LogTreeTX.GetLeafHashesByRange", begin, len(ret), err)
+	return ret, err
+}
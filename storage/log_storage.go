@@ -16,6 +16,8 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/trillian"
@@ -101,10 +103,164 @@ type LogTreeTX interface {
 	DequeueLeaves(ctx context.Context, limit int, cutoff time.Time) ([]*trillian.LogLeaf, error)
 
 	// UpdateSequencedLeaves associates the leaves with the sequence numbers
-	// assigned to them.
+	// assigned to them. If the update fails because of a particular leaf, the
+	// returned error is a *LeafError identifying it.
 	UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
 }
 
+// LeafError is returned when a batch of leaves is rejected because of one
+// particular leaf, e.g. because it is malformed. The sequencer quarantines the
+// leaf and retries the rest of the batch.
+type LeafError struct {
+	// LeafIdentityHash identifies the offending leaf.
+	LeafIdentityHash []byte
+	// Err describes what is wrong with the leaf.
+	Err error
+}
+
+func (e *LeafError) Error() string {
+	return fmt.Sprintf("leaf %x: %v", e.LeafIdentityHash, e.Err)
+}
+
+// Unwrap returns the error describing what is wrong with the leaf.
+func (e *LeafError) Unwrap() error {
+	return e.Err
+}
+
+// QuarantineLogTreeTX is implemented by LogTreeTX implementations which can
+// set aside dequeued leaves that fail to be integrated, so that a bad leaf
+// doesn't prevent the rest of the queue from being integrated.
+type QuarantineLogTreeTX interface {
+	LogTreeTX
+
+	// QuarantineLeaves records the given leaves, which must have been returned
+	// by DequeueLeaves in this transaction, as quarantined.
+	//
+	// For LOG trees, the leaves are also removed from the queue. For
+	// PREORDERED_LOG trees they are only recorded, as the sequenced entries
+	// can't be removed without leaving a gap in the log.
+	//
+	// Quarantining a leaf which is already quarantined replaces its record.
+	QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error
+
+	// ListQuarantinedLeaves returns the quarantined leaves of the tree, ordered
+	// by their quarantine timestamp.
+	ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error)
+
+	// RequeueQuarantinedLeaves releases the quarantined leaves with the given
+	// identity hashes. For LOG trees, the leaves are queued again with the
+	// given queue timestamp; for PREORDERED_LOG trees the sequencer simply
+	// attempts to integrate them again. Returns the identity hashes of the
+	// released leaves, omitting those which weren't quarantined.
+	RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error)
+}
+
+// RecompressLogTreeTX is implemented by LogTreeTX implementations which store
+// leaf data compressed according to the tree's LeafCompression settings, and
+// can rewrite existing leaf data after those settings have changed.
+type RecompressLogTreeTX interface {
+	LogTreeTX
+
+	// RecompressLeaves re-encodes the stored data of up to limit leaves, in
+	// order of their identity hash and starting after the given one, so that
+	// it matches the current settings of the tree. It returns the identity
+	// hash of the last leaf visited, or nil if there are no more leaves, and
+	// the number of leaves which were rewritten. Leaves whose data was moved
+	// to cold storage by TieringLogTreeTX are not visited.
+	RecompressLeaves(ctx context.Context, after []byte, limit int) ([]byte, int, error)
+}
+
+// SubtreeCompactionTX is implemented by LogTreeTX implementations which store
+// a new row for each revision of a subtree, and can delete the rows which are
+// superseded by later ones.
+type SubtreeCompactionTX interface {
+	LogTreeTX
+
+	// RevisionAt returns the revision of the latest signed root with a
+	// timestamp at or before ts, or -1 if there is no such root.
+	RevisionAt(ctx context.Context, ts time.Time) (int64, error)
+	// CompactSubtrees deletes the rows of up to limit subtrees, in order of
+	// subtree ID and starting after the given one, which are superseded by a
+	// later row with a revision at or before the given revision. The tree can
+	// still be read at that revision and any later one. It returns the ID of
+	// the last subtree visited, or nil if there are no more subtrees, and the
+	// number of rows which were deleted.
+	CompactSubtrees(ctx context.Context, revision int64, after []byte, limit int) ([]byte, int, error)
+}
+
+// LogRootFilter selects stored signed log roots by ranges of their revision,
+// tree size and timestamp. Each range includes its begin and excludes its end,
+// and an end of zero means that the range has no upper bound.
+type LogRootFilter struct {
+	RevisionBegin, RevisionEnd   int64
+	TreeSizeBegin, TreeSizeEnd   int64
+	TimestampBegin, TimestampEnd int64 // In nanoseconds since the Unix epoch.
+}
+
+// Bounded returns a copy of the filter with each end of zero replaced by
+// math.MaxInt64, for use in queries.
+func (f LogRootFilter) Bounded() LogRootFilter {
+	for _, end := range []*int64{&f.RevisionEnd, &f.TreeSizeEnd, &f.TimestampEnd} {
+		if *end == 0 {
+			*end = math.MaxInt64
+		}
+	}
+	return f
+}
+
+// LogRootHistoryTX is implemented by ReadOnlyLogTreeTX implementations which
+// can read the signed roots stored before the latest one.
+type LogRootHistoryTX interface {
+	ReadOnlyLogTreeTX
+
+	// GetSignedLogRoots returns up to limit of the stored signed roots of the
+	// tree which match the filter, in order of revision.
+	GetSignedLogRoots(ctx context.Context, filter LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error)
+}
+
+// LeafTimeIndexTX is implemented by ReadOnlyLogTreeTX implementations which
+// index sequenced leaves by their integrate timestamp.
+type LeafTimeIndexTX interface {
+	ReadOnlyLogTreeTX
+
+	// GetLeafIndexByTime returns the lowest index below treeSize of a leaf
+	// which was integrated at or after ts, or treeSize if there is no such
+	// leaf. Leaves are assumed to be integrated in order of their index, so
+	// that their integrate timestamps never decrease.
+	GetLeafIndexByTime(ctx context.Context, ts time.Time, treeSize int64) (int64, error)
+}
+
+// LeafHashesTX is implemented by ReadOnlyLogTreeTX implementations which can
+// read the Merkle leaf hashes of sequenced leaves without their data.
+type LeafHashesTX interface {
+	ReadOnlyLogTreeTX
+
+	// GetLeafHashesByIndex is like GetLeavesByIndex, but only populates the
+	// MerkleLeafHash and LeafIndex fields of the returned leaves.
+	GetLeafHashesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error)
+	// GetLeafHashesByRange is like GetLeavesByRange, but only populates the
+	// MerkleLeafHash and LeafIndex fields of the returned leaves.
+	GetLeafHashesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error)
+}
+
+// TieringLogTreeTX is implemented by LogTreeTX implementations which can move
+// the data of old leaves and complete log tiles out to a cold object store,
+// and read them back from there transparently.
+type TieringLogTreeTX interface {
+	LogTreeTX
+
+	// TierLeaves moves the data of the sequenced leaves with indices in
+	// [start, end) to the cold store, and returns the number of leaves moved.
+	// Leaves which are already tiered are skipped.
+	TierLeaves(ctx context.Context, start, end int64) (int, error)
+	// TierTiles moves up to limit log tiles, in order of subtree ID and
+	// starting after the given one, to the cold store. Only the latest
+	// revision of each tile is moved, and only if all the leaves it covers
+	// are below treeSize. It returns the ID of the last subtree visited, or
+	// nil if there are no more subtrees, and the number of tiles moved.
+	TierTiles(ctx context.Context, treeSize int64, after []byte, limit int) ([]byte, int, error)
+}
+
 // ReadOnlyLogStorage represents a narrowed read-only view into a LogStorage.
 type ReadOnlyLogStorage interface {
 	DatabaseChecker
@@ -105,6 +105,9 @@ func (t *adminTX) Close() error {
 }
 
 func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	if err := t.ms.syncWAL(); err != nil {
+		return nil, err
+	}
 	tree := t.ms.getTree(treeID)
 	if tree == nil {
 		return nil, fmt.Errorf("no such treeID %d", treeID)
@@ -116,6 +119,9 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 }
 
 func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	if err := t.ms.syncWAL(); err != nil {
+		return nil, err
+	}
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
@@ -127,6 +133,9 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 }
 
 func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.ms.syncWAL(); err != nil {
+		return nil, err
+	}
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
@@ -163,6 +172,14 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 		return nil, err
 	}
 
+	if err := t.ms.lockWAL(); err != nil {
+		return nil, err
+	}
+	defer t.ms.unlockWAL()
+	if err := t.ms.logTree(meta); err != nil {
+		return nil, err
+	}
+
 	t.ms.mu.Lock()
 	defer t.ms.mu.Unlock()
 	t.ms.trees[id] = newTree(meta)
@@ -173,12 +190,20 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 }
 
 func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	if err := t.ms.lockWAL(); err != nil {
+		return nil, err
+	}
+	defer t.ms.unlockWAL()
+
 	mTree := t.ms.getTree(treeID)
+	if mTree == nil {
+		return nil, fmt.Errorf("no such treeID %d", treeID)
+	}
 	mTree.mu.Lock()
 	defer mTree.mu.Unlock()
 
-	tree := mTree.meta
-	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	beforeUpdate := mTree.meta
+	tree := proto.Clone(beforeUpdate).(*trillian.Tree)
 	updateFunc(tree)
 	if err := storage.ValidateTreeForUpdate(ctx, beforeUpdate, tree); err != nil {
 		return nil, err
@@ -192,6 +217,10 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 	if err != nil {
 		return nil, err
 	}
+	if err := t.ms.logTree(tree); err != nil {
+		return nil, err
+	}
+	mTree.meta = tree
 	return tree, nil
 }
 
@@ -30,4 +30,9 @@
 // rolled-back.
 //
 // Currently, the Admin Storage does not honor transactional semantics.
+//
+// Optionally, the committed transactions can be persisted in a directory (see
+// NewPersistentTreeStorage), by appending them to a write-ahead log which is
+// periodically compacted into a snapshot, and replayed on startup. Processes
+// on the same host can share the directory, e.g. a log server and signer.
 package memory
@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/btree"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+// GetSignedLogRoots implements storage.LogRootHistoryTX.
+func (t *logTreeTX) GetSignedLogRoots(ctx context.Context, filter storage.LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error) {
+	type entry struct {
+		revision uint64
+		slr      *trillian.SignedLogRoot
+	}
+	f := filter.Bounded()
+	prefix := fmt.Sprintf("/%d/sth/", t.treeID)
+	var entries []entry
+	var err error
+	t.tx.AscendGreaterOrEqual(&kv{k: prefix}, func(i btree.Item) bool {
+		if !strings.HasPrefix(i.(*kv).k, prefix) {
+			return false
+		}
+		slr := i.(*kv).v.(*trillian.SignedLogRoot)
+		var root types.LogRootV1
+		if err = root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return false
+		}
+		rev, size, ts := int64(root.Revision), int64(root.TreeSize), int64(root.TimestampNanos)
+		if rev >= f.RevisionBegin && rev < f.RevisionEnd &&
+			size >= f.TreeSizeBegin && size < f.TreeSizeEnd &&
+			ts >= f.TimestampBegin && ts < f.TimestampEnd {
+			entries = append(entries, entry{revision: root.Revision, slr: slr})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Roots are keyed by timestamp, so order them by revision here.
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].revision < entries[j].revision })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	ret := make([]*trillian.SignedLogRoot, 0, len(entries))
+	for _, e := range entries {
+		ret = append(ret, e.slr)
+	}
+	return ret, nil
+}
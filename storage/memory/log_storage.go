@@ -20,10 +20,14 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/btree"
 	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/compact"
@@ -62,6 +66,12 @@ func unseqKey(treeID int64) btree.Item {
 	return &kv{k: fmt.Sprintf("/%d/unseq", treeID)}
 }
 
+// queuedLeaf is an entry of a tree's unsequenced queue.
+type queuedLeaf struct {
+	leaf      *trillian.LogLeaf
+	submitter string
+}
+
 // seqLeafKey formats a key for use in a tree's BTree store.
 // The associated Item value will be the leaf at the given sequence number.
 func seqLeafKey(treeID, seq int64) btree.Item {
@@ -75,6 +85,18 @@ func hashToSeqKey(treeID int64) btree.Item {
 	return &kv{k: fmt.Sprintf("/%d/h2s", treeID)}
 }
 
+// quarantineKeyPrefix returns the prefix of the keys returned by quarantineKey.
+func quarantineKeyPrefix(treeID int64) string {
+	return fmt.Sprintf("/%d/quarantine/", treeID)
+}
+
+// quarantineKey formats a key for use in a tree's BTree store.
+// The associated Item value will be the quarantined leaf with the given
+// identity hash.
+func quarantineKey(treeID int64, leafIDHash []byte) btree.Item {
+	return &kv{k: fmt.Sprintf("%s%x", quarantineKeyPrefix(treeID), leafIDHash)}
+}
+
 // sthKey formats a key for use in a tree's BTree store.
 // The associated Item value will be the STH with the given timestamp.
 func sthKey(treeID int64, timestamp uint64) btree.Item {
@@ -104,16 +126,23 @@ func getActiveLogIDs(trees map[int64]*tree) []int64 {
 type memoryLogStorage struct {
 	*TreeStorage
 	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
 }
 
 // NewLogStorage creates an in-memory LogStorage instance.
 func NewLogStorage(ts *TreeStorage, mf monitoring.MetricFactory) storage.LogStorage {
+	return newLogStorage(ts, mf, nil)
+}
+
+func newLogStorage(ts *TreeStorage, mf monitoring.MetricFactory, weights storage.SubmitterWeights) *memoryLogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
 	ret := &memoryLogStorage{
-		TreeStorage:   ts,
-		metricFactory: mf,
+		TreeStorage:      ts,
+		metricFactory:    mf,
+		submitterWeights: weights,
 	}
 	return ret
 }
@@ -143,6 +172,9 @@ func (t *readOnlyLogTX) Close() error {
 }
 
 func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	if err := t.ms.syncWAL(); err != nil {
+		return nil, err
+	}
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
@@ -165,8 +197,10 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, tree *trillian.Tre
 	}
 
 	ltx := &logTreeTX{
-		treeTX: ttx,
-		ls:     m,
+		treeTX:       ttx,
+		ls:           m,
+		treeType:     tree.TreeType,
+		dequeueOrder: tree.DequeueOrder,
 	}
 
 	ltx.slr, err = ltx.fetchLatestRoot(ctx)
@@ -247,9 +281,50 @@ func (m *memoryLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree,
 
 type logTreeTX struct {
 	treeTX
-	ls   *memoryLogStorage
-	root types.LogRootV1
-	slr  *trillian.SignedLogRoot
+	ls           *memoryLogStorage
+	root         types.LogRootV1
+	slr          *trillian.SignedLogRoot
+	treeType     trillian.TreeType
+	dequeueOrder trillian.DequeueOrder
+	// queueCopied and indexCopied are whether the TX has its own copy of the
+	// unsequenced queue and of the Merkle leaf hash index of the tree.
+	queueCopied bool
+	indexCopied bool
+}
+
+// writableQueue returns the unsequenced queue of the tree for modification.
+// The queue is copied on first use, as the TX only has a shallow copy of the
+// store, so that the changes are discarded if the TX is rolled back.
+func (t *logTreeTX) writableQueue() *list.List {
+	k := unseqKey(t.treeID)
+	q := t.tx.Get(k).(*kv).v.(*list.List)
+	if t.queueCopied {
+		return q
+	}
+	cp := list.New()
+	cp.PushBackList(q)
+	k.(*kv).v = cp
+	t.tx.ReplaceOrInsert(k)
+	t.queueCopied = true
+	return cp
+}
+
+// writableIndex returns the Merkle leaf hash index of the tree for
+// modification, copying it on first use like writableQueue.
+func (t *logTreeTX) writableIndex() map[string][]int64 {
+	k := hashToSeqKey(t.treeID)
+	m := t.tx.Get(k).(*kv).v.(map[string][]int64)
+	if t.indexCopied {
+		return m
+	}
+	cp := make(map[string][]int64, len(m))
+	for h, seqs := range m {
+		cp[h] = seqs
+	}
+	k.(*kv).v = cp
+	t.tx.ReplaceOrInsert(k)
+	t.indexCopied = true
+	return cp
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -270,14 +345,27 @@ func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([
 }
 
 func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
-	leaves := make([]*trillian.LogLeaf, 0, limit)
-
 	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
-	e := q.Front()
-	for i := 0; i < limit && e != nil; i++ {
-		// TODO(al): consider cutoffTime
-		leaves = append(leaves, e.Value.(*trillian.LogLeaf))
-		e = e.Next()
+	var leaves []*trillian.LogLeaf
+	if t.dequeueOrder == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		// The queue is in queue order, so each submitter's queue will be too.
+		queues := make(map[string][]*trillian.LogLeaf)
+		for e := q.Front(); e != nil; e = e.Next() {
+			// TODO(al): consider cutoffTime
+			ql := e.Value.(*queuedLeaf)
+			if len(queues[ql.submitter]) < limit {
+				queues[ql.submitter] = append(queues[ql.submitter], ql.leaf)
+			}
+		}
+		leaves = storage.FairShare(queues, t.ls.submitterWeights.ForTree(t.treeID), limit)
+	} else {
+		leaves = make([]*trillian.LogLeaf, 0, limit)
+		e := q.Front()
+		for i := 0; i < limit && e != nil; i++ {
+			// TODO(al): consider cutoffTime
+			leaves = append(leaves, e.Value.(*queuedLeaf).leaf)
+			e = e.Next()
+		}
 	}
 
 	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
@@ -293,10 +381,13 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	}
 	queuedCounter.Add(float64(len(leaves)), labelForTX(t))
 	// No deduping in this storage!
-	k := unseqKey(t.treeID)
-	q := t.tx.Get(k).(*kv).v.(*list.List)
+	q := t.writableQueue()
+	submitter := storage.SubmitterFromContext(ctx)
 	for _, l := range leaves {
-		q.PushBack(l)
+		q.PushBack(&queuedLeaf{leaf: l, submitter: submitter})
+		if err := t.logEnqueue(l, submitter); err != nil {
+			return nil, err
+		}
 	}
 	return make([]*trillian.LogLeaf, len(leaves)), nil
 }
@@ -363,7 +454,7 @@ func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLo
 
 // fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
 func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
-	r := t.tx.Get(sthKey(t.treeID, t.tree.currentSTH))
+	r := t.tx.Get(sthKey(t.treeID, t.currentSTH))
 	if r == nil {
 		return nil, storage.ErrTreeNeedsInit
 	}
@@ -378,10 +469,12 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, slr *trillian.Signed
 	k := sthKey(t.treeID, root.TimestampNanos)
 	k.(*kv).v = slr
 	t.tx.ReplaceOrInsert(k)
+	if err := t.logPut(k, slr); err != nil {
+		return err
+	}
 
-	// TODO(alcutter): this breaks the transactional model
-	if root.TimestampNanos > t.tree.currentSTH {
-		t.tree.currentSTH = root.TimestampNanos
+	if root.TimestampNanos > t.currentSTH {
+		t.currentSTH = root.TimestampNanos
 	}
 	return nil
 }
@@ -391,7 +484,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	for _, leaf := range leaves {
 		// This should fail on insert but catch it early
 		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
-			return fmt.Errorf("sequenced leaf has incorrect hash size: got %v, want %v", got, want)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("sequenced leaf has incorrect hash size: got %v, want %v", got, want)}
 		}
 		mh := string(leaf.MerkleLeafHash)
 		countByMerkleHash[mh]++
@@ -399,17 +492,21 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 		k := seqLeafKey(t.treeID, leaf.LeafIndex)
 		k.(*kv).v = leaf
 		t.tx.ReplaceOrInsert(k)
+		if err := t.logPut(k, leaf); err != nil {
+			return err
+		}
 		// update merkle-to-seq mapping:
-		m := t.tx.Get(hashToSeqKey(t.treeID))
-		l := m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)]
-		l = append(l, leaf.LeafIndex)
-		m.(*kv).v.(map[string][]int64)[string(leaf.MerkleLeafHash)] = l
+		m := t.writableIndex()
+		m[mh] = append(m[mh], leaf.LeafIndex)
+		if err := t.logIndex(leaf.MerkleLeafHash, leaf.LeafIndex); err != nil {
+			return err
+		}
 	}
 
-	q := t.tx.Get(unseqKey(t.treeID)).(*kv).v.(*list.List)
+	q := t.writableQueue()
 	toRemove := make([]*list.Element, 0, q.Len())
 	for e := q.Front(); e != nil && len(countByMerkleHash) > 0; e = e.Next() {
-		h := e.Value.(*trillian.LogLeaf).MerkleLeafHash
+		h := e.Value.(*queuedLeaf).leaf.MerkleLeafHash
 		mh := string(h)
 		if countByMerkleHash[mh] > 0 {
 			countByMerkleHash[mh]--
@@ -421,6 +518,9 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	}
 	for _, e := range toRemove {
 		q.Remove(e)
+		if err := t.logUnqueue(e.Value.(*queuedLeaf).leaf); err != nil {
+			return err
+		}
 	}
 
 	if unknown := len(countByMerkleHash); unknown != 0 {
@@ -433,3 +533,83 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 func (t *logTreeTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	return getActiveLogIDs(t.ts.trees), nil
 }
+
+func (t *logTreeTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	countByIDHash := make(map[string]int)
+	for _, ql := range leaves {
+		k := quarantineKey(t.treeID, ql.Leaf.LeafIdentityHash)
+		k.(*kv).v = ql
+		t.tx.ReplaceOrInsert(k)
+		if err := t.logPut(k, ql); err != nil {
+			return err
+		}
+		countByIDHash[string(ql.Leaf.LeafIdentityHash)]++
+	}
+
+	q := t.writableQueue()
+	for e := q.Front(); e != nil && len(countByIDHash) > 0; {
+		next := e.Next()
+		leaf := e.Value.(*queuedLeaf).leaf
+		id := string(leaf.LeafIdentityHash)
+		if countByIDHash[id] > 0 {
+			q.Remove(e)
+			if err := t.logUnqueue(leaf); err != nil {
+				return err
+			}
+			if countByIDHash[id]--; countByIDHash[id] == 0 {
+				delete(countByIDHash, id)
+			}
+		}
+		e = next
+	}
+	return nil
+}
+
+func (t *logTreeTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	prefix := quarantineKeyPrefix(t.treeID)
+	var ret []*trillian.QuarantinedLeaf
+	t.tx.AscendGreaterOrEqual(&kv{k: prefix}, func(i btree.Item) bool {
+		if !strings.HasPrefix(i.(*kv).k, prefix) {
+			return false
+		}
+		ret = append(ret, i.(*kv).v.(*trillian.QuarantinedLeaf))
+		return true
+	})
+	sort.SliceStable(ret, func(i, j int) bool {
+		ti, tj := ret[i].QuarantineTimestamp, ret[j].QuarantineTimestamp
+		if ti.GetSeconds() != tj.GetSeconds() {
+			return ti.GetSeconds() < tj.GetSeconds()
+		}
+		return ti.GetNanos() < tj.GetNanos()
+	})
+	return ret, nil
+}
+
+func (t *logTreeTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	queueTS, err := ptypes.TimestampProto(queueTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	var ret [][]byte
+	for _, id := range leafIdentityHashes {
+		k := quarantineKey(t.treeID, id)
+		item := t.tx.Delete(k)
+		if item == nil {
+			continue
+		}
+		if err := t.logDelete(k); err != nil {
+			return nil, err
+		}
+		if t.treeType != trillian.TreeType_PREORDERED_LOG {
+			leaf := proto.Clone(item.(*kv).v.(*trillian.QuarantinedLeaf).Leaf).(*trillian.LogLeaf)
+			leaf.LeafIndex = 0
+			leaf.QueueTimestamp = queueTS
+			t.writableQueue().PushBack(&queuedLeaf{leaf: leaf})
+			if err := t.logEnqueue(leaf, ""); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
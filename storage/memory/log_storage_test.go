@@ -0,0 +1,75 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/storage"
+)
+
+func TestReadWriteTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	leaves := makeLeaves(4)
+	errRollback := errors.New("rollback")
+
+	for _, tc := range []struct {
+		desc string
+		f    storage.LogTXFunc
+	}{
+		{
+			desc: "sequence",
+			f: func(ctx context.Context, tx storage.LogTreeTX) error {
+				dequeued, err := tx.DequeueLeaves(ctx, 10, time.Now())
+				if err != nil {
+					return err
+				}
+				for i, leaf := range dequeued {
+					leaf.LeafIndex = int64(2 + i)
+				}
+				if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+					return err
+				}
+				if err := storeRoot(ctx, tx, 3, 2); err != nil {
+					return err
+				}
+				return errRollback
+			},
+		},
+		{
+			desc: "queue",
+			f: func(ctx context.Context, tx storage.LogTreeTX) error {
+				if _, err := tx.(*logTreeTX).QueueLeaves(ctx, leaves[3:], time.Now()); err != nil {
+					return err
+				}
+				return errRollback
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := NewTreeStorage()
+			tree := populate(ctx, t, ts, leaves[:3])
+
+			ls := NewLogStorage(ts, nil)
+			if err := ls.ReadWriteTransaction(ctx, tree, tc.f); !errors.Is(err, errRollback) {
+				t.Fatalf("ReadWriteTransaction()=%v, want %v", err, errRollback)
+			}
+			checkPopulated(ctx, t, ts, tree, leaves[:3])
+		})
+	}
+}
@@ -15,31 +15,47 @@
 package memory
 
 import (
+	"flag"
+
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 )
 
+var (
+	persistDir       = flag.String("memory_persist_dir", "", "If set, the directory in which the memory storage provider persists its trees, so that they survive restarts and can be shared by the processes on a host")
+	snapshotInterval = flag.Int("memory_snapshot_interval", 1000, "Number of transactions in the memory storage write-ahead log after which it's compacted into a snapshot, or 0 to never compact it")
+)
+
 func init() {
-	if err := storage.RegisterProvider("memory", newMemoryStorageProvider); err != nil {
+	if err := storage.RegisterProviderWithOptions("memory", newMemoryStorageProvider); err != nil {
 		glog.Fatalf("Failed to register storage provider memory: %v", err)
 	}
 }
 
 type memProvider struct {
-	mf monitoring.MetricFactory
-	ts *TreeStorage
+	mf      monitoring.MetricFactory
+	ts      *TreeStorage
+	weights storage.SubmitterWeights
 }
 
-func newMemoryStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+func newMemoryStorageProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
+	ts := NewTreeStorage()
+	if *persistDir != "" {
+		var err error
+		if ts, err = NewPersistentTreeStorage(*persistDir, *snapshotInterval); err != nil {
+			return nil, err
+		}
+	}
 	return &memProvider{
-		mf: mf,
-		ts: NewTreeStorage(),
+		mf:      mf,
+		ts:      ts,
+		weights: opts.SubmitterWeights,
 	}, nil
 }
 
 func (s *memProvider) LogStorage() storage.LogStorage {
-	return NewLogStorage(s.ts, s.mf)
+	return newLogStorage(s.ts, s.mf, s.weights)
 }
 
 func (s *memProvider) AdminStorage() storage.AdminStorage {
@@ -47,5 +63,5 @@ func (s *memProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *memProvider) Close() error {
-	return nil
+	return s.ts.Close()
 }
@@ -28,6 +28,8 @@ import (
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/storagepb"
 	stree "github.com/google/trillian/storage/tree"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const degree = 8
@@ -59,6 +61,12 @@ type tree struct {
 	// currentSTH is the timestamp of the current STH.
 	currentSTH uint64
 	meta       *trillian.Tree
+	// version is incremented whenever the transactions of another process are
+	// applied to store, so that the concurrent writable TXs can be aborted.
+	version int64
+	// writer serializes the writable TXs of the tree. Unlike mu, it's held for
+	// the duration of the TX.
+	writer sync.Mutex
 }
 
 func (t *tree) Lock() {
@@ -83,6 +91,9 @@ type TreeStorage struct {
 	// mu only protects access to the trees map.
 	mu    sync.RWMutex
 	trees map[int64]*tree
+	// wal persists the committed transactions, or is nil if the storage is
+	// not persistent.
+	wal *wal
 }
 
 // NewTreeStorage returns a new instance of the in-memory tree storage database.
@@ -92,6 +103,114 @@ func NewTreeStorage() *TreeStorage {
 	}
 }
 
+// NewPersistentTreeStorage returns a new instance of the in-memory tree
+// storage database, whose committed transactions are persisted in dir.
+// The trees already persisted in dir are loaded, and the WAL is compacted into
+// a snapshot every snapshotInterval transactions (never, if it's 0).
+//
+// Processes on the same host can share dir, as each transaction starts by
+// replaying the transactions committed by the others.
+func NewPersistentTreeStorage(dir string, snapshotInterval int) (*TreeStorage, error) {
+	w, err := openWAL(dir, snapshotInterval)
+	if err != nil {
+		return nil, err
+	}
+	m := NewTreeStorage()
+	m.wal = w
+	if err := m.syncWAL(); err != nil {
+		w.close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Close releases the files of a persistent tree storage.
+func (m *TreeStorage) Close() error {
+	if m.wal == nil {
+		return nil
+	}
+	return m.wal.close()
+}
+
+// syncWAL applies the transactions committed by other processes to the trees,
+// if the storage is persistent.
+func (m *TreeStorage) syncWAL() error {
+	if m.wal == nil {
+		return nil
+	}
+	if err := m.wal.lock(false /* exclusive */); err != nil {
+		return err
+	}
+	defer m.wal.unlock()
+	return m.wal.catchUp(m, false /* writable */)
+}
+
+// lockWAL is like syncWAL, but keeps the WAL locked so that a transaction can
+// be appended to it, until unlockWAL is called.
+func (m *TreeStorage) lockWAL() error {
+	if m.wal == nil {
+		return nil
+	}
+	if err := m.wal.lock(true /* exclusive */); err != nil {
+		return err
+	}
+	if err := m.wal.catchUp(m, true /* writable */); err != nil {
+		m.wal.unlock()
+		return err
+	}
+	return nil
+}
+
+// unlockWAL releases the WAL locked by lockWAL, after compacting it if it's
+// due a snapshot.
+func (m *TreeStorage) unlockWAL() {
+	if m.wal == nil {
+		return
+	}
+	if err := m.wal.maybeSnapshot(m); err != nil {
+		glog.Warningf("Failed to snapshot WAL: %v", err)
+	}
+	m.wal.unlock()
+}
+
+// commitTX appends the mutations of the writable TX t to the WAL, if the
+// storage is persistent, and publishes them to the tree. The WAL is only
+// locked for the duration of the commit, so the TX fails if the transactions
+// of another process have modified the tree since it began.
+func (m *TreeStorage) commitTX(t *treeTX) error {
+	if err := m.lockWAL(); err != nil {
+		return err
+	}
+	defer m.unlockWAL()
+
+	t.tree.Lock()
+	defer t.tree.Unlock()
+	if t.tree.version != t.version {
+		return status.Errorf(codes.Aborted, "tree %d was modified by another process during the TX", t.treeID)
+	}
+	if len(t.ops) > 0 {
+		if err := m.wal.append(&walRecord{TreeID: t.treeID, Ops: t.ops}); err != nil {
+			return err
+		}
+	}
+	t.tree.store = t.tx
+	t.tree.currentSTH = t.currentSTH
+	return nil
+}
+
+// logTree appends the creation or update of the tree described by meta to the
+// WAL, which must be locked by lockWAL, if the storage is persistent.
+func (m *TreeStorage) logTree(meta *trillian.Tree) error {
+	if m.wal == nil {
+		return nil
+	}
+	op, err := treeOp(meta)
+	if err != nil {
+		return err
+	}
+	return m.wal.append(&walRecord{TreeID: meta.TreeId, Ops: []walOp{op}})
+}
+
 // getTree returns the tree associated with id, or nil if no such tree exists.
 func (m *TreeStorage) getTree(id int64) *tree {
 	m.mu.RLock()
@@ -128,16 +247,25 @@ func newTree(t *trillian.Tree) *tree {
 }
 
 func (m *TreeStorage) beginTreeTX(ctx context.Context, treeID int64, hashSizeBytes int, cache *cache.SubtreeCache, readonly bool) (treeTX, error) {
+	if err := m.syncWAL(); err != nil {
+		return treeTX{}, err
+	}
 	tree := m.getTree(treeID)
-	// Lock the tree for the duration of the TX.
-	// It will be unlocked by a call to Commit or Rollback.
+	if tree == nil {
+		return treeTX{}, fmt.Errorf("no such treeID %d", treeID)
+	}
+	// Read-only TXs lock the tree for their duration, while writable TXs only
+	// exclude each other, and lock the tree and the WAL when they commit.
+	// The locks held for the TX are released by a call to Commit or Rollback.
 	var unlock func()
 	if readonly {
 		tree.RLock()
 		unlock = tree.RUnlock
 	} else {
-		tree.Lock()
-		unlock = tree.Unlock
+		tree.writer.Lock()
+		unlock = tree.writer.Unlock
+		tree.RLock()
+		defer tree.RUnlock()
 	}
 	return treeTX{
 		ts:            m,
@@ -147,6 +275,9 @@ func (m *TreeStorage) beginTreeTX(ctx context.Context, treeID int64, hashSizeByt
 		hashSizeBytes: hashSizeBytes,
 		subtreeCache:  cache,
 		writeRevision: -1,
+		currentSTH:    tree.currentSTH,
+		writable:      !readonly,
+		version:       tree.version,
 		unlock:        unlock,
 	}, nil
 }
@@ -160,7 +291,59 @@ type treeTX struct {
 	hashSizeBytes int
 	subtreeCache  *cache.SubtreeCache
 	writeRevision int64
-	unlock        func()
+	// currentSTH is the timestamp of the current STH as seen by the TX, which
+	// becomes that of the tree on commit.
+	currentSTH uint64
+	// writable is whether the TX publishes its changes to the tree on commit.
+	writable bool
+	// version is the version of the tree when the TX began.
+	version int64
+	unlock  func()
+	// ops are the mutations made by the TX, which are appended to the WAL on
+	// commit if the storage is persistent.
+	ops []walOp
+}
+
+// logOp records the op returned by f, if the storage is persistent.
+func (t *treeTX) logOp(f func() (walOp, error)) error {
+	if t.ts.wal == nil {
+		return nil
+	}
+	op, err := f()
+	if err != nil {
+		return err
+	}
+	t.ops = append(t.ops, op)
+	return nil
+}
+
+// logPut records the storing of v under k.
+func (t *treeTX) logPut(k btree.Item, v proto.Message) error {
+	return t.logOp(func() (walOp, error) { return putOp(k, v) })
+}
+
+// logDelete records the deletion of k.
+func (t *treeTX) logDelete(k btree.Item) error {
+	return t.logOp(func() (walOp, error) { return walOp{Kind: opDelete, Key: k.(*kv).k}, nil })
+}
+
+// logEnqueue records the queueing of leaf by submitter.
+func (t *treeTX) logEnqueue(leaf *trillian.LogLeaf, submitter string) error {
+	return t.logOp(func() (walOp, error) { return enqueueOp(leaf, submitter) })
+}
+
+// logUnqueue records the removal of leaf from the unsequenced queue.
+func (t *treeTX) logUnqueue(leaf *trillian.LogLeaf) error {
+	return t.logOp(func() (walOp, error) {
+		return walOp{Kind: opUnqueue, LeafIDHash: leaf.LeafIdentityHash, MerkleLeafHash: leaf.MerkleLeafHash}, nil
+	})
+}
+
+// logIndex records the mapping of merkleLeafHash to seq.
+func (t *treeTX) logIndex(merkleLeafHash []byte, seq int64) error {
+	return t.logOp(func() (walOp, error) {
+		return walOp{Kind: opIndex, MerkleLeafHash: merkleLeafHash, Index: seq}, nil
+	})
 }
 
 func (t *treeTX) getSubtree(ctx context.Context, treeRevision int64, nodeID stree.NodeID) (*storagepb.SubtreeProto, error) {
@@ -223,6 +406,9 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 		k := subtreeKey(t.treeID, t.writeRevision, stree.NewNodeIDFromHash(s.Prefix))
 		k.(*kv).v = s
 		t.tx.ReplaceOrInsert(k)
+		if err := t.logPut(k, s); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -249,6 +435,7 @@ func (t *treeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
 
 func (t *treeTX) Commit(ctx context.Context) error {
 	defer t.unlock()
+	t.closed = true
 
 	if t.writeRevision > -1 {
 		if err := t.subtreeCache.Flush(ctx, func(ctx context.Context, st []*storagepb.SubtreeProto) error {
@@ -258,9 +445,13 @@ func (t *treeTX) Commit(ctx context.Context) error {
 			return err
 		}
 	}
-	t.closed = true
-	// update the shared view of the tree post TX:
-	t.tree.store = t.tx
+	if t.writable {
+		// update the shared view of the tree post TX:
+		if err := t.ts.commitTX(t); err != nil {
+			glog.Warningf("TX commit error: %v", err)
+			return err
+		}
+	}
 	return nil
 }
 
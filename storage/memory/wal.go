@@ -0,0 +1,698 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/btree"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+)
+
+const (
+	walFileName      = "wal"
+	snapshotFileName = "snapshot"
+	lockFileName     = "lock"
+
+	// recordHeaderSize is the size of the length and CRC-32 which precede
+	// each record in the WAL and snapshot files.
+	recordHeaderSize = 8
+)
+
+// errTornRecord is returned when reading a record which was only partially
+// written, e.g. because its writer crashed.
+var errTornRecord = errors.New("torn record")
+
+// opKind identifies the mutation of a tree made by a walOp.
+type opKind string
+
+const (
+	// opTree creates a tree, or updates its metadata, to the Tree in Value.
+	opTree opKind = "tree"
+	// opPut stores the proto in Value under Key.
+	opPut opKind = "put"
+	// opDelete deletes Key.
+	opDelete opKind = "delete"
+	// opEnqueue appends the LogLeaf in Value to the unsequenced queue.
+	opEnqueue opKind = "enqueue"
+	// opUnqueue removes the first leaf with the given hashes from the
+	// unsequenced queue.
+	opUnqueue opKind = "unqueue"
+	// opIndex adds Index to the sequence numbers of MerkleLeafHash.
+	opIndex opKind = "index"
+)
+
+// walOp is a single mutation of a tree, as recorded in the WAL.
+type walOp struct {
+	Kind opKind `json:"kind"`
+	Key  string `json:"key,omitempty"`
+	// Value is a marshalled Any holding the proto which the op stores.
+	Value          []byte `json:"value,omitempty"`
+	Submitter      string `json:"submitter,omitempty"`
+	LeafIDHash     []byte `json:"leafIDHash,omitempty"`
+	MerkleLeafHash []byte `json:"merkleLeafHash,omitempty"`
+	Index          int64  `json:"index,omitempty"`
+}
+
+// walRecord is the unit of the WAL and snapshot files. Each file starts with
+// a header record, which only has Generation set, followed by records which
+// hold the mutations of a single tree by one committed transaction.
+type walRecord struct {
+	Generation int64   `json:"generation,omitempty"`
+	TreeID     int64   `json:"treeID,omitempty"`
+	Ops        []walOp `json:"ops,omitempty"`
+}
+
+// marshalValue returns the serialized form of pb for use in a walOp.
+func marshalValue(pb proto.Message) ([]byte, error) {
+	a, err := ptypes.MarshalAny(pb)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(a)
+}
+
+// unmarshalValue parses a value serialized by marshalValue.
+func unmarshalValue(b []byte) (proto.Message, error) {
+	var a any.Any
+	if err := proto.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+	var d ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(&a, &d); err != nil {
+		return nil, err
+	}
+	return d.Message, nil
+}
+
+func putOp(k btree.Item, v proto.Message) (walOp, error) {
+	b, err := marshalValue(v)
+	if err != nil {
+		return walOp{}, err
+	}
+	return walOp{Kind: opPut, Key: k.(*kv).k, Value: b}, nil
+}
+
+func enqueueOp(leaf *trillian.LogLeaf, submitter string) (walOp, error) {
+	b, err := marshalValue(leaf)
+	if err != nil {
+		return walOp{}, err
+	}
+	return walOp{Kind: opEnqueue, Value: b, Submitter: submitter}, nil
+}
+
+func treeOp(meta *trillian.Tree) (walOp, error) {
+	b, err := marshalValue(meta)
+	if err != nil {
+		return walOp{}, err
+	}
+	return walOp{Kind: opTree, Value: b}, nil
+}
+
+// writeRecord writes the framed rec to w, and returns the number of bytes
+// written.
+func writeRecord(w io.Writer, rec *walRecord) (int64, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf, uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(payload))
+	copy(buf[recordHeaderSize:], payload)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// readRecord reads the next framed record from r, and returns it along with
+// its size. It returns io.EOF if r has no more data, and errTornRecord if the
+// record is incomplete or corrupt.
+func readRecord(r io.Reader) (*walRecord, int64, error) {
+	var hdr [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err == io.ErrUnexpectedEOF {
+		return nil, 0, errTornRecord
+	} else if err != nil {
+		return nil, 0, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+	if _, err := io.ReadFull(r, payload); err == io.ErrUnexpectedEOF || err == io.EOF {
+		return nil, 0, errTornRecord
+	} else if err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(hdr[4:]) {
+		return nil, 0, errTornRecord
+	}
+	var rec walRecord
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse record: %v", err)
+	}
+	return &rec, int64(len(hdr) + len(payload)), nil
+}
+
+// wal persists the transactions committed to a TreeStorage in a directory.
+//
+// Committed transactions are appended to a write-ahead log, which is
+// periodically compacted into a snapshot of all the trees. The files are
+// guarded by a file lock, so several processes on a host can share the
+// directory: each of them replays the records appended by the others before
+// starting a transaction.
+//
+// A snapshot replaces the WAL with a new file, and bumps the generation in
+// the header of both files. A WAL whose generation doesn't match the snapshot
+// was left behind by a crash while snapshotting, and is already included in
+// the snapshot.
+type wal struct {
+	dir              string
+	snapshotInterval int
+
+	// mu serializes the use of the files within this process, and guards all
+	// the fields below.
+	mu       sync.Mutex
+	lockFile *os.File
+	// f is the WAL file which has been replayed, or nil before it's loaded.
+	f *os.File
+	// gen is the generation of the snapshot which the WAL applies to.
+	gen int64
+	// offset is the end of the last complete record read from or appended to
+	// f.
+	offset int64
+	// records is the number of transactions in f.
+	records int
+}
+
+func openWAL(dir string, snapshotInterval int) (*wal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	lockFile, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &wal{
+		dir:              dir,
+		snapshotInterval: snapshotInterval,
+		lockFile:         lockFile,
+	}, nil
+}
+
+func (w *wal) path(name string) string {
+	return filepath.Join(w.dir, name)
+}
+
+// lock acquires the WAL for this process, and takes the file lock.
+func (w *wal) lock(exclusive bool) error {
+	w.mu.Lock()
+	if err := lockFile(w.lockFile, exclusive); err != nil {
+		w.mu.Unlock()
+		return fmt.Errorf("failed to lock %s: %v", w.lockFile.Name(), err)
+	}
+	return nil
+}
+
+func (w *wal) unlock() {
+	if err := unlockFile(w.lockFile); err != nil {
+		glog.Warningf("Failed to unlock %s: %v", w.lockFile.Name(), err)
+	}
+	w.mu.Unlock()
+}
+
+// catchUp applies the records appended to the WAL since it was last read to
+// m. If the WAL has been replaced by a snapshot since, the trees of m are
+// reloaded from the snapshot and the new WAL instead. The WAL must be locked,
+// and exclusively so if writable is true, in which case any torn record at the
+// end of the WAL is truncated.
+func (w *wal) catchUp(m *TreeStorage, writable bool) error {
+	if w.f != nil {
+		current, err := w.isCurrent()
+		if err != nil {
+			return err
+		}
+		if current {
+			return w.replay(m, writable)
+		}
+		w.f.Close()
+		w.f = nil
+	}
+
+	fresh := NewTreeStorage()
+	gen, err := readSnapshot(w.path(snapshotFileName), fresh)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path(walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	w.f, w.gen, w.offset, w.records = f, gen, 0, 0
+	if err := w.replay(fresh, writable); err != nil {
+		return err
+	}
+	m.replaceTrees(fresh.trees)
+	return nil
+}
+
+// isCurrent returns whether f is still the WAL file in the directory.
+func (w *wal) isCurrent() (bool, error) {
+	fi, err := w.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	cur, err := os.Stat(w.path(walFileName))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return os.SameFile(fi, cur), nil
+}
+
+// replay applies the records of f after offset to m.
+func (w *wal) replay(m *TreeStorage, writable bool) error {
+	fi, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	r := bufio.NewReader(io.NewSectionReader(w.f, w.offset, size-w.offset))
+	for {
+		rec, n, err := readRecord(r)
+		switch {
+		case err == io.EOF:
+			return nil
+		case err == errTornRecord:
+			if !writable {
+				// The record may be completed by a writer which hasn't crashed,
+				// or truncated by the next one.
+				return nil
+			}
+			glog.Warningf("Truncating torn record at offset %d of %s", w.offset, w.f.Name())
+			return w.f.Truncate(w.offset)
+		case err != nil:
+			return err
+		}
+
+		if w.offset == 0 {
+			if rec.Generation != w.gen {
+				glog.Warningf("Ignoring %s of generation %d, which precedes the snapshot of generation %d", w.f.Name(), rec.Generation, w.gen)
+				if writable {
+					return w.reset(w.gen)
+				}
+				w.offset = size
+				return nil
+			}
+			w.offset += n
+			continue
+		}
+		if err := m.applyRecord(rec); err != nil {
+			return fmt.Errorf("failed to apply record at offset %d of %s: %v", w.offset, w.f.Name(), err)
+		}
+		w.offset += n
+		w.records++
+	}
+}
+
+// append durably appends rec to the WAL, which must be exclusively locked and
+// caught up.
+func (w *wal) append(rec *walRecord) error {
+	var buf bytes.Buffer
+	if w.offset == 0 {
+		if _, err := writeRecord(&buf, &walRecord{Generation: w.gen}); err != nil {
+			return err
+		}
+	}
+	if _, err := writeRecord(&buf, rec); err != nil {
+		return err
+	}
+	if _, err := w.f.WriteAt(buf.Bytes(), w.offset); err != nil {
+		return err
+	}
+	if err := w.f.Sync(); err != nil {
+		return err
+	}
+	w.offset += int64(buf.Len())
+	w.records++
+	return nil
+}
+
+// maybeSnapshot compacts the WAL into a snapshot of m, if the WAL holds at
+// least snapshotInterval transactions. The WAL must be exclusively locked.
+func (w *wal) maybeSnapshot(m *TreeStorage) error {
+	if w.snapshotInterval <= 0 || w.records < w.snapshotInterval {
+		return nil
+	}
+	recs, err := m.snapshotRecords()
+	if err != nil {
+		return err
+	}
+	gen := w.gen + 1
+	if err := writeFileAtomically(w.path(snapshotFileName), append([]*walRecord{{Generation: gen}}, recs...)); err != nil {
+		return err
+	}
+	glog.V(1).Infof("Wrote snapshot of generation %d after %d transactions", gen, w.records)
+	return w.reset(gen)
+}
+
+// reset replaces the WAL with an empty one for the snapshot of the given
+// generation.
+func (w *wal) reset(gen int64) error {
+	path := w.path(walFileName)
+	if err := writeFileAtomically(path, []*walRecord{{Generation: gen}}); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if w.f != nil {
+		w.f.Close()
+	}
+	w.f, w.gen, w.offset, w.records = f, gen, fi.Size(), 0
+	return nil
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f != nil {
+		w.f.Close()
+	}
+	return w.lockFile.Close()
+}
+
+// writeFileAtomically replaces the file at path with one holding recs.
+func writeFileAtomically(path string, recs []*walRecord) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	for _, rec := range recs {
+		if _, err := writeRecord(bw, rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSnapshot applies the records of the snapshot at path to m, and returns
+// its generation. A missing snapshot is generation 0.
+func readSnapshot(path string, m *TreeStorage) (int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	hdr, _, err := readRecord(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header of %s: %v", path, err)
+	}
+	for {
+		rec, _, err := readRecord(r)
+		if err == io.EOF {
+			return hdr.Generation, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if err := m.applyRecord(rec); err != nil {
+			return 0, fmt.Errorf("failed to apply %s: %v", path, err)
+		}
+	}
+}
+
+// applyRecord applies the mutations in rec to the trees of m.
+func (m *TreeStorage) applyRecord(rec *walRecord) error {
+	var tr *tree
+	for _, op := range rec.Ops {
+		if op.Kind == opTree {
+			v, err := unmarshalValue(op.Value)
+			if err != nil {
+				return err
+			}
+			meta, ok := v.(*trillian.Tree)
+			if !ok {
+				return fmt.Errorf("tree op holds %T", v)
+			}
+			m.setTreeMeta(meta)
+			continue
+		}
+		if tr == nil {
+			if tr = m.getTree(rec.TreeID); tr == nil {
+				return fmt.Errorf("no such treeID %d", rec.TreeID)
+			}
+			tr.Lock()
+			defer tr.Unlock()
+			tr.beginApply(rec.Ops)
+		}
+		if err := tr.apply(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTreeMeta creates the tree described by meta, or updates its metadata.
+func (m *TreeStorage) setTreeMeta(meta *trillian.Tree) {
+	m.mu.Lock()
+	tr, ok := m.trees[meta.TreeId]
+	if !ok {
+		m.trees[meta.TreeId] = newTree(meta)
+	}
+	m.mu.Unlock()
+	if ok {
+		tr.Lock()
+		tr.meta = meta
+		tr.Unlock()
+	}
+}
+
+// replaceTrees replaces the contents of the trees of m with the given ones.
+func (m *TreeStorage) replaceTrees(trees map[int64]*tree) {
+	replaced := make(map[*tree]*tree)
+	m.mu.Lock()
+	for id, t := range trees {
+		if old, ok := m.trees[id]; ok {
+			replaced[old] = t
+		} else {
+			m.trees[id] = t
+		}
+	}
+	m.mu.Unlock()
+
+	for old, t := range replaced {
+		old.Lock()
+		old.store, old.currentSTH, old.meta = t.store, t.currentSTH, t.meta
+		old.version++
+		old.Unlock()
+	}
+}
+
+// beginApply prepares the tree, which must be locked, for ops to be applied
+// to it. The store and the queue or index which ops modify are copied, as
+// they are shared with the TXs in progress.
+func (t *tree) beginApply(ops []walOp) {
+	t.store = t.store.Clone()
+	t.version++
+	var queue, index bool
+	for _, op := range ops {
+		switch op.Kind {
+		case opEnqueue, opUnqueue:
+			queue = true
+		case opIndex:
+			index = true
+		}
+	}
+	if queue {
+		k := unseqKey(t.meta.TreeId)
+		q := list.New()
+		q.PushBackList(t.queue())
+		k.(*kv).v = q
+		t.store.ReplaceOrInsert(k)
+	}
+	if index {
+		k := hashToSeqKey(t.meta.TreeId)
+		m := t.store.Get(k).(*kv).v.(map[string][]int64)
+		cp := make(map[string][]int64, len(m))
+		for h, seqs := range m {
+			cp[h] = seqs
+		}
+		k.(*kv).v = cp
+		t.store.ReplaceOrInsert(k)
+	}
+}
+
+// apply applies op to the tree, which must be locked.
+func (t *tree) apply(op walOp) error {
+	switch op.Kind {
+	case opPut:
+		v, err := unmarshalValue(op.Value)
+		if err != nil {
+			return err
+		}
+		t.store.ReplaceOrInsert(&kv{k: op.Key, v: v})
+		if slr, ok := v.(*trillian.SignedLogRoot); ok {
+			var root types.LogRootV1
+			if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+				return err
+			}
+			if root.TimestampNanos > t.currentSTH {
+				t.currentSTH = root.TimestampNanos
+			}
+		}
+	case opDelete:
+		t.store.Delete(&kv{k: op.Key})
+	case opEnqueue:
+		v, err := unmarshalValue(op.Value)
+		if err != nil {
+			return err
+		}
+		leaf, ok := v.(*trillian.LogLeaf)
+		if !ok {
+			return fmt.Errorf("enqueue op holds %T", v)
+		}
+		t.queue().PushBack(&queuedLeaf{leaf: leaf, submitter: op.Submitter})
+	case opUnqueue:
+		q := t.queue()
+		for e := q.Front(); e != nil; e = e.Next() {
+			leaf := e.Value.(*queuedLeaf).leaf
+			if bytes.Equal(leaf.LeafIdentityHash, op.LeafIDHash) && bytes.Equal(leaf.MerkleLeafHash, op.MerkleLeafHash) {
+				q.Remove(e)
+				return nil
+			}
+		}
+		return fmt.Errorf("leaf %x is not queued", op.LeafIDHash)
+	case opIndex:
+		m := t.store.Get(hashToSeqKey(t.meta.TreeId)).(*kv).v.(map[string][]int64)
+		m[string(op.MerkleLeafHash)] = append(m[string(op.MerkleLeafHash)], op.Index)
+	default:
+		return fmt.Errorf("unknown op %q", op.Kind)
+	}
+	return nil
+}
+
+// queue returns the unsequenced queue of the tree.
+func (t *tree) queue() *list.List {
+	return t.store.Get(unseqKey(t.meta.TreeId)).(*kv).v.(*list.List)
+}
+
+// snapshotRecords returns records which recreate the trees of m when
+// applied to an empty TreeStorage.
+func (m *TreeStorage) snapshotRecords() ([]*walRecord, error) {
+	m.mu.RLock()
+	trees := make([]*tree, 0, len(m.trees))
+	for _, t := range m.trees {
+		trees = append(trees, t)
+	}
+	m.mu.RUnlock()
+	sort.Slice(trees, func(i, j int) bool { return trees[i].meta.TreeId < trees[j].meta.TreeId })
+
+	var recs []*walRecord
+	for _, t := range trees {
+		t.RLock()
+		r, err := t.snapshotRecords()
+		t.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, r...)
+	}
+	return recs, nil
+}
+
+// snapshotRecords returns records which recreate the tree, which must be
+// locked.
+func (t *tree) snapshotRecords() ([]*walRecord, error) {
+	id := t.meta.TreeId
+	op, err := treeOp(t.meta)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*walRecord{{TreeID: id, Ops: []walOp{op}}}
+
+	data := &walRecord{TreeID: id}
+	t.store.Ascend(func(i btree.Item) bool {
+		switch v := i.(*kv).v.(type) {
+		case proto.Message:
+			op, err = putOp(i, v)
+			data.Ops = append(data.Ops, op)
+		case *list.List:
+			for e := v.Front(); e != nil && err == nil; e = e.Next() {
+				ql := e.Value.(*queuedLeaf)
+				op, err = enqueueOp(ql.leaf, ql.submitter)
+				data.Ops = append(data.Ops, op)
+			}
+		case map[string][]int64:
+			hashes := make([]string, 0, len(v))
+			for h := range v {
+				hashes = append(hashes, h)
+			}
+			sort.Strings(hashes)
+			for _, h := range hashes {
+				for _, seq := range v[h] {
+					data.Ops = append(data.Ops, walOp{Kind: opIndex, MerkleLeafHash: []byte(h), Index: seq})
+				}
+			}
+		default:
+			err = fmt.Errorf("can't snapshot %T stored under %q", v, i.(*kv).k)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Ops) > 0 {
+		ret = append(ret, data)
+	}
+	return ret, nil
+}
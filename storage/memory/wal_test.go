@@ -0,0 +1,283 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/google/trillian/merkle/rfc6962" // Register the hasher.
+)
+
+func openPersistent(t *testing.T, dir string, snapshotInterval int) *TreeStorage {
+	t.Helper()
+	ts, err := NewPersistentTreeStorage(dir, snapshotInterval)
+	if err != nil {
+		t.Fatalf("NewPersistentTreeStorage(): %v", err)
+	}
+	return ts
+}
+
+func makeLeaves(n int) []*trillian.LogLeaf {
+	leaves := make([]*trillian.LogLeaf, 0, n)
+	for i := 0; i < n; i++ {
+		value := []byte(fmt.Sprintf("leaf %d", i))
+		hash := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafValue:        value,
+			LeafIdentityHash: hash[:],
+			MerkleLeafHash:   hash[:],
+		})
+	}
+	return leaves
+}
+
+func storeRoot(ctx context.Context, tx storage.LogTreeTX, treeSize, revision uint64) error {
+	root, err := (&types.LogRootV1{
+		TreeSize:       treeSize,
+		RootHash:       make([]byte, 32),
+		TimestampNanos: uint64(time.Now().UnixNano()),
+		Revision:       revision,
+	}).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+}
+
+// populate creates a tree in ts with 2 of the given 3 leaves sequenced.
+func populate(ctx context.Context, t *testing.T, ts *TreeStorage, leaves []*trillian.LogLeaf) *trillian.Tree {
+	t.Helper()
+	ls := NewLogStorage(ts, nil)
+	tree, err := storage.CreateTree(ctx, NewAdminStorage(ts), testonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(ctx, tx, 0, 0)
+	}); err != nil {
+		t.Fatalf("Failed to initialize tree: %v", err)
+	}
+	if _, err := ls.QueueLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("QueueLeaves(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		dequeued, err := tx.DequeueLeaves(ctx, 2, time.Now())
+		if err != nil {
+			return err
+		}
+		for i, leaf := range dequeued {
+			leaf.LeafIndex = int64(i)
+		}
+		if err := tx.UpdateSequencedLeaves(ctx, dequeued); err != nil {
+			return err
+		}
+		return storeRoot(ctx, tx, 2, 1)
+	}); err != nil {
+		t.Fatalf("Failed to sequence leaves: %v", err)
+	}
+	return tree
+}
+
+// checkPopulated checks that ts holds the tree created by populate.
+func checkPopulated(ctx context.Context, t *testing.T, ts *TreeStorage, tree *trillian.Tree, leaves []*trillian.LogLeaf) {
+	t.Helper()
+	got, err := storage.GetTree(ctx, NewAdminStorage(ts), tree.TreeId)
+	if err != nil {
+		t.Fatalf("GetTree(): %v", err)
+	}
+	if got.DisplayName != tree.DisplayName {
+		t.Errorf("GetTree().DisplayName=%q, want %q", got.DisplayName, tree.DisplayName)
+	}
+
+	ls := NewLogStorage(ts, nil)
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		slr, err := tx.LatestSignedLogRoot(ctx)
+		if err != nil {
+			return err
+		}
+		var root types.LogRootV1
+		if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+			return err
+		}
+		if root.TreeSize != 2 {
+			t.Errorf("LatestSignedLogRoot().TreeSize=%d, want 2", root.TreeSize)
+		}
+
+		seq, err := tx.GetLeavesByIndex(ctx, []int64{0, 1})
+		if err != nil {
+			return err
+		}
+		if len(seq) != 2 {
+			t.Fatalf("GetLeavesByIndex() returned %d leaves, want 2", len(seq))
+		}
+		for i, leaf := range seq {
+			if got, want := string(leaf.LeafValue), string(leaves[i].LeafValue); got != want {
+				t.Errorf("GetLeavesByIndex()[%d].LeafValue=%q, want %q", i, got, want)
+			}
+		}
+		byHash, err := tx.GetLeavesByHash(ctx, [][]byte{leaves[1].MerkleLeafHash}, false)
+		if err != nil {
+			return err
+		}
+		if len(byHash) != 1 || byHash[0].LeafIndex != 1 {
+			t.Errorf("GetLeavesByHash() returned %v, want leaf 1", byHash)
+		}
+
+		queued, err := tx.DequeueLeaves(ctx, 10, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(queued) != 1 || string(queued[0].LeafValue) != string(leaves[2].LeafValue) {
+			t.Errorf("DequeueLeaves() returned %v, want leaf 2", queued)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
+
+func TestPersistentTreeStorageReopen(t *testing.T) {
+	ctx := context.Background()
+	for _, interval := range []int{0, 1, 2, 100} {
+		t.Run(fmt.Sprintf("interval:%d", interval), func(t *testing.T) {
+			dir := t.TempDir()
+			leaves := makeLeaves(3)
+			ts := openPersistent(t, dir, interval)
+			tree := populate(ctx, t, ts, leaves)
+			if err := ts.Close(); err != nil {
+				t.Fatalf("Close(): %v", err)
+			}
+
+			_, err := os.Stat(filepath.Join(dir, snapshotFileName))
+			if got, want := err == nil, interval > 0 && interval < 100; got != want {
+				t.Errorf("snapshot exists: %v, want %v", got, want)
+			}
+
+			ts = openPersistent(t, dir, interval)
+			defer ts.Close()
+			checkPopulated(ctx, t, ts, tree, leaves)
+		})
+	}
+}
+
+func TestPersistentTreeStorageShared(t *testing.T) {
+	ctx := context.Background()
+	for _, interval := range []int{0, 1} {
+		t.Run(fmt.Sprintf("interval:%d", interval), func(t *testing.T) {
+			dir := t.TempDir()
+			leaves := makeLeaves(3)
+			ts1 := openPersistent(t, dir, interval)
+			defer ts1.Close()
+			ts2 := openPersistent(t, dir, interval)
+			defer ts2.Close()
+
+			tree := populate(ctx, t, ts1, leaves)
+			checkPopulated(ctx, t, ts2, tree, leaves)
+
+			if err := NewAdminStorage(ts2).ReadWriteTransaction(ctx, func(ctx context.Context, tx storage.AdminTX) error {
+				_, err := tx.UpdateTree(ctx, tree.TreeId, func(tree *trillian.Tree) { tree.DisplayName = "updated" })
+				return err
+			}); err != nil {
+				t.Fatalf("UpdateTree(): %v", err)
+			}
+			got, err := storage.GetTree(ctx, NewAdminStorage(ts1), tree.TreeId)
+			if err != nil {
+				t.Fatalf("GetTree(): %v", err)
+			}
+			if got.DisplayName != "updated" {
+				t.Errorf("GetTree().DisplayName=%q, want %q", got.DisplayName, "updated")
+			}
+		})
+	}
+}
+
+func TestPersistentTreeStorageTornRecord(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	leaves := makeLeaves(3)
+	ts := openPersistent(t, dir, 0)
+	tree := populate(ctx, t, ts, leaves)
+	ts.Close()
+
+	// Simulate a crash while appending a record.
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(): %v", err)
+	}
+	if _, err := f.Write([]byte{0, 0, 1, 0, 1, 2, 3}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	f.Close()
+
+	// The torn record should be truncated by the next writer, which appends
+	// the transaction of checkPopulated after it.
+	ts = openPersistent(t, dir, 0)
+	checkPopulated(ctx, t, ts, tree, leaves)
+	ts.Close()
+
+	ts = openPersistent(t, dir, 0)
+	defer ts.Close()
+	if _, err := storage.GetTree(ctx, NewAdminStorage(ts), tree.TreeId); err != nil {
+		t.Errorf("GetTree() after truncation: %v", err)
+	}
+}
+
+func TestPersistentTreeStorageConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	leaves := makeLeaves(4)
+	ts1 := openPersistent(t, dir, 0)
+	defer ts1.Close()
+	ts2 := openPersistent(t, dir, 0)
+	defer ts2.Close()
+	tree := populate(ctx, t, ts1, leaves[:3])
+
+	// The WAL isn't locked while the TX of ts1 is in progress, so ts2 can
+	// commit, but then the TX of ts1 must fail as the tree has changed.
+	err := NewLogStorage(ts1, nil).ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if _, err := tx.DequeueLeaves(ctx, 1, time.Now()); err != nil {
+			return err
+		}
+		_, err := NewLogStorage(ts2, nil).QueueLeaves(ctx, tree, leaves[3:], time.Now())
+		return err
+	})
+	if got, want := status.Code(err), codes.Aborted; got != want {
+		t.Fatalf("ReadWriteTransaction()=%v, want code %v", err, want)
+	}
+
+	var got []*trillian.LogLeaf
+	if err := NewLogStorage(ts1, nil).ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err = tx.DequeueLeaves(ctx, 10, time.Now())
+		return err
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("DequeueLeaves() returned %d leaves, want 2", len(got))
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate upgrades the schema of the SQL storage implementations with
+// versioned forward migrations, and records the applied versions in the
+// database.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LegacyVersion is the version assumed for databases which contain the tables
+// of the initial schema but no schema version table, i.e. which were created
+// before schema versioning was introduced.
+const LegacyVersion = 1
+
+var autoMigrate = flag.Bool("auto_migrate", false, "If true, the MySQL and PostgreSQL storage providers apply pending schema migrations at startup instead of refusing to start. Only one server should be started with this flag at a time")
+
+// Migration is a forward change to a schema.
+type Migration struct {
+	// Version is the schema version after applying the migration. Versions of
+	// consecutive migrations must be consecutive, starting at 1.
+	Version int
+	// Description is a short human-readable summary of the change.
+	Description string
+	// Statements are executed in order to apply the migration.
+	Statements []string
+}
+
+// Dialect holds the SQL which manages the schema version table of a
+// particular database.
+type Dialect struct {
+	// VersionTable is the name of the schema version table.
+	VersionTable string
+	// LegacyTable is the name of a table of the initial schema, whose presence
+	// marks an unversioned database as being at LegacyVersion.
+	LegacyTable string
+	// TableExists counts the tables with the name given as its only argument.
+	TableExists string
+	// CreateVersionTable creates the schema version table if it doesn't exist.
+	CreateVersionTable string
+	// SelectVersion returns the highest recorded version.
+	SelectVersion string
+	// InsertVersion records a version, given the version, its description,
+	// and the time at which it was applied in nanoseconds since the epoch.
+	InsertVersion string
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// NewMigrator returns a Migrator which applies the given migrations to db.
+// It panics if the migrations aren't numbered consecutively from 1, as that
+// is a programming error.
+func NewMigrator(db *sql.DB, dialect Dialect, migrations []Migration) *Migrator {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			panic(fmt.Sprintf("migration %d has version %d, want %d", i, m.Version, i+1))
+		}
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}
+}
+
+// Latest returns the version of the schema after applying all migrations.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version returns the current version of the schema, which is 0 for an empty
+// database. The second return value is true if the database has a schema
+// version table.
+func (m *Migrator) Version(ctx context.Context) (int, bool, error) {
+	versioned, err := m.tableExists(ctx, m.dialect.VersionTable)
+	if err != nil {
+		return 0, false, err
+	}
+	if versioned {
+		var version sql.NullInt64
+		if err := m.db.QueryRowContext(ctx, m.dialect.SelectVersion).Scan(&version); err != nil {
+			return 0, true, fmt.Errorf("failed to read schema version: %v", err)
+		}
+		return int(version.Int64), true, nil
+	}
+	legacy, err := m.tableExists(ctx, m.dialect.LegacyTable)
+	if err != nil || !legacy {
+		return 0, false, err
+	}
+	return LegacyVersion, false, nil
+}
+
+func (m *Migrator) tableExists(ctx context.Context, name string) (bool, error) {
+	var count int
+	if err := m.db.QueryRowContext(ctx, m.dialect.TableExists, name).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %v", name, err)
+	}
+	return count > 0, nil
+}
+
+// Check returns an error with code FailedPrecondition unless the schema is at
+// the latest version.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, _, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	switch latest := m.Latest(); {
+	case version > latest:
+		return status.Errorf(codes.FailedPrecondition, "schema version %d is newer than the latest version %d known to this binary", version, latest)
+	case version == 0:
+		return status.Errorf(codes.FailedPrecondition, "database has no schema, run migrateschema or set --auto_migrate")
+	case version < latest:
+		return status.Errorf(codes.FailedPrecondition, "schema version %d is older than the latest version %d, run migrateschema or set --auto_migrate", version, latest)
+	}
+	return nil
+}
+
+// Migrate applies the pending migrations in order, and writes the SQL which
+// it executes to w. If dryRun is true, the SQL is only written.
+//
+// Most databases can't apply schema changes transactionally, so a migration
+// which fails part-way may need to be completed by hand.
+func (m *Migrator) Migrate(ctx context.Context, w io.Writer, dryRun bool) error {
+	version, versioned, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if latest := m.Latest(); version > latest {
+		return status.Errorf(codes.FailedPrecondition, "schema version %d is newer than the latest version %d known to this binary", version, latest)
+	}
+
+	run := func(stmt string, args ...interface{}) error {
+		if dryRun {
+			return nil
+		}
+		if _, err := m.db.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("failed to execute %q: %v", stmt, err)
+		}
+		return nil
+	}
+	exec := func(stmt string) error {
+		fmt.Fprintf(w, "%s;\n", stmt)
+		return run(stmt)
+	}
+	record := func(mig Migration) error {
+		fmt.Fprintf(w, "%s; -- version %d\n", m.dialect.InsertVersion, mig.Version)
+		return run(m.dialect.InsertVersion, mig.Version, mig.Description, time.Now().UnixNano())
+	}
+
+	if !versioned {
+		if err := exec(m.dialect.CreateVersionTable); err != nil {
+			return err
+		}
+		if version == LegacyVersion {
+			fmt.Fprintf(w, "-- Recording unversioned schema as version %d\n", LegacyVersion)
+			if err := record(m.migrations[0]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mig := range m.migrations[version:] {
+		fmt.Fprintf(w, "-- Migration %d: %s\n", mig.Version, mig.Description)
+		for _, stmt := range mig.Statements {
+			if err := exec(stmt); err != nil {
+				return fmt.Errorf("migration %d: %v", mig.Version, err)
+			}
+		}
+		if err := record(mig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Baseline records that an unversioned database is at the given version,
+// without changing its schema. It is needed for databases which were created
+// at a later version than LegacyVersion before schema versioning existed.
+func (m *Migrator) Baseline(ctx context.Context, version int) error {
+	if version < 1 || version > m.Latest() {
+		return status.Errorf(codes.InvalidArgument, "version %d is not between 1 and %d", version, m.Latest())
+	}
+	if _, versioned, err := m.Version(ctx); err != nil {
+		return err
+	} else if versioned {
+		return status.Errorf(codes.FailedPrecondition, "database already has a schema version")
+	}
+	if _, err := m.db.ExecContext(ctx, m.dialect.CreateVersionTable); err != nil {
+		return fmt.Errorf("failed to create schema version table: %v", err)
+	}
+	_, err := m.db.ExecContext(ctx, m.dialect.InsertVersion, version, m.migrations[version-1].Description, time.Now().UnixNano())
+	return err
+}
+
+// CheckOrMigrate checks that the schema is at the latest version, or applies
+// the pending migrations if --auto_migrate is set. It is called by the
+// storage providers at startup.
+func CheckOrMigrate(ctx context.Context, m *Migrator) error {
+	if !*autoMigrate {
+		return m.Check(ctx)
+	}
+	version, _, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version == m.Latest() {
+		return nil
+	}
+	glog.Infof("Migrating schema from version %d to %d", version, m.Latest())
+	return m.Migrate(ctx, ioutil.Discard, false)
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ "github.com/mattn/go-sqlite3" // Load SQLite driver
+)
+
+var testDialect = Dialect{
+	VersionTable:       "SchemaVersion",
+	LegacyTable:        "Trees",
+	TableExists:        "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?",
+	CreateVersionTable: "CREATE TABLE IF NOT EXISTS SchemaVersion(Version INTEGER NOT NULL PRIMARY KEY, Description TEXT NOT NULL, AppliedTimestampNanos BIGINT NOT NULL)",
+	SelectVersion:      "SELECT MAX(Version) FROM SchemaVersion",
+	InsertVersion:      "INSERT INTO SchemaVersion(Version,Description,AppliedTimestampNanos) VALUES(?,?,?)",
+}
+
+var testMigrations = []Migration{
+	{Version: 1, Description: "Initial schema", Statements: []string{
+		"CREATE TABLE Trees(TreeId BIGINT NOT NULL PRIMARY KEY)",
+	}},
+	{Version: 2, Description: "Add tree state", Statements: []string{
+		"ALTER TABLE Trees ADD COLUMN TreeState TEXT NOT NULL DEFAULT 'ACTIVE'",
+	}},
+	{Version: 3, Description: "Add leaves", Statements: []string{
+		"CREATE TABLE Leaves(TreeId BIGINT NOT NULL, LeafIndex BIGINT NOT NULL)",
+		"CREATE INDEX LeavesIdx ON Leaves(TreeId, LeafIndex)",
+	}},
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, stmts ...string) {
+	t.Helper()
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Exec(%q): %v", stmt, err)
+		}
+	}
+}
+
+func TestNewMigratorPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewMigrator() didn't panic on a gap in versions")
+		}
+	}()
+	NewMigrator(nil, testDialect, []Migration{{Version: 1}, {Version: 3}})
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc          string
+		setup         []string
+		wantBefore    int
+		wantVersioned bool
+		wantApplied   []string
+	}{
+		{
+			desc:        "empty",
+			wantApplied: []string{"Migration 1", "Migration 2", "Migration 3"},
+		},
+		{
+			desc:        "legacy",
+			setup:       testMigrations[0].Statements,
+			wantBefore:  LegacyVersion,
+			wantApplied: []string{"Recording unversioned schema as version 1", "Migration 2", "Migration 3"},
+		},
+		{
+			desc: "versioned",
+			setup: append(append([]string{}, testMigrations[0].Statements...),
+				testMigrations[1].Statements[0],
+				testDialect.CreateVersionTable,
+				"INSERT INTO SchemaVersion VALUES(1,'',0)",
+				"INSERT INTO SchemaVersion VALUES(2,'',0)"),
+			wantBefore:    2,
+			wantVersioned: true,
+			wantApplied:   []string{"Migration 3"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			db := openTestDB(t)
+			mustExec(t, db, tc.setup...)
+			m := NewMigrator(db, testDialect, testMigrations)
+
+			version, versioned, err := m.Version(ctx)
+			if err != nil {
+				t.Fatalf("Version(): %v", err)
+			}
+			if version != tc.wantBefore || versioned != tc.wantVersioned {
+				t.Errorf("Version() = %d, %v, want %d, %v", version, versioned, tc.wantBefore, tc.wantVersioned)
+			}
+			if err := m.Check(ctx); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("Check() = %v, want code %v", err, codes.FailedPrecondition)
+			}
+
+			// A dry run only describes the changes.
+			var dry bytes.Buffer
+			if err := m.Migrate(ctx, &dry, true); err != nil {
+				t.Fatalf("Migrate(dryRun): %v", err)
+			}
+			for _, want := range tc.wantApplied {
+				if !strings.Contains(dry.String(), want) {
+					t.Errorf("Migrate(dryRun) output %q doesn't contain %q", dry.String(), want)
+				}
+			}
+			if version, _, err := m.Version(ctx); err != nil || version != tc.wantBefore {
+				t.Errorf("Version() after dry run = %d, %v, want %d", version, err, tc.wantBefore)
+			}
+
+			var out bytes.Buffer
+			if err := m.Migrate(ctx, &out, false); err != nil {
+				t.Fatalf("Migrate(): %v", err)
+			}
+			if got, want := out.String(), dry.String(); got != want {
+				t.Errorf("Migrate() output = %q, want the dry run output %q", got, want)
+			}
+			if err := m.Check(ctx); err != nil {
+				t.Errorf("Check() after Migrate() = %v", err)
+			}
+			mustExec(t, db, "INSERT INTO Leaves(TreeId,LeafIndex) SELECT TreeId,0 FROM Trees WHERE TreeState='ACTIVE'")
+
+			// Migrating an up to date schema does nothing.
+			out.Reset()
+			if err := m.Migrate(ctx, &out, false); err != nil {
+				t.Fatalf("Migrate() again: %v", err)
+			}
+			if out.Len() != 0 {
+				t.Errorf("Migrate() again wrote %q, want nothing", out.String())
+			}
+		})
+	}
+}
+
+func TestNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := NewMigrator(db, testDialect, testMigrations)
+	if err := m.Migrate(ctx, &bytes.Buffer{}, false); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	old := NewMigrator(db, testDialect, testMigrations[:2])
+	if err := old.Check(ctx); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Check() = %v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err := old.Migrate(ctx, &bytes.Buffer{}, false); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Migrate() = %v, want code %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	mustExec(t, db, testMigrations[0].Statements...)
+	mustExec(t, db, testMigrations[1].Statements...)
+	m := NewMigrator(db, testDialect, testMigrations)
+
+	for _, version := range []int{0, 4} {
+		if err := m.Baseline(ctx, version); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Baseline(%d) = %v, want code %v", version, err, codes.InvalidArgument)
+		}
+	}
+	if err := m.Baseline(ctx, 2); err != nil {
+		t.Fatalf("Baseline(2): %v", err)
+	}
+	if version, versioned, err := m.Version(ctx); err != nil || version != 2 || !versioned {
+		t.Errorf("Version() = %d, %v, %v, want 2, true, nil", version, versioned, err)
+	}
+	if err := m.Baseline(ctx, 2); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Baseline(2) again = %v, want code %v", err, codes.FailedPrecondition)
+	}
+	if err := m.Migrate(ctx, &bytes.Buffer{}, false); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/google/trillian/storage/migrate"
+)
+
+var dialect = migrate.Dialect{
+	VersionTable: "SchemaVersion",
+	LegacyTable:  "Trees",
+	TableExists:  "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=DATABASE() AND table_name=?",
+	CreateVersionTable: `CREATE TABLE IF NOT EXISTS SchemaVersion(
+  Version               INTEGER NOT NULL,
+  Description           VARCHAR(255) NOT NULL,
+  AppliedTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(Version)
+)`,
+	SelectVersion: "SELECT MAX(Version) FROM SchemaVersion",
+	InsertVersion: "INSERT INTO SchemaVersion(Version,Description,AppliedTimestampNanos) VALUES(?,?,?)",
+}
+
+// migrations upgrade the schema from the initial version, which predates
+// schema versioning. Changes to schema/storage.sql must be accompanied by a
+// new migration, and storage.sql must record the latest version.
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "Initial schema",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Trees(
+  TreeId                BIGINT NOT NULL,
+  TreeState             ENUM('ACTIVE', 'FROZEN', 'DRAINING') NOT NULL,
+  TreeType              ENUM('LOG', 'MAP', 'PREORDERED_LOG') NOT NULL,
+  HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256') NOT NULL,
+  HashAlgorithm         ENUM('SHA256') NOT NULL,
+  SignatureAlgorithm    ENUM('ECDSA', 'RSA', 'ED25519') NOT NULL,
+  DisplayName           VARCHAR(20),
+  Description           VARCHAR(200),
+  CreateTimeMillis      BIGINT NOT NULL,
+  UpdateTimeMillis      BIGINT NOT NULL,
+  MaxRootDurationMillis BIGINT NOT NULL,
+  PrivateKey            MEDIUMBLOB NOT NULL,
+  PublicKey             MEDIUMBLOB NOT NULL,
+  Deleted               BOOLEAN,
+  DeleteTimeMillis      BIGINT,
+  PRIMARY KEY(TreeId)
+)`,
+			`CREATE TABLE IF NOT EXISTS TreeControl(
+  TreeId                  BIGINT NOT NULL,
+  SigningEnabled          BOOLEAN NOT NULL,
+  SequencingEnabled       BOOLEAN NOT NULL,
+  SequenceIntervalSeconds INTEGER NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS Subtree(
+  TreeId               BIGINT NOT NULL,
+  SubtreeId            VARBINARY(255) NOT NULL,
+  Nodes                MEDIUMBLOB NOT NULL,
+  SubtreeRevision      INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS TreeHead(
+  TreeId               BIGINT NOT NULL,
+  TreeHeadTimestamp    BIGINT,
+  TreeSize             BIGINT,
+  RootHash             VARBINARY(255) NOT NULL,
+  RootSignature        VARBINARY(1024) NOT NULL,
+  TreeRevision         BIGINT,
+  PRIMARY KEY(TreeId, TreeHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+)`,
+			`CREATE UNIQUE INDEX TreeHeadRevisionIdx
+  ON TreeHead(TreeId, TreeRevision)`,
+			`CREATE TABLE IF NOT EXISTS LeafData(
+  TreeId               BIGINT NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  LeafValue            LONGBLOB NOT NULL,
+  ExtraData            LONGBLOB,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS SequencedLeafData(
+  TreeId               BIGINT NOT NULL,
+  SequenceNumber       BIGINT UNSIGNED NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  MerkleLeafHash       VARBINARY(255) NOT NULL,
+  IntegrateTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, SequenceNumber),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+)`,
+			`CREATE INDEX SequencedLeafMerkleIdx
+  ON SequencedLeafData(TreeId, MerkleLeafHash)`,
+			`CREATE TABLE IF NOT EXISTS Unsequenced(
+  TreeId               BIGINT NOT NULL,
+  Bucket               INTEGER NOT NULL,
+  LeafIdentityHash     VARBINARY(255) NOT NULL,
+  MerkleLeafHash       VARBINARY(255) NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  QueueID VARBINARY(32) DEFAULT NULL UNIQUE,
+  PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
+)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "Add fair-share dequeue order",
+		Statements: []string{
+			"ALTER TABLE Trees ADD COLUMN DequeueOrder ENUM('QUEUE_TIMESTAMP_ORDER', 'FAIR_SHARE_ORDER') NOT NULL DEFAULT 'QUEUE_TIMESTAMP_ORDER'",
+			"ALTER TABLE Unsequenced ADD COLUMN Submitter VARCHAR(255) NOT NULL DEFAULT ''",
+			"CREATE INDEX UnsequencedSubmitterIdx ON Unsequenced(TreeId, Bucket, Submitter, QueueTimestampNanos)",
+		},
+	},
+	{
+		Version:     3,
+		Description: "Add leaf quarantine",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Quarantined(
+  TreeId                   BIGINT NOT NULL,
+  LeafIdentityHash         VARBINARY(255) NOT NULL,
+  MerkleLeafHash           VARBINARY(255) NOT NULL,
+  QueueTimestampNanos      BIGINT NOT NULL,
+  SequenceNumber           BIGINT NOT NULL,
+  Reason                   TEXT NOT NULL,
+  QuarantineTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY (TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+)`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "Add leaf compression",
+		Statements: []string{
+			"ALTER TABLE Trees ADD COLUMN LeafCompression ENUM('NO_LEAF_COMPRESSION', 'ZSTD_LEAF_COMPRESSION') NOT NULL DEFAULT 'NO_LEAF_COMPRESSION'",
+			"ALTER TABLE Trees ADD COLUMN LeafCompressionDictionaryId INTEGER UNSIGNED NOT NULL DEFAULT 0",
+			"ALTER TABLE LeafData ADD COLUMN Compression INTEGER NOT NULL DEFAULT 0",
+		},
+	},
+	{
+		Version:     5,
+		Description: "Add leaf integrate timestamp index",
+		Statements: []string{
+			"CREATE INDEX SequencedLeafIntegrateTimestampIdx ON SequencedLeafData(TreeId, IntegrateTimestampNanos, SequenceNumber)",
+		},
+	},
+	{
+		Version:     6,
+		Description: "Add cold storage tiering",
+		Statements: []string{
+			"ALTER TABLE Subtree ADD COLUMN Tiered BOOLEAN NOT NULL DEFAULT FALSE",
+			"ALTER TABLE LeafData ADD COLUMN Tiered BOOLEAN NOT NULL DEFAULT FALSE",
+		},
+	},
+}
+
+// NewMigrator returns a migrate.Migrator which upgrades the Trillian schema
+// in the given MySQL database.
+func NewMigrator(db *sql.DB) *migrate.Migrator {
+	return migrate.NewMigrator(db, dialect, migrations)
+}
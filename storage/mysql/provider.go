@@ -15,13 +15,20 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/migrate"
+	"github.com/google/trillian/storage/replica"
+	"github.com/google/trillian/storage/tiering"
 
 	// Load MySQL driver
 	_ "github.com/go-sql-driver/mysql"
@@ -32,6 +39,10 @@ var (
 	maxConns = flag.Int("mysql_max_conns", 0, "Maximum connections to the database")
 	maxIdle  = flag.Int("mysql_max_idle_conns", -1, "Maximum idle database connections in the connection pool")
 
+	replicaURIs          = flag.String("mysql_replica_uris", "", "Comma-separated connection URIs of MySQL read replicas, which serve read-only tree snapshots")
+	replicaMaxStaleness  = flag.Duration("mysql_replica_max_staleness", 5*time.Minute, "Maximum age of the latest root of a tree on a read replica for the replica to serve it; 0 means no limit")
+	replicaCheckInterval = flag.Duration("mysql_replica_health_check_interval", 10*time.Second, "Interval between health checks of the MySQL read replicas")
+
 	mysqlMu              sync.Mutex
 	mysqlErr             error
 	mysqlDB              *sql.DB
@@ -49,17 +60,24 @@ func GetDatabase() (*sql.DB, error) {
 }
 
 func init() {
-	if err := storage.RegisterProvider("mysql", newMySQLStorageProvider); err != nil {
+	if err := storage.RegisterProviderWithOptions("mysql", newMySQLStorageProvider); err != nil {
 		glog.Fatalf("Failed to register storage provider mysql: %v", err)
 	}
 }
 
 type mysqlProvider struct {
-	db *sql.DB
-	mf monitoring.MetricFactory
+	db       *sql.DB
+	replicas []*sql.DB
+	cold     tiering.ObjectStore
+	mf       monitoring.MetricFactory
+	weights  storage.SubmitterWeights
+
+	logStorageOnce sync.Once
+	logStorage     storage.LogStorage
+	router         *replica.LogStorage
 }
 
-func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+func newMySQLStorageProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
 	mysqlMu.Lock()
 	defer mysqlMu.Unlock()
 	if mysqlStorageInstance == nil {
@@ -67,9 +85,23 @@ func newMySQLStorageProvider(mf monitoring.MetricFactory) (storage.Provider, err
 		if err != nil {
 			return nil, err
 		}
+		if err := migrate.CheckOrMigrate(context.Background(), NewMigrator(db)); err != nil {
+			return nil, fmt.Errorf("failed to check MySQL schema: %v", err)
+		}
+		cold, err := tiering.SharedStore(mf)
+		if err != nil {
+			return nil, err
+		}
+		replicas, err := openReplicas(*replicaURIs)
+		if err != nil {
+			return nil, err
+		}
 		mysqlStorageInstance = &mysqlProvider{
-			db: db,
-			mf: mf,
+			db:       db,
+			replicas: replicas,
+			cold:     cold,
+			mf:       mf,
+			weights:  opts.SubmitterWeights,
 		}
 	}
 	return mysqlStorageInstance, nil
@@ -96,8 +128,51 @@ func getMySQLDatabaseLocked() (*sql.DB, error) {
 	return db, nil
 }
 
+// openReplicas opens the read replicas with the given comma-separated URIs.
+func openReplicas(uris string) ([]*sql.DB, error) {
+	var dbs []*sql.DB
+	for i, uri := range strings.Split(uris, ",") {
+		if uri = strings.TrimSpace(uri); uri == "" {
+			continue
+		}
+		db, err := OpenDB(uri)
+		if err != nil {
+			for _, db := range dbs {
+				db.Close()
+			}
+			return nil, fmt.Errorf("failed to open MySQL read replica %d: %v", i, err)
+		}
+		if *maxConns > 0 {
+			db.SetMaxOpenConns(*maxConns)
+		}
+		if *maxIdle >= 0 {
+			db.SetMaxIdleConns(*maxIdle)
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
 func (s *mysqlProvider) LogStorage() storage.LogStorage {
-	return NewLogStorage(s.db, s.mf)
+	s.logStorageOnce.Do(func() {
+		s.logStorage = newLogStorage(s.db, s.mf, s.cold, s.weights)
+		if len(s.replicas) == 0 {
+			return
+		}
+		var replicas []replica.Replica
+		for i, db := range s.replicas {
+			replicas = append(replicas, replica.Replica{
+				Name:    fmt.Sprintf("mysql%d", i),
+				Storage: NewLogStorageWithColdStore(db, s.mf, s.cold),
+			})
+		}
+		s.router = replica.NewLogStorage(s.logStorage, replicas, replica.Options{
+			MaxStaleness:        *replicaMaxStaleness,
+			HealthCheckInterval: *replicaCheckInterval,
+		}, s.mf)
+		s.logStorage = s.router
+	})
+	return s.logStorage
 }
 
 func (s *mysqlProvider) AdminStorage() storage.AdminStorage {
@@ -105,5 +180,11 @@ func (s *mysqlProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *mysqlProvider) Close() error {
+	if s.router != nil {
+		s.router.Close()
+	}
+	for _, db := range s.replicas {
+		db.Close()
+	}
 	return s.db.Close()
 }
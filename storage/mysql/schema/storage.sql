@@ -22,6 +22,9 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  DequeueOrder          ENUM('QUEUE_TIMESTAMP_ORDER', 'FAIR_SHARE_ORDER') NOT NULL DEFAULT 'QUEUE_TIMESTAMP_ORDER',
+  LeafCompression       ENUM('NO_LEAF_COMPRESSION', 'ZSTD_LEAF_COMPRESSION') NOT NULL DEFAULT 'NO_LEAF_COMPRESSION',
+  LeafCompressionDictionaryId INTEGER UNSIGNED NOT NULL DEFAULT 0,
   PRIMARY KEY(TreeId)
 );
 
@@ -41,6 +44,8 @@ CREATE TABLE IF NOT EXISTS Subtree(
   SubtreeId            VARBINARY(255) NOT NULL,
   Nodes                MEDIUMBLOB NOT NULL,
   SubtreeRevision      INTEGER NOT NULL,
+  -- Set if Nodes have been moved to cold storage, leaving an empty stub here.
+  Tiered               BOOLEAN NOT NULL DEFAULT FALSE,
   -- Key columns must be in ASC order in order to benefit from group-by/min-max
   -- optimization in MySQL.
   PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision),
@@ -87,6 +92,11 @@ CREATE TABLE IF NOT EXISTS LeafData(
   ExtraData            LONGBLOB,
   -- The timestamp from when this leaf data was first queued for inclusion.
   QueueTimestampNanos  BIGINT NOT NULL,
+  -- The LeafCompression format which LeafValue and ExtraData are stored in.
+  Compression          INTEGER NOT NULL DEFAULT 0,
+  -- Set if LeafValue and ExtraData have been moved to cold storage, leaving
+  -- empty stubs here.
+  Tiered               BOOLEAN NOT NULL DEFAULT FALSE,
   PRIMARY KEY(TreeId, LeafIdentityHash),
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
@@ -116,6 +126,10 @@ CREATE TABLE IF NOT EXISTS SequencedLeafData(
 CREATE INDEX SequencedLeafMerkleIdx
   ON SequencedLeafData(TreeId, MerkleLeafHash);
 
+-- Allows finding the leaves which were integrated within a time window.
+CREATE INDEX SequencedLeafIntegrateTimestampIdx
+  ON SequencedLeafData(TreeId, IntegrateTimestampNanos, SequenceNumber);
+
 CREATE TABLE IF NOT EXISTS Unsequenced(
   TreeId               BIGINT NOT NULL,
   -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
@@ -133,5 +147,39 @@ CREATE TABLE IF NOT EXISTS Unsequenced(
   -- for batched deletes from the table when trillian_log_server and trillian_log_signer are
   -- built with the batched_queue tag.
   QueueID VARBINARY(32) DEFAULT NULL UNIQUE,
+  -- The identity of the submitter which queued the leaf, used to dequeue leaves
+  -- fairly across submitters for trees with the FAIR_SHARE_ORDER dequeue order.
+  Submitter            VARCHAR(255) NOT NULL DEFAULT '',
   PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
 );
+
+CREATE INDEX UnsequencedSubmitterIdx
+  ON Unsequenced(TreeId, Bucket, Submitter, QueueTimestampNanos);
+
+-- Leaves which the log signer failed to integrate, and set aside so that the
+-- rest of the queue can make progress. For PREORDERED_LOG trees the leaves
+-- also remain in SequencedLeafData.
+CREATE TABLE IF NOT EXISTS Quarantined(
+  TreeId                   BIGINT NOT NULL,
+  LeafIdentityHash         VARBINARY(255) NOT NULL,
+  MerkleLeafHash           VARBINARY(255) NOT NULL,
+  QueueTimestampNanos      BIGINT NOT NULL,
+  -- The index of the leaf, only meaningful for PREORDERED_LOG trees.
+  SequenceNumber           BIGINT NOT NULL,
+  Reason                   TEXT NOT NULL,
+  QuarantineTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY (TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- The versions of the schema which have been applied to the database, see
+-- storage/mysql/migrations.go. This file creates the latest version.
+CREATE TABLE IF NOT EXISTS SchemaVersion(
+  Version               INTEGER NOT NULL,
+  Description           VARCHAR(255) NOT NULL,
+  AppliedTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(Version)
+);
+
+INSERT INTO SchemaVersion(Version, Description, AppliedTimestampNanos)
+  VALUES(6, 'Add cold storage tiering', 0);
@@ -0,0 +1,75 @@
+// Copyright 2016 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mysql provides a MySQL-based storage layer implementation.
+//
+// The storage itself is implemented by the sqlcommon package; this package
+// opens and migrates the database, and describes its dialect.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/sqlcommon"
+	"github.com/google/trillian/storage/tiering"
+)
+
+var sqlDialect = &sqlcommon.Dialect{
+	Name:        "mysql",
+	ToGRPC:      mysqlToGRPC,
+	IsDuplicate: isDuplicateErr,
+}
+
+// OpenDB opens a database connection for all MySQL-based storage implementations.
+func OpenDB(dbURL string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dbURL)
+	if err != nil {
+		// Don't log uri as it could contain credentials
+		glog.Warningf("Could not open MySQL database, check config: %s", err)
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(context.TODO(), "SET sql_mode = 'STRICT_ALL_TABLES'"); err != nil {
+		glog.Warningf("Failed to set strict mode on mysql db: %s", err)
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// NewLogStorage creates a storage.LogStorage instance for the specified MySQL URL.
+// It assumes storage.AdminStorage is backed by the same MySQL database as well.
+func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithColdStore(db, mf, nil)
+}
+
+// NewLogStorageWithColdStore is like NewLogStorage, but keeps the data of the
+// leaves and subtrees tiered through storage.TieringLogTreeTX in the given
+// object store. If it is nil, tiering fails, as do reads of tiered data.
+func NewLogStorageWithColdStore(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore) storage.LogStorage {
+	return newLogStorage(db, mf, cold, nil)
+}
+
+func newLogStorage(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore, weights storage.SubmitterWeights) storage.LogStorage {
+	return sqlcommon.NewLogStorage(db, sqlDialect, mf, cold, weights)
+}
+
+// NewAdminStorage returns a MySQL storage.AdminStorage implementation backed by DB.
+func NewAdminStorage(db *sql.DB) storage.AdminStorage {
+	return sqlcommon.NewAdminStorage(db)
+}
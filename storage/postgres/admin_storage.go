@@ -49,7 +49,10 @@ const (
 		public_key,
 		max_root_duration_millis,
 		deleted,
-		delete_time_millis
+		delete_time_millis,
+		dequeue_order,
+		leaf_compression,
+		leaf_compression_dictionary_id
 	FROM trees`
 
 	nonDeletedWhere       = " WHERE deleted = false"
@@ -73,8 +76,11 @@ const (
 		update_time_millis,
 		private_key,
 		public_key,
-		max_root_duration_millis)
-	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+		max_root_duration_millis,
+		dequeue_order,
+		leaf_compression,
+		leaf_compression_dictionary_id)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	insertTreeControlSQL = `INSERT INTO tree_control(
 		tree_id,
@@ -84,8 +90,9 @@ const (
 	VALUES($1, $2, $3, $4)`
 
 	updateTreeSQL = `UPDATE trees SET tree_state = $1, tree_type = $2, display_name = $3, 
-		description = $4, update_time_millis = $5, max_root_duration_millis = $6, private_key = $7
-		WHERE tree_id = $8`
+		description = $4, update_time_millis = $5, max_root_duration_millis = $6, private_key = $7,
+		dequeue_order = $8, leaf_compression = $9, leaf_compression_dictionary_id = $10
+		WHERE tree_id = $11`
 
 	softDeleteSQL = "UPDATE trees SET deleted = $1, delete_time_millis = $2 WHERE tree_id = $3"
 
@@ -326,6 +333,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		privateKey,
 		newTree.PublicKey.GetDer(),
 		rootDuration/time.Millisecond,
+		newTree.DequeueOrder.String(),
+		newTree.LeafCompression.String(),
+		newTree.LeafCompressionDictionaryId,
 	)
 	if err != nil {
 		return nil, err
@@ -397,6 +407,9 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
+		tree.DequeueOrder.String(),
+		tree.LeafCompression.String(),
+		tree.LeafCompressionDictionaryId,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,53 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian/storage/sqlcommon"
+)
+
+const (
+	selectRevisionAtSQL = `SELECT MAX(tree_revision) FROM tree_head
+			WHERE tree_id=$1 AND tree_head_timestamp<=$2`
+	// Selects the latest revision at or before the compaction revision of
+	// each subtree in the batch; all earlier revisions are superseded by it.
+	selectSubtreesForCompactionSQL = `SELECT subtree_id,MAX(subtree_revision)
+			FROM subtree
+			WHERE tree_id=$1 AND subtree_id>$2 AND subtree_revision<=$3
+			GROUP BY subtree_id
+			ORDER BY subtree_id
+			LIMIT $4`
+	deleteSupersededSubtreesSQL = `DELETE FROM subtree
+			WHERE tree_id=$1 AND subtree_id=$2 AND subtree_revision<$3`
+)
+
+var compactionSQL = &sqlcommon.CompactionSQL{
+	SelectRevisionAt: selectRevisionAtSQL,
+	SelectSubtrees:   selectSubtreesForCompactionSQL,
+	DeleteSuperseded: deleteSupersededSubtreesSQL,
+}
+
+// RevisionAt implements storage.SubtreeCompactionTX.
+func (t *logTreeTX) RevisionAt(ctx context.Context, ts time.Time) (int64, error) {
+	return sqlcommon.RevisionAt(ctx, t.tx, compactionSQL, t.treeID, ts)
+}
+
+// CompactSubtrees implements storage.SubtreeCompactionTX.
+func (t *logTreeTX) CompactSubtrees(ctx context.Context, revision int64, after []byte, limit int) ([]byte, int, error) {
+	return sqlcommon.CompactSubtrees(ctx, t.tx, compactionSQL, t.treeID, revision, after, limit)
+}
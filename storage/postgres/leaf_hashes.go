@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The leaf hash statements only read sequenced_leaf_data, so the leaf data is
+// not joined.
+const (
+	selectLeafHashesByRangeSQL = `SELECT s.sequence_number,s.merkle_leaf_hash
+                        FROM sequenced_leaf_data s
+                        WHERE s.sequence_number >= $1 AND s.sequence_number < $2 AND s.tree_id = $3` + orderBySequenceNumberSQL
+	// This statement needs to be expanded to provide the correct number of parameter placeholders.
+	selectLeafHashesByIndexSQL = `SELECT s.sequence_number,s.merkle_leaf_hash
+                        FROM sequenced_leaf_data s
+                        WHERE s.sequence_number IN (` + placeholderSQL + `) AND s.tree_id = <param>`
+)
+
+// GetLeafHashesByIndex implements storage.LeafHashesTX.
+func (t *logTreeTX) GetLeafHashesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	if err := t.checkLeafIndices(leaves); err != nil {
+		return nil, err
+	}
+	tmpl, err := t.ls.getStmt(ctx, &statementSkeleton{
+		sql:               selectLeafHashesByIndexSQL,
+		firstInsertion:    "%s",
+		firstPlaceholders: 1,
+		restInsertion:     "%s",
+		restPlaceholders:  1,
+		num:               len(leaves),
+	})
+	if err != nil {
+		return nil, err
+	}
+	stx := t.tx.StmtContext(ctx, tmpl)
+	defer stx.Close()
+
+	args := make([]interface{}, 0, len(leaves)+1)
+	for _, leaf := range leaves {
+		args = append(args, leaf)
+	}
+	args = append(args, t.treeID)
+	rows, err := stx.QueryContext(ctx, args...)
+	if err != nil {
+		glog.Warningf("Failed to get leaf hashes by idx: %s", err)
+		return nil, err
+	}
+	ret, err := scanLeafHashes(rows, len(leaves))
+	if err != nil {
+		return nil, err
+	}
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+// GetLeafHashesByRange implements storage.LeafHashesTX.
+func (t *logTreeTX) GetLeafHashesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.tx.QueryContext(ctx, selectLeafHashesByRangeSQL, start, start+count, t.treeID)
+	if err != nil {
+		glog.Warningf("Failed to get leaf hashes by range: %s", err)
+		return nil, err
+	}
+	ret, err := scanLeafHashes(rows, int(count))
+	if err != nil {
+		return nil, err
+	}
+	// Pre-ordered logs may have gaps above the tree size, which end the range.
+	for i, leaf := range ret {
+		if want := start + int64(i); leaf.LeafIndex != want {
+			if want < int64(t.root.TreeSize) {
+				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, want)
+			}
+			return ret[:i], nil
+		}
+	}
+	return ret, nil
+}
+
+// scanLeafHashes reads the leaves returned by one of the leaf hash
+// statements, and closes rows.
+func scanLeafHashes(rows *sql.Rows, count int) ([]*trillian.LogLeaf, error) {
+	defer rows.Close()
+	ret := make([]*trillian.LogLeaf, 0, count)
+	for rows.Next() {
+		leaf := &trillian.LogLeaf{}
+		if err := rows.Scan(&leaf.LeafIndex, &leaf.MerkleLeafHash); err != nil {
+			glog.Warningf("Failed to scan leaf hashes: %s", err)
+			return nil, err
+		}
+		ret = append(ret, leaf)
+	}
+	if err := rows.Err(); err != nil {
+		glog.Warningf("Failed to read returned leaf hashes: %s", err)
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian/storage/sqlcommon"
+)
+
+// selectLeafIndexByTimeSQL reads SequencedLeafIntegrateTimestampIdx in order,
+// so it stops at the first matching leaf.
+const selectLeafIndexByTimeSQL = `SELECT sequence_number
+			FROM sequenced_leaf_data
+			WHERE tree_id=$1 AND integrate_timestamp_nanos>=$2 AND sequence_number<$3
+			ORDER BY integrate_timestamp_nanos,sequence_number LIMIT 1`
+
+// GetLeafIndexByTime implements storage.LeafTimeIndexTX.
+func (t *logTreeTX) GetLeafIndexByTime(ctx context.Context, ts time.Time, treeSize int64) (int64, error) {
+	return sqlcommon.GetLeafIndexByTime(ctx, t.tx, selectLeafIndexByTimeSQL, t.treeID, ts, treeSize)
+}
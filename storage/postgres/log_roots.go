@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+const selectSignedLogRootsSQL = `SELECT tree_head_timestamp,tree_size,root_hash,tree_revision,root_signature
+			FROM tree_head
+			WHERE tree_id=$1
+			AND tree_revision>=$2 AND tree_revision<$3
+			AND tree_size>=$4 AND tree_size<$5
+			AND tree_head_timestamp>=$6 AND tree_head_timestamp<$7
+			ORDER BY tree_revision LIMIT $8`
+
+// GetSignedLogRoots implements storage.LogRootHistoryTX.
+func (t *logTreeTX) GetSignedLogRoots(ctx context.Context, filter storage.LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error) {
+	f := filter.Bounded()
+	rows, err := t.tx.QueryContext(ctx, selectSignedLogRootsSQL, t.treeID,
+		f.RevisionBegin, f.RevisionEnd, f.TreeSizeBegin, f.TreeSizeEnd, f.TimestampBegin, f.TimestampEnd, limit)
+	if err != nil {
+		glog.Warningf("Failed to select signed log roots: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.SignedLogRoot
+	for rows.Next() {
+		var timestamp, treeSize, treeRevision int64
+		var rootHash, rootSignature []byte
+		if err := rows.Scan(&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignature); err != nil {
+			return nil, err
+		}
+		// LogRootV1 has a deterministic serialization, so this reproduces the
+		// signed bytes.
+		logRoot, err := (&types.LogRootV1{
+			RootHash:       rootHash,
+			TimestampNanos: uint64(timestamp),
+			Revision:       uint64(treeRevision),
+			TreeSize:       uint64(treeSize),
+		}).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &trillian.SignedLogRoot{
+			KeyHint:          types.SerializeKeyHint(t.treeID),
+			LogRoot:          logRoot,
+			LogRootSignature: rootSignature,
+		})
+	}
+	return ret, rows.Err()
+}
@@ -34,6 +34,8 @@ import (
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compression"
+	"github.com/google/trillian/storage/tiering"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"google.golang.org/grpc/codes"
@@ -42,7 +44,7 @@ import (
 
 const (
 	valuesPlaceholder5     = "($1,$2,$3,$4,$5)"
-	insertLeafDataSQL      = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4,$5)"
+	insertLeafDataSQL      = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4,$5,$6)"
 	insertSequencedLeafSQL = "select insert_sequenced_leaf_data_ignore_duplicates($1,$2,$3,$4,$5)"
 
 	selectNonDeletedTreeIDByTypeAndStateSQL = `
@@ -53,17 +55,17 @@ const (
 	//              FROM tree_head WHERE tree_id=$1
 	//              ORDER BY tree_head_timestamp DESC LIMIT 1`
 
-	selectLeavesByRangeSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
+	selectLeavesByRangeSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos,l.compression,l.tiered
                         FROM leaf_data l,sequenced_leaf_data s
                         WHERE l.leaf_identity_hash = s.leaf_identity_hash
                         AND s.sequence_number >= $1 AND s.sequence_number < $2 AND l.tree_id = $3 AND s.tree_id = l.tree_id` + orderBySequenceNumberSQL
 
 	// These statements need to be expanded to provide the correct number of parameter placeholders.
-	selectLeavesByIndexSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
+	selectLeavesByIndexSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos,l.compression,l.tiered
                         FROM leaf_data l,sequenced_leaf_data s
                         WHERE l.leaf_identity_hash = s.leaf_identity_hash
                         AND s.sequence_number IN (` + placeholderSQL + `) AND l.tree_id = <param> AND s.tree_id = l.tree_id`
-	selectLeavesByMerkleHashSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
+	selectLeavesByMerkleHashSQL = `SELECT s.merkle_leaf_hash,l.leaf_identity_hash,l.leaf_value,s.sequence_number,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos,l.compression,l.tiered
                         FROM leaf_data l,sequenced_leaf_data s
                         WHERE l.leaf_identity_hash = s.leaf_identity_hash
                         AND s.merkle_leaf_hash IN (` + placeholderSQL + `) AND l.tree_id = <param> AND s.tree_id = l.tree_id`
@@ -72,7 +74,7 @@ const (
 	// This statement returns a dummy Merkle leaf hash value (which must be
 	// of the right size) so that its signature matches that of the other
 	// leaf-selection statements.
-	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummymerkleLeafHash + `',l.leaf_identity_hash,l.leaf_value,-1,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos
+	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummymerkleLeafHash + `',l.leaf_identity_hash,l.leaf_value,-1,l.extra_data,l.queue_timestamp_nanos,s.integrate_timestamp_nanos,l.compression,l.tiered
                         FROM leaf_data l LEFT JOIN sequenced_leaf_data s ON (l.leaf_identity_hash = s.leaf_identity_hash AND l.tree_id = s.tree_id)
                         WHERE l.leaf_identity_hash IN (` + placeholderSQL + `) AND l.tree_id = <param>`
 
@@ -131,18 +133,34 @@ type postgresLogStorage struct {
 	*pgTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
 }
 
 // NewLogStorage creates a storage.LogStorage instance for the specified PostgreSQL URL.
 // It assumes storage.AdminStorage is backed by the same PostgreSQL database as well.
 func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithColdStore(db, mf, nil)
+}
+
+// NewLogStorageWithColdStore is like NewLogStorage, but keeps the data of the
+// leaves and subtrees tiered through storage.TieringLogTreeTX in the given
+// object store. If it is nil, tiering fails, as do reads of tiered data.
+func NewLogStorageWithColdStore(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore) storage.LogStorage {
+	return newLogStorage(db, mf, cold, nil)
+}
+
+func newLogStorage(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore, weights storage.SubmitterWeights) *postgresLogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
+	ts := newTreeStorage(db)
+	ts.cold = cold
 	return &postgresLogStorage{
-		admin:         NewAdminStorage(db),
-		pgTreeStorage: newTreeStorage(db),
-		metricFactory: mf,
+		admin:            NewAdminStorage(db),
+		pgTreeStorage:    ts,
+		metricFactory:    mf,
+		submitterWeights: weights,
 	}
 }
 
@@ -263,6 +281,10 @@ func (m *postgresLogStorage) beginInternal(ctx context.Context, tree *trillian.T
 	if err != nil {
 		return nil, err
 	}
+	codec, err := compression.NewCodec(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stCache := cache.NewLogSubtreeCache(defaultLogStrata, hasher)
 	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
@@ -273,6 +295,8 @@ func (m *postgresLogStorage) beginInternal(ctx context.Context, tree *trillian.T
 	ltx := &logTreeTX{
 		treeTX: ttx,
 		ls:     m,
+		order:  tree.DequeueOrder,
+		codec:  codec,
 	}
 	ltx.slr, err = ltx.fetchLatestRoot(ctx)
 	if err == storage.ErrTreeNeedsInit {
@@ -287,6 +311,7 @@ func (m *postgresLogStorage) beginInternal(ctx context.Context, tree *trillian.T
 	}
 
 	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	stCache.UseTileCache(cache.SharedTileCache(m.metricFactory), tree.TreeId, int64(ltx.root.Revision))
 	return ltx, nil
 }
 
@@ -355,9 +380,11 @@ func (m *postgresLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tre
 
 type logTreeTX struct {
 	treeTX
-	ls   *postgresLogStorage
-	root types.LogRootV1
-	slr  *trillian.SignedLogRoot
+	ls    *postgresLogStorage
+	root  types.LogRootV1
+	slr   *trillian.SignedLogRoot
+	order trillian.DequeueOrder
+	codec *compression.Codec
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -384,40 +411,24 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	}
 
 	start := time.Now()
-	stx, err := t.tx.PrepareContext(ctx, selectQueuedLeavesSQL)
-	if err != nil {
-		glog.Warningf("Failed to prepare dequeue select: %s", err)
-		return nil, err
+	var queued []queuedLeaf
+	var err error
+	if t.order == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		queued, err = t.selectQueuedLeavesFairShare(ctx, limit, cutoffTime)
+	} else {
+		queued, err = t.selectQueuedLeaves(ctx, selectQueuedLeavesSQL, t.treeID, cutoffTime.UnixNano(), limit)
 	}
-	defer stx.Close()
-
-	leaves := make([]*trillian.LogLeaf, 0, limit)
-	dq := make([]dequeuedLeaf, 0, limit)
-	rows, err := stx.QueryContext(ctx, t.treeID, cutoffTime.UnixNano(), limit)
 	if err != nil {
-		glog.Warningf("Failed to select rows for work: %s", err)
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		leaf, dqInfo, err := t.dequeueLeaf(rows)
-		if err != nil {
-			glog.Warningf("Error dequeuing leaf: %v %v", err, selectQueuedLeavesSQL)
-			return nil, err
-		}
 
-		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return nil, errors.New("dequeued a leaf with incorrect hash size")
-		}
-
-		leaves = append(leaves, leaf)
-		dq = append(dq, dqInfo)
+	leaves := make([]*trillian.LogLeaf, 0, len(queued))
+	dq := make([]dequeuedLeaf, 0, len(queued))
+	for _, q := range queued {
+		leaves = append(leaves, q.leaf)
+		dq = append(dq, q.info)
 	}
 
-	if rows.Err() != nil {
-		return nil, rows.Err()
-	}
 	label := labelForTX(t)
 	selectDuration := time.Since(start)
 	observe(dequeueSelectLatency, selectDuration, label)
@@ -441,6 +452,77 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	return leaves, nil
 }
 
+// queuedLeaf is a leaf read from the unsequenced table, along with the
+// information needed to remove it from there.
+type queuedLeaf struct {
+	leaf *trillian.LogLeaf
+	info dequeuedLeaf
+}
+
+// selectQueuedLeaves runs the given query against the unsequenced table, and
+// returns the queued leaves in the order that they were selected.
+func (t *logTreeTX) selectQueuedLeaves(ctx context.Context, query string, args ...interface{}) ([]queuedLeaf, error) {
+	stx, err := t.tx.PrepareContext(ctx, query)
+	if err != nil {
+		glog.Warningf("Failed to prepare dequeue select: %s", err)
+		return nil, err
+	}
+	defer stx.Close()
+
+	rows, err := stx.QueryContext(ctx, args...)
+	if err != nil {
+		glog.Warningf("Failed to select rows for work: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queued []queuedLeaf
+	for rows.Next() {
+		leaf, dqInfo, err := t.dequeueLeaf(rows)
+		if err != nil {
+			glog.Warningf("Error dequeuing leaf: %v %v", err, query)
+			return nil, err
+		}
+		queued = append(queued, queuedLeaf{leaf: leaf, info: dqInfo})
+	}
+	return queued, rows.Err()
+}
+
+// selectQueuedLeavesFairShare selects up to limit queued leaves, shared
+// between their submitters as described by storage.FairShare.
+func (t *logTreeTX) selectQueuedLeavesFairShare(ctx context.Context, limit int, cutoffTime time.Time) ([]queuedLeaf, error) {
+	rows, err := t.tx.QueryContext(ctx, selectQueuedLeavesFairShareSQL, t.treeID, cutoffTime.UnixNano(), limit)
+	if err != nil {
+		glog.Warningf("Failed to select rows for work: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The rows are in queue order, so each submitter's queue will be too.
+	queues := make(map[string][]*trillian.LogLeaf)
+	infos := make(map[string]dequeuedLeaf)
+	for rows.Next() {
+		var submitter string
+		leaf, dqInfo, err := t.dequeueLeaf(rows, &submitter)
+		if err != nil {
+			glog.Warningf("Error dequeuing leaf: %v", err)
+			return nil, err
+		}
+		queues[submitter] = append(queues[submitter], leaf)
+		infos[string(leaf.LeafIdentityHash)] = dqInfo
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leaves := storage.FairShare(queues, t.ls.submitterWeights.ForTree(t.treeID), limit)
+	ret := make([]queuedLeaf, len(leaves))
+	for i, leaf := range leaves {
+		ret[i] = queuedLeaf{leaf: leaf, info: infos[string(leaf.LeafIdentityHash)]}
+	}
+	return ret, nil
+}
+
 // sortLeavesForInsert returns a slice containing the passed in leaves sorted
 // by LeafIdentityHash, and paired with their original positions.
 // QueueLeaves and AddSequencedLeaves use this to make the order that LeafData
@@ -468,6 +550,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	}
 	start := time.Now()
 	label := labelForTX(t)
+	submitter := storage.SubmitterFromContext(ctx)
 
 	ordLeaves := sortLeavesForInsert(leaves)
 	existingCount := 0
@@ -481,7 +564,8 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		if err != nil {
 			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
-		dupCheckRow, err := t.tx.QueryContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
+		value, extra, format := t.codec.Encode(leaf.LeafValue, leaf.ExtraData)
+		dupCheckRow, err := t.tx.QueryContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, value, extra, qTimestamp.UnixNano(), format)
 		if err != nil {
 			return nil, fmt.Errorf("dupecheck failed: %v", err)
 		}
@@ -518,6 +602,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
 		args = append(args, queueArgs(t.treeID, leaf.LeafIdentityHash, queueTimestamp)...)
+		args = append(args, submitter)
 		_, err = t.tx.ExecContext(
 			ctx,
 			insertUnsequencedEntrySQL,
@@ -534,6 +619,11 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	observe(queueInsertLatency, insertDuration, label)
 	queuedCounter.Add(float64(len(leaves)), label)
 
+	if existingCount < len(leaves) {
+		if err := t.notifyQueued(ctx); err != nil {
+			return nil, err
+		}
+	}
 	if existingCount == 0 {
 		return existingLeaves, nil
 	}
@@ -614,8 +704,9 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		res[i] = &trillian.QueuedLogLeaf{Status: ok}
 
 		// TODO(pavelkalinnikov): Measure latencies.
+		value, extra, format := t.codec.Encode(leaf.LeafValue, leaf.ExtraData)
 		_, err := t.tx.ExecContext(ctx, insertLeafDataSQL,
-			t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, timestamp.UnixNano())
+			t.treeID, leaf.LeafIdentityHash, value, extra, timestamp.UnixNano(), format)
 		// TODO(pavelkalinnikov): Detach PREORDERED_LOG integration latency metric.
 		if err != nil {
 			glog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
@@ -651,6 +742,9 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 		glog.Errorf("Error releasing savepoint: %s", err)
 		return nil, err
 	}
+	if err := t.notifyQueued(ctx); err != nil {
+		return nil, err
+	}
 
 	return res, nil
 }
@@ -666,18 +760,27 @@ func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
 	return sequencedLeafCount, err
 }
 
-func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+// checkLeafIndices returns an error if any of the given indices is outside
+// the tree. The indices of pre-ordered logs are not checked.
+func (t *logTreeTX) checkLeafIndices(leaves []int64) error {
 	if t.treeType == trillian.TreeType_LOG {
 		treeSize := int64(t.root.TreeSize)
 		for _, leaf := range leaves {
 			if leaf < 0 {
-				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+				return status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
 			}
 			if leaf >= treeSize {
-				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+				return status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
 			}
 		}
 	}
+	return nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	if err := t.checkLeafIndices(leaves); err != nil {
+		return nil, err
+	}
 	tmpl, err := t.ls.getLeavesByIndexStmt(ctx, len(leaves))
 	if err != nil {
 		return nil, err
@@ -701,6 +804,8 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 	for rows.Next() {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
+		var format int32
+		var tiered bool
 		if err := rows.Scan(
 			&leaf.MerkleLeafHash,
 			&leaf.LeafIdentityHash,
@@ -708,10 +813,20 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 			&leaf.LeafIndex,
 			&leaf.ExtraData,
 			&qTimestamp,
-			&iTimestamp); err != nil {
+			&iTimestamp,
+			&format,
+			&tiered); err != nil {
 			glog.Warningf("Failed to scan merkle leaves: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, qTimestamp))
 		if err != nil {
@@ -730,20 +845,22 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 	return ret, nil
 }
 
-func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+// clipLeafRange checks the given range of leaves, and returns its count
+// clipped to the tree. The ranges of pre-ordered logs are not clipped.
+func (t *logTreeTX) clipLeafRange(start, count int64) (int64, error) {
 	if count <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
 	}
 	if start < 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
 	}
 
 	if t.treeType == trillian.TreeType_LOG {
 		treeSize := int64(t.root.TreeSize)
 		if treeSize <= 0 {
-			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+			return 0, status.Errorf(codes.OutOfRange, "empty tree")
 		} else if start >= treeSize {
-			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+			return 0, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
 		}
 		// Ensure no entries queried/returned beyond the tree.
 		if maxCount := treeSize - start; count > maxCount {
@@ -751,6 +868,14 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 		}
 	}
 	// TODO(pavelkalinnikov): Further clip `count` to a safe upper bound like 64k.
+	return count, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return nil, err
+	}
 
 	args := []interface{}{start, start + count, t.treeID}
 	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, args...)
@@ -764,6 +889,8 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 	for wantIndex := start; rows.Next(); wantIndex++ {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
+		var format int32
+		var tiered bool
 		if err := rows.Scan(
 			&leaf.MerkleLeafHash,
 			&leaf.LeafIdentityHash,
@@ -771,10 +898,20 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 			&leaf.LeafIndex,
 			&leaf.ExtraData,
 			&qTimestamp,
-			&iTimestamp); err != nil {
+			&iTimestamp,
+			&format,
+			&tiered); err != nil {
 			glog.Warningf("Failed to scan merkle leaves: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		if leaf.LeafIndex != wantIndex {
 			if wantIndex < int64(t.root.TreeSize) {
 				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
@@ -903,11 +1040,21 @@ func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]
 		// check its validity below.
 		var integrateTS sql.NullInt64
 		var queueTS int64
+		var format int32
+		var tiered bool
 
-		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS); err != nil {
+		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS, &format, &tiered); err != nil {
 			glog.Warningf("LogID: %d Scan() %s = %s", t.treeID, desc, err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, queueTS))
 		if err != nil {
@@ -708,6 +708,54 @@ func TestDequeueLeavesTimeOrdering(t *testing.T) {
 	}
 }
 
+func TestDequeueLeavesFairShare(t *testing.T) {
+	// Queue leaves from a noisy submitter first, then from a submitter with
+	// double weight and a quiet one. The dequeued batch must be shared between
+	// them, rather than taken from the oldest leaves.
+	ctx := context.Background()
+	cleanTestDB(db, t)
+	create := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	create.DequeueOrder = trillian.DequeueOrder_FAIR_SHARE_ORDER
+	tree := createTreeOrPanic(db, create)
+	weights := func(int64) map[string]int { return map[string]int{"heavy": 2} }
+	s := newLogStorage(db, nil, nil, weights)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	submitters := make(map[string]string)
+	queueTime := fakeQueueTime
+	for _, q := range []struct {
+		submitter string
+		count     int
+	}{{"noisy", 10}, {"heavy", 10}, {"quiet", 1}} {
+		leaves := make([]*trillian.LogLeaf, 0, q.count)
+		for i := 0; i < q.count; i++ {
+			value := []byte(fmt.Sprintf("%s %d", q.submitter, i))
+			id := sha256.Sum256(value)
+			submitters[string(id[:])] = q.submitter
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: id[:], MerkleLeafHash: id[:], LeafValue: value})
+		}
+		queueTime = queueTime.Add(time.Second)
+		if _, err := s.QueueLeaves(storage.NewSubmitterContext(ctx, q.submitter), tree, leaves, queueTime); err != nil {
+			t.Fatalf("QueueLeaves(%s): %v", q.submitter, err)
+		}
+	}
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.DequeueLeaves(ctx, 8, queueTime.Add(time.Second))
+		if err != nil {
+			t.Fatalf("DequeueLeaves() = %v", err)
+		}
+		got := make(map[string]int)
+		for _, l := range leaves {
+			got[submitters[string(l.LeafIdentityHash)]]++
+		}
+		if diff := cmp.Diff(got, map[string]int{"noisy": 3, "heavy": 4, "quiet": 1}); diff != "" {
+			t.Errorf("DequeueLeaves() per submitter diff (-got +want):\n%s", diff)
+		}
+		return nil
+	})
+}
+
 func TestGetLeavesByHashNotPresent(t *testing.T) {
 	cleanTestDB(db, t)
 	tree := createTreeOrPanic(db, testonly.LogTree)
@@ -0,0 +1,258 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/google/trillian/storage/migrate"
+)
+
+var dialect = migrate.Dialect{
+	VersionTable: "schema_version",
+	LegacyTable:  "trees",
+	TableExists:  "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=current_schema() AND table_name=$1",
+	CreateVersionTable: `CREATE TABLE IF NOT EXISTS schema_version(
+  version                 INTEGER NOT NULL,
+  description             VARCHAR(255) NOT NULL,
+  applied_timestamp_nanos BIGINT NOT NULL,
+  PRIMARY KEY(version)
+)`,
+	SelectVersion: "SELECT MAX(version) FROM schema_version",
+	InsertVersion: "INSERT INTO schema_version(version,description,applied_timestamp_nanos) VALUES($1,$2,$3)",
+}
+
+// migrations upgrade the schema from the initial version, which predates
+// schema versioning. Changes to schema/storage.sql must be accompanied by a
+// new migration, and storage.sql must record the latest version.
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "Initial schema",
+		Statements: []string{
+			`CREATE TYPE E_TREE_STATE AS ENUM('ACTIVE', 'FROZEN', 'DRAINING')`,
+			`CREATE TYPE E_TREE_TYPE AS ENUM('LOG', 'MAP', 'PREORDERED_LOG')`,
+			`CREATE TYPE E_HASH_STRATEGY AS ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256')`,
+			`CREATE TYPE E_HASH_ALGORITHM AS ENUM('SHA256')`,
+			`CREATE TYPE E_SIGNATURE_ALGORITHM AS ENUM('ECDSA', 'RSA', 'ED25519')`,
+			`CREATE TABLE IF NOT EXISTS trees (
+  tree_id                  BIGINT NOT NULL,
+  tree_state               E_TREE_STATE NOT NULL,
+  tree_type                E_TREE_TYPE NOT NULL,
+  hash_strategy            E_HASH_STRATEGY NOT NULL,
+  hash_algorithm           E_HASH_ALGORITHM NOT NULL,
+  signature_algorithm      E_SIGNATURE_ALGORITHM NOT NULL,
+  display_name             VARCHAR(20),
+  description              VARCHAR(200),
+  create_time_millis       BIGINT NOT NULL,
+  update_time_millis       BIGINT NOT NULL,
+  max_root_duration_millis BIGINT NOT NULL,
+  private_key              BYTEA NOT NULL,
+  public_key               BYTEA NOT NULL,
+  deleted                  BOOLEAN NOT NULL DEFAULT FALSE,
+  delete_time_millis       BIGINT,
+  current_tree_data	   json,
+  root_signature	   BYTEA,
+  PRIMARY KEY(tree_id)
+)`,
+			`CREATE TABLE IF NOT EXISTS tree_control(
+  tree_id                   BIGINT NOT NULL,
+  signing_enabled           BOOLEAN NOT NULL,
+  sequencing_enabled        BOOLEAN NOT NULL,
+  sequence_interval_seconds INTEGER NOT NULL,
+  PRIMARY KEY(tree_id),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS subtree(
+  tree_id               BIGINT NOT NULL,
+  subtree_id            BYTEA NOT NULL,
+  nodes                 BYTEA NOT NULL,
+  subtree_revision      INTEGER NOT NULL,
+  PRIMARY KEY(tree_id, subtree_id, subtree_revision),
+  FOREIGN KEY(tree_id) REFERENCES Trees(tree_id) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS tree_head(
+  tree_id                BIGINT NOT NULL,
+  tree_head_timestamp    BIGINT,
+  tree_size              BIGINT,
+  root_hash              BYTEA NOT NULL,
+  root_signature         BYTEA NOT NULL,
+  tree_revision          BIGINT,
+  PRIMARY KEY(tree_id, tree_revision),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+)`,
+			`CREATE UNIQUE INDEX TreeHeadRevisionIdx ON tree_head(tree_id, tree_revision DESC)`,
+			`CREATE TABLE IF NOT EXISTS leaf_data(
+  tree_id               BIGINT NOT NULL,
+  leaf_identity_hash     BYTEA NOT NULL,
+  leaf_value            BYTEA NOT NULL,
+  extra_data            BYTEA,
+  queue_timestamp_nanos  BIGINT NOT NULL,
+  PRIMARY KEY(tree_id, leaf_identity_hash),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+)`,
+			`CREATE TABLE IF NOT EXISTS sequenced_leaf_data(
+  tree_id                   BIGINT NOT NULL,
+  sequence_number           BIGINT NOT NULL,
+  leaf_identity_hash        BYTEA NOT NULL,
+  merkle_leaf_hash          BYTEA NOT NULL,
+  integrate_timestamp_nanos BIGINT NOT NULL,
+  PRIMARY KEY(tree_id, sequence_number),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE,
+  FOREIGN KEY(tree_id, leaf_identity_hash) REFERENCES leaf_data(tree_id, leaf_identity_hash) ON DELETE CASCADE
+)`,
+			`CREATE INDEX SequencedLeafMerkleIdx ON sequenced_leaf_data(tree_id, merkle_leaf_hash)`,
+			`CREATE TABLE IF NOT EXISTS unsequenced(
+  tree_id               BIGINT NOT NULL,
+  bucket                INTEGER NOT NULL,
+  leaf_identity_hash    BYTEA NOT NULL,
+  merkle_leaf_hash      BYTEA NOT NULL,
+  queue_timestamp_nanos BIGINT NOT NULL,
+  queue_id              BYTEA DEFAULT NULL UNIQUE,
+  PRIMARY KEY (tree_id, bucket, queue_timestamp_nanos, leaf_identity_hash)
+)`,
+			`CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, leaf_value bytea, extra_data bytea, queue_timestamp_nanos bigint)
+ RETURNS boolean
+ LANGUAGE plpgsql
+AS $function$
+    begin
+        INSERT INTO leaf_data(tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos) VALUES (tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos);
+	return true;
+    exception
+        when unique_violation then
+		return false;
+        when others then
+                raise notice '% %', SQLERRM, SQLSTATE;
+    end;
+$function$`,
+			`CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, merkle_leaf_hash bytea, queue_timestamp_nanos bigint)
+ RETURNS boolean
+ LANGUAGE plpgsql
+AS $function$
+    begin
+        INSERT INTO unsequenced(tree_id,bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos) VALUES(tree_id,0,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos);
+        return true;
+    exception
+        when unique_violation then
+                return false;
+        when others then
+                raise notice '% %', SQLERRM, SQLSTATE;
+    end;
+$function$`,
+			`CREATE OR REPLACE FUNCTION public.insert_sequenced_leaf_data_ignore_duplicates(tree_id bigint, sequence_number bigint, leaf_identity_hash bytea, merkle_leaf_hash bytea, integrate_timestamp_nanos bigint)
+ RETURNS boolean
+ LANGUAGE plpgsql
+AS $function$
+    begin
+       INSERT INTO sequenced_leaf_data(tree_id, sequence_number, leaf_identity_hash, merkle_leaf_hash, integrate_timestamp_nanos) VALUES(tree_id, sequence_number, leaf_identity_hash, merkle_leaf_hash, integrate_timestamp_nanos);
+	return true;
+    exception
+        when unique_violation then
+                return false;
+        when others then
+                raise notice '% %', SQLERRM, SQLSTATE;
+    end;
+$function$`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "Add fair-share dequeue order",
+		Statements: []string{
+			"CREATE TYPE E_DEQUEUE_ORDER AS ENUM('QUEUE_TIMESTAMP_ORDER', 'FAIR_SHARE_ORDER')",
+			"ALTER TABLE trees ADD COLUMN dequeue_order E_DEQUEUE_ORDER NOT NULL DEFAULT 'QUEUE_TIMESTAMP_ORDER'",
+			"ALTER TABLE unsequenced ADD COLUMN submitter VARCHAR(255) NOT NULL DEFAULT ''",
+			"CREATE INDEX UnsequencedSubmitterIdx ON unsequenced(tree_id, bucket, submitter, queue_timestamp_nanos)",
+			"DROP FUNCTION IF EXISTS public.insert_leaf_data_ignore_duplicates(bigint, bytea, bytea, bigint)",
+			`CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, merkle_leaf_hash bytea, queue_timestamp_nanos bigint, submitter varchar)
+ RETURNS boolean
+ LANGUAGE plpgsql
+AS $function$
+    begin
+        INSERT INTO unsequenced(tree_id,bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,submitter) VALUES(tree_id,0,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,submitter);
+        return true;
+    exception
+        when unique_violation then
+                return false;
+        when others then
+                raise notice '% %', SQLERRM, SQLSTATE;
+    end;
+$function$`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "Add leaf quarantine",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS quarantined(
+  tree_id                     BIGINT NOT NULL,
+  leaf_identity_hash          BYTEA NOT NULL,
+  merkle_leaf_hash            BYTEA NOT NULL,
+  queue_timestamp_nanos       BIGINT NOT NULL,
+  sequence_number             BIGINT NOT NULL,
+  reason                      TEXT NOT NULL,
+  quarantine_timestamp_nanos  BIGINT NOT NULL,
+  PRIMARY KEY (tree_id, leaf_identity_hash),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+)`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "Add leaf compression",
+		Statements: []string{
+			"CREATE TYPE E_LEAF_COMPRESSION AS ENUM('NO_LEAF_COMPRESSION', 'ZSTD_LEAF_COMPRESSION')",
+			"ALTER TABLE trees ADD COLUMN leaf_compression E_LEAF_COMPRESSION NOT NULL DEFAULT 'NO_LEAF_COMPRESSION'",
+			"ALTER TABLE trees ADD COLUMN leaf_compression_dictionary_id BIGINT NOT NULL DEFAULT 0",
+			"ALTER TABLE leaf_data ADD COLUMN compression INTEGER NOT NULL DEFAULT 0",
+			"DROP FUNCTION IF EXISTS public.insert_leaf_data_ignore_duplicates(bigint, bytea, bytea, bytea, bigint)",
+			`CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, leaf_value bytea, extra_data bytea, queue_timestamp_nanos bigint, compression integer)
+ RETURNS boolean
+ LANGUAGE plpgsql
+AS $function$
+    begin
+        INSERT INTO leaf_data(tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos,compression) VALUES (tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos,compression);
+	return true;
+    exception
+        when unique_violation then
+		return false;
+        when others then
+                raise notice '% %', SQLERRM, SQLSTATE;
+    end;
+$function$`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "Add leaf integrate timestamp index",
+		Statements: []string{
+			"CREATE INDEX SequencedLeafIntegrateTimestampIdx ON sequenced_leaf_data(tree_id, integrate_timestamp_nanos, sequence_number)",
+		},
+	},
+	{
+		Version:     6,
+		Description: "Add cold storage tiering",
+		Statements: []string{
+			"ALTER TABLE subtree ADD COLUMN tiered BOOLEAN NOT NULL DEFAULT FALSE",
+			"ALTER TABLE leaf_data ADD COLUMN tiered BOOLEAN NOT NULL DEFAULT FALSE",
+		},
+	},
+}
+
+// NewMigrator returns a migrate.Migrator which upgrades the Trillian schema
+// in the given Postgres database.
+func NewMigrator(db *sql.DB) *migrate.Migrator {
+	return migrate.NewMigrator(db, dialect, migrations)
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian/storage/postgres/testdb"
+)
+
+// TestSchemaIsLatest checks that schema/storage.sql creates and records the
+// latest schema version.
+func TestSchemaIsLatest(t *testing.T) {
+	ctx := context.Background()
+	db, done, err := testdb.NewTrillianDB(ctx)
+	if err != nil {
+		t.Fatalf("NewTrillianDB(): %v", err)
+	}
+	defer done(ctx)
+
+	m := NewMigrator(db)
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+	if version, versioned, err := m.Version(ctx); err != nil || version != len(migrations) || !versioned {
+		t.Errorf("Version() = %d, %v, %v, want %d, true, nil", version, versioned, err, len(migrations))
+	}
+}
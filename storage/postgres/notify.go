@@ -0,0 +1,91 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+)
+
+const (
+	// queueChannel is the LISTEN/NOTIFY channel which is notified with the
+	// tree ID when leaves are queued. Notifications are delivered when the
+	// queueing transaction commits.
+	queueChannel   = "trillian_queued"
+	notifyQueueSQL = "SELECT pg_notify($1,$2)"
+)
+
+// notifyQueued notifies listeners that leaves have been queued in the tree.
+func (t *logTreeTX) notifyQueued(ctx context.Context) error {
+	if _, err := t.tx.ExecContext(ctx, notifyQueueSQL, queueChannel, strconv.FormatInt(t.treeID, 10)); err != nil {
+		glog.Warningf("Failed to notify queued leaves: %s", err)
+		return err
+	}
+	return nil
+}
+
+// WatchQueue implements storage.QueueWatcher.
+func (s *pgProvider) WatchQueue(ctx context.Context) (<-chan int64, error) {
+	return watchQueue(ctx, *pgConnStr)
+}
+
+// watchQueue listens for notifications of queued leaves on a dedicated
+// connection to the given database until ctx is done.
+func watchQueue(ctx context.Context, connStr string) (<-chan int64, error) {
+	l := pq.NewListener(connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			glog.Warningf("Queue notification listener: %v", err)
+		}
+	})
+	if err := l.Listen(queueChannel); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	ch := make(chan int64, 1)
+	go func() {
+		defer close(ch)
+		defer l.Close()
+		for {
+			var treeID int64
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-l.Notify:
+				// A nil notification means that the connection was re-established,
+				// and notifications may have been missed in the meantime.
+				if n != nil {
+					id, err := strconv.ParseInt(n.Extra, 10, 64)
+					if err != nil {
+						glog.Warningf("Invalid queue notification %q: %v", n.Extra, err)
+						continue
+					}
+					treeID = id
+				}
+			}
+			// Don't block on a slow receiver, which only needs to know that
+			// there is work to do.
+			select {
+			case ch <- treeID:
+			default:
+			}
+		}
+	}()
+	return ch, nil
+}
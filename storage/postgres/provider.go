@@ -15,47 +15,86 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/migrate"
+	"github.com/google/trillian/storage/replica"
+	"github.com/google/trillian/storage/tiering"
 
 	// Load PG driver
 	_ "github.com/lib/pq"
 )
 
 var (
-	pgConnStr         = flag.String("pg_conn_str", "user=postgres dbname=test port=5432 sslmode=disable", "Connection string for Postgres database")
+	pgConnStr = flag.String("pg_conn_str", "user=postgres dbname=test port=5432 sslmode=disable", "Connection string for Postgres database")
+	// Connection strings may contain commas, e.g. in a list of hosts, so
+	// replicas are separated by semicolons.
+	pgReplicaConnStrs    = flag.String("pg_replica_conn_strs", "", "Semicolon-separated connection strings of Postgres read replicas, which serve read-only tree snapshots")
+	pgReplicaMaxStale    = flag.Duration("pg_replica_max_staleness", 5*time.Minute, "Maximum age of the latest root of a tree on a read replica for the replica to serve it; 0 means no limit")
+	pgReplicaCheckPeriod = flag.Duration("pg_replica_health_check_interval", 10*time.Second, "Interval between health checks of the Postgres read replicas")
+
 	pgOnce            sync.Once
 	pgOnceErr         error
 	pgStorageInstance *pgProvider
 )
 
 func init() {
-	if err := storage.RegisterProvider("postgres", newPGProvider); err != nil {
+	if err := storage.RegisterProviderWithOptions("postgres", newPGProvider); err != nil {
 		glog.Fatalf("Failed to register storage provider postgres: %v", err)
 	}
 }
 
 type pgProvider struct {
-	db *sql.DB
-	mf monitoring.MetricFactory
+	db       *sql.DB
+	replicas []*sql.DB
+	cold     tiering.ObjectStore
+	mf       monitoring.MetricFactory
+	weights  storage.SubmitterWeights
+
+	logStorageOnce sync.Once
+	logStorage     storage.LogStorage
+	router         *replica.LogStorage
 }
 
-func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
+func newPGProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
 	pgOnce.Do(func() {
+		var cold tiering.ObjectStore
+		cold, pgOnceErr = tiering.SharedStore(mf)
+		if pgOnceErr != nil {
+			return
+		}
 		var db *sql.DB
 		db, pgOnceErr = OpenDB(*pgConnStr)
 		if pgOnceErr != nil {
 			return
 		}
+		if err := migrate.CheckOrMigrate(context.Background(), NewMigrator(db)); err != nil {
+			db.Close()
+			pgOnceErr = fmt.Errorf("failed to check Postgres schema: %v", err)
+			return
+		}
+		var replicas []*sql.DB
+		replicas, pgOnceErr = openReplicas(*pgReplicaConnStrs)
+		if pgOnceErr != nil {
+			db.Close()
+			return
+		}
 
 		pgStorageInstance = &pgProvider{
-			db: db,
-			mf: mf,
+			db:       db,
+			replicas: replicas,
+			cold:     cold,
+			mf:       mf,
+			weights:  opts.SubmitterWeights,
 		}
 	})
 	if pgOnceErr != nil {
@@ -64,9 +103,52 @@ func newPGProvider(mf monitoring.MetricFactory) (storage.Provider, error) {
 	return pgStorageInstance, nil
 }
 
+// GetDatabase opens the Postgres database given by the --pg_conn_str flag.
+func GetDatabase() (*sql.DB, error) {
+	return OpenDB(*pgConnStr)
+}
+
+// openReplicas opens the read replicas with the given semicolon-separated
+// connection strings.
+func openReplicas(connStrs string) ([]*sql.DB, error) {
+	var dbs []*sql.DB
+	for i, connStr := range strings.Split(connStrs, ";") {
+		if connStr = strings.TrimSpace(connStr); connStr == "" {
+			continue
+		}
+		db, err := OpenDB(connStr)
+		if err != nil {
+			for _, db := range dbs {
+				db.Close()
+			}
+			return nil, fmt.Errorf("failed to open Postgres read replica %d: %v", i, err)
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
 func (s *pgProvider) LogStorage() storage.LogStorage {
 	glog.Warningf("Support for the PostgreSQL log is experimental.  Please use at your own risk!!!")
-	return NewLogStorage(s.db, s.mf)
+	s.logStorageOnce.Do(func() {
+		s.logStorage = newLogStorage(s.db, s.mf, s.cold, s.weights)
+		if len(s.replicas) == 0 {
+			return
+		}
+		var replicas []replica.Replica
+		for i, db := range s.replicas {
+			replicas = append(replicas, replica.Replica{
+				Name:    fmt.Sprintf("postgres%d", i),
+				Storage: NewLogStorageWithColdStore(db, s.mf, s.cold),
+			})
+		}
+		s.router = replica.NewLogStorage(s.logStorage, replicas, replica.Options{
+			MaxStaleness:        *pgReplicaMaxStale,
+			HealthCheckInterval: *pgReplicaCheckPeriod,
+		}, s.mf)
+		s.logStorage = s.router
+	})
+	return s.logStorage
 }
 
 func (s *pgProvider) AdminStorage() storage.AdminStorage {
@@ -74,5 +156,11 @@ func (s *pgProvider) AdminStorage() storage.AdminStorage {
 }
 
 func (s *pgProvider) Close() error {
+	if s.router != nil {
+		s.router.Close()
+	}
+	for _, db := range s.replicas {
+		db.Close()
+	}
 	return s.db.Close()
 }
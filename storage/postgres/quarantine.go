@@ -0,0 +1,130 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+)
+
+const (
+	upsertQuarantinedSQL = `INSERT INTO quarantined(tree_id,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,sequence_number,reason,quarantine_timestamp_nanos)
+			VALUES($1,$2,$3,$4,$5,$6,$7)
+			ON CONFLICT (tree_id,leaf_identity_hash) DO UPDATE
+			SET merkle_leaf_hash=EXCLUDED.merkle_leaf_hash,
+			queue_timestamp_nanos=EXCLUDED.queue_timestamp_nanos,
+			sequence_number=EXCLUDED.sequence_number,
+			reason=EXCLUDED.reason,
+			quarantine_timestamp_nanos=EXCLUDED.quarantine_timestamp_nanos`
+	selectQuarantinedSQL = `SELECT leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,sequence_number,reason,quarantine_timestamp_nanos
+			FROM quarantined
+			WHERE tree_id=$1
+			ORDER BY quarantine_timestamp_nanos,leaf_identity_hash`
+	deleteQuarantinedSQL = `DELETE FROM quarantined
+			WHERE tree_id=$1 AND leaf_identity_hash=$2
+			RETURNING merkle_leaf_hash`
+)
+
+// QuarantineLeaves records the given leaves as quarantined. DequeueLeaves has
+// already removed them from the queue of LOG trees.
+func (t *logTreeTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	for _, ql := range leaves {
+		leaf := ql.Leaf
+		queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		quarantineTimestamp, err := ptypes.Timestamp(ql.QuarantineTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid quarantine timestamp: %v", err)
+		}
+		if _, err := t.tx.ExecContext(ctx, upsertQuarantinedSQL,
+			t.treeID,
+			leaf.LeafIdentityHash,
+			leaf.MerkleLeafHash,
+			queueTimestamp.UnixNano(),
+			leaf.LeafIndex,
+			ql.Reason,
+			quarantineTimestamp.UnixNano()); err != nil {
+			glog.Warningf("Failed to quarantine leaf: %s", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	rows, err := t.tx.QueryContext(ctx, selectQuarantinedSQL, t.treeID)
+	if err != nil {
+		glog.Warningf("Failed to select quarantined leaves: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.QuarantinedLeaf
+	for rows.Next() {
+		var queueTimestamp, quarantineTimestamp int64
+		ql := &trillian.QuarantinedLeaf{Leaf: &trillian.LogLeaf{}}
+		if err := rows.Scan(&ql.Leaf.LeafIdentityHash, &ql.Leaf.MerkleLeafHash, &queueTimestamp, &ql.Leaf.LeafIndex, &ql.Reason, &quarantineTimestamp); err != nil {
+			glog.Warningf("Failed to scan quarantined leaf: %s", err)
+			return nil, err
+		}
+		if ql.Leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, queueTimestamp)); err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if ql.QuarantineTimestamp, err = ptypes.TimestampProto(time.Unix(0, quarantineTimestamp)); err != nil {
+			return nil, fmt.Errorf("got invalid quarantine timestamp: %v", err)
+		}
+		ret = append(ret, ql)
+	}
+	return ret, rows.Err()
+}
+
+func (t *logTreeTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	var ret [][]byte
+	for _, id := range leafIdentityHashes {
+		var merkleHash []byte
+		if err := t.tx.QueryRowContext(ctx, deleteQuarantinedSQL, t.treeID, id).Scan(&merkleHash); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			glog.Warningf("Failed to delete quarantined leaf: %s", err)
+			return nil, err
+		}
+
+		if t.treeType != trillian.TreeType_PREORDERED_LOG {
+			args := []interface{}{t.treeID, id, merkleHash}
+			args = append(args, queueArgs(t.treeID, id, queueTimestamp)...)
+			args = append(args, "")
+			if _, err := t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, args...); err != nil {
+				glog.Warningf("Error inserting into unsequenced: %s query %v arguments: %v", err, insertUnsequencedEntrySQL, args)
+				return nil, err
+			}
+		}
+		ret = append(ret, id)
+	}
+	if len(ret) > 0 {
+		if err := t.notifyQueued(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
@@ -1,3 +1,4 @@
+//go:build !batched_queue
 // +build !batched_queue
 
 // Copyright 2017 Google LLC. All Rights Reserved.
@@ -26,17 +27,34 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 )
 
 const (
-	// If this statement ORDER BY clause is changed refer to the comment in removeSequencedLeaves
+	// If this statement ORDER BY clause is changed refer to the comment in removeSequencedLeaves.
+	// Rows locked by a concurrent dequeue are skipped, rather than waited for.
 	selectQueuedLeavesSQL = `SELECT leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos
                         FROM unsequenced
                         WHERE tree_id=$1
                         AND bucket=0
                         AND queue_timestamp_nanos<=$2
-                        ORDER BY queue_timestamp_nanos,leaf_identity_hash ASC LIMIT $3`
-	insertUnsequencedEntrySQL = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4)"
+                        ORDER BY queue_timestamp_nanos,leaf_identity_hash ASC LIMIT $3
+                        FOR UPDATE SKIP LOCKED`
+	// selectQueuedLeavesFairShareSQL selects up to the given number of the
+	// oldest queued leaves of every submitter, along with their submitter.
+	// Rows locked by a concurrent dequeue are skipped, rather than waited for.
+	selectQueuedLeavesFairShareSQL = `SELECT u.leaf_identity_hash,u.merkle_leaf_hash,u.queue_timestamp_nanos,u.submitter
+                        FROM unsequenced u
+                        JOIN (SELECT tree_id,bucket,queue_timestamp_nanos,leaf_identity_hash,
+                                ROW_NUMBER() OVER (PARTITION BY submitter ORDER BY queue_timestamp_nanos,leaf_identity_hash) AS submitter_rank
+                                FROM unsequenced
+                                WHERE tree_id=$1
+                                AND bucket=0
+                                AND queue_timestamp_nanos<=$2) AS ranked
+                        USING (tree_id,bucket,queue_timestamp_nanos,leaf_identity_hash)
+                        WHERE ranked.submitter_rank<=$3
+                        FOR UPDATE OF u SKIP LOCKED`
+	insertUnsequencedEntrySQL = "select insert_leaf_data_ignore_duplicates($1,$2,$3,$4,$5)"
 	deleteUnsequencedSQL      = "DELETE FROM unsequenced WHERE tree_id = $1 and bucket=0 and queue_timestamp_nanos = $2 and leaf_identity_hash=$3"
 )
 
@@ -49,12 +67,14 @@ func dequeueInfo(leafIDHash []byte, queueTimestamp int64) dequeuedLeaf {
 	return dequeuedLeaf{queueTimestampNanos: queueTimestamp, leafIdentityHash: leafIDHash}
 }
 
-func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf, error) {
+// dequeueLeaf scans a row selected from the Unsequenced table. The columns
+// following those of the leaf are scanned into extra.
+func (t *logTreeTX) dequeueLeaf(rows *sql.Rows, extra ...interface{}) (*trillian.LogLeaf, dequeuedLeaf, error) {
 	var leafIDHash []byte
 	var merkleHash []byte
 	var queueTimestamp int64
 
-	err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp)
+	err := rows.Scan(append([]interface{}{&leafIDHash, &merkleHash, &queueTimestamp}, extra...)...)
 	if err != nil {
 		glog.Warningf("Error scanning work rows: %s", err)
 		return nil, dequeuedLeaf{}, err
@@ -83,12 +103,12 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	for _, leaf := range leaves {
 		// This should fail on insert but catch it early
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return errors.New("sequenced leaf has incorrect hash size")
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: errors.New("sequenced leaf has incorrect hash size")}
 		}
 
 		iTimestamp, err := ptypes.Timestamp(leaf.IntegrateTimestamp)
 		if err != nil {
-			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid integrate timestamp: %v", err)}
 		}
 		_, err = t.tx.ExecContext(
 			ctx,
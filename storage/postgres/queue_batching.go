@@ -1,3 +1,4 @@
+//go:build batched_queue
 // +build batched_queue
 
 // Copyright 2017 Google LLC. All Rights Reserved.
@@ -28,17 +29,34 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 )
 
 const (
-	// If this statement ORDER BY clause is changed refer to the comment in removeSequencedLeaves
+	// If this statement ORDER BY clause is changed refer to the comment in removeSequencedLeaves.
+	// Rows locked by a concurrent dequeue are skipped, rather than waited for.
 	selectQueuedLeavesSQL = `SELECT leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,queue_id
                         FROM unsequenced
                         WHERE tree_id=$1
                         AND Bucket=0
                         AND queue_timestamp_nanos<=$2
-                        ORDER BY queue_timestamp_nanos,leaf_identity_hash ASC LIMIT $3`
-	insertUnsequencedEntrySQL = `INSERT INTO unsequenced(tree_id,Bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,queue_id) VALUES($1,0,$2,$3,$4,$5)`
+                        ORDER BY queue_timestamp_nanos,leaf_identity_hash ASC LIMIT $3
+                        FOR UPDATE SKIP LOCKED`
+	// selectQueuedLeavesFairShareSQL selects up to the given number of the
+	// oldest queued leaves of every submitter, along with their submitter.
+	// Rows locked by a concurrent dequeue are skipped, rather than waited for.
+	selectQueuedLeavesFairShareSQL = `SELECT u.leaf_identity_hash,u.merkle_leaf_hash,u.queue_timestamp_nanos,u.queue_id,u.submitter
+                        FROM unsequenced u
+                        JOIN (SELECT tree_id,bucket,queue_timestamp_nanos,leaf_identity_hash,
+                                ROW_NUMBER() OVER (PARTITION BY submitter ORDER BY queue_timestamp_nanos,leaf_identity_hash) AS submitter_rank
+                                FROM unsequenced
+                                WHERE tree_id=$1
+                                AND bucket=0
+                                AND queue_timestamp_nanos<=$2) AS ranked
+                        USING (tree_id,bucket,queue_timestamp_nanos,leaf_identity_hash)
+                        WHERE ranked.submitter_rank<=$3
+                        FOR UPDATE OF u SKIP LOCKED`
+	insertUnsequencedEntrySQL = `INSERT INTO unsequenced(tree_id,Bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,queue_id,submitter) VALUES($1,0,$2,$3,$4,$5,$6)`
 	deleteUnsequencedSQL      = "DELETE FROM unsequenced WHERE queue_id IN (<placeholder>)"
 )
 
@@ -48,13 +66,15 @@ func dequeueInfo(_ []byte, queueID []byte) dequeuedLeaf {
 	return dequeuedLeaf(queueID)
 }
 
-func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf, error) {
+// dequeueLeaf scans a row selected from the Unsequenced table. The columns
+// following those of the leaf are scanned into extra.
+func (t *logTreeTX) dequeueLeaf(rows *sql.Rows, extra ...interface{}) (*trillian.LogLeaf, dequeuedLeaf, error) {
 	var leafIDHash []byte
 	var merkleHash []byte
 	var queueTimestamp int64
 	var queueID []byte
 
-	err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp, &queueID)
+	err := rows.Scan(append([]interface{}{&leafIDHash, &merkleHash, &queueTimestamp, &queueID}, extra...)...)
 	if err != nil {
 		glog.Warningf("Error scanning work rows: %s", err)
 		return nil, nil, err
@@ -98,7 +118,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	for _, leaf := range leaves {
 		iTimestamp, err := ptypes.Timestamp(leaf.IntegrateTimestamp)
 		if err != nil {
-			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid integrate timestamp: %v", err)}
 		}
 		querySuffix = append(querySuffix, valuesPlaceholder5)
 		args = append(args, t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.LeafIndex, iTimestamp.UnixNano())
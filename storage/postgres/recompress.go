@@ -0,0 +1,83 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+)
+
+const (
+	selectLeafDataForRecompressionSQL = `SELECT leaf_identity_hash,leaf_value,extra_data,compression
+			FROM leaf_data
+			WHERE tree_id=$1 AND leaf_identity_hash>$2 AND NOT tiered
+			ORDER BY leaf_identity_hash
+			LIMIT $3
+			FOR UPDATE`
+	updateLeafDataCompressionSQL = `UPDATE leaf_data SET leaf_value=$1,extra_data=$2,compression=$3
+			WHERE tree_id=$4 AND leaf_identity_hash=$5`
+)
+
+// RecompressLeaves implements storage.RecompressLogTreeTX.
+func (t *logTreeTX) RecompressLeaves(ctx context.Context, after []byte, limit int) ([]byte, int, error) {
+	if after == nil {
+		after = []byte{}
+	}
+	rows, err := t.tx.QueryContext(ctx, selectLeafDataForRecompressionSQL, t.treeID, after, limit)
+	if err != nil {
+		glog.Warningf("Failed to select leaf data for recompression: %s", err)
+		return nil, 0, err
+	}
+	type leafData struct {
+		id, value, extra []byte
+		format           int32
+	}
+	var batch []leafData
+	for rows.Next() {
+		var l leafData
+		if err := rows.Scan(&l.id, &l.value, &l.extra, &l.format); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		batch = append(batch, l)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, 0, err
+	}
+	rows.Close()
+
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+	rewritten := 0
+	for _, l := range batch {
+		value, extra, format, changed, err := t.codec.Recode(trillian.LeafCompression(l.format), l.value, l.extra)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !changed {
+			continue
+		}
+		if _, err := t.tx.ExecContext(ctx, updateLeafDataCompressionSQL, value, extra, format, t.treeID, l.id); err != nil {
+			glog.Warningf("Failed to update leaf data: %s", err)
+			return nil, 0, err
+		}
+		rewritten++
+	}
+	return batch[len(batch)-1].id, rewritten, nil
+}
@@ -9,6 +9,8 @@ CREATE TYPE E_TREE_TYPE AS ENUM('LOG', 'MAP', 'PREORDERED_LOG');--end
 CREATE TYPE E_HASH_STRATEGY AS ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256');--end
 CREATE TYPE E_HASH_ALGORITHM AS ENUM('SHA256');--end
 CREATE TYPE E_SIGNATURE_ALGORITHM AS ENUM('ECDSA', 'RSA', 'ED25519');--end
+CREATE TYPE E_DEQUEUE_ORDER AS ENUM('QUEUE_TIMESTAMP_ORDER', 'FAIR_SHARE_ORDER');--end
+CREATE TYPE E_LEAF_COMPRESSION AS ENUM('NO_LEAF_COMPRESSION', 'ZSTD_LEAF_COMPRESSION');--end
 
 -- Tree parameters should not be changed after creation. Doing so can
 -- render the data in the tree unusable or inconsistent.
@@ -30,6 +32,9 @@ CREATE TABLE IF NOT EXISTS trees (
   delete_time_millis       BIGINT,
   current_tree_data	   json,
   root_signature	   BYTEA,
+  dequeue_order            E_DEQUEUE_ORDER NOT NULL DEFAULT 'QUEUE_TIMESTAMP_ORDER',
+  leaf_compression         E_LEAF_COMPRESSION NOT NULL DEFAULT 'NO_LEAF_COMPRESSION',
+  leaf_compression_dictionary_id BIGINT NOT NULL DEFAULT 0,
   PRIMARY KEY(tree_id)
 );--end
 
@@ -49,6 +54,8 @@ CREATE TABLE IF NOT EXISTS subtree(
   subtree_id            BYTEA NOT NULL,
   nodes                 BYTEA NOT NULL,
   subtree_revision      INTEGER NOT NULL,
+  -- Set if nodes have been moved to cold storage, leaving an empty stub here.
+  tiered                BOOLEAN NOT NULL DEFAULT FALSE,
   PRIMARY KEY(tree_id, subtree_id, subtree_revision),
   FOREIGN KEY(tree_id) REFERENCES Trees(tree_id) ON DELETE CASCADE
 );--end
@@ -94,6 +101,11 @@ CREATE TABLE IF NOT EXISTS leaf_data(
   extra_data            BYTEA,
   -- The timestamp from when this leaf data was first queued for inclusion.
   queue_timestamp_nanos  BIGINT NOT NULL,
+  -- The LeafCompression format which leaf_value and extra_data are stored in.
+  compression           INTEGER NOT NULL DEFAULT 0,
+  -- Set if leaf_value and extra_data have been moved to cold storage, leaving
+  -- empty stubs here.
+  tiered                BOOLEAN NOT NULL DEFAULT FALSE,
   PRIMARY KEY(tree_id, leaf_identity_hash),
   FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
 );--end
@@ -122,6 +134,9 @@ CREATE TABLE IF NOT EXISTS sequenced_leaf_data(
 
 CREATE INDEX SequencedLeafMerkleIdx ON sequenced_leaf_data(tree_id, merkle_leaf_hash);--end
 
+-- Allows finding the leaves which were integrated within a time window.
+CREATE INDEX SequencedLeafIntegrateTimestampIdx ON sequenced_leaf_data(tree_id, integrate_timestamp_nanos, sequence_number);--end
+
 CREATE TABLE IF NOT EXISTS unsequenced(
   tree_id               BIGINT NOT NULL,
   -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
@@ -139,15 +154,36 @@ CREATE TABLE IF NOT EXISTS unsequenced(
   -- for batched deletes from the table when trillian_log_server and trillian_log_signer are
   -- built with the batched_queue tag.
   queue_id              BYTEA DEFAULT NULL UNIQUE,
+  -- The identity of the submitter which queued the leaf, used to dequeue leaves
+  -- fairly across submitters for trees with the FAIR_SHARE_ORDER dequeue order.
+  submitter             VARCHAR(255) NOT NULL DEFAULT '',
   PRIMARY KEY (tree_id, bucket, queue_timestamp_nanos, leaf_identity_hash)
 );--end
 
-CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, leaf_value bytea, extra_data bytea, queue_timestamp_nanos bigint)
+CREATE INDEX UnsequencedSubmitterIdx ON unsequenced(tree_id, bucket, submitter, queue_timestamp_nanos);--end
+
+-- Leaves which the log signer failed to integrate, and set aside so that the
+-- rest of the queue can make progress. For PREORDERED_LOG trees the leaves
+-- also remain in sequenced_leaf_data.
+CREATE TABLE IF NOT EXISTS quarantined(
+  tree_id                     BIGINT NOT NULL,
+  leaf_identity_hash          BYTEA NOT NULL,
+  merkle_leaf_hash            BYTEA NOT NULL,
+  queue_timestamp_nanos       BIGINT NOT NULL,
+  -- The index of the leaf, only meaningful for PREORDERED_LOG trees.
+  sequence_number             BIGINT NOT NULL,
+  reason                      TEXT NOT NULL,
+  quarantine_timestamp_nanos  BIGINT NOT NULL,
+  PRIMARY KEY (tree_id, leaf_identity_hash),
+  FOREIGN KEY(tree_id) REFERENCES trees(tree_id) ON DELETE CASCADE
+);--end
+
+CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, leaf_value bytea, extra_data bytea, queue_timestamp_nanos bigint, compression integer)
  RETURNS boolean
  LANGUAGE plpgsql
 AS $function$
     begin
-        INSERT INTO leaf_data(tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos) VALUES (tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos);
+        INSERT INTO leaf_data(tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos,compression) VALUES (tree_id,leaf_identity_hash,leaf_value,extra_data,queue_timestamp_nanos,compression);
 	return true;
     exception
         when unique_violation then
@@ -157,12 +193,12 @@ AS $function$
     end;
 $function$;--end
 
-CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, merkle_leaf_hash bytea, queue_timestamp_nanos bigint)
+CREATE OR REPLACE FUNCTION public.insert_leaf_data_ignore_duplicates(tree_id bigint, leaf_identity_hash bytea, merkle_leaf_hash bytea, queue_timestamp_nanos bigint, submitter varchar)
  RETURNS boolean
  LANGUAGE plpgsql
 AS $function$
     begin
-        INSERT INTO unsequenced(tree_id,bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos) VALUES(tree_id,0,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos);
+        INSERT INTO unsequenced(tree_id,bucket,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,submitter) VALUES(tree_id,0,leaf_identity_hash,merkle_leaf_hash,queue_timestamp_nanos,submitter);
         return true;
     exception
         when unique_violation then
@@ -188,3 +224,15 @@ AS $function$
                 raise notice '% %', SQLERRM, SQLSTATE;
     end;
 $function$;--end
+
+-- The versions of the schema which have been applied to the database, see
+-- storage/postgres/migrations.go. This file creates the latest version.
+CREATE TABLE IF NOT EXISTS schema_version(
+  version                 INTEGER NOT NULL,
+  description             VARCHAR(255) NOT NULL,
+  applied_timestamp_nanos BIGINT NOT NULL,
+  PRIMARY KEY(version)
+);--end
+
+INSERT INTO schema_version(version, description, applied_timestamp_nanos)
+  VALUES(6, 'Add cold storage tiering', 0);--end
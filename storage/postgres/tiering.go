@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage/sqlcommon"
+	"github.com/google/trillian/storage/tiering"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	selectLeafDataForTieringSQL = `SELECT l.leaf_identity_hash,l.leaf_value,l.extra_data
+			FROM leaf_data l,sequenced_leaf_data s
+			WHERE l.leaf_identity_hash = s.leaf_identity_hash
+			AND s.sequence_number >= $1 AND s.sequence_number < $2 AND l.tree_id = $3 AND s.tree_id = l.tree_id
+			AND NOT l.tiered
+			FOR UPDATE OF l`
+	updateLeafDataTieredSQL = `UPDATE leaf_data SET leaf_value=$1,extra_data=NULL,tiered=TRUE
+			WHERE tree_id=$2 AND leaf_identity_hash=$3`
+
+	selectSubtreesForTieringSQL = `SELECT subtree_id,MAX(subtree_revision)
+			FROM subtree
+			WHERE tree_id=$1 AND subtree_id>$2
+			GROUP BY subtree_id
+			ORDER BY subtree_id
+			LIMIT $3`
+	selectSubtreeForTieringSQL = `SELECT nodes,tiered FROM subtree
+			WHERE tree_id=$1 AND subtree_id=$2 AND subtree_revision=$3
+			FOR UPDATE`
+	updateSubtreeTieredSQL = `UPDATE subtree SET nodes=$1,tiered=TRUE
+			WHERE tree_id=$2 AND subtree_id=$3 AND subtree_revision=$4`
+)
+
+var tieringSQL = &sqlcommon.TieringSQL{
+	SelectLeafData:       selectLeafDataForTieringSQL,
+	UpdateLeafDataTiered: updateLeafDataTieredSQL,
+	SelectSubtrees:       selectSubtreesForTieringSQL,
+	SelectSubtree:        selectSubtreeForTieringSQL,
+	UpdateSubtreeTiered:  updateSubtreeTieredSQL,
+}
+
+// coldStore returns the object store which tiered data is kept in, or an
+// error if the storage was created without one.
+func (t *treeTX) coldStore() (tiering.ObjectStore, error) {
+	if t.ts.cold == nil {
+		return nil, status.Error(codes.FailedPrecondition, "cold storage is not configured")
+	}
+	return t.ts.cold, nil
+}
+
+// readTieredLeaf replaces the stub data of a tiered leaf with its data read
+// from the cold store.
+func (t *logTreeTX) readTieredLeaf(ctx context.Context, leaf *trillian.LogLeaf) error {
+	cold, err := t.coldStore()
+	if err != nil {
+		return err
+	}
+	data, err := cold.Get(ctx, tiering.LeafKey(t.treeID, leaf.LeafIdentityHash))
+	if err != nil {
+		return fmt.Errorf("failed to read tiered leaf %x: %v", leaf.LeafIdentityHash, err)
+	}
+	leaf.LeafValue, leaf.ExtraData, err = tiering.UnmarshalLeafData(data)
+	return err
+}
+
+// readTieredTile returns the stored form of a tiered subtree, read from the
+// cold store.
+func (t *treeTX) readTieredTile(ctx context.Context, subtreeID []byte, rev int64) ([]byte, error) {
+	cold, err := t.coldStore()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := cold.Get(ctx, tiering.TileKey(t.treeID, subtreeID, rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tiered subtree %x at revision %d: %v", subtreeID, rev, err)
+	}
+	return nodes, nil
+}
+
+// TierLeaves implements storage.TieringLogTreeTX.
+func (t *logTreeTX) TierLeaves(ctx context.Context, start, end int64) (int, error) {
+	cold, err := t.coldStore()
+	if err != nil {
+		return 0, err
+	}
+	return sqlcommon.TierLeaves(ctx, t.tx, tieringSQL, cold, t.treeID, start, end)
+}
+
+// TierTiles implements storage.TieringLogTreeTX.
+func (t *logTreeTX) TierTiles(ctx context.Context, treeSize int64, after []byte, limit int) ([]byte, int, error) {
+	cold, err := t.coldStore()
+	if err != nil {
+		return nil, 0, err
+	}
+	return sqlcommon.TierTiles(ctx, t.tx, tieringSQL, cold, t.treeID, treeSize, after, limit)
+}
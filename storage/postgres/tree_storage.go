@@ -29,6 +29,7 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tiering"
 	"github.com/google/trillian/storage/tree"
 )
 
@@ -39,7 +40,7 @@ const (
 	// to get the benefits of a loose index scan, which would improve
 	// performance: https://wiki.postgresql.org/wiki/Loose_indexscan
 	selectSubtreeSQL = `
-		SELECT x.subtree_id, x.max_revision, subtree.nodes
+		SELECT x.subtree_id, x.max_revision, subtree.nodes, subtree.tiered
 		FROM (
 			SELECT n.subtree_id, max(n.subtree_revision) AS max_revision
 			FROM subtree n
@@ -65,6 +66,10 @@ type pgTreeStorage struct {
 	// in the query to the statement that should be used.
 	statementMutex sync.Mutex
 	statements     map[string]map[int]*sql.Stmt
+
+	// cold holds the data of tiered leaves and subtrees, or is nil if tiering
+	// is not configured.
+	cold tiering.ObjectStore
 }
 
 // OpenDB opens a database connection for all PG-based storage implementations.
@@ -280,11 +285,17 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 		var subtreeIDBytes []byte
 		var subtreeRev int64
 		var nodesRaw []byte
+		var tiered bool
 		var subtree storagepb.SubtreeProto
-		if err := rows.Scan(&subtreeIDBytes, &subtreeRev, &nodesRaw); err != nil {
+		if err := rows.Scan(&subtreeIDBytes, &subtreeRev, &nodesRaw, &tiered); err != nil {
 			glog.Warningf("Failed to scan merkle subtree: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if nodesRaw, err = t.readTieredTile(ctx, subtreeIDBytes, subtreeRev); err != nil {
+				return nil, err
+			}
+		}
 		if err := proto.Unmarshal(nodesRaw, &subtree); err != nil {
 			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
 			return nil, err
@@ -15,23 +15,44 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/google/trillian/monitoring"
 )
 
+// ProviderOptions holds the options which apply to all storage providers.
+type ProviderOptions struct {
+	// SubmitterWeights, if set, weights the submitters of the leaves dequeued
+	// from trees with FAIR_SHARE_ORDER.
+	SubmitterWeights SubmitterWeights
+}
+
 // NewProviderFunc is the signature of a function which can be registered to
 // provide instances of storage providers.
 type NewProviderFunc func(monitoring.MetricFactory) (Provider, error)
 
+// NewProviderWithOptionsFunc is the signature of a function which can be
+// registered to provide instances of storage providers which take options.
+type NewProviderWithOptionsFunc func(monitoring.MetricFactory, ProviderOptions) (Provider, error)
+
 var (
 	spMu     sync.RWMutex
-	spByName = make(map[string]NewProviderFunc)
+	spByName = make(map[string]NewProviderWithOptionsFunc)
 )
 
-// RegisterProvider registers the given storage Provider.
+// RegisterProvider registers the given storage Provider. The provider ignores
+// the options passed to NewProviderWithOptions.
 func RegisterProvider(name string, sp NewProviderFunc) error {
+	return RegisterProviderWithOptions(name, func(mf monitoring.MetricFactory, _ ProviderOptions) (Provider, error) {
+		return sp(mf)
+	})
+}
+
+// RegisterProviderWithOptions registers the given storage Provider, which
+// takes the options passed to NewProviderWithOptions.
+func RegisterProviderWithOptions(name string, sp NewProviderWithOptionsFunc) error {
 	spMu.Lock()
 	defer spMu.Unlock()
 
@@ -43,17 +64,25 @@ func RegisterProvider(name string, sp NewProviderFunc) error {
 	return nil
 }
 
-// NewProvider returns a new Provider instance of the type specified by name.
+// NewProvider returns a new Provider instance of the type specified by name,
+// with the default options.
 func NewProvider(name string, mf monitoring.MetricFactory) (Provider, error) {
-	spMu.RLock()
-	defer spMu.RUnlock()
+	return NewProviderWithOptions(name, mf, ProviderOptions{})
+}
 
+// NewProviderWithOptions returns a new Provider instance of the type specified
+// by name, with the given options.
+func NewProviderWithOptions(name string, mf monitoring.MetricFactory, opts ProviderOptions) (Provider, error) {
+	// The lock isn't held while creating the Provider, so that providers
+	// which wrap others can create them.
+	spMu.RLock()
 	sp := spByName[name]
+	spMu.RUnlock()
 	if sp == nil {
 		return nil, fmt.Errorf("no such storage provider %v", name)
 	}
 
-	return sp(mf)
+	return sp(mf, opts)
 }
 
 // Providers returns a slice of all registered storage provider names.
@@ -80,3 +109,13 @@ type Provider interface {
 	// Close closes the underlying storage.
 	Close() error
 }
+
+// QueueWatcher is implemented by Providers which can notify the log signer
+// of newly queued leaves, so that it needn't poll for them.
+type QueueWatcher interface {
+	// WatchQueue returns a channel which receives the ID of a tree after
+	// leaves are queued in it, or 0 if notifications may have been missed,
+	// until ctx is done. Notifications may be coalesced or dropped while the
+	// receiver is busy.
+	WatchQueue(ctx context.Context) (<-chan int64, error)
+}
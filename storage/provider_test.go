@@ -98,3 +98,19 @@ func TestProviders(t *testing.T) {
 		t.Errorf("Providers() gave %d 'b', want 1", b)
 	}
 }
+
+func TestProviderWithOptions(t *testing.T) {
+	var got map[string]int
+	RegisterProviderWithOptions("with options", func(_ monitoring.MetricFactory, opts ProviderOptions) (Provider, error) {
+		got = opts.SubmitterWeights(1)
+		return &provider{}, nil
+	})
+	want := map[string]int{"a": 2}
+	opts := ProviderOptions{SubmitterWeights: func(int64) map[string]int { return want }}
+	if _, err := NewProviderWithOptions("with options", nil, opts); err != nil {
+		t.Fatalf("NewProviderWithOptions = %v, want no error", err)
+	}
+	if got["a"] != 2 {
+		t.Errorf("provider got weights %v, want %v", got, want)
+	}
+}
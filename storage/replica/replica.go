@@ -0,0 +1,251 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replica provides a storage.LogStorage which serves read-only tree
+// snapshots from read replicas of the database of a primary LogStorage.
+package replica
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+const (
+	replicaLabel = "replica"
+	reasonLabel  = "reason"
+
+	// Reasons for serving a snapshot from the primary.
+	reasonUnhealthy = "unhealthy"
+	reasonError     = "error"
+	reasonNoRoot    = "no_root"
+	reasonStale     = "stale"
+	reasonTooSmall  = "too_small"
+)
+
+var (
+	once             sync.Once
+	healthyGauge     monitoring.Gauge
+	snapshotsCounter monitoring.Counter
+	fallbackCounter  monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	healthyGauge = mf.NewGauge("replica_healthy", "Whether the read replica passed its last health check", replicaLabel)
+	snapshotsCounter = mf.NewCounter("replica_snapshots", "Number of tree snapshots served by the read replica", replicaLabel)
+	fallbackCounter = mf.NewCounter("replica_fallbacks", "Number of tree snapshots served by the primary instead of the read replica", replicaLabel, reasonLabel)
+}
+
+// Replica is a LogStorage backed by a read replica of the primary database.
+type Replica struct {
+	// Name identifies the replica in logs and metrics, so it must not
+	// contain credentials.
+	Name    string
+	Storage storage.LogStorage
+}
+
+// Options configures the routing of snapshots to replicas.
+type Options struct {
+	// MaxStaleness is the maximum age of the latest root of a tree on a
+	// replica for the replica to serve snapshots of the tree. Zero means
+	// that replicas serve snapshots regardless of the age of the root.
+	// Logs without new leaves only get a new root every MaxRootDuration
+	// of the tree, so MaxStaleness should be longer than that.
+	MaxStaleness time.Duration
+	// HealthCheckInterval is the interval between health checks of each
+	// replica. Zero disables health checking.
+	HealthCheckInterval time.Duration
+}
+
+// replicaState is a Replica along with its health.
+type replicaState struct {
+	Replica
+	// healthy is 1 if the replica passed its last health check, 0 otherwise.
+	healthy int32
+}
+
+func (r *replicaState) setHealthy(healthy bool) {
+	v := int32(0)
+	if healthy {
+		v = 1
+	}
+	if atomic.SwapInt32(&r.healthy, v) != v {
+		if healthy {
+			glog.Infof("Read replica %s is healthy", r.Name)
+		} else {
+			glog.Warningf("Read replica %s is unhealthy", r.Name)
+		}
+	}
+	healthyGauge.Set(float64(v), r.Name)
+}
+
+func (r *replicaState) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// LogStorage is a storage.LogStorage which serves SnapshotForTree from its
+// replicas in round-robin order, and everything else from the primary.
+//
+// A snapshot is taken from the primary instead if the chosen replica is
+// unhealthy, has no root for the tree, or has a latest root which is older
+// than Options.MaxStaleness or smaller than the tree size carried by
+// storage.MinTreeSizeFromContext. Snapshot, which lists the active logs to
+// sequence, is always served by the primary.
+type LogStorage struct {
+	storage.LogStorage
+	replicas []*replicaState
+	opts     Options
+	next     uint32
+	timeNow  func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLogStorage returns a LogStorage which routes snapshots to the given
+// replicas of primary. If health checking is enabled, the replicas are
+// checked in the background until Close is called.
+func NewLogStorage(primary storage.LogStorage, replicas []Replica, opts Options, mf monitoring.MetricFactory) *LogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	once.Do(func() {
+		createMetrics(mf)
+	})
+	s := &LogStorage{
+		LogStorage: primary,
+		opts:       opts,
+		timeNow:    time.Now,
+		done:       make(chan struct{}),
+	}
+	for _, r := range replicas {
+		rs := &replicaState{Replica: r}
+		// Replicas are presumed healthy until checked.
+		rs.setHealthy(true)
+		s.replicas = append(s.replicas, rs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	if opts.HealthCheckInterval <= 0 || len(replicas) == 0 {
+		close(s.done)
+		return s
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(opts.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			s.checkHealth(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Close stops the health checks of the replicas. It doesn't close the
+// underlying storage.
+func (s *LogStorage) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// checkHealth checks whether each replica's database is accessible.
+func (s *LogStorage) checkHealth(ctx context.Context) {
+	for _, r := range s.replicas {
+		cctx, cancel := context.WithTimeout(ctx, s.opts.HealthCheckInterval)
+		err := r.Storage.CheckDatabaseAccessible(cctx)
+		cancel()
+		if err != nil && ctx.Err() != nil {
+			// Shutting down.
+			return
+		}
+		if err != nil {
+			glog.V(1).Infof("Health check of read replica %s failed: %v", r.Name, err)
+		}
+		r.setHealthy(err == nil)
+	}
+}
+
+// pick returns the next replica in round-robin order.
+func (s *LogStorage) pick() *replicaState {
+	i := atomic.AddUint32(&s.next, 1)
+	return s.replicas[int(i)%len(s.replicas)]
+}
+
+// SnapshotForTree implements storage.LogStorage.
+func (s *LogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	if len(s.replicas) == 0 {
+		return s.LogStorage.SnapshotForTree(ctx, tree)
+	}
+	r := s.pick()
+	if !r.isHealthy() {
+		fallbackCounter.Inc(r.Name, reasonUnhealthy)
+		return s.LogStorage.SnapshotForTree(ctx, tree)
+	}
+	tx, reason := s.replicaSnapshot(ctx, r, tree)
+	if tx == nil {
+		fallbackCounter.Inc(r.Name, reason)
+		return s.LogStorage.SnapshotForTree(ctx, tree)
+	}
+	snapshotsCounter.Inc(r.Name)
+	return tx, nil
+}
+
+// replicaSnapshot returns a snapshot of the tree taken from the replica, or
+// the reason why the replica can't serve it.
+func (s *LogStorage) replicaSnapshot(ctx context.Context, r *replicaState, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, string) {
+	tx, err := r.Storage.SnapshotForTree(ctx, tree)
+	if err == storage.ErrTreeNeedsInit {
+		// The replica may not have caught up with the first root yet.
+		tx.Close()
+		return nil, reasonNoRoot
+	} else if err != nil {
+		glog.Warningf("Failed to take snapshot of tree %d from read replica %s: %v", tree.TreeId, r.Name, err)
+		r.setHealthy(false)
+		return nil, reasonError
+	}
+	slr, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		tx.Close()
+		return nil, reasonNoRoot
+	}
+	var root types.LogRootV1
+	if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+		tx.Close()
+		return nil, reasonError
+	}
+	if root.TreeSize < storage.MinTreeSizeFromContext(ctx) {
+		tx.Close()
+		return nil, reasonTooSmall
+	}
+	if s.opts.MaxStaleness > 0 {
+		if age := s.timeNow().Sub(time.Unix(0, int64(root.TimestampNanos))); age > s.opts.MaxStaleness {
+			tx.Close()
+			return nil, reasonStale
+		}
+	}
+	return tx, ""
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replica
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+var (
+	tree = &trillian.Tree{TreeId: 1}
+	now  = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+func mustRoot(t *testing.T, size uint64, age time.Duration) *trillian.SignedLogRoot {
+	t.Helper()
+	b, err := (&types.LogRootV1{TreeSize: size, TimestampNanos: uint64(now.Add(-age).UnixNano())}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(): %v", err)
+	}
+	return &trillian.SignedLogRoot{LogRoot: b}
+}
+
+func TestSnapshotForTree(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		unhealthy   bool
+		minTreeSize uint64
+		rootSize    uint64
+		rootAge     time.Duration
+		rootErr     error
+		snapshotErr error
+		wantPrimary bool
+	}{
+		{desc: "fresh", rootSize: 10, rootAge: time.Second},
+		{desc: "unhealthy", unhealthy: true, wantPrimary: true},
+		{desc: "stale", rootSize: 10, rootAge: time.Hour, wantPrimary: true},
+		{desc: "large-enough", minTreeSize: 10, rootSize: 10, rootAge: time.Second},
+		{desc: "too-small", minTreeSize: 11, rootSize: 10, rootAge: time.Second, wantPrimary: true},
+		{desc: "no-root", snapshotErr: storage.ErrTreeNeedsInit, wantPrimary: true},
+		{desc: "root-error", rootErr: errors.New("boom"), wantPrimary: true},
+		{desc: "snapshot-error", snapshotErr: errors.New("boom"), wantPrimary: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := storage.NewMinTreeSizeContext(context.Background(), tc.minTreeSize)
+
+			primary := storage.NewMockLogStorage(ctrl)
+			replica := storage.NewMockLogStorage(ctrl)
+			primaryTX := storage.NewMockLogTreeTX(ctrl)
+			replicaTX := storage.NewMockLogTreeTX(ctrl)
+
+			if !tc.unhealthy {
+				if tc.snapshotErr == storage.ErrTreeNeedsInit {
+					replica.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(replicaTX, tc.snapshotErr)
+					replicaTX.EXPECT().Close()
+				} else if tc.snapshotErr != nil {
+					replica.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(nil, tc.snapshotErr)
+				} else {
+					replica.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(replicaTX, nil)
+					replicaTX.EXPECT().LatestSignedLogRoot(gomock.Any()).Return(mustRoot(t, tc.rootSize, tc.rootAge), tc.rootErr)
+					if tc.wantPrimary {
+						replicaTX.EXPECT().Close()
+					}
+				}
+			}
+			if tc.wantPrimary {
+				primary.EXPECT().SnapshotForTree(gomock.Any(), tree).Return(primaryTX, nil)
+			}
+
+			s := NewLogStorage(primary, []Replica{{Name: "r0", Storage: replica}}, Options{MaxStaleness: time.Minute}, nil)
+			defer s.Close()
+			s.timeNow = func() time.Time { return now }
+			s.replicas[0].setHealthy(!tc.unhealthy)
+
+			tx, err := s.SnapshotForTree(ctx, tree)
+			if err != nil {
+				t.Fatalf("SnapshotForTree(): %v", err)
+			}
+			want := storage.ReadOnlyLogTreeTX(replicaTX)
+			if tc.wantPrimary {
+				want = primaryTX
+			}
+			if tx != want {
+				t.Errorf("SnapshotForTree() returned the snapshot of the wrong storage, want primary: %v", tc.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := storage.NewMockLogStorage(ctrl)
+	var replicas []Replica
+	for _, name := range []string{"up", "down"} {
+		r := storage.NewMockLogStorage(ctrl)
+		var err error
+		if name == "down" {
+			err = errors.New("connection refused")
+		}
+		r.EXPECT().CheckDatabaseAccessible(gomock.Any()).Return(err).AnyTimes()
+		replicas = append(replicas, Replica{Name: name, Storage: r})
+	}
+
+	s := NewLogStorage(primary, replicas, Options{}, nil)
+	defer s.Close()
+	s.checkHealth(context.Background())
+	for i, want := range []bool{true, false} {
+		if got := s.replicas[i].isHealthy(); got != want {
+			t.Errorf("replica %s healthy: %v, want %v", s.replicas[i].Name, got, want)
+		}
+		if got, want := healthyGauge.Value(s.replicas[i].Name), map[bool]float64{true: 1}[want]; got != want {
+			t.Errorf("replica_healthy{%s}: %v, want %v", s.replicas[i].Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "context"
+
+type minTreeSizeKey struct{}
+
+// NewMinTreeSizeContext returns a context carrying the smallest tree size a
+// caller of LogStorage.SnapshotForTree needs the latest root to cover, e.g.
+// the tree size of a requested proof. Storage implementations which may serve
+// snapshots from stale read replicas use it to fall back to the primary.
+func NewMinTreeSizeContext(ctx context.Context, treeSize uint64) context.Context {
+	return context.WithValue(ctx, minTreeSizeKey{}, treeSize)
+}
+
+// MinTreeSizeFromContext returns the tree size carried by ctx, or 0 if there
+// is none.
+func MinTreeSizeFromContext(ctx context.Context) uint64 {
+	size, _ := ctx.Value(minTreeSizeKey{}).(uint64)
+	return size
+}
@@ -54,7 +54,7 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 	tree := &trillian.Tree{}
 
 	// Enums and Datetimes need an extra conversion step
-	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
+	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm, dequeueOrder, leafCompression string
 	var createMillis, updateMillis, maxRootDurationMillis int64
 	var displayName, description sql.NullString
 	var privateKey, publicKey []byte
@@ -76,6 +76,9 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&dequeueOrder,
+		&leafCompression,
+		&tree.LeafCompressionDictionaryId,
 	)
 	if err != nil {
 		return nil, err
@@ -110,18 +113,30 @@ func ReadTree(row Row) (*trillian.Tree, error) {
 	} else {
 		return nil, fmt.Errorf("unknown SignatureAlgorithm: %v", signatureAlgorithm)
 	}
+	if do, ok := trillian.DequeueOrder_value[dequeueOrder]; ok {
+		tree.DequeueOrder = trillian.DequeueOrder(do)
+	} else {
+		return nil, fmt.Errorf("unknown DequeueOrder: %v", dequeueOrder)
+	}
+	if lc, ok := trillian.LeafCompression_value[leafCompression]; ok {
+		tree.LeafCompression = trillian.LeafCompression(lc)
+	} else {
+		return nil, fmt.Errorf("unknown LeafCompression: %v", leafCompression)
+	}
 
 	// Let's make sure we didn't mismatch any of the casts above
 	ok := tree.TreeState.String() == treeState &&
 		tree.TreeType.String() == treeType &&
 		tree.HashStrategy.String() == hashStrategy &&
 		tree.HashAlgorithm.String() == hashAlgorithm &&
-		tree.SignatureAlgorithm.String() == signatureAlgorithm
+		tree.SignatureAlgorithm.String() == signatureAlgorithm &&
+		tree.DequeueOrder.String() == dequeueOrder &&
+		tree.LeafCompression.String() == leafCompression
 	if !ok {
 		return nil, fmt.Errorf(
-			"mismatched enum: tree = %v, enums = [%v, %v, %v, %v, %v]",
+			"mismatched enum: tree = %v, enums = [%v, %v, %v, %v, %v, %v, %v]",
 			tree,
-			treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm)
+			treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm, dequeueOrder, leafCompression)
 	}
 
 	tree.CreateTime, err = ptypes.TimestampProto(FromMillisSinceEpoch(createMillis))
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mysql
+package sqlcommon
 
 import (
 	"context"
@@ -33,7 +33,7 @@ import (
 const (
 	defaultSequenceIntervalSeconds = 60
 
-	nonDeletedWhere = " WHERE (Deleted IS NULL OR Deleted = 'false')"
+	nonDeletedWhere = " WHERE (Deleted IS NULL OR Deleted = FALSE)"
 
 	selectTreeIDs           = "SELECT TreeId FROM Trees"
 	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
@@ -54,31 +54,36 @@ const (
 			PublicKey,
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			DequeueOrder,
+			LeafCompression,
+			LeafCompressionDictionaryId
 		FROM Trees`
 	selectNonDeletedTrees = selectTrees + nonDeletedWhere
 	selectTreeByID        = selectTrees + " WHERE TreeId = ?"
 
 	updateTreeSQL = `UPDATE Trees
-		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?
+		SET TreeState = ?, TreeType = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, DequeueOrder = ?,
+			LeafCompression = ?, LeafCompressionDictionaryId = ?
 		WHERE TreeId = ?`
 )
 
-// NewAdminStorage returns a MySQL storage.AdminStorage implementation backed by DB.
+// NewAdminStorage returns a storage.AdminStorage implementation backed by DB,
+// which holds the MySQL schema.
 func NewAdminStorage(db *sql.DB) storage.AdminStorage {
-	return &mysqlAdminStorage{db}
+	return &sqlAdminStorage{db}
 }
 
-// mysqlAdminStorage implements storage.AdminStorage
-type mysqlAdminStorage struct {
+// sqlAdminStorage implements storage.AdminStorage
+type sqlAdminStorage struct {
 	db *sql.DB
 }
 
-func (s *mysqlAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+func (s *sqlAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
 	return s.beginInternal(ctx)
 }
 
-func (s *mysqlAdminStorage) beginInternal(ctx context.Context) (storage.AdminTX, error) {
+func (s *sqlAdminStorage) beginInternal(ctx context.Context) (storage.AdminTX, error) {
 	tx, err := s.db.BeginTx(ctx, nil /* opts */)
 	if err != nil {
 		return nil, err
@@ -86,7 +91,7 @@ func (s *mysqlAdminStorage) beginInternal(ctx context.Context) (storage.AdminTX,
 	return &adminTX{tx: tx}, nil
 }
 
-func (s *mysqlAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
+func (s *sqlAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.AdminTXFunc) error {
 	tx, err := s.beginInternal(ctx)
 	if err != nil {
 		return err
@@ -98,7 +103,7 @@ func (s *mysqlAdminStorage) ReadWriteTransaction(ctx context.Context, f storage.
 	return tx.Commit()
 }
 
-func (s *mysqlAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
+func (s *sqlAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
@@ -276,8 +281,11 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 			UpdateTimeMillis,
 			PrivateKey,
 			PublicKey,
-			MaxRootDurationMillis)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+			MaxRootDurationMillis,
+			DequeueOrder,
+			LeafCompression,
+			LeafCompressionDictionaryId)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +311,9 @@ func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillia
 		privateKey,
 		newTree.PublicKey.GetDer(),
 		rootDuration/time.Millisecond,
+		newTree.DequeueOrder.String(),
+		newTree.LeafCompression.String(),
+		newTree.LeafCompressionDictionaryId,
 	)
 	if err != nil {
 		return nil, err
@@ -393,6 +404,9 @@ func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
+		tree.DequeueOrder.String(),
+		tree.LeafCompression.String(),
+		tree.LeafCompressionDictionaryId,
 		tree.TreeId); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,148 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// CompactionSQL holds the statements used to compact subtrees. They take the
+// arguments, and return the columns, in the order listed.
+type CompactionSQL struct {
+	// SelectRevisionAt returns the revision of the latest tree head at or
+	// before a time, given the tree ID and the time in nanoseconds.
+	SelectRevisionAt string
+	// SelectSubtrees returns the ID of each subtree, and its latest revision
+	// at or before the compaction revision, given the tree ID, the subtree ID
+	// to start after, the compaction revision, and the max number of them.
+	SelectSubtrees string
+	// DeleteSuperseded deletes the revisions of a subtree which are superseded
+	// by a later one, given the tree ID, subtree ID and later revision.
+	DeleteSuperseded string
+}
+
+// RevisionAt returns the revision of the latest root of the tree signed at or
+// before the given time, or -1 if there is none.
+func RevisionAt(ctx context.Context, tx *sql.Tx, q *CompactionSQL, treeID int64, ts time.Time) (int64, error) {
+	var rev sql.NullInt64
+	if err := tx.QueryRowContext(ctx, q.SelectRevisionAt, treeID, ts.UnixNano()).Scan(&rev); err != nil {
+		glog.Warningf("Failed to select revision at %v: %s", ts, err)
+		return 0, err
+	}
+	if !rev.Valid {
+		return -1, nil
+	}
+	return rev.Int64, nil
+}
+
+// CompactSubtrees deletes the revisions of subtrees of the given tree which
+// are superseded by a later revision at or before the given one. It looks at
+// up to limit subtrees with IDs after the given one, and returns the ID of the
+// last one, or nil if there are none left, along with the number of subtree
+// revisions deleted.
+func CompactSubtrees(ctx context.Context, tx *sql.Tx, q *CompactionSQL, treeID, revision int64, after []byte, limit int) ([]byte, int, error) {
+	if after == nil {
+		after = []byte{}
+	}
+	batch, err := selectSubtreeRevisions(ctx, tx, q.SelectSubtrees, treeID, after, revision, limit)
+	if err != nil {
+		glog.Warningf("Failed to select subtrees for compaction: %s", err)
+		return nil, 0, err
+	}
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+	deleted := 0
+	for _, s := range batch {
+		res, err := tx.ExecContext(ctx, q.DeleteSuperseded, treeID, s.id, s.rev)
+		if err != nil {
+			glog.Warningf("Failed to delete superseded subtrees: %s", err)
+			return nil, 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, 0, err
+		}
+		deleted += int(n)
+	}
+	return batch[len(batch)-1].id, deleted, nil
+}
+
+const (
+	selectRevisionAtSQL = `SELECT MAX(TreeRevision) FROM TreeHead
+			WHERE TreeId=? AND TreeHeadTimestamp<=?`
+	// Selects the latest revision at or before the compaction revision of
+	// each subtree in the batch; all earlier revisions are superseded by it.
+	selectSubtreesForCompactionSQL = `SELECT SubtreeId,MAX(SubtreeRevision)
+			FROM Subtree
+			WHERE TreeId=? AND SubtreeId>? AND SubtreeRevision<=?
+			GROUP BY SubtreeId
+			ORDER BY SubtreeId
+			LIMIT ?`
+	deleteSupersededSubtreesSQL = `DELETE FROM Subtree
+			WHERE TreeId=? AND SubtreeId=? AND SubtreeRevision<?`
+)
+
+var compactionSQL = &CompactionSQL{
+	SelectRevisionAt: selectRevisionAtSQL,
+	SelectSubtrees:   selectSubtreesForCompactionSQL,
+	DeleteSuperseded: deleteSupersededSubtreesSQL,
+}
+
+// subtreeRevision identifies a revision of a subtree.
+type subtreeRevision struct {
+	id  []byte
+	rev int64
+}
+
+// selectSubtreeRevisions runs a query which returns subtree IDs and revisions.
+func selectSubtreeRevisions(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]subtreeRevision, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []subtreeRevision
+	for rows.Next() {
+		var s subtreeRevision
+		if err := rows.Scan(&s.id, &s.rev); err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, rows.Err()
+}
+
+// RevisionAt implements storage.SubtreeCompactionTX.
+func (t *logTreeTX) RevisionAt(ctx context.Context, ts time.Time) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	rev, err := RevisionAt(ctx, t.tx, compactionSQL, t.treeID, ts)
+	return rev, t.ts.dialect.ToGRPC(err)
+}
+
+// CompactSubtrees implements storage.SubtreeCompactionTX.
+func (t *logTreeTX) CompactSubtrees(ctx context.Context, revision int64, after []byte, limit int) ([]byte, int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	next, n, err := CompactSubtrees(ctx, t.tx, compactionSQL, t.treeID, revision, after, limit)
+	return next, n, t.ts.dialect.ToGRPC(err)
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"database/sql"
+
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tiering"
+)
+
+// Dialect describes a database which holds the MySQL schema and understands
+// the SQL run by the storage in this package, e.g. MySQL or SQLite.
+type Dialect struct {
+	// Name prefixes the names of the metrics of the storage, and identifies
+	// the database in errors.
+	Name string
+	// ToGRPC converts errors of the database to GRPC errors, where that gives
+	// clients more signal, e.g. when the operation can be retried.
+	ToGRPC func(error) error
+	// IsDuplicate returns whether an error is a violation of a unique key.
+	IsDuplicate func(error) bool
+	// NoRowLocks is set for databases which don't support SELECT ... FOR
+	// UPDATE, because their transactions lock the whole database instead.
+	NoRowLocks bool
+}
+
+// lockRows returns the given SELECT statement locking the rows it reads, if
+// the database supports that.
+func (d *Dialect) lockRows(query string) string {
+	if d.NoRowLocks {
+		return query
+	}
+	return query + "\n\t\t\tFOR UPDATE"
+}
+
+// NewLogStorage creates a storage.LogStorage instance for the given database
+// of the given dialect. It assumes storage.AdminStorage is backed by the same
+// database. The data of the leaves and subtrees tiered through
+// storage.TieringLogTreeTX is kept in the given object store; if it is nil,
+// tiering fails, as do reads of tiered data. The FAIR_SHARE_ORDER trees of the
+// storage dequeue leaves with the given submitter weights, which may be nil.
+func NewLogStorage(db *sql.DB, d *Dialect, mf monitoring.MetricFactory, cold tiering.ObjectStore, weights storage.SubmitterWeights) storage.LogStorage {
+	return newLogStorage(db, d, mf, cold, weights)
+}
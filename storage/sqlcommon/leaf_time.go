@@ -0,0 +1,54 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// GetLeafIndexByTime runs a query which returns the sequence number of the
+// first leaf integrated at or after a time, given the tree ID, the time in
+// nanoseconds and treeSize. It returns treeSize if there is no such leaf.
+func GetLeafIndexByTime(ctx context.Context, tx *sql.Tx, query string, treeID int64, ts time.Time, treeSize int64) (int64, error) {
+	var index int64
+	err := tx.QueryRowContext(ctx, query, treeID, ts.UnixNano(), treeSize).Scan(&index)
+	if err == sql.ErrNoRows {
+		return treeSize, nil
+	} else if err != nil {
+		glog.Warningf("Failed to select leaf index by time: %s", err)
+		return 0, err
+	}
+	return index, nil
+}
+
+// selectLeafIndexByTimeSQL reads SequencedLeafIntegrateTimestampIdx in order,
+// so it stops at the first matching leaf.
+const selectLeafIndexByTimeSQL = `SELECT SequenceNumber
+			FROM SequencedLeafData
+			WHERE TreeId=? AND IntegrateTimestampNanos>=? AND SequenceNumber<?
+			ORDER BY IntegrateTimestampNanos,SequenceNumber LIMIT 1`
+
+// GetLeafIndexByTime implements storage.LeafTimeIndexTX.
+func (t *logTreeTX) GetLeafIndexByTime(ctx context.Context, ts time.Time, treeSize int64) (int64, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	index, err := GetLeafIndexByTime(ctx, t.tx, selectLeafIndexByTimeSQL, t.treeID, ts, treeSize)
+	return index, t.ts.dialect.ToGRPC(err)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/types"
+)
+
+const selectSignedLogRootsSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature
+			FROM TreeHead
+			WHERE TreeId=?
+			AND TreeRevision>=? AND TreeRevision<?
+			AND TreeSize>=? AND TreeSize<?
+			AND TreeHeadTimestamp>=? AND TreeHeadTimestamp<?
+			ORDER BY TreeRevision LIMIT ?`
+
+// GetSignedLogRoots implements storage.LogRootHistoryTX.
+func (t *logTreeTX) GetSignedLogRoots(ctx context.Context, filter storage.LogRootFilter, limit int) ([]*trillian.SignedLogRoot, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	f := filter.Bounded()
+	rows, err := t.tx.QueryContext(ctx, selectSignedLogRootsSQL, t.treeID,
+		f.RevisionBegin, f.RevisionEnd, f.TreeSizeBegin, f.TreeSizeEnd, f.TimestampBegin, f.TimestampEnd, limit)
+	if err != nil {
+		glog.Warningf("Failed to select signed log roots: %s", err)
+		return nil, t.ts.dialect.ToGRPC(err)
+	}
+	defer rows.Close()
+
+	var ret []*trillian.SignedLogRoot
+	for rows.Next() {
+		var timestamp, treeSize, treeRevision int64
+		var rootHash, rootSignature []byte
+		if err := rows.Scan(&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignature); err != nil {
+			return nil, err
+		}
+		// LogRootV1 has a deterministic serialization, so this reproduces the
+		// signed bytes.
+		logRoot, err := (&types.LogRootV1{
+			RootHash:       rootHash,
+			TimestampNanos: uint64(timestamp),
+			Revision:       uint64(treeRevision),
+			TreeSize:       uint64(treeSize),
+		}).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &trillian.SignedLogRoot{
+			KeyHint:          types.SerializeKeyHint(t.treeID),
+			LogRoot:          logRoot,
+			LogRootSignature: rootSignature,
+		})
+	}
+	return ret, rows.Err()
+}
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mysql
+package sqlcommon
 
 import (
 	"bytes"
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,6 +34,8 @@ import (
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
+	"github.com/google/trillian/storage/compression"
+	"github.com/google/trillian/storage/tiering"
 	"github.com/google/trillian/storage/tree"
 	"github.com/google/trillian/types"
 	"google.golang.org/grpc/codes"
@@ -42,31 +45,31 @@ import (
 const (
 	valuesPlaceholder5 = "(?,?,?,?,?)"
 
-	insertLeafDataSQL      = "INSERT INTO LeafData(TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos) VALUES" + valuesPlaceholder5
+	insertLeafDataSQL      = "INSERT INTO LeafData(TreeId,LeafIdentityHash,LeafValue,ExtraData,QueueTimestampNanos,Compression) VALUES(?,?,?,?,?,?)"
 	insertSequencedLeafSQL = "INSERT INTO SequencedLeafData(TreeId,LeafIdentityHash,MerkleLeafHash,SequenceNumber,IntegrateTimestampNanos) VALUES"
 
 	selectNonDeletedTreeIDByTypeAndStateSQL = `
 		SELECT TreeId FROM Trees
 		  WHERE TreeType IN(?,?)
 		  AND TreeState IN(?,?)
-		  AND (Deleted IS NULL OR Deleted = 'false')`
+		  AND (Deleted IS NULL OR Deleted = FALSE)`
 
 	selectSequencedLeafCountSQL  = "SELECT COUNT(*) FROM SequencedLeafData WHERE TreeId=?"
 	selectLatestSignedLogRootSQL = `SELECT TreeHeadTimestamp,TreeSize,RootHash,TreeRevision,RootSignature
 			FROM TreeHead WHERE TreeId=?
 			ORDER BY TreeHeadTimestamp DESC LIMIT 1`
 
-	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,l.Compression,l.Tiered
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
 			AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId` + orderBySequenceNumberSQL
 
 	// These statements need to be expanded to provide the correct number of parameter placeholders.
-	selectLeavesByIndexSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByIndexSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,l.Compression,l.Tiered
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
 			AND s.SequenceNumber IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
-	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,l.Compression,l.Tiered
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
 			AND s.MerkleLeafHash IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
@@ -75,7 +78,7 @@ const (
 	// This statement returns a dummy Merkle leaf hash value (which must be
 	// of the right size) so that its signature matches that of the other
 	// leaf-selection statements.
-	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos
+	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData,l.QueueTimestampNanos,s.IntegrateTimestampNanos,l.Compression,l.Tiered
 			FROM LeafData l LEFT JOIN SequencedLeafData s ON (l.LeafIdentityHash = s.LeafIdentityHash AND l.TreeID = s.TreeID)
 			WHERE l.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ?`
 
@@ -83,6 +86,15 @@ const (
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
 	selectLeavesByMerkleHashOrderedBySequenceSQL = selectLeavesByMerkleHashSQL + orderBySequenceNumberSQL
 
+	selectQueuedSubmittersSQL = `SELECT DISTINCT Submitter
+			FROM Unsequenced
+			WHERE TreeID=?
+			AND Bucket=0
+			AND QueueTimestampNanos<=?`
+	// maxSubmittersPerDequeueSelect bounds the number of queries in the UNION
+	// which selects the queued leaves of a FAIR_SHARE_ORDER tree.
+	maxSubmittersPerDequeueSelect = 64
+
 	logIDLabel = "logid"
 )
 
@@ -104,20 +116,23 @@ var (
 	dequeueRemoveLatency    monitoring.Histogram
 )
 
-func createMetrics(mf monitoring.MetricFactory) {
-	queuedCounter = mf.NewCounter("mysql_queued_leaves", "Number of leaves queued", logIDLabel)
-	queuedDupCounter = mf.NewCounter("mysql_queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
-	dequeuedCounter = mf.NewCounter("mysql_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
-
-	queueLatency = mf.NewHistogram("mysql_queue_leaves_latency", "Latency of queue leaves operation in seconds", logIDLabel)
-	queueInsertLatency = mf.NewHistogram("mysql_queue_leaves_latency_insert", "Latency of insertion part of queue leaves operation in seconds", logIDLabel)
-	queueReadLatency = mf.NewHistogram("mysql_queue_leaves_latency_read_dups", "Latency of read-duplicates part of queue leaves operation in seconds", logIDLabel)
-	queueInsertLeafLatency = mf.NewHistogram("mysql_queue_leaf_latency_leaf", "Latency of insert-leaf part of queue (single) leaf operation in seconds", logIDLabel)
-	queueInsertEntryLatency = mf.NewHistogram("mysql_queue_leaf_latency_entry", "Latency of insert-entry part of queue (single) leaf operation in seconds", logIDLabel)
-
-	dequeueLatency = mf.NewHistogram("mysql_dequeue_leaves_latency", "Latency of dequeue leaves operation in seconds", logIDLabel)
-	dequeueSelectLatency = mf.NewHistogram("mysql_dequeue_leaves_latency_select", "Latency of selection part of dequeue leaves operation in seconds", logIDLabel)
-	dequeueRemoveLatency = mf.NewHistogram("mysql_dequeue_leaves_latency_remove", "Latency of removal part of dequeue leaves operation in seconds", logIDLabel)
+// createMetrics creates the metrics of the storage, named with the given
+// prefix. They are shared by all storages in the process, so the prefix of
+// the first one wins.
+func createMetrics(mf monitoring.MetricFactory, prefix string) {
+	queuedCounter = mf.NewCounter(prefix+"queued_leaves", "Number of leaves queued", logIDLabel)
+	queuedDupCounter = mf.NewCounter(prefix+"queued_dup_leaves", "Number of duplicate leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter(prefix+"dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+
+	queueLatency = mf.NewHistogram(prefix+"queue_leaves_latency", "Latency of queue leaves operation in seconds", logIDLabel)
+	queueInsertLatency = mf.NewHistogram(prefix+"queue_leaves_latency_insert", "Latency of insertion part of queue leaves operation in seconds", logIDLabel)
+	queueReadLatency = mf.NewHistogram(prefix+"queue_leaves_latency_read_dups", "Latency of read-duplicates part of queue leaves operation in seconds", logIDLabel)
+	queueInsertLeafLatency = mf.NewHistogram(prefix+"queue_leaf_latency_leaf", "Latency of insert-leaf part of queue (single) leaf operation in seconds", logIDLabel)
+	queueInsertEntryLatency = mf.NewHistogram(prefix+"queue_leaf_latency_entry", "Latency of insert-entry part of queue (single) leaf operation in seconds", logIDLabel)
+
+	dequeueLatency = mf.NewHistogram(prefix+"dequeue_leaves_latency", "Latency of dequeue leaves operation in seconds", logIDLabel)
+	dequeueSelectLatency = mf.NewHistogram(prefix+"dequeue_leaves_latency_select", "Latency of selection part of dequeue leaves operation in seconds", logIDLabel)
+	dequeueRemoveLatency = mf.NewHistogram(prefix+"dequeue_leaves_latency_remove", "Latency of removal part of dequeue leaves operation in seconds", logIDLabel)
 }
 
 func labelForTX(t *logTreeTX) string {
@@ -128,34 +143,37 @@ func observe(hist monitoring.Histogram, duration time.Duration, label string) {
 	hist.Observe(duration.Seconds(), label)
 }
 
-type mySQLLogStorage struct {
-	*mySQLTreeStorage
+type sqlLogStorage struct {
+	*sqlTreeStorage
 	admin         storage.AdminStorage
 	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
 }
 
-// NewLogStorage creates a storage.LogStorage instance for the specified MySQL URL.
-// It assumes storage.AdminStorage is backed by the same MySQL database as well.
-func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+func newLogStorage(db *sql.DB, d *Dialect, mf monitoring.MetricFactory, cold tiering.ObjectStore, weights storage.SubmitterWeights) *sqlLogStorage {
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
 	}
-	return &mySQLLogStorage{
+	ts := newTreeStorage(db, d)
+	ts.cold = cold
+	return &sqlLogStorage{
 		admin:            NewAdminStorage(db),
-		mySQLTreeStorage: newTreeStorage(db),
+		sqlTreeStorage:   ts,
 		metricFactory:    mf,
+		submitterWeights: weights,
 	}
 }
 
-func (m *mySQLLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+func (m *sqlLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return m.db.PingContext(ctx)
 }
 
-func (m *mySQLLogStorage) getLeavesByIndexStmt(ctx context.Context, num int) (*sql.Stmt, error) {
+func (m *sqlLogStorage) getLeavesByIndexStmt(ctx context.Context, num int) (*sql.Stmt, error) {
 	return m.getStmt(ctx, selectLeavesByIndexSQL, num, "?", "?")
 }
 
-func (m *mySQLLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence bool) (*sql.Stmt, error) {
+func (m *sqlLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int, orderBySequence bool) (*sql.Stmt, error) {
 	if orderBySequence {
 		return m.getStmt(ctx, selectLeavesByMerkleHashOrderedBySequenceSQL, num, "?", "?")
 	}
@@ -163,20 +181,20 @@ func (m *mySQLLogStorage) getLeavesByMerkleHashStmt(ctx context.Context, num int
 	return m.getStmt(ctx, selectLeavesByMerkleHashSQL, num, "?", "?")
 }
 
-func (m *mySQLLogStorage) getLeavesByLeafIdentityHashStmt(ctx context.Context, num int) (*sql.Stmt, error) {
+func (m *sqlLogStorage) getLeavesByLeafIdentityHashStmt(ctx context.Context, num int) (*sql.Stmt, error) {
 	return m.getStmt(ctx, selectLeavesByLeafIdentityHashSQL, num, "?", "?")
 }
 
 // readOnlyLogTX implements storage.ReadOnlyLogTX
 type readOnlyLogTX struct {
-	ls *mySQLLogStorage
+	ls *sqlLogStorage
 
 	// mu ensures that tx can only be used for one query/exec at a time.
 	mu *sync.Mutex
 	tx *sql.Tx
 }
 
-func (m *mySQLLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+func (m *sqlLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
 	tx, err := m.db.BeginTx(ctx, nil /* opts */)
 	if err != nil {
 		glog.Warningf("Could not start ReadOnlyLogTX: %s", err)
@@ -232,14 +250,18 @@ func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	return ids, rows.Err()
 }
 
-func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
+func (m *sqlLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree) (*logTreeTX, error) {
 	once.Do(func() {
-		createMetrics(m.metricFactory)
+		createMetrics(m.metricFactory, m.dialect.Name+"_")
 	})
 	hasher, err := registry.NewLogHasher(tree.HashStrategy)
 	if err != nil {
 		return nil, err
 	}
+	codec, err := compression.NewCodec(tree)
+	if err != nil {
+		return nil, err
+	}
 
 	stCache := cache.NewLogSubtreeCache(defaultLogStrata, hasher)
 	ttx, err := m.beginTreeTx(ctx, tree, hasher.Size(), stCache)
@@ -251,6 +273,8 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree
 		treeTX:   ttx,
 		ls:       m,
 		dequeued: make(map[string]dequeuedLeaf),
+		order:    tree.DequeueOrder,
+		codec:    codec,
 	}
 	ltx.slr, err = ltx.fetchLatestRoot(ctx)
 	if err == storage.ErrTreeNeedsInit {
@@ -267,6 +291,7 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree
 	}
 
 	ltx.treeTX.writeRevision = int64(ltx.root.Revision) + 1
+	stCache.UseTileCache(cache.SharedTileCache(m.metricFactory), tree.TreeId, int64(ltx.root.Revision))
 	return ltx, nil
 }
 
@@ -274,7 +299,7 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree
 // implementation can leak a specific sql.ErrTxDone all the way to the client,
 // if the transaction is rolled back as a result of a canceled context. It must
 // return "generic" errors, and only log the specific ones for debugging.
-func (m *mySQLLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+func (m *sqlLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
 	tx, err := m.beginInternal(ctx, tree)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return err
@@ -286,7 +311,7 @@ func (m *mySQLLogStorage) ReadWriteTransaction(ctx context.Context, tree *trilli
 	return tx.Commit(ctx)
 }
 
-func (m *mySQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+func (m *sqlLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
 	tx, err := m.beginInternal(ctx, tree)
 	if tx != nil {
 		// Ensure we don't leak the transaction. For example if we get an
@@ -307,7 +332,7 @@ func (m *mySQLLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian
 	return res, nil
 }
 
-func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+func (m *sqlLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
 	tx, err := m.beginInternal(ctx, tree)
 	if err != nil && err != storage.ErrTreeNeedsInit {
 		return nil, err
@@ -315,7 +340,7 @@ func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tr
 	return tx, err
 }
 
-func (m *mySQLLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+func (m *sqlLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
 	tx, err := m.beginInternal(ctx, tree)
 	if tx != nil {
 		// Ensure we don't leak the transaction. For example if we get an
@@ -351,10 +376,12 @@ func (m *mySQLLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree,
 
 type logTreeTX struct {
 	treeTX
-	ls       *mySQLLogStorage
+	ls       *sqlLogStorage
 	root     types.LogRootV1
 	slr      *trillian.SignedLogRoot
 	dequeued map[string]dequeuedLeaf
+	order    trillian.DequeueOrder
+	codec    *compression.Codec
 }
 
 func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
@@ -393,50 +420,159 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 	}
 
 	start := time.Now()
-	stx, err := t.tx.PrepareContext(ctx, selectQueuedLeavesSQL)
+	var queued []queuedLeaf
+	var err error
+	if t.order == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		queued, err = t.selectQueuedLeavesFairShare(ctx, limit, cutoffTime)
+	} else {
+		queued, err = t.selectQueuedLeaves(ctx, selectQueuedLeavesSQL, t.treeID, cutoffTime.UnixNano(), limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make([]*trillian.LogLeaf, 0, len(queued))
+	for _, q := range queued {
+		k := string(q.leaf.LeafIdentityHash)
+		if _, ok := t.dequeued[k]; ok {
+			// dupe, user probably called DequeueLeaves more than once.
+			continue
+		}
+		t.dequeued[k] = q.info
+		leaves = append(leaves, q.leaf)
+	}
+
+	label := labelForTX(t)
+	observe(dequeueSelectLatency, time.Since(start), label)
+	observe(dequeueLatency, time.Since(start), label)
+	dequeuedCounter.Add(float64(len(leaves)), label)
+
+	return leaves, nil
+}
+
+// queuedLeaf is a leaf read from the Unsequenced table, along with the
+// information needed to remove it from there.
+type queuedLeaf struct {
+	leaf *trillian.LogLeaf
+	info dequeuedLeaf
+}
+
+// selectQueuedLeaves runs the given query against the Unsequenced table, and
+// returns the queued leaves in the order that they were selected.
+func (t *logTreeTX) selectQueuedLeaves(ctx context.Context, query string, args ...interface{}) ([]queuedLeaf, error) {
+	stx, err := t.tx.PrepareContext(ctx, query)
 	if err != nil {
 		glog.Warningf("Failed to prepare dequeue select: %s", err)
 		return nil, err
 	}
 	defer stx.Close()
 
-	leaves := make([]*trillian.LogLeaf, 0, limit)
-	rows, err := stx.QueryContext(ctx, t.treeID, cutoffTime.UnixNano(), limit)
+	rows, err := stx.QueryContext(ctx, args...)
 	if err != nil {
 		glog.Warningf("Failed to select rows for work: %s", err)
 		return nil, err
 	}
 	defer rows.Close()
 
+	var queued []queuedLeaf
 	for rows.Next() {
 		leaf, dqInfo, err := t.dequeueLeaf(rows)
 		if err != nil {
 			glog.Warningf("Error dequeuing leaf: %v", err)
 			return nil, err
 		}
+		queued = append(queued, queuedLeaf{leaf: leaf, info: dqInfo})
+	}
+	return queued, rows.Err()
+}
 
-		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return nil, errors.New("dequeued a leaf with incorrect hash size")
+// selectQueuedLeavesFairShare selects up to limit queued leaves, shared
+// between their submitters as described by storage.FairShare.
+//
+// It reads up to limit leaves of every submitter. They are selected by a
+// UNION of a query per submitter, rather than a windowed query, which MySQL
+// 5.7 doesn't support.
+func (t *logTreeTX) selectQueuedLeavesFairShare(ctx context.Context, limit int, cutoffTime time.Time) ([]queuedLeaf, error) {
+	submitters, err := t.selectQueuedSubmitters(ctx, cutoffTime)
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make(map[string][]*trillian.LogLeaf)
+	infos := make(map[string]dequeuedLeaf)
+	for len(submitters) > 0 {
+		n := len(submitters)
+		if n > maxSubmittersPerDequeueSelect {
+			n = maxSubmittersPerDequeueSelect
 		}
+		if err := t.selectSubmittersQueuedLeaves(ctx, submitters[:n], limit, cutoffTime, queues, infos); err != nil {
+			return nil, err
+		}
+		submitters = submitters[n:]
+	}
 
-		k := string(leaf.LeafIdentityHash)
-		if _, ok := t.dequeued[k]; ok {
-			// dupe, user probably called DequeueLeaves more than once.
-			continue
+	leaves := storage.FairShare(queues, t.ls.submitterWeights.ForTree(t.treeID), limit)
+	ret := make([]queuedLeaf, len(leaves))
+	for i, leaf := range leaves {
+		ret[i] = queuedLeaf{leaf: leaf, info: infos[string(leaf.LeafIdentityHash)]}
+	}
+	return ret, nil
+}
+
+// selectQueuedSubmitters returns the submitters of the leaves queued in the
+// tree before cutoffTime.
+func (t *logTreeTX) selectQueuedSubmitters(ctx context.Context, cutoffTime time.Time) ([]string, error) {
+	rows, err := t.tx.QueryContext(ctx, selectQueuedSubmittersSQL, t.treeID, cutoffTime.UnixNano())
+	if err != nil {
+		glog.Warningf("Failed to select queued submitters: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submitters []string
+	for rows.Next() {
+		var submitter string
+		if err := rows.Scan(&submitter); err != nil {
+			return nil, err
 		}
-		t.dequeued[k] = dqInfo
-		leaves = append(leaves, leaf)
+		submitters = append(submitters, submitter)
 	}
+	return submitters, rows.Err()
+}
 
-	if rows.Err() != nil {
-		return nil, rows.Err()
+// selectSubmittersQueuedLeaves reads up to limit of the oldest queued leaves
+// of each of the given submitters, and appends them to the submitter's queue.
+func (t *logTreeTX) selectSubmittersQueuedLeaves(ctx context.Context, submitters []string, limit int, cutoffTime time.Time, queues map[string][]*trillian.LogLeaf, infos map[string]dequeuedLeaf) error {
+	var query strings.Builder
+	args := make([]interface{}, 0, 4*len(submitters))
+	for i, submitter := range submitters {
+		if i > 0 {
+			query.WriteString("\n\t\t\tUNION ALL ")
+		}
+		fmt.Fprintf(&query, selectSubmitterQueuedLeavesSQL, i)
+		args = append(args, t.treeID, submitter, cutoffTime.UnixNano(), limit)
 	}
-	label := labelForTX(t)
-	observe(dequeueSelectLatency, time.Since(start), label)
-	observe(dequeueLatency, time.Since(start), label)
-	dequeuedCounter.Add(float64(len(leaves)), label)
+	query.WriteString("\n\t\t\tORDER BY QueueTimestampNanos,LeafIdentityHash")
 
-	return leaves, nil
+	rows, err := t.tx.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		glog.Warningf("Failed to select rows for work: %s", err)
+		return err
+	}
+	defer rows.Close()
+
+	// The rows are in queue order, so each submitter's queue will be too.
+	for rows.Next() {
+		var submitter string
+		leaf, dqInfo, err := t.dequeueLeaf(rows, &submitter)
+		if err != nil {
+			glog.Warningf("Error dequeuing leaf: %v", err)
+			return err
+		}
+		queues[submitter] = append(queues[submitter], leaf)
+		infos[string(leaf.LeafIdentityHash)] = dqInfo
+	}
+	return rows.Err()
 }
 
 // sortLeavesForInsert returns a slice containing the passed in leaves sorted
@@ -469,6 +605,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	}
 	start := time.Now()
 	label := labelForTX(t)
+	submitter := storage.SubmitterFromContext(ctx)
 
 	ordLeaves := sortLeavesForInsert(leaves)
 	existingCount := 0
@@ -482,10 +619,11 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		if err != nil {
 			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
 		}
-		_, err = t.tx.ExecContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, qTimestamp.UnixNano())
+		value, extra, format := t.codec.Encode(leaf.LeafValue, leaf.ExtraData)
+		_, err = t.tx.ExecContext(ctx, insertLeafDataSQL, t.treeID, leaf.LeafIdentityHash, value, extra, qTimestamp.UnixNano(), format)
 		insertDuration := time.Since(leafStart)
 		observe(queueInsertLeafLatency, insertDuration, label)
-		if isDuplicateErr(err) {
+		if t.ts.dialect.IsDuplicate(err) {
 			// Remember the duplicate leaf, using the requested leaf for now.
 			existingLeaves[i] = leaf
 			existingCount++
@@ -494,7 +632,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		}
 		if err != nil {
 			glog.Warningf("Error inserting %d into LeafData: %s", i, err)
-			return nil, mysqlToGRPC(err)
+			return nil, t.ts.dialect.ToGRPC(err)
 		}
 
 		// Create the work queue entry
@@ -502,6 +640,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 			t.treeID,
 			leaf.LeafIdentityHash,
 			leaf.MerkleLeafHash,
+			submitter,
 		}
 		queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
 		if err != nil {
@@ -515,7 +654,7 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 		)
 		if err != nil {
 			glog.Warningf("Error inserting into Unsequenced: %s", err)
-			return nil, mysqlToGRPC(err)
+			return nil, t.ts.dialect.ToGRPC(err)
 		}
 		leafDuration := time.Since(leafStart)
 		observe(queueInsertEntryLatency, (leafDuration - insertDuration), label)
@@ -580,7 +719,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 	const savepoint = "SAVEPOINT AddSequencedLeaves"
 	if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
 		glog.Errorf("Error adding savepoint: %s", err)
-		return nil, mysqlToGRPC(err)
+		return nil, t.ts.dialect.ToGRPC(err)
 	}
 	// TODO(pavelkalinnikov): Consider performance implication of executing this
 	// extra SAVEPOINT, especially for 1-entry batches. Optimize if necessary.
@@ -601,39 +740,40 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 
 		if _, err := t.tx.ExecContext(ctx, savepoint); err != nil {
 			glog.Errorf("Error updating savepoint: %s", err)
-			return nil, mysqlToGRPC(err)
+			return nil, t.ts.dialect.ToGRPC(err)
 		}
 
 		res[i] = &trillian.QueuedLogLeaf{Status: ok}
 
 		// TODO(pavelkalinnikov): Measure latencies.
+		value, extra, format := t.codec.Encode(leaf.LeafValue, leaf.ExtraData)
 		_, err := t.tx.ExecContext(ctx, insertLeafDataSQL,
-			t.treeID, leaf.LeafIdentityHash, leaf.LeafValue, leaf.ExtraData, timestamp.UnixNano())
+			t.treeID, leaf.LeafIdentityHash, value, extra, timestamp.UnixNano(), format)
 		// TODO(pavelkalinnikov): Detach PREORDERED_LOG integration latency metric.
 
 		// TODO(pavelkalinnikov): Support opting out from duplicates detection.
-		if isDuplicateErr(err) {
+		if t.ts.dialect.IsDuplicate(err) {
 			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
 			// Note: No rolling back to savepoint because there is no side effect.
 			continue
 		} else if err != nil {
 			glog.Errorf("Error inserting leaves[%d] into LeafData: %s", i, err)
-			return nil, mysqlToGRPC(err)
+			return nil, t.ts.dialect.ToGRPC(err)
 		}
 
 		_, err = t.tx.ExecContext(ctx, insertSequencedLeafSQL+valuesPlaceholder5,
 			t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.LeafIndex, 0)
 		// TODO(pavelkalinnikov): Update IntegrateTimestamp on integrating the leaf.
 
-		if isDuplicateErr(err) {
+		if t.ts.dialect.IsDuplicate(err) {
 			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
 			if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint); err != nil {
 				glog.Errorf("Error rolling back to savepoint: %s", err)
-				return nil, mysqlToGRPC(err)
+				return nil, t.ts.dialect.ToGRPC(err)
 			}
 		} else if err != nil {
 			glog.Errorf("Error inserting leaves[%d] into SequencedLeafData: %s", i, err)
-			return nil, mysqlToGRPC(err)
+			return nil, t.ts.dialect.ToGRPC(err)
 		}
 
 		// TODO(pavelkalinnikov): Load LeafData for conflicting entries.
@@ -641,7 +781,7 @@ func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.L
 
 	if _, err := t.tx.ExecContext(ctx, "RELEASE "+savepoint); err != nil {
 		glog.Errorf("Error releasing savepoint: %s", err)
-		return nil, mysqlToGRPC(err)
+		return nil, t.ts.dialect.ToGRPC(err)
 	}
 
 	return res, nil
@@ -661,18 +801,27 @@ func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
 	return sequencedLeafCount, err
 }
 
-func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+// checkLeafIndices returns an error if any of the given indices is outside
+// the tree. The indices of pre-ordered logs are not checked.
+func (t *logTreeTX) checkLeafIndices(leaves []int64) error {
 	if t.treeType == trillian.TreeType_LOG {
 		treeSize := int64(t.root.TreeSize)
 		for _, leaf := range leaves {
 			if leaf < 0 {
-				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+				return status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
 			}
 			if leaf >= treeSize {
-				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+				return status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
 			}
 		}
 	}
+	return nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	if err := t.checkLeafIndices(leaves); err != nil {
+		return nil, err
+	}
 	tmpl, err := t.ls.getLeavesByIndexStmt(ctx, len(leaves))
 	if err != nil {
 		return nil, err
@@ -696,6 +845,8 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 	for rows.Next() {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
+		var format int32
+		var tiered bool
 		if err := rows.Scan(
 			&leaf.MerkleLeafHash,
 			&leaf.LeafIdentityHash,
@@ -703,10 +854,20 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 			&leaf.LeafIndex,
 			&leaf.ExtraData,
 			&qTimestamp,
-			&iTimestamp); err != nil {
+			&iTimestamp,
+			&format,
+			&tiered); err != nil {
 			glog.Warningf("Failed to scan merkle leaves: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, qTimestamp))
 		if err != nil {
@@ -735,20 +896,22 @@ func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([
 	return t.getLeavesByRangeInternal(ctx, start, count)
 }
 
-func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+// clipLeafRange checks the given range of leaves, and returns its count
+// clipped to the tree. The ranges of pre-ordered logs are not clipped.
+func (t *logTreeTX) clipLeafRange(start, count int64) (int64, error) {
 	if count <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
 	}
 	if start < 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+		return 0, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
 	}
 
 	if t.treeType == trillian.TreeType_LOG {
 		treeSize := int64(t.root.TreeSize)
 		if treeSize <= 0 {
-			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+			return 0, status.Errorf(codes.OutOfRange, "empty tree")
 		} else if start >= treeSize {
-			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+			return 0, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
 		}
 		// Ensure no entries queried/returned beyond the tree.
 		if maxCount := treeSize - start; count > maxCount {
@@ -756,6 +919,14 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 		}
 	}
 	// TODO(pavelkalinnikov): Further clip `count` to a safe upper bound like 64k.
+	return count, nil
+}
+
+func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	count, err := t.clipLeafRange(start, count)
+	if err != nil {
+		return nil, err
+	}
 
 	args := []interface{}{start, start + count, t.treeID}
 	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, args...)
@@ -769,6 +940,8 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 	for wantIndex := start; rows.Next(); wantIndex++ {
 		leaf := &trillian.LogLeaf{}
 		var qTimestamp, iTimestamp int64
+		var format int32
+		var tiered bool
 		if err := rows.Scan(
 			&leaf.MerkleLeafHash,
 			&leaf.LeafIdentityHash,
@@ -776,10 +949,20 @@ func (t *logTreeTX) getLeavesByRangeInternal(ctx context.Context, start, count i
 			&leaf.LeafIndex,
 			&leaf.ExtraData,
 			&qTimestamp,
-			&iTimestamp); err != nil {
+			&iTimestamp,
+			&format,
+			&tiered); err != nil {
 			glog.Warningf("Failed to scan merkle leaves: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		if leaf.LeafIndex != wantIndex {
 			if wantIndex < int64(t.root.TreeSize) {
 				return nil, fmt.Errorf("got unexpected index %d, want %d", leaf.LeafIndex, wantIndex)
@@ -882,7 +1065,7 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
 	}
 	if len(logRoot.Metadata) != 0 {
-		return fmt.Errorf("unimplemented: mysql storage does not support log root metadata")
+		return fmt.Errorf("unimplemented: %s storage does not support log root metadata", t.ts.dialect.Name)
 	}
 
 	res, err := t.tx.ExecContext(
@@ -898,7 +1081,7 @@ func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.Signe
 		glog.Warningf("Failed to store signed root: %s", err)
 	}
 
-	return checkResultOkAndRowCountIs(res, err, 1)
+	return t.checkResultOkAndRowCountIs(res, err, 1)
 }
 
 func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]byte, tmpl *sql.Stmt, desc string) ([]*trillian.LogLeaf, error) {
@@ -928,11 +1111,21 @@ func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]
 		// check its validity below.
 		var integrateTS sql.NullInt64
 		var queueTS int64
+		var format int32
+		var tiered bool
 
-		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS); err != nil {
+		if err := rows.Scan(&leaf.MerkleLeafHash, &leaf.LeafIdentityHash, &leaf.LeafValue, &leaf.LeafIndex, &leaf.ExtraData, &queueTS, &integrateTS, &format, &tiered); err != nil {
 			glog.Warningf("LogID: %d Scan() %s = %s", t.treeID, desc, err)
 			return nil, err
 		}
+		if tiered {
+			if err := t.readTieredLeaf(ctx, leaf); err != nil {
+				return nil, err
+			}
+		}
+		if err := compression.DecodeLeaf(trillian.LeafCompression(format), leaf); err != nil {
+			return nil, err
+		}
 		var err error
 		leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, queueTS))
 		if err != nil {
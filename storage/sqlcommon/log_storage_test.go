@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mysql
+package sqlcommon
 
 import (
 	"bytes"
@@ -140,7 +140,7 @@ func checkLeafContents(leaf *trillian.LogLeaf, seq int64, rawHash, hash, data, e
 func TestLogSuite(t *testing.T) {
 	storageFactory := func(context.Context, *testing.T) (storage.LogStorage, storage.AdminStorage) {
 		t.Cleanup(func() { cleanTestDB(DB) })
-		return NewLogStorage(DB, nil), NewAdminStorage(DB)
+		return NewLogStorage(DB, testDialect, nil, nil, nil), NewAdminStorage(DB)
 	}
 
 	storagetest.RunLogStorageTests(t, storageFactory)
@@ -151,7 +151,7 @@ func TestQueueDuplicateLeaf(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	count := 15
@@ -216,7 +216,7 @@ func TestQueueLeaves(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	leaves := createTestLeaves(leavesToInsert, 20)
@@ -250,7 +250,7 @@ func TestQueueLeavesDuplicateBigBatch(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	const leafCount = 999 + 1
@@ -281,7 +281,7 @@ func TestDequeueLeavesHaveQueueTimestamp(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	leaves := createTestLeaves(leavesToInsert, 20)
@@ -313,7 +313,7 @@ func TestDequeueLeavesGuardInterval(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	leaves := createTestLeaves(leavesToInsert, 20)
@@ -354,7 +354,7 @@ func TestDequeueLeavesTimeOrdering(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
 
 	batchSize := 2
@@ -418,12 +418,61 @@ func TestDequeueLeavesTimeOrdering(t *testing.T) {
 	}
 }
 
+func TestDequeueLeavesFairShare(t *testing.T) {
+	// Queue leaves from a noisy submitter first, then from a submitter with
+	// double weight and a quiet one. The dequeued batch must be shared between
+	// them, rather than taken from the oldest leaves.
+	ctx := context.Background()
+	cleanTestDB(DB)
+	as := NewAdminStorage(DB)
+	create := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	create.DequeueOrder = trillian.DequeueOrder_FAIR_SHARE_ORDER
+	tree := mustCreateTree(ctx, t, as, create)
+	weights := func(int64) map[string]int { return map[string]int{"heavy": 2} }
+	s := NewLogStorage(DB, testDialect, nil, nil, weights)
+	mustSignAndStoreLogRoot(ctx, t, s, tree, 0)
+
+	submitters := make(map[string]string)
+	queueTime := fakeQueueTime
+	for _, q := range []struct {
+		submitter string
+		count     int
+	}{{"noisy", 10}, {"heavy", 10}, {"quiet", 1}} {
+		leaves := make([]*trillian.LogLeaf, 0, q.count)
+		for i := 0; i < q.count; i++ {
+			value := []byte(fmt.Sprintf("%s %d", q.submitter, i))
+			id := sha256.Sum256(value)
+			submitters[string(id[:])] = q.submitter
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: id[:], MerkleLeafHash: id[:], LeafValue: value})
+		}
+		queueTime = queueTime.Add(time.Second)
+		if _, err := s.QueueLeaves(storage.NewSubmitterContext(ctx, q.submitter), tree, leaves, queueTime); err != nil {
+			t.Fatalf("QueueLeaves(%s): %v", q.submitter, err)
+		}
+	}
+
+	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.DequeueLeaves(ctx, 8, queueTime.Add(time.Second))
+		if err != nil {
+			t.Fatalf("DequeueLeaves() = %v", err)
+		}
+		got := make(map[string]int)
+		for _, l := range leaves {
+			got[submitters[string(l.LeafIdentityHash)]]++
+		}
+		if diff := cmp.Diff(got, map[string]int{"noisy": 3, "heavy": 4, "quiet": 1}); diff != "" {
+			t.Errorf("DequeueLeaves() per submitter diff (-got +want):\n%s", diff)
+		}
+		return nil
+	})
+}
+
 func TestGetLeavesByHashNotPresent(t *testing.T) {
 	ctx := context.Background()
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	runLogTX(s, tree, t, func(ctx context.Context, tx storage.LogTreeTX) error {
 		hashes := [][]byte{[]byte("thisdoesn'texist")}
@@ -445,7 +494,7 @@ func TestGetLeavesByHash(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	data := []byte("some data")
 	createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, data, someExtraData, sequenceNumber, t)
@@ -472,7 +521,7 @@ func TestGetLeavesByHashBigBatch(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	const leafCount = 999 + 1
 	hashes := make([][]byte, leafCount)
@@ -502,7 +551,7 @@ func TestGetLeafDataByIdentityHash(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	data := []byte("some data")
 	leaf := createFakeLeaf(ctx, DB, tree.TreeId, dummyRawHash, dummyHash, data, someExtraData, sequenceNumber, t)
 	leaf.LeafIndex = -1
@@ -575,7 +624,7 @@ func TestGetLeavesByIndex(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	// The leaf indices are checked against the tree size so we need a root.
 	mustSignAndStoreLogRoot(ctx, t, s, tree, uint64(sequenceNumber+1))
@@ -676,7 +725,7 @@ func TestLatestSignedRootNoneWritten(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	tx, err := s.SnapshotForTree(ctx, tree)
 	if err != storage.ErrTreeNeedsInit {
@@ -690,7 +739,7 @@ func TestLatestSignedLogRoot(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
 	root, err := signer.SignLogRoot(&types.LogRootV1{
@@ -729,7 +778,7 @@ func TestDuplicateSignedLogRoot(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
 	root, err := signer.SignLogRoot(&types.LogRootV1{
@@ -760,7 +809,7 @@ func TestLogRootUpdate(t *testing.T) {
 	cleanTestDB(DB)
 	as := NewAdminStorage(DB)
 	tree := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	signer := tcrypto.NewSigner(tree.TreeId, ttestonly.NewSignerWithFixedSig(nil, []byte("notempty")), crypto.SHA256)
 	root, err := signer.SignLogRoot(&types.LogRootV1{
@@ -850,7 +899,7 @@ func TestGetActiveLogIDs(t *testing.T) {
 		}
 	}
 
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	tx, err := s.Snapshot(ctx)
 	if err != nil {
 		t.Fatalf("Snapshot() returns err = %v", err)
@@ -876,7 +925,7 @@ func TestGetActiveLogIDsEmpty(t *testing.T) {
 	ctx := context.Background()
 
 	cleanTestDB(DB)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	tx, err := s.Snapshot(context.Background())
 	if err != nil {
@@ -899,7 +948,7 @@ func TestGetActiveLogIDsEmpty(t *testing.T) {
 func TestReadOnlyLogTX_Rollback(t *testing.T) {
 	ctx := context.Background()
 	cleanTestDB(DB)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 	tx, err := s.Snapshot(ctx)
 	if err != nil {
 		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
@@ -922,7 +971,7 @@ func TestGetSequencedLeafCount(t *testing.T) {
 	as := NewAdminStorage(DB)
 	log1 := mustCreateTree(ctx, t, as, testonly.LogTree)
 	log2 := mustCreateTree(ctx, t, as, testonly.LogTree)
-	s := NewLogStorage(DB, nil)
+	s := NewLogStorage(DB, testDialect, nil, nil, nil)
 
 	{
 		// Create fake leaf as if it had been sequenced
@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+)
+
+const (
+	replaceQuarantinedSQL = `REPLACE INTO Quarantined(TreeId,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,SequenceNumber,Reason,QuarantineTimestampNanos)
+			VALUES(?,?,?,?,?,?,?)`
+	selectQuarantinedSQL = `SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,SequenceNumber,Reason,QuarantineTimestampNanos
+			FROM Quarantined
+			WHERE TreeId=?
+			ORDER BY QuarantineTimestampNanos,LeafIdentityHash`
+	selectQuarantinedByIDSQL = `SELECT MerkleLeafHash FROM Quarantined
+			WHERE TreeId=? AND LeafIdentityHash=?`
+	deleteQuarantinedSQL = "DELETE FROM Quarantined WHERE TreeId=? AND LeafIdentityHash=?"
+)
+
+func (t *logTreeTX) QuarantineLeaves(ctx context.Context, leaves []*trillian.QuarantinedLeaf) error {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	dequeued := make([]dequeuedLeaf, 0, len(leaves))
+	for _, ql := range leaves {
+		leaf := ql.Leaf
+		queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		quarantineTimestamp, err := ptypes.Timestamp(ql.QuarantineTimestamp)
+		if err != nil {
+			return fmt.Errorf("got invalid quarantine timestamp: %v", err)
+		}
+		if _, err := t.tx.ExecContext(ctx, replaceQuarantinedSQL,
+			t.treeID,
+			leaf.LeafIdentityHash,
+			leaf.MerkleLeafHash,
+			queueTimestamp.UnixNano(),
+			leaf.LeafIndex,
+			ql.Reason,
+			quarantineTimestamp.UnixNano()); err != nil {
+			glog.Warningf("Failed to quarantine leaf: %s", err)
+			return t.ts.dialect.ToGRPC(err)
+		}
+
+		if t.treeType == trillian.TreeType_PREORDERED_LOG {
+			continue
+		}
+		k := string(leaf.LeafIdentityHash)
+		dq, ok := t.dequeued[k]
+		if !ok {
+			return fmt.Errorf("attempting to quarantine leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		delete(t.dequeued, k)
+		dequeued = append(dequeued, dq)
+	}
+
+	if len(dequeued) == 0 {
+		return nil
+	}
+	return t.removeSequencedLeaves(ctx, dequeued)
+}
+
+func (t *logTreeTX) ListQuarantinedLeaves(ctx context.Context) ([]*trillian.QuarantinedLeaf, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	rows, err := t.tx.QueryContext(ctx, selectQuarantinedSQL, t.treeID)
+	if err != nil {
+		glog.Warningf("Failed to select quarantined leaves: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.QuarantinedLeaf
+	for rows.Next() {
+		var queueTimestamp, quarantineTimestamp int64
+		ql := &trillian.QuarantinedLeaf{Leaf: &trillian.LogLeaf{}}
+		if err := rows.Scan(&ql.Leaf.LeafIdentityHash, &ql.Leaf.MerkleLeafHash, &queueTimestamp, &ql.Leaf.LeafIndex, &ql.Reason, &quarantineTimestamp); err != nil {
+			glog.Warningf("Failed to scan quarantined leaf: %s", err)
+			return nil, err
+		}
+		if ql.Leaf.QueueTimestamp, err = ptypes.TimestampProto(time.Unix(0, queueTimestamp)); err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+		if ql.QuarantineTimestamp, err = ptypes.TimestampProto(time.Unix(0, quarantineTimestamp)); err != nil {
+			return nil, fmt.Errorf("got invalid quarantine timestamp: %v", err)
+		}
+		ret = append(ret, ql)
+	}
+	return ret, rows.Err()
+}
+
+func (t *logTreeTX) RequeueQuarantinedLeaves(ctx context.Context, leafIdentityHashes [][]byte, queueTimestamp time.Time) ([][]byte, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	var ret [][]byte
+	for _, id := range leafIdentityHashes {
+		var merkleHash []byte
+		if err := t.tx.QueryRowContext(ctx, selectQuarantinedByIDSQL, t.treeID, id).Scan(&merkleHash); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, err
+		}
+		if _, err := t.tx.ExecContext(ctx, deleteQuarantinedSQL, t.treeID, id); err != nil {
+			glog.Warningf("Failed to delete quarantined leaf: %s", err)
+			return nil, err
+		}
+
+		if t.treeType != trillian.TreeType_PREORDERED_LOG {
+			args := []interface{}{t.treeID, id, merkleHash, ""}
+			args = append(args, queueArgs(t.treeID, id, queueTimestamp)...)
+			if _, err := t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, args...); err != nil {
+				glog.Warningf("Error inserting into Unsequenced: %s query %v arguments: %v", err, insertUnsequencedEntrySQL, args)
+				return nil, t.ts.dialect.ToGRPC(err)
+			}
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
@@ -1,3 +1,4 @@
+//go:build !batched_queue
 // +build !batched_queue
 
 // Copyright 2017 Google LLC. All Rights Reserved.
@@ -14,7 +15,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mysql
+package sqlcommon
 
 import (
 	"context"
@@ -26,6 +27,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 )
 
 const (
@@ -36,8 +38,18 @@ const (
 			AND Bucket=0
 			AND QueueTimestampNanos<=?
 			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
-	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos)
-			VALUES(?,0,?,?,?)`
+	// selectSubmitterQueuedLeavesSQL selects up to the given number of the
+	// oldest queued leaves of a submitter, along with their submitter. It is
+	// repeated for several submitters in a UNION, see selectQueuedLeavesFairShare.
+	selectSubmitterQueuedLeavesSQL = `SELECT * FROM (SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,Submitter
+				FROM Unsequenced
+				WHERE TreeID=?
+				AND Bucket=0
+				AND Submitter=?
+				AND QueueTimestampNanos<=?
+				ORDER BY QueueTimestampNanos,LeafIdentityHash LIMIT ?) AS Queued%d`
+	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,Submitter,QueueTimestampNanos)
+			VALUES(?,0,?,?,?,?)`
 	deleteUnsequencedSQL = "DELETE FROM Unsequenced WHERE TreeId=? AND Bucket=0 AND QueueTimestampNanos=? AND LeafIdentityHash=?"
 )
 
@@ -50,12 +62,14 @@ func dequeueInfo(leafIDHash []byte, queueTimestamp int64) dequeuedLeaf {
 	return dequeuedLeaf{queueTimestampNanos: queueTimestamp, leafIdentityHash: leafIDHash}
 }
 
-func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf, error) {
+// dequeueLeaf scans a row selected from the Unsequenced table. The columns
+// following those of the leaf are scanned into extra.
+func (t *logTreeTX) dequeueLeaf(rows *sql.Rows, extra ...interface{}) (*trillian.LogLeaf, dequeuedLeaf, error) {
 	var leafIDHash []byte
 	var merkleHash []byte
 	var queueTimestamp int64
 
-	err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp)
+	err := rows.Scan(append([]interface{}{&leafIDHash, &merkleHash, &queueTimestamp}, extra...)...)
 	if err != nil {
 		glog.Warningf("Error scanning work rows: %s", err)
 		return nil, dequeuedLeaf{}, err
@@ -85,12 +99,12 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	for _, leaf := range leaves {
 		// This should fail on insert but catch it early
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return errors.New("sequenced leaf has incorrect hash size")
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: errors.New("sequenced leaf has incorrect hash size")}
 		}
 
 		iTimestamp, err := ptypes.Timestamp(leaf.IntegrateTimestamp)
 		if err != nil {
-			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid integrate timestamp: %v", err)}
 		}
 		_, err = t.tx.ExecContext(
 			ctx,
@@ -130,7 +144,7 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, leaves []dequeued
 	defer stx.Close()
 	for _, dql := range leaves {
 		result, err := stx.ExecContext(ctx, t.treeID, dql.queueTimestampNanos, dql.leafIdentityHash)
-		err = checkResultOkAndRowCountIs(result, err, int64(1))
+		err = t.checkResultOkAndRowCountIs(result, err, int64(1))
 		if err != nil {
 			return err
 		}
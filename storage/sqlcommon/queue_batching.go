@@ -1,3 +1,4 @@
+//go:build batched_queue
 // +build batched_queue
 
 // Copyright 2017 Google LLC. All Rights Reserved.
@@ -14,7 +15,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mysql
+package sqlcommon
 
 import (
 	"context"
@@ -28,6 +29,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
 )
 
 const (
@@ -38,7 +40,17 @@ const (
 			AND Bucket=0
 			AND QueueTimestampNanos<=?
 			ORDER BY QueueTimestampNanos,LeafIdentityHash ASC LIMIT ?`
-	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID) VALUES(?,0,?,?,?,?)`
+	// selectSubmitterQueuedLeavesSQL selects up to the given number of the
+	// oldest queued leaves of a submitter, along with their submitter. It is
+	// repeated for several submitters in a UNION, see selectQueuedLeavesFairShare.
+	selectSubmitterQueuedLeavesSQL = `SELECT * FROM (SELECT LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID,Submitter
+				FROM Unsequenced
+				WHERE TreeID=?
+				AND Bucket=0
+				AND Submitter=?
+				AND QueueTimestampNanos<=?
+				ORDER BY QueueTimestampNanos,LeafIdentityHash LIMIT ?) AS Queued%d`
+	insertUnsequencedEntrySQL = `INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,Submitter,QueueTimestampNanos,QueueID) VALUES(?,0,?,?,?,?,?)`
 	deleteUnsequencedSQL      = "DELETE FROM Unsequenced WHERE QueueID IN (<placeholder>)"
 )
 
@@ -48,13 +60,15 @@ func dequeueInfo(_ []byte, queueID []byte) dequeuedLeaf {
 	return dequeuedLeaf(queueID)
 }
 
-func (t *logTreeTX) dequeueLeaf(rows *sql.Rows) (*trillian.LogLeaf, dequeuedLeaf, error) {
+// dequeueLeaf scans a row selected from the Unsequenced table. The columns
+// following those of the leaf are scanned into extra.
+func (t *logTreeTX) dequeueLeaf(rows *sql.Rows, extra ...interface{}) (*trillian.LogLeaf, dequeuedLeaf, error) {
 	var leafIDHash []byte
 	var merkleHash []byte
 	var queueTimestamp int64
 	var queueID []byte
 
-	err := rows.Scan(&leafIDHash, &merkleHash, &queueTimestamp, &queueID)
+	err := rows.Scan(append([]interface{}{&leafIDHash, &merkleHash, &queueTimestamp, &queueID}, extra...)...)
 	if err != nil {
 		glog.Warningf("Error scanning work rows: %s", err)
 		return nil, nil, err
@@ -99,7 +113,7 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	for _, leaf := range leaves {
 		iTimestamp, err := ptypes.Timestamp(leaf.IntegrateTimestamp)
 		if err != nil {
-			return fmt.Errorf("got invalid integrate timestamp: %v", err)
+			return &storage.LeafError{LeafIdentityHash: leaf.LeafIdentityHash, Err: fmt.Errorf("got invalid integrate timestamp: %v", err)}
 		}
 		querySuffix = append(querySuffix, valuesPlaceholder5)
 		args = append(args, t.treeID, leaf.LeafIdentityHash, leaf.MerkleLeafHash, leaf.LeafIndex, iTimestamp.UnixNano())
@@ -113,14 +127,14 @@ func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillia
 	if err != nil {
 		glog.Warningf("Failed to update sequenced leaves: %s", err)
 	}
-	if err := checkResultOkAndRowCountIs(result, err, int64(len(leaves))); err != nil {
+	if err := t.checkResultOkAndRowCountIs(result, err, int64(len(leaves))); err != nil {
 		return err
 	}
 
 	return t.removeSequencedLeaves(ctx, dequeuedLeaves)
 }
 
-func (m *mySQLLogStorage) getDeleteUnsequencedStmt(ctx context.Context, num int) (*sql.Stmt, error) {
+func (m *sqlLogStorage) getDeleteUnsequencedStmt(ctx context.Context, num int) (*sql.Stmt, error) {
 	return m.getStmt(ctx, deleteUnsequencedSQL, num, "?", "?")
 }
 
@@ -145,5 +159,5 @@ func (t *logTreeTX) removeSequencedLeaves(ctx context.Context, queueIDs []dequeu
 		// Error is handled by checkResultOkAndRowCountIs() below
 		glog.Warningf("Failed to delete sequenced work: %s", err)
 	}
-	return checkResultOkAndRowCountIs(result, err, int64(len(queueIDs)))
+	return t.checkResultOkAndRowCountIs(result, err, int64(len(queueIDs)))
 }
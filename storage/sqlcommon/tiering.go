@@ -0,0 +1,246 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tiering"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TieringSQL holds the statements used to tier leaves and tiles. They take
+// the arguments, and return the columns, in the order listed.
+type TieringSQL struct {
+	// SelectLeafData locks and returns the identity hash, value and extra data
+	// of the untiered leaves, given the start and end of the range of sequence
+	// numbers and the tree ID.
+	SelectLeafData string
+	// UpdateLeafDataTiered replaces the data of a leaf with a stub, given the
+	// stub value, tree ID and leaf identity hash.
+	UpdateLeafDataTiered string
+	// SelectSubtrees returns the ID and latest revision of the subtrees, given
+	// the tree ID, the subtree ID to start after, and the max number of them.
+	SelectSubtrees string
+	// SelectSubtree locks and returns the nodes of a subtree revision and
+	// whether it is tiered, given the tree ID, subtree ID and revision.
+	SelectSubtree string
+	// UpdateSubtreeTiered replaces the nodes of a subtree revision with a stub,
+	// given the stub nodes, tree ID, subtree ID and revision.
+	UpdateSubtreeTiered string
+}
+
+// TierLeaves writes the data of the sequenced leaves in [start, end) of the
+// given tree to the cold store, and replaces it with stubs in the database.
+// It returns the number of leaves tiered.
+func TierLeaves(ctx context.Context, tx *sql.Tx, q *TieringSQL, cold tiering.ObjectStore, treeID, start, end int64) (int, error) {
+	rows, err := tx.QueryContext(ctx, q.SelectLeafData, start, end, treeID)
+	if err != nil {
+		glog.Warningf("Failed to select leaf data for tiering: %s", err)
+		return 0, err
+	}
+	type leafData struct {
+		id, value, extra []byte
+	}
+	var batch []leafData
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var l leafData
+		if err := rows.Scan(&l.id, &l.value, &l.extra); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		// Leaves with duplicate data share a LeafData row.
+		if !seen[string(l.id)] {
+			seen[string(l.id)] = true
+			batch = append(batch, l)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	rows.Close()
+
+	for _, l := range batch {
+		data, err := tiering.MarshalLeafData(l.value, l.extra)
+		if err != nil {
+			return 0, err
+		}
+		if err := cold.Put(ctx, tiering.LeafKey(treeID, l.id), data); err != nil {
+			return 0, fmt.Errorf("failed to write leaf %x to cold storage: %v", l.id, err)
+		}
+		if _, err := tx.ExecContext(ctx, q.UpdateLeafDataTiered, []byte{}, treeID, l.id); err != nil {
+			glog.Warningf("Failed to update tiered leaf data: %s", err)
+			return 0, err
+		}
+	}
+	return len(batch), nil
+}
+
+// TierTiles writes the complete tiles of the given tree which are below
+// treeSize to the cold store, and replaces them with stubs in the database.
+// It looks at up to limit subtrees with IDs after the given one, and returns
+// the ID of the last one, or nil if there are none left, along with the number
+// of tiles tiered.
+func TierTiles(ctx context.Context, tx *sql.Tx, q *TieringSQL, cold tiering.ObjectStore, treeID, treeSize int64, after []byte, limit int) ([]byte, int, error) {
+	if after == nil {
+		after = []byte{}
+	}
+	batch, err := selectSubtreeRevisions(ctx, tx, q.SelectSubtrees, treeID, after, limit)
+	if err != nil {
+		glog.Warningf("Failed to select subtrees for tiering: %s", err)
+		return nil, 0, err
+	}
+	if len(batch) == 0 {
+		return nil, 0, nil
+	}
+	tiered := 0
+	for _, s := range batch {
+		if !tiering.TileBelow(s.id, treeSize) {
+			continue
+		}
+		var nodes []byte
+		var isTiered bool
+		if err := tx.QueryRowContext(ctx, q.SelectSubtree, treeID, s.id, s.rev).Scan(&nodes, &isTiered); err != nil {
+			glog.Warningf("Failed to select subtree for tiering: %s", err)
+			return nil, 0, err
+		}
+		if isTiered {
+			continue
+		}
+		var tile storagepb.SubtreeProto
+		if err := proto.Unmarshal(nodes, &tile); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal subtree %x: %v", s.id, err)
+		}
+		if tile.Depth <= 0 || len(tile.Leaves) != 1<<uint(tile.Depth) {
+			// The tile isn't complete yet at its latest revision.
+			continue
+		}
+		if err := cold.Put(ctx, tiering.TileKey(treeID, s.id, s.rev), nodes); err != nil {
+			return nil, 0, fmt.Errorf("failed to write subtree %x to cold storage: %v", s.id, err)
+		}
+		if _, err := tx.ExecContext(ctx, q.UpdateSubtreeTiered, []byte{}, treeID, s.id, s.rev); err != nil {
+			glog.Warningf("Failed to update tiered subtree: %s", err)
+			return nil, 0, err
+		}
+		tiered++
+	}
+	return batch[len(batch)-1].id, tiered, nil
+}
+
+const (
+	selectLeafDataForTieringSQL = `SELECT l.LeafIdentityHash,l.LeafValue,l.ExtraData
+			FROM LeafData l,SequencedLeafData s
+			WHERE l.LeafIdentityHash = s.LeafIdentityHash
+			AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId
+			AND l.Tiered = FALSE`
+	updateLeafDataTieredSQL = `UPDATE LeafData SET LeafValue=?,ExtraData=NULL,Tiered=TRUE
+			WHERE TreeId=? AND LeafIdentityHash=?`
+
+	selectSubtreesForTieringSQL = `SELECT SubtreeId,MAX(SubtreeRevision)
+			FROM Subtree
+			WHERE TreeId=? AND SubtreeId>?
+			GROUP BY SubtreeId
+			ORDER BY SubtreeId
+			LIMIT ?`
+	selectSubtreeForTieringSQL = `SELECT Nodes,Tiered FROM Subtree
+			WHERE TreeId=? AND SubtreeId=? AND SubtreeRevision=?`
+	updateSubtreeTieredSQL = `UPDATE Subtree SET Nodes=?,Tiered=TRUE
+			WHERE TreeId=? AND SubtreeId=? AND SubtreeRevision=?`
+)
+
+// coldStore returns the object store which tiered data is kept in, or an
+// error if the storage was created without one.
+func (t *treeTX) coldStore() (tiering.ObjectStore, error) {
+	if t.ts.cold == nil {
+		return nil, status.Error(codes.FailedPrecondition, "cold storage is not configured")
+	}
+	return t.ts.cold, nil
+}
+
+// readTieredLeaf replaces the stub data of a tiered leaf with its data read
+// from the cold store.
+func (t *logTreeTX) readTieredLeaf(ctx context.Context, leaf *trillian.LogLeaf) error {
+	cold, err := t.coldStore()
+	if err != nil {
+		return err
+	}
+	data, err := cold.Get(ctx, tiering.LeafKey(t.treeID, leaf.LeafIdentityHash))
+	if err != nil {
+		return fmt.Errorf("failed to read tiered leaf %x: %v", leaf.LeafIdentityHash, err)
+	}
+	leaf.LeafValue, leaf.ExtraData, err = tiering.UnmarshalLeafData(data)
+	return err
+}
+
+// readTieredTile returns the stored form of a tiered subtree, read from the
+// cold store.
+func (t *treeTX) readTieredTile(ctx context.Context, subtreeID []byte, rev int64) ([]byte, error) {
+	cold, err := t.coldStore()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := cold.Get(ctx, tiering.TileKey(t.treeID, subtreeID, rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tiered subtree %x at revision %d: %v", subtreeID, rev, err)
+	}
+	return nodes, nil
+}
+
+// tieringSQL returns the statements which tier the leaves and subtrees of
+// the tree.
+func (t *treeTX) tieringSQL() *TieringSQL {
+	return &TieringSQL{
+		SelectLeafData:       t.ts.dialect.lockRows(selectLeafDataForTieringSQL),
+		UpdateLeafDataTiered: updateLeafDataTieredSQL,
+		SelectSubtrees:       selectSubtreesForTieringSQL,
+		SelectSubtree:        t.ts.dialect.lockRows(selectSubtreeForTieringSQL),
+		UpdateSubtreeTiered:  updateSubtreeTieredSQL,
+	}
+}
+
+// TierLeaves implements storage.TieringLogTreeTX.
+func (t *logTreeTX) TierLeaves(ctx context.Context, start, end int64) (int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	cold, err := t.coldStore()
+	if err != nil {
+		return 0, err
+	}
+	n, err := TierLeaves(ctx, t.tx, t.tieringSQL(), cold, t.treeID, start, end)
+	return n, t.ts.dialect.ToGRPC(err)
+}
+
+// TierTiles implements storage.TieringLogTreeTX.
+func (t *logTreeTX) TierTiles(ctx context.Context, treeSize int64, after []byte, limit int) ([]byte, int, error) {
+	t.treeTX.mu.Lock()
+	defer t.treeTX.mu.Unlock()
+
+	cold, err := t.coldStore()
+	if err != nil {
+		return nil, 0, err
+	}
+	next, n, err := TierTiles(ctx, t.tx, t.tieringSQL(), cold, t.treeID, treeSize, after, limit)
+	return next, n, t.ts.dialect.ToGRPC(err)
+}
@@ -12,8 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package mysql provides a MySQL-based storage layer implementation.
-package mysql
+// Package sqlcommon holds the storage layer implementation shared by the SQL
+// databases which hold the MySQL schema, e.g. MySQL and SQLite, as described
+// by a Dialect.
+//
+// It also holds the parts of all SQL storage implementations which only
+// differ in the SQL that they run, e.g. CompactSubtrees. Each of those
+// functions runs the statements it is given in an existing transaction, and
+// returns the database errors as they are, for the caller to convert.
+package sqlcommon
 
 import (
 	"context"
@@ -29,6 +36,7 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/storagepb"
+	"github.com/google/trillian/storage/tiering"
 	"github.com/google/trillian/storage/tree"
 )
 
@@ -39,7 +47,7 @@ const (
 		 VALUES(?,?,?,?,?,?)`
 
 	selectSubtreeSQL = `
- SELECT x.SubtreeId, x.MaxRevision, Subtree.Nodes
+ SELECT x.SubtreeId, x.MaxRevision, Subtree.Nodes, Subtree.Tiered
  FROM (
  	SELECT n.TreeId, n.SubtreeId, max(n.SubtreeRevision) AS MaxRevision
 	FROM Subtree n
@@ -55,9 +63,9 @@ const (
 	placeholderSQL = "<placeholder>"
 )
 
-// mySQLTreeStorage is shared between the mySQLLog- and (forthcoming) mySQLMap-
+// sqlTreeStorage is shared between the sqlLog- and (forthcoming) sqlMap-
 // Storage implementations, and contains functionality which is common to both,
-type mySQLTreeStorage struct {
+type sqlTreeStorage struct {
 	db *sql.DB
 
 	// Must hold the mutex before manipulating the statement map. Sharing a lock because
@@ -66,29 +74,19 @@ type mySQLTreeStorage struct {
 	// in the query to the statement that should be used.
 	statementMutex sync.Mutex
 	statements     map[string]map[int]*sql.Stmt
-}
-
-// OpenDB opens a database connection for all MySQL-based storage implementations.
-func OpenDB(dbURL string) (*sql.DB, error) {
-	db, err := sql.Open("mysql", dbURL)
-	if err != nil {
-		// Don't log uri as it could contain credentials
-		glog.Warningf("Could not open MySQL database, check config: %s", err)
-		return nil, err
-	}
 
-	if _, err := db.ExecContext(context.TODO(), "SET sql_mode = 'STRICT_ALL_TABLES'"); err != nil {
-		glog.Warningf("Failed to set strict mode on mysql db: %s", err)
-		return nil, err
-	}
+	// cold holds the data of tiered leaves and subtrees, or is nil if tiering
+	// is not configured.
+	cold tiering.ObjectStore
 
-	return db, nil
+	dialect *Dialect
 }
 
-func newTreeStorage(db *sql.DB) *mySQLTreeStorage {
-	return &mySQLTreeStorage{
+func newTreeStorage(db *sql.DB, d *Dialect) *sqlTreeStorage {
+	return &sqlTreeStorage{
 		db:         db,
 		statements: make(map[string]map[int]*sql.Stmt),
+		dialect:    d,
 	}
 }
 
@@ -108,7 +106,7 @@ func expandPlaceholderSQL(sql string, num int, first, rest string) string {
 // and number of bound arguments.
 // TODO(al,martin): consider pulling this all out as a separate unit for reuse
 // elsewhere.
-func (m *mySQLTreeStorage) getStmt(ctx context.Context, statement string, num int, first, rest string) (*sql.Stmt, error) {
+func (m *sqlTreeStorage) getStmt(ctx context.Context, statement string, num int, first, rest string) (*sql.Stmt, error) {
 	m.statementMutex.Lock()
 	defer m.statementMutex.Unlock()
 
@@ -133,15 +131,15 @@ func (m *mySQLTreeStorage) getStmt(ctx context.Context, statement string, num in
 	return s, nil
 }
 
-func (m *mySQLTreeStorage) getSubtreeStmt(ctx context.Context, num int) (*sql.Stmt, error) {
+func (m *sqlTreeStorage) getSubtreeStmt(ctx context.Context, num int) (*sql.Stmt, error) {
 	return m.getStmt(ctx, selectSubtreeSQL, num, "?", "?")
 }
 
-func (m *mySQLTreeStorage) setSubtreeStmt(ctx context.Context, num int) (*sql.Stmt, error) {
+func (m *sqlTreeStorage) setSubtreeStmt(ctx context.Context, num int) (*sql.Stmt, error) {
 	return m.getStmt(ctx, insertSubtreeMultiSQL, num, "VALUES(?, ?, ?, ?)", "(?, ?, ?, ?)")
 }
 
-func (m *mySQLTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
+func (m *sqlTreeStorage) beginTreeTx(ctx context.Context, tree *trillian.Tree, hashSizeBytes int, subtreeCache *cache.SubtreeCache) (treeTX, error) {
 	t, err := m.db.BeginTx(ctx, nil /* opts */)
 	if err != nil {
 		glog.Warningf("Could not start tree TX: %s", err)
@@ -164,7 +162,7 @@ type treeTX struct {
 	mu            *sync.Mutex
 	closed        bool
 	tx            *sql.Tx
-	ts            *mySQLTreeStorage
+	ts            *sqlTreeStorage
 	treeID        int64
 	treeType      trillian.TreeType
 	hashSizeBytes int
@@ -237,10 +235,16 @@ func (t *treeTX) getSubtrees(ctx context.Context, treeRevision int64, nodeIDs []
 		var subtreeIDBytes []byte
 		var subtreeRev int64
 		var nodesRaw []byte
-		if err := rows.Scan(&subtreeIDBytes, &subtreeRev, &nodesRaw); err != nil {
+		var tiered bool
+		if err := rows.Scan(&subtreeIDBytes, &subtreeRev, &nodesRaw, &tiered); err != nil {
 			glog.Warningf("Failed to scan merkle subtree: %s", err)
 			return nil, err
 		}
+		if tiered {
+			if nodesRaw, err = t.readTieredTile(ctx, subtreeIDBytes, subtreeRev); err != nil {
+				return nil, err
+			}
+		}
 		var subtree storagepb.SubtreeProto
 		if err := proto.Unmarshal(nodesRaw, &subtree); err != nil {
 			glog.Warningf("Failed to unmarshal SubtreeProto: %s", err)
@@ -323,17 +327,17 @@ func (t *treeTX) storeSubtrees(ctx context.Context, subtrees []*storagepb.Subtre
 	return nil
 }
 
-func checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
+func (t *treeTX) checkResultOkAndRowCountIs(res sql.Result, err error, count int64) error {
 	// The Exec() might have just failed
 	if err != nil {
-		return mysqlToGRPC(err)
+		return t.ts.dialect.ToGRPC(err)
 	}
 
 	// Otherwise we have to look at the result of the operation
 	rowsAffected, rowsError := res.RowsAffected()
 
 	if rowsError != nil {
-		return mysqlToGRPC(rowsError)
+		return t.ts.dialect.ToGRPC(rowsError)
 	}
 
 	if rowsAffected != count {
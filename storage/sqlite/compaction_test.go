@@ -0,0 +1,163 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+)
+
+func TestCompactSubtrees(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ls, as := NewLogStorage(db, nil), NewAdminStorage(db)
+	logTree, err := storage.CreateTree(ctx, as, storageto.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	// Store a root at each of the revisions 0 to 4, with timestamps 100 to
+	// 104. Each revision after the first writes a leaf node to the same
+	// subtree, so that there is a subtree row at revisions 1 to 4.
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	const numRoots = 5
+	var nodes []tree.Node
+	for i := 0; i < numRoots; i++ {
+		if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			var rev int64
+			if i > 0 {
+				var err error
+				if rev, err = tx.WriteRevision(ctx); err != nil {
+					return err
+				}
+				hash := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+				node := tree.Node{ID: compact.NewNodeID(0, uint64(i-1)), Hash: hash[:]}
+				if err := tx.SetMerkleNodes(ctx, []tree.Node{node}); err != nil {
+					return err
+				}
+				nodes = append(nodes, node)
+			}
+			root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: uint64(i), TimestampNanos: uint64(100 + i), Revision: uint64(rev)})
+			if err != nil {
+				return err
+			}
+			return tx.StoreSignedLogRoot(ctx, root)
+		}); err != nil {
+			t.Fatalf("Failed to store revision %d: %v", i, err)
+		}
+	}
+
+	revisions := func() []int64 {
+		t.Helper()
+		rows, err := db.QueryContext(ctx, "SELECT SubtreeRevision FROM Subtree WHERE TreeId=? ORDER BY SubtreeRevision", logTree.TreeId)
+		if err != nil {
+			t.Fatalf("Failed to select subtrees: %v", err)
+		}
+		defer rows.Close()
+		var ret []int64
+		for rows.Next() {
+			var rev int64
+			if err := rows.Scan(&rev); err != nil {
+				t.Fatalf("Failed to scan subtree: %v", err)
+			}
+			ret = append(ret, rev)
+		}
+		return ret
+	}
+	if diff := cmp.Diff([]int64{1, 2, 3, 4}, revisions()); diff != "" {
+		t.Fatalf("Subtree revisions diff (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		ts      int64
+		wantRev int64
+		// wantDeleted is the number of rows deleted by compacting at wantRev,
+		// after compacting at the revisions of the previous cases.
+		wantDeleted   int
+		wantRevisions []int64
+	}{
+		{ts: 99, wantRev: -1, wantRevisions: []int64{1, 2, 3, 4}},
+		{ts: 101, wantRev: 1, wantRevisions: []int64{1, 2, 3, 4}},
+		{ts: 103, wantRev: 3, wantDeleted: 2, wantRevisions: []int64{3, 4}},
+		{ts: 1000, wantRev: 4, wantDeleted: 1, wantRevisions: []int64{4}},
+	} {
+		if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			compactTX := tx.(storage.SubtreeCompactionTX)
+			rev, err := compactTX.RevisionAt(ctx, time.Unix(0, tc.ts))
+			if err != nil {
+				return err
+			}
+			if rev != tc.wantRev {
+				t.Errorf("RevisionAt(%d)=%d, want %d", tc.ts, rev, tc.wantRev)
+			}
+			if rev < 0 {
+				return nil
+			}
+			last, deleted, err := compactTX.CompactSubtrees(ctx, rev, nil, 10)
+			if err != nil {
+				return err
+			}
+			if deleted != tc.wantDeleted || last == nil {
+				t.Errorf("CompactSubtrees(%d)=%x, %d, want non-nil, %d", rev, last, deleted, tc.wantDeleted)
+			}
+			if last, _, err := compactTX.CompactSubtrees(ctx, rev, last, 10); err != nil || last != nil {
+				t.Errorf("CompactSubtrees(%d) after last subtree=%x, %v, want nil", rev, last, err)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("Failed to compact at %d: %v", tc.ts, err)
+		}
+		if diff := cmp.Diff(tc.wantRevisions, revisions()); diff != "" {
+			t.Errorf("Subtree revisions after compaction at %d diff (-want +got):\n%s", tc.ts, diff)
+		}
+	}
+
+	// All the nodes can still be read at the latest revision.
+	if err := ls.ReadWriteTransaction(ctx, logTree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		ids := make([]compact.NodeID, 0, len(nodes))
+		for _, n := range nodes {
+			ids = append(ids, n.ID)
+		}
+		got, err := tx.GetMerkleNodes(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if len(got) != len(nodes) {
+			t.Fatalf("GetMerkleNodes() returned %d nodes, want %d", len(got), len(nodes))
+		}
+		for i, n := range got {
+			if n.ID != nodes[i].ID || !bytes.Equal(n.Hash, nodes[i].Hash) {
+				t.Errorf("GetMerkleNodes()[%d]=%+v, want %+v", i, n, nodes[i])
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to read nodes: %v", err)
+	}
+}
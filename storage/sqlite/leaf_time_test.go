@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+)
+
+func TestGetLeafIndexByTime(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ls, as := NewLogStorage(db, nil), NewAdminStorage(db)
+	tree, err := storage.CreateTree(ctx, as, storageto.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := signer.SignLogRoot(&types.LogRootV1{})
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("Failed to initialise tree: %v", err)
+	}
+
+	// Integrate a leaf at each of these timestamps, in seconds.
+	integrated := []int64{100, 100, 101, 103, 103, 104}
+	for i, secs := range integrated {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("leaf %d", i)))
+		leaf := &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafValue: []byte{byte(i)}}
+		if _, err := ls.QueueLeaves(ctx, tree, []*trillian.LogLeaf{leaf}, time.Unix(secs, 0)); err != nil {
+			t.Fatalf("QueueLeaves(%d): %v", i, err)
+		}
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			leaves, err := tx.DequeueLeaves(ctx, 1, time.Unix(secs+1, 0))
+			if err != nil {
+				return err
+			}
+			if len(leaves) != 1 {
+				return fmt.Errorf("dequeued %d leaves, want 1", len(leaves))
+			}
+			leaves[0].LeafIndex = int64(i)
+			if leaves[0].IntegrateTimestamp, err = ptypes.TimestampProto(time.Unix(secs, 0)); err != nil {
+				return err
+			}
+			return tx.UpdateSequencedLeaves(ctx, leaves)
+		}); err != nil {
+			t.Fatalf("Failed to integrate leaf %d: %v", i, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		secs     int64
+		treeSize int64
+		want     int64
+	}{
+		{secs: 99, treeSize: 6, want: 0},
+		{secs: 100, treeSize: 6, want: 0},
+		{secs: 101, treeSize: 6, want: 2},
+		{secs: 102, treeSize: 6, want: 3},
+		{secs: 103, treeSize: 6, want: 3},
+		{secs: 104, treeSize: 6, want: 5},
+		{secs: 105, treeSize: 6, want: 6},
+		{secs: 104, treeSize: 5, want: 5},
+		{secs: 101, treeSize: 2, want: 2},
+	} {
+		var got int64
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			var err error
+			got, err = tx.(storage.LeafTimeIndexTX).GetLeafIndexByTime(ctx, time.Unix(tc.secs, 0), tc.treeSize)
+			return err
+		}); err != nil {
+			t.Fatalf("GetLeafIndexByTime(%d, %d): %v", tc.secs, tc.treeSize, err)
+		}
+		if got != tc.want {
+			t.Errorf("GetLeafIndexByTime(%d, %d): %d, want %d", tc.secs, tc.treeSize, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"crypto"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+)
+
+func TestGetSignedLogRoots(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ls, as := NewLogStorage(db, nil), NewAdminStorage(db)
+	tree, err := storage.CreateTree(ctx, as, storageto.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+
+	// Store a root at each of the revisions 0 to 4, with sizes 0 to 40 and
+	// timestamps 100 to 104.
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	const numRoots = 5
+	for i := 0; i < numRoots; i++ {
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			var rev int64
+			if i > 0 {
+				var err error
+				if rev, err = tx.WriteRevision(ctx); err != nil {
+					return err
+				}
+			}
+			root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: uint64(10 * i), TimestampNanos: uint64(100 + i), Revision: uint64(rev)})
+			if err != nil {
+				return err
+			}
+			return tx.StoreSignedLogRoot(ctx, root)
+		}); err != nil {
+			t.Fatalf("StoreSignedLogRoot(%d): %v", i, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		filter storage.LogRootFilter
+		limit  int
+		want   []uint64
+	}{
+		{limit: 10, want: []uint64{0, 1, 2, 3, 4}},
+		{limit: 2, want: []uint64{0, 1}},
+		{filter: storage.LogRootFilter{RevisionBegin: 3}, limit: 10, want: []uint64{3, 4}},
+		{filter: storage.LogRootFilter{RevisionBegin: 5}, limit: 10},
+		{filter: storage.LogRootFilter{RevisionBegin: 2, RevisionEnd: 3}, limit: 10, want: []uint64{2}},
+		{filter: storage.LogRootFilter{TreeSizeBegin: 15, TreeSizeEnd: 40}, limit: 10, want: []uint64{2, 3}},
+		{filter: storage.LogRootFilter{TimestampEnd: 102}, limit: 10, want: []uint64{0, 1}},
+		{filter: storage.LogRootFilter{TreeSizeBegin: 10, TimestampBegin: 103}, limit: 1, want: []uint64{3}},
+	} {
+		var got []uint64
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			roots, err := tx.(storage.LogRootHistoryTX).GetSignedLogRoots(ctx, tc.filter, tc.limit)
+			if err != nil {
+				return err
+			}
+			for _, slr := range roots {
+				var root types.LogRootV1
+				if err := root.UnmarshalBinary(slr.LogRoot); err != nil {
+					return err
+				}
+				if root.TreeSize != 10*root.Revision || string(slr.LogRootSignature) != "notnil" {
+					t.Errorf("GetSignedLogRoots(): got root %+v with signature %q", root, slr.LogRootSignature)
+				}
+				got = append(got, root.Revision)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("GetSignedLogRoots(%+v, %d): %v", tc.filter, tc.limit, err)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("GetSignedLogRoots(%+v, %d): revisions diff (-want +got):\n%s", tc.filter, tc.limit, diff)
+		}
+	}
+}
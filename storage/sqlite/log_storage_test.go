@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+)
+
+func TestLogSuite(t *testing.T) {
+	storageFactory := func(_ context.Context, t *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		db := openTestDB(t)
+		return NewLogStorage(db, nil), NewAdminStorage(db)
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
+
+func TestDequeueLeavesFairShare(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	weights := func(int64) map[string]int { return map[string]int{"heavy": 2} }
+	ls, as := newLogStorage(db, nil, nil, weights), NewAdminStorage(db)
+
+	create := proto.Clone(storageto.LogTree).(*trillian.Tree)
+	create.DequeueOrder = trillian.DequeueOrder_FAIR_SHARE_ORDER
+	tr, err := storage.CreateTree(ctx, as, create)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		root, err := signer.SignLogRoot(&types.LogRootV1{})
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	// The noisy submitter queues its leaves first, so they are the oldest.
+	queueTime := time.Now().Add(-time.Minute)
+	submitters := make(map[string]string)
+	for _, q := range []struct {
+		submitter string
+		count     int
+	}{{"noisy", 10}, {"heavy", 10}, {"quiet", 1}} {
+		leaves := make([]*trillian.LogLeaf, 0, q.count)
+		for i := 0; i < q.count; i++ {
+			value := []byte(fmt.Sprintf("%s %d", q.submitter, i))
+			id := sha256.Sum256(value)
+			submitters[string(id[:])] = q.submitter
+			leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: id[:], MerkleLeafHash: id[:], LeafValue: value})
+		}
+		queueTime = queueTime.Add(time.Second)
+		if _, err := ls.QueueLeaves(storage.NewSubmitterContext(ctx, q.submitter), tr, leaves, queueTime); err != nil {
+			t.Fatalf("QueueLeaves(%s): %v", q.submitter, err)
+		}
+	}
+
+	got := make(map[string]int)
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		leaves, err := tx.DequeueLeaves(ctx, 8, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, l := range leaves {
+			got[submitters[string(l.LeafIdentityHash)]]++
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("DequeueLeaves(): %v", err)
+	}
+	if want := map[string]int{"noisy": 3, "heavy": 4, "quiet": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DequeueLeaves() per submitter = %v, want %v", got, want)
+	}
+}
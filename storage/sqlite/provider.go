@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/tiering"
+)
+
+var (
+	sqliteFile = flag.String("sqlite_file", "trillian.db", "Path of the SQLite database file, which is created if it does not exist")
+
+	sqliteMu  sync.Mutex
+	sqliteDBs = make(map[string]*sharedDB)
+)
+
+func init() {
+	if err := storage.RegisterProviderWithOptions("sqlite", newSQLiteProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider sqlite: %v", err)
+	}
+}
+
+// sharedDB is a database shared by the providers of the same file, which is
+// closed when the last of them is closed.
+type sharedDB struct {
+	db   *sql.DB
+	refs int
+}
+
+type sqliteProvider struct {
+	path    string
+	db      *sql.DB
+	cold    tiering.ObjectStore
+	mf      monitoring.MetricFactory
+	weights storage.SubmitterWeights
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newSQLiteProvider returns a provider of the storage in the --sqlite_file
+// database. The providers of the same file share the database, but each has
+// its own metrics and options.
+func newSQLiteProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
+	cold, err := tiering.SharedStore(mf)
+	if err != nil {
+		return nil, err
+	}
+	path := *sqliteFile
+	db, err := openSharedDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteProvider{
+		path:    path,
+		db:      db,
+		cold:    cold,
+		mf:      mf,
+		weights: opts.SubmitterWeights,
+	}, nil
+}
+
+// openSharedDB returns the shared database in the given file, opening it and
+// creating the tree schema in it if no provider has it open yet.
+func openSharedDB(path string) (*sql.DB, error) {
+	sqliteMu.Lock()
+	defer sqliteMu.Unlock()
+	if s, ok := sqliteDBs[path]; ok {
+		s.refs++
+		return s.db, nil
+	}
+	db, err := OpenDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := createSchema(context.TODO(), db); err != nil {
+		glog.Warningf("Failed to create schema in SQLite database %q: %s", path, err)
+		db.Close()
+		return nil, err
+	}
+	sqliteDBs[path] = &sharedDB{db: db, refs: 1}
+	return db, nil
+}
+
+func (s *sqliteProvider) LogStorage() storage.LogStorage {
+	return newLogStorage(s.db, s.mf, s.cold, s.weights)
+}
+
+func (s *sqliteProvider) AdminStorage() storage.AdminStorage {
+	return NewAdminStorage(s.db)
+}
+
+// Close closes the database once all the providers sharing it are closed.
+func (s *sqliteProvider) Close() error {
+	s.closeOnce.Do(func() {
+		sqliteMu.Lock()
+		defer sqliteMu.Unlock()
+		shared := sqliteDBs[s.path]
+		if shared.refs--; shared.refs == 0 {
+			delete(sqliteDBs, s.path)
+			s.closeErr = shared.db.Close()
+		}
+	})
+	return s.closeErr
+}
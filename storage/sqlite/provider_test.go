@@ -0,0 +1,66 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/testonly/flagsaver"
+)
+
+func TestProvidersShareDatabase(t *testing.T) {
+	defer flagsaver.Save().MustRestore()
+	if err := flag.Set("sqlite_file", filepath.Join(t.TempDir(), "trillian.db")); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+	ctx := context.Background()
+
+	newProvider := func(weight int) storage.Provider {
+		t.Helper()
+		weights := func(int64) map[string]int { return map[string]int{"a": weight} }
+		p, err := storage.NewProviderWithOptions("sqlite", nil, storage.ProviderOptions{SubmitterWeights: weights})
+		if err != nil {
+			t.Fatalf("NewProviderWithOptions(): %v", err)
+		}
+		return p
+	}
+	p1, p2 := newProvider(1), newProvider(2)
+	for i, p := range []storage.Provider{p1, p2} {
+		if got, want := p.(*sqliteProvider).weights(0)["a"], i+1; got != want {
+			t.Errorf("provider %d has weight %d, want %d", i, got, want)
+		}
+	}
+
+	if err := p1.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	// Closing a provider twice doesn't release the database of the others.
+	if err := p1.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if err := p2.AdminStorage().CheckDatabaseAccessible(ctx); err != nil {
+		t.Errorf("CheckDatabaseAccessible() after closing another provider: %v", err)
+	}
+	if err := p2.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if err := p2.AdminStorage().CheckDatabaseAccessible(ctx); err == nil {
+		t.Error("CheckDatabaseAccessible() after closing all providers succeeded, want error")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+)
+
+func TestLeafCompression(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ls, as := NewLogStorage(db, nil), NewAdminStorage(db)
+
+	create := proto.Clone(storageto.PreorderedLogTree).(*trillian.Tree)
+	create.LeafCompression = trillian.LeafCompression_ZSTD_LEAF_COMPRESSION
+	tree, err := storage.CreateTree(ctx, as, create)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	root, err := signer.SignLogRoot(&types.LogRootV1{})
+	if err != nil {
+		t.Fatalf("SignLogRoot(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return tx.StoreSignedLogRoot(ctx, root)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	const numLeaves = 5
+	leaves := make([]*trillian.LogLeaf, 0, numLeaves)
+	for i := 0; i < numLeaves; i++ {
+		value := bytes.Repeat([]byte(fmt.Sprintf("leaf %d ", i)), 100)
+		id := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafIdentityHash: id[:],
+			MerkleLeafHash:   id[:],
+			LeafValue:        value,
+			ExtraData:        []byte("extra"),
+			LeafIndex:        int64(i),
+		})
+	}
+	if _, err := ls.AddSequencedLeaves(ctx, tree, leaves, time.Now()); err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	checkCompression(ctx, t, db, tree.TreeId, numLeaves, trillian.LeafCompression_ZSTD_LEAF_COMPRESSION)
+	checkLeaves(ctx, t, ls, tree, leaves)
+
+	tree, err = storage.UpdateTree(ctx, as, tree.TreeId, func(tree *trillian.Tree) {
+		tree.LeafCompression = trillian.LeafCompression_NO_LEAF_COMPRESSION
+	})
+	if err != nil {
+		t.Fatalf("UpdateTree(): %v", err)
+	}
+	var after []byte
+	total := 0
+	for {
+		var last []byte
+		var n int
+		if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+			var err error
+			last, n, err = tx.(storage.RecompressLogTreeTX).RecompressLeaves(ctx, after, 2)
+			return err
+		}); err != nil {
+			t.Fatalf("RecompressLeaves(): %v", err)
+		}
+		total += n
+		if last == nil {
+			break
+		}
+		after = last
+	}
+	if total != numLeaves {
+		t.Errorf("RecompressLeaves() rewrote %d leaves, want %d", total, numLeaves)
+	}
+	checkCompression(ctx, t, db, tree.TreeId, numLeaves, trillian.LeafCompression_NO_LEAF_COMPRESSION)
+	checkLeaves(ctx, t, ls, tree, leaves)
+}
+
+// checkCompression verifies that all leaf data rows of the tree are stored in
+// the given format.
+func checkCompression(ctx context.Context, t *testing.T, db *sql.DB, treeID int64, wantCount int, want trillian.LeafCompression) {
+	t.Helper()
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM LeafData WHERE TreeId=? AND Compression=?", treeID, int32(want)).Scan(&count); err != nil {
+		t.Fatalf("Failed to count leaf data: %v", err)
+	}
+	if count != wantCount {
+		t.Errorf("Got %d leaves stored with %v, want %d", count, want, wantCount)
+	}
+}
+
+// checkLeaves verifies that the stored leaves read back as the given ones.
+func checkLeaves(ctx context.Context, t *testing.T, ls storage.LogStorage, tree *trillian.Tree, want []*trillian.LogLeaf) {
+	t.Helper()
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err := tx.GetLeavesByRange(ctx, 0, int64(len(want)))
+		if err != nil {
+			return err
+		}
+		if len(got) != len(want) {
+			t.Fatalf("GetLeavesByRange() returned %d leaves, want %d", len(got), len(want))
+		}
+		for i, l := range got {
+			if !bytes.Equal(l.LeafValue, want[i].LeafValue) || !bytes.Equal(l.ExtraData, want[i].ExtraData) {
+				t.Errorf("GetLeavesByRange()[%d] = %x/%x, want %x/%x", i, l.LeafValue, l.ExtraData, want[i].LeafValue, want[i].ExtraData)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+// schemaVersion is the version of the tree schema, which is recorded in the
+// user_version of the database. Increment it when changing the schema, and
+// bring existing databases up to date in createSchema.
+const schemaVersion = 1
+
+// schemaSQL is the SQLite version of the tree schema, which is created by
+// createSchema if it does not exist yet. It mirrors
+// storage/mysql/schema/storage.sql, with enums stored as TEXT and all binary
+// columns as BLOB.
+const schemaSQL = `
+-- Tree parameters should not be changed after creation. Doing so can
+-- render the data in the tree unusable or inconsistent.
+CREATE TABLE IF NOT EXISTS Trees(
+  TreeId                BIGINT NOT NULL,
+  TreeState             TEXT NOT NULL CHECK(TreeState IN ('ACTIVE', 'FROZEN', 'DRAINING')),
+  TreeType              TEXT NOT NULL CHECK(TreeType IN ('LOG', 'MAP', 'PREORDERED_LOG')),
+  HashStrategy          TEXT NOT NULL CHECK(HashStrategy IN ('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256', 'CONIKS_SHA256')),
+  HashAlgorithm         TEXT NOT NULL CHECK(HashAlgorithm IN ('SHA256')),
+  SignatureAlgorithm    TEXT NOT NULL CHECK(SignatureAlgorithm IN ('ECDSA', 'RSA', 'ED25519')),
+  DisplayName           VARCHAR(20),
+  Description           VARCHAR(200),
+  CreateTimeMillis      BIGINT NOT NULL,
+  UpdateTimeMillis      BIGINT NOT NULL,
+  MaxRootDurationMillis BIGINT NOT NULL,
+  PrivateKey            BLOB NOT NULL,
+  PublicKey             BLOB NOT NULL,
+  Deleted               BOOLEAN,
+  DeleteTimeMillis      BIGINT,
+  DequeueOrder          TEXT NOT NULL DEFAULT 'QUEUE_TIMESTAMP_ORDER' CHECK(DequeueOrder IN ('QUEUE_TIMESTAMP_ORDER', 'FAIR_SHARE_ORDER')),
+  LeafCompression       TEXT NOT NULL DEFAULT 'NO_LEAF_COMPRESSION' CHECK(LeafCompression IN ('NO_LEAF_COMPRESSION', 'ZSTD_LEAF_COMPRESSION')),
+  LeafCompressionDictionaryId INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId)
+);
+
+-- This table contains tree parameters that can be changed at runtime such as for
+-- administrative purposes.
+CREATE TABLE IF NOT EXISTS TreeControl(
+  TreeId                  BIGINT NOT NULL,
+  SigningEnabled          BOOLEAN NOT NULL,
+  SequencingEnabled       BOOLEAN NOT NULL,
+  SequenceIntervalSeconds INTEGER NOT NULL,
+  PRIMARY KEY(TreeId),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS Subtree(
+  TreeId               BIGINT NOT NULL,
+  SubtreeId            BLOB NOT NULL,
+  Nodes                BLOB NOT NULL,
+  SubtreeRevision      INTEGER NOT NULL,
+  Tiered               BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, SubtreeId, SubtreeRevision),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- The TreeRevisionIdx is used to enforce that there is only one STH at any
+-- tree revision
+CREATE TABLE IF NOT EXISTS TreeHead(
+  TreeId               BIGINT NOT NULL,
+  TreeHeadTimestamp    BIGINT,
+  TreeSize             BIGINT,
+  RootHash             BLOB NOT NULL,
+  RootSignature        BLOB NOT NULL,
+  TreeRevision         BIGINT,
+  PRIMARY KEY(TreeId, TreeHeadTimestamp),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS TreeHeadRevisionIdx
+  ON TreeHead(TreeId, TreeRevision);
+
+-- A leaf that has not been sequenced has a row in this table. If duplicate leaves
+-- are allowed they will all reference this row.
+CREATE TABLE IF NOT EXISTS LeafData(
+  TreeId               BIGINT NOT NULL,
+  LeafIdentityHash     BLOB NOT NULL,
+  LeafValue            BLOB NOT NULL,
+  ExtraData            BLOB,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  Compression          INTEGER NOT NULL DEFAULT 0,
+  Tiered               BOOLEAN NOT NULL DEFAULT 0,
+  PRIMARY KEY(TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- When a leaf is sequenced a row is added to this table. If logs allow duplicates then
+-- multiple rows will exist with different sequence numbers.
+CREATE TABLE IF NOT EXISTS SequencedLeafData(
+  TreeId               BIGINT NOT NULL,
+  SequenceNumber       BIGINT NOT NULL CHECK(SequenceNumber >= 0),
+  LeafIdentityHash     BLOB NOT NULL,
+  MerkleLeafHash       BLOB NOT NULL,
+  IntegrateTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY(TreeId, SequenceNumber),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE,
+  FOREIGN KEY(TreeId, LeafIdentityHash) REFERENCES LeafData(TreeId, LeafIdentityHash) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS SequencedLeafMerkleIdx
+  ON SequencedLeafData(TreeId, MerkleLeafHash);
+
+-- Allows finding the leaves which were integrated within a time window.
+CREATE INDEX IF NOT EXISTS SequencedLeafIntegrateTimestampIdx
+  ON SequencedLeafData(TreeId, IntegrateTimestampNanos, SequenceNumber);
+
+CREATE TABLE IF NOT EXISTS Unsequenced(
+  TreeId               BIGINT NOT NULL,
+  -- The bucket field is to allow the use of time based ring bucketed schemes if desired. If
+  -- unused this should be set to zero for all entries.
+  Bucket               INTEGER NOT NULL,
+  LeafIdentityHash     BLOB NOT NULL,
+  MerkleLeafHash       BLOB NOT NULL,
+  QueueTimestampNanos  BIGINT NOT NULL,
+  -- The identity of the submitter which queued the leaf, used to dequeue leaves
+  -- fairly across submitters for trees with the FAIR_SHARE_ORDER dequeue order.
+  Submitter            VARCHAR(255) NOT NULL DEFAULT '',
+  -- Identifies a queued leaf when leaves are dequeued in batches, see the
+  -- batched_queue build tag of the sqlcommon package.
+  QueueID              BLOB DEFAULT NULL,
+  PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
+);
+
+CREATE INDEX IF NOT EXISTS UnsequencedSubmitterIdx
+  ON Unsequenced(TreeId, Bucket, Submitter, QueueTimestampNanos);
+
+-- Leaves which the log signer failed to integrate, and set aside so that the
+-- rest of the queue can make progress. For PREORDERED_LOG trees the leaves
+-- also remain in SequencedLeafData.
+CREATE TABLE IF NOT EXISTS Quarantined(
+  TreeId                   BIGINT NOT NULL,
+  LeafIdentityHash         BLOB NOT NULL,
+  MerkleLeafHash           BLOB NOT NULL,
+  QueueTimestampNanos      BIGINT NOT NULL,
+  SequenceNumber           BIGINT NOT NULL,
+  Reason                   TEXT NOT NULL,
+  QuarantineTimestampNanos BIGINT NOT NULL,
+  PRIMARY KEY (TreeId, LeafIdentityHash),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+`
+
+// createUnsequencedQueueIDIdxSQL makes QueueID unique, like the UNIQUE column
+// of the MySQL schema. It is separate from schemaSQL as it must be created
+// after the QueueID column is added to existing databases.
+const createUnsequencedQueueIDIdxSQL = `CREATE UNIQUE INDEX IF NOT EXISTS UnsequencedQueueIDIdx
+  ON Unsequenced(QueueID)`
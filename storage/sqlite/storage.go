@@ -0,0 +1,129 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite provides a storage layer implementation on top of an embedded
+// SQLite database file, for small deployments which do not want to run a
+// separate database server.
+//
+// The database holds the MySQL schema, and the storage is implemented by the
+// sqlcommon package; this package only holds the differences of SQLite.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/sqlcommon"
+	"github.com/google/trillian/storage/tiering"
+
+	// Load SQLite driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var dialect = &sqlcommon.Dialect{
+	Name:        "sqlite",
+	ToGRPC:      sqliteToGRPC,
+	IsDuplicate: isDuplicateErr,
+	// All transactions lock the database, see OpenDB.
+	NoRowLocks: true,
+}
+
+// OpenDB opens the SQLite database in the given file, creating the file if
+// necessary. It does not create the tree schema in it.
+//
+// All transactions take the database write lock when they begin, so they are
+// serialized, and never fail because of a lock upgrade conflict. Reads which
+// are not part of a transaction are not blocked by them, as the database is
+// switched to write-ahead logging.
+func OpenDB(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("_txlock", "immediate")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", "10000")
+	params.Set("_foreign_keys", "on")
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		glog.Warningf("Could not open SQLite database %q: %s", path, err)
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewLogStorage creates a storage.LogStorage instance for the given SQLite
+// database. It assumes storage.AdminStorage is backed by the same database.
+func NewLogStorage(db *sql.DB, mf monitoring.MetricFactory) storage.LogStorage {
+	return NewLogStorageWithColdStore(db, mf, nil)
+}
+
+// NewLogStorageWithColdStore is like NewLogStorage, but keeps the data of the
+// leaves and subtrees tiered through storage.TieringLogTreeTX in the given
+// object store. If it is nil, tiering fails, as do reads of tiered data.
+func NewLogStorageWithColdStore(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore) storage.LogStorage {
+	return newLogStorage(db, mf, cold, nil)
+}
+
+func newLogStorage(db *sql.DB, mf monitoring.MetricFactory, cold tiering.ObjectStore, weights storage.SubmitterWeights) storage.LogStorage {
+	return sqlcommon.NewLogStorage(db, dialect, mf, cold, weights)
+}
+
+// NewAdminStorage returns a SQLite storage.AdminStorage implementation backed
+// by DB.
+func NewAdminStorage(db *sql.DB) storage.AdminStorage {
+	return sqlcommon.NewAdminStorage(db)
+}
+
+// createSchema creates the tree schema in the database, or brings it up to
+// date. It does not write to databases which are up to date already.
+func createSchema(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version >= schemaVersion {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, schemaSQL); err != nil {
+		return err
+	}
+	// Databases created before the schema was versioned lack the QueueID
+	// column, which CREATE TABLE IF NOT EXISTS does not add.
+	var hasQueueID bool
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info('Unsequenced') WHERE name = 'QueueID'").Scan(&hasQueueID); err != nil {
+		return err
+	}
+	if !hasQueueID {
+		if _, err := tx.ExecContext(ctx, "ALTER TABLE Unsequenced ADD COLUMN QueueID BLOB DEFAULT NULL"); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, createUnsequencedQueueIDIdxSQL); err != nil {
+		return err
+	}
+	// PRAGMA doesn't take placeholders.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB opens a new SQLite database with the tree schema in a temporary
+// directory, which is removed at the end of the test.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenDB(filepath.Join(t.TempDir(), "trillian.db"))
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := createSchema(context.Background(), db); err != nil {
+		t.Fatalf("createSchema(): %v", err)
+	}
+	return db
+}
+
+func TestOpenDBDoesNotCreateSchema(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "trillian.db"))
+	if err != nil {
+		t.Fatalf("OpenDB(): %v", err)
+	}
+	defer db.Close()
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		t.Fatalf("Failed to count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("OpenDB() created %d tables, want 0", tables)
+	}
+}
+
+func TestCreateSchema(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc  string
+		setup string
+	}{
+		{desc: "empty"},
+		{
+			// The schema of databases created before it was versioned.
+			desc: "unversioned",
+			setup: `CREATE TABLE Unsequenced(
+				TreeId BIGINT NOT NULL,
+				Bucket INTEGER NOT NULL,
+				LeafIdentityHash BLOB NOT NULL,
+				MerkleLeafHash BLOB NOT NULL,
+				QueueTimestampNanos BIGINT NOT NULL,
+				Submitter VARCHAR(255) NOT NULL DEFAULT '',
+				PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash))`,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			db, err := OpenDB(filepath.Join(t.TempDir(), "trillian.db"))
+			if err != nil {
+				t.Fatalf("OpenDB(): %v", err)
+			}
+			defer db.Close()
+			if tc.setup != "" {
+				if _, err := db.Exec(tc.setup); err != nil {
+					t.Fatalf("Failed to set up database: %v", err)
+				}
+			}
+
+			// The second call must find the schema up to date.
+			for i := 0; i < 2; i++ {
+				if err := createSchema(ctx, db); err != nil {
+					t.Fatalf("createSchema() #%d: %v", i, err)
+				}
+			}
+			var version int
+			if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+				t.Fatalf("Failed to read schema version: %v", err)
+			}
+			if version != schemaVersion {
+				t.Errorf("user_version = %d, want %d", version, schemaVersion)
+			}
+			if _, err := db.Exec("INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID) VALUES(1,0,x'01',x'01',1,x'01')"); err != nil {
+				t.Fatalf("Failed to queue leaf: %v", err)
+			}
+			if _, err := db.Exec("INSERT INTO Unsequenced(TreeId,Bucket,LeafIdentityHash,MerkleLeafHash,QueueTimestampNanos,QueueID) VALUES(1,0,x'02',x'02',2,x'01')"); err == nil {
+				t.Error("Queued a leaf with a duplicate QueueID")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/storage"
+	storageto "github.com/google/trillian/storage/testonly"
+	"github.com/google/trillian/storage/tiering"
+	"github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTiering(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	cold, err := tiering.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore(): %v", err)
+	}
+	ls, as := NewLogStorageWithColdStore(db, nil, cold), NewAdminStorage(db)
+
+	create := proto.Clone(storageto.PreorderedLogTree).(*trillian.Tree)
+	create.LeafCompression = trillian.LeafCompression_ZSTD_LEAF_COMPRESSION
+	tr, err := storage.CreateTree(ctx, as, create)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	signer := tcrypto.NewSigner(0, testonly.NewSignerWithFixedSig(nil, []byte("notnil")), crypto.SHA256)
+	storeRoot := func(tx storage.LogTreeTX, size uint64) error {
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		root, err := signer.SignLogRoot(&types.LogRootV1{TreeSize: size, Revision: uint64(rev), TimestampNanos: uint64(rev)})
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, root)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		return storeRoot(tx, 0)
+	}); err != nil {
+		t.Fatalf("StoreSignedLogRoot(): %v", err)
+	}
+
+	// The first log tile is complete, and the second one isn't.
+	const numLeaves = 300
+	leaves := make([]*trillian.LogLeaf, 0, numLeaves)
+	nodes := make([]tree.Node, 0, numLeaves)
+	for i := 0; i < numLeaves; i++ {
+		value := bytes.Repeat([]byte(fmt.Sprintf("leaf %d ", i)), 10)
+		id := sha256.Sum256(value)
+		leaves = append(leaves, &trillian.LogLeaf{
+			LeafIdentityHash: id[:],
+			MerkleLeafHash:   id[:],
+			LeafValue:        value,
+			ExtraData:        []byte("extra"),
+			LeafIndex:        int64(i),
+		})
+		nodes = append(nodes, tree.Node{ID: compact.NewNodeID(0, uint64(i)), Hash: id[:]})
+	}
+	if _, err := ls.AddSequencedLeaves(ctx, tr, leaves, time.Now()); err != nil {
+		t.Fatalf("AddSequencedLeaves(): %v", err)
+	}
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+			return err
+		}
+		return storeRoot(tx, numLeaves)
+	}); err != nil {
+		t.Fatalf("SetMerkleNodes(): %v", err)
+	}
+
+	var tieredLeaves, tieredTiles int
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		ttx := tx.(storage.TieringLogTreeTX)
+		for start := int64(0); start < numLeaves; start += 100 {
+			n, err := ttx.TierLeaves(ctx, start, start+100)
+			if err != nil {
+				return err
+			}
+			tieredLeaves += n
+		}
+		var after []byte
+		for {
+			last, n, err := ttx.TierTiles(ctx, numLeaves, after, 1)
+			if err != nil {
+				return err
+			}
+			tieredTiles += n
+			if last == nil {
+				return nil
+			}
+			after = last
+		}
+	}); err != nil {
+		t.Fatalf("Tiering failed: %v", err)
+	}
+	if tieredLeaves != numLeaves {
+		t.Errorf("TierLeaves() tiered %d leaves, want %d", tieredLeaves, numLeaves)
+	}
+	if tieredTiles != 1 {
+		t.Errorf("TierTiles() tiered %d tiles, want 1", tieredTiles)
+	}
+	var stubs int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM LeafData WHERE TreeId=? AND Tiered=1 AND LENGTH(LeafValue)=0", tr.TreeId).Scan(&stubs); err != nil {
+		t.Fatalf("Failed to count tiered leaves: %v", err)
+	}
+	if stubs != numLeaves {
+		t.Errorf("Got %d tiered leaf stubs, want %d", stubs, numLeaves)
+	}
+
+	// Tiering again finds nothing left to move.
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		n, err := tx.(storage.TieringLogTreeTX).TierLeaves(ctx, 0, numLeaves)
+		if n != 0 {
+			t.Errorf("TierLeaves() again tiered %d leaves, want 0", n)
+		}
+		return err
+	}); err != nil {
+		t.Fatalf("TierLeaves(): %v", err)
+	}
+
+	checkLeaves(ctx, t, ls, tr, leaves)
+	ids := []compact.NodeID{compact.NewNodeID(0, 0), compact.NewNodeID(0, 255), compact.NewNodeID(0, 299)}
+	if err := ls.ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		got, err := tx.GetMerkleNodes(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for i, n := range got {
+			if want := nodes[ids[i].Index].Hash; !bytes.Equal(n.Hash, want) {
+				t.Errorf("GetMerkleNodes()[%d]=%x, want %x", i, n.Hash, want)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("GetMerkleNodes(): %v", err)
+	}
+
+	// Without the cold store, tiered data can't be read.
+	if err := NewLogStorage(db, nil).ReadWriteTransaction(ctx, tr, func(ctx context.Context, tx storage.LogTreeTX) error {
+		_, err := tx.GetLeavesByRange(ctx, 0, 1)
+		return err
+	}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("GetLeavesByRange() without cold store: %v, want %v", err, codes.FailedPrecondition)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiering
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+)
+
+// cacheEntry is an element of the CachingStore LRU list.
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+// CachingStore is an ObjectStore which keeps up to a fixed number of the
+// objects most recently read from or written to another ObjectStore in
+// memory. Tiered objects never change, so cached ones never go stale.
+type CachingStore struct {
+	store ObjectStore
+	size  int
+
+	mu      sync.Mutex
+	lru     *list.List // Of *cacheEntry, most recently used at the front.
+	entries map[string]*list.Element
+
+	hits   monitoring.Counter
+	misses monitoring.Counter
+}
+
+// NewCachingStore returns a CachingStore which holds up to size objects of the
+// given store.
+func NewCachingStore(store ObjectStore, size int, mf monitoring.MetricFactory) *CachingStore {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &CachingStore{
+		store:   store,
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		hits:    mf.NewCounter("cold_storage_cache_hits", "Number of objects served from the cold storage cache"),
+		misses:  mf.NewCounter("cold_storage_cache_misses", "Number of objects read from cold storage"),
+	}
+}
+
+// Get implements ObjectStore.
+func (c *CachingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(el)
+		data := el.Value.(*cacheEntry).data
+		c.mu.Unlock()
+		c.hits.Inc()
+		return data, nil
+	}
+	c.mu.Unlock()
+	c.misses.Inc()
+
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	c.add(key, data)
+	return data, nil
+}
+
+// Put implements ObjectStore.
+func (c *CachingStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := c.store.Put(ctx, key, data); err != nil {
+		return err
+	}
+	c.add(key, data)
+	return nil
+}
+
+// Len returns the number of objects in the cache.
+func (c *CachingStore) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *CachingStore) add(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).data = data
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, data: data})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
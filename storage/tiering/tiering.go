@@ -0,0 +1,124 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tiering moves the data of old leaves and complete log tiles out of
+// the database and into a cheaper object store. The database keeps a stub
+// row for each tiered leaf and tile, which tells readers to fetch the data
+// from the object store instead.
+package tiering
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+)
+
+var (
+	coldStorageDir       = flag.String("cold_storage_dir", "", "Directory which tiered leaf data and log tiles are stored in, empty disables tiering")
+	coldStorageCacheSize = flag.Int("cold_storage_cache_size", 4096, "Max number of objects read from cold storage kept in the process-wide cache, 0 disables the cache")
+)
+
+var (
+	sharedStoreOnce sync.Once
+	sharedStore     ObjectStore
+	sharedStoreErr  error
+)
+
+// ErrNotFound is returned by ObjectStore.Get for keys which have no object.
+var ErrNotFound = errors.New("object not found")
+
+// ObjectStore stores immutable objects by key. Keys are slash-separated
+// paths, as returned by LeafKey and TileKey.
+type ObjectStore interface {
+	// Get returns the object with the given key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores the object with the given key, replacing any existing one.
+	// It must not return before the object is durably stored.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// SharedStore returns the process-wide ObjectStore configured by the
+// --cold_storage_dir and --cold_storage_cache_size flags, or nil if tiering is
+// disabled. The metric factory passed in by the first caller is used to create
+// the cache metrics.
+func SharedStore(mf monitoring.MetricFactory) (ObjectStore, error) {
+	sharedStoreOnce.Do(func() {
+		if *coldStorageDir == "" {
+			return
+		}
+		var fs *FileStore
+		fs, sharedStoreErr = NewFileStore(*coldStorageDir)
+		if sharedStoreErr != nil {
+			return
+		}
+		sharedStore = fs
+		if *coldStorageCacheSize > 0 {
+			sharedStore = NewCachingStore(fs, *coldStorageCacheSize, mf)
+		}
+	})
+	return sharedStore, sharedStoreErr
+}
+
+// LeafKey returns the key of the tiered data of the leaf with the given
+// identity hash.
+func LeafKey(treeID int64, leafIdentityHash []byte) string {
+	return fmt.Sprintf("%d/leaves/%x", treeID, leafIdentityHash)
+}
+
+// TileKey returns the key of the tiered log tile with the given subtree ID,
+// as stored at the given revision.
+func TileKey(treeID int64, subtreeID []byte, rev int64) string {
+	return fmt.Sprintf("%d/tiles/%x/%d", treeID, subtreeID, rev)
+}
+
+// MarshalLeafData returns the object which holds the given leaf value and
+// extra data. They are stored as they are, so compressed data stays
+// compressed.
+func MarshalLeafData(value, extra []byte) ([]byte, error) {
+	return proto.Marshal(&trillian.LogLeaf{LeafValue: value, ExtraData: extra})
+}
+
+// UnmarshalLeafData returns the leaf value and extra data held by an object
+// created by MarshalLeafData.
+func UnmarshalLeafData(data []byte) ([]byte, []byte, error) {
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(data, &leaf); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tiered leaf data: %v", err)
+	}
+	return leaf.LeafValue, leaf.ExtraData, nil
+}
+
+// TileBelow returns whether all the leaves covered by the log tile with the
+// given subtree ID have indices below treeSize. The tile is assumed to be
+// stored with the 8-bit strata used by the SQL storages, so its ID holds the
+// top bytes of the indices of the leaves which it covers.
+func TileBelow(subtreeID []byte, treeSize int64) bool {
+	if len(subtreeID) == 0 || len(subtreeID) > 8 || treeSize <= 0 {
+		return false
+	}
+	var begin uint64
+	for _, b := range subtreeID {
+		begin = begin<<8 | uint64(b)
+	}
+	width := uint(8 * (8 - len(subtreeID)))
+	begin <<= width
+	size := uint64(treeSize)
+	return begin < size && uint64(1)<<width <= size-begin
+}
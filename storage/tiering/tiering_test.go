@@ -0,0 +1,151 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiering
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore(): %v", err)
+	}
+
+	key := LeafKey(1, []byte{0xab, 0xcd})
+	if _, err := s.Get(ctx, key); err != ErrNotFound {
+		t.Errorf("Get(%q) before Put: %v, want %v", key, err, ErrNotFound)
+	}
+	for _, data := range [][]byte{[]byte("first"), []byte("second")} {
+		if err := s.Put(ctx, key, data); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		got, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", key, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Get(%q)=%q, want %q", key, got, data)
+		}
+	}
+}
+
+// countingStore is an in-memory ObjectStore which counts the calls to Get.
+type countingStore struct {
+	objects map[string][]byte
+	gets    int
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.gets++
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *countingStore) Put(ctx context.Context, key string, data []byte) error {
+	s.objects[key] = data
+	return nil
+}
+
+func TestCachingStore(t *testing.T) {
+	ctx := context.Background()
+	base := &countingStore{objects: map[string][]byte{
+		"a": []byte("A"),
+		"b": []byte("B"),
+		"c": []byte("C"),
+	}}
+	c := NewCachingStore(base, 2, nil)
+
+	for _, tc := range []struct {
+		key      string
+		wantGets int
+	}{
+		{key: "a", wantGets: 1},
+		{key: "a", wantGets: 1},
+		{key: "b", wantGets: 2},
+		{key: "c", wantGets: 3}, // Evicts "a".
+		{key: "b", wantGets: 3},
+		{key: "a", wantGets: 4},
+	} {
+		if _, err := c.Get(ctx, tc.key); err != nil {
+			t.Fatalf("Get(%q): %v", tc.key, err)
+		}
+		if base.gets != tc.wantGets {
+			t.Errorf("after Get(%q): %d underlying reads, want %d", tc.key, base.gets, tc.wantGets)
+		}
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len()=%d, want %d", got, want)
+	}
+
+	if _, err := c.Get(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("Get(missing): %v, want %v", err, ErrNotFound)
+	}
+	if err := c.Put(ctx, "d", []byte("D")); err != nil {
+		t.Fatalf("Put(d): %v", err)
+	}
+	gets := base.gets
+	if got, err := c.Get(ctx, "d"); err != nil || !bytes.Equal(got, []byte("D")) {
+		t.Errorf("Get(d)=%q, %v, want %q", got, err, "D")
+	}
+	if base.gets != gets {
+		t.Errorf("Get(d) after Put read the underlying store")
+	}
+}
+
+func TestLeafData(t *testing.T) {
+	value, extra := []byte("value"), []byte("extra")
+	data, err := MarshalLeafData(value, extra)
+	if err != nil {
+		t.Fatalf("MarshalLeafData(): %v", err)
+	}
+	gotValue, gotExtra, err := UnmarshalLeafData(data)
+	if err != nil {
+		t.Fatalf("UnmarshalLeafData(): %v", err)
+	}
+	if !bytes.Equal(gotValue, value) || !bytes.Equal(gotExtra, extra) {
+		t.Errorf("UnmarshalLeafData()=%q, %q, want %q, %q", gotValue, gotExtra, value, extra)
+	}
+}
+
+func TestTileBelow(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		id       []byte
+		treeSize int64
+		want     bool
+	}{
+		{desc: "root", id: []byte{}, treeSize: 1 << 40, want: false},
+		{desc: "bottom-full", id: []byte{0, 0, 0, 0, 0, 0, 1}, treeSize: 512, want: true},
+		{desc: "bottom-partial", id: []byte{0, 0, 0, 0, 0, 0, 1}, treeSize: 511, want: false},
+		{desc: "bottom-beyond", id: []byte{0, 0, 0, 0, 0, 0, 2}, treeSize: 512, want: false},
+		{desc: "second-full", id: []byte{0, 0, 0, 0, 0, 1}, treeSize: 1 << 17, want: true},
+		{desc: "second-partial", id: []byte{0, 0, 0, 0, 0, 1}, treeSize: 1<<17 - 1, want: false},
+		{desc: "top-last", id: []byte{0xff}, treeSize: 1<<63 - 1, want: false},
+		{desc: "empty-tree", id: []byte{0, 0, 0, 0, 0, 0, 0}, treeSize: 0, want: false},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := TileBelow(tc.id, tc.treeSize); got != tc.want {
+				t.Errorf("TileBelow(%x, %d)=%v, want %v", tc.id, tc.treeSize, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/trillian"
+)
+
+// leafIndex maps the identity and Merkle leaf hashes of the sequenced leaves
+// of a log to their indices. It is shared by the transactions of the log, and
+// only grows, so it may hold leaves committed after the state a transaction
+// reads, which the transaction must ignore.
+type leafIndex struct {
+	mu sync.Mutex
+	// leaves is the number of leaves of the entry bundles which are indexed.
+	leaves int64
+	// staged holds the indices of the staged leaves which are indexed, above
+	// leaves.
+	staged map[int64]bool
+	byID   map[string]int64
+	byHash map[string][]int64
+}
+
+func newLeafIndex() *leafIndex {
+	return &leafIndex{
+		staged: make(map[int64]bool),
+		byID:   make(map[string]int64),
+		byHash: make(map[string][]int64),
+	}
+}
+
+// update indexes the leaves of the log in dir, as of state st, which are not
+// indexed yet.
+func (x *leafIndex) update(dir string, st *treeState) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for x.leaves < st.Leaves {
+		n := x.leaves / tileWidth
+		width := st.Leaves - n*tileWidth
+		if width > tileWidth {
+			width = tileWidth
+		}
+		entries, err := readTile(dir, entriesDir, n, int(width), 0)
+		if err != nil {
+			return fmt.Errorf("failed to read entry bundle %d: %v", n, err)
+		}
+		if int64(len(entries)) < width {
+			return fmt.Errorf("entry bundle %d has %d entries, want %d", n, len(entries), width)
+		}
+		for i := x.leaves - n*tileWidth; i < width; i++ {
+			if index := n*tileWidth + i; x.staged[index] {
+				// The leaf was indexed while staged.
+				delete(x.staged, index)
+				continue
+			}
+			leaf, err := unmarshalLeaf(entries[i])
+			if err != nil {
+				return err
+			}
+			x.add(leaf)
+		}
+		x.leaves = n*tileWidth + width
+	}
+
+	for _, index := range st.Staged {
+		if index < x.leaves || x.staged[index] {
+			continue
+		}
+		leaf, err := readStaged(dir, index)
+		if os.IsNotExist(err) {
+			// The leaf has been moved into the entry bundles since st was
+			// read, and is indexed from there once they are.
+			continue
+		} else if err != nil {
+			return err
+		}
+		x.add(leaf)
+		x.staged[index] = true
+	}
+	return nil
+}
+
+func (x *leafIndex) add(leaf *trillian.LogLeaf) {
+	x.byID[string(leaf.LeafIdentityHash)] = leaf.LeafIndex
+	x.byHash[string(leaf.MerkleLeafHash)] = append(x.byHash[string(leaf.MerkleLeafHash)], leaf.LeafIndex)
+}
+
+// lookupID returns the index of the leaf with the given identity hash.
+func (x *leafIndex) lookupID(leafIDHash []byte) (int64, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	index, ok := x.byID[string(leafIDHash)]
+	return index, ok
+}
+
+// lookupHash returns the indices of the leaves with the given Merkle leaf
+// hash.
+func (x *leafIndex) lookupHash(hash []byte) []int64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return append([]int64(nil), x.byHash[string(hash)]...)
+}
+
+func stagedPath(index int64) string {
+	return filepath.Join(stagedDir, strconv.FormatInt(index, 10))
+}
+
+// readStaged reads the staged leaf with the given index of the log in dir.
+func readStaged(dir string, index int64) (*trillian.LogLeaf, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, stagedPath(index)))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalLeaf(b)
+}
+
+func unmarshalLeaf(b []byte) (*trillian.LogLeaf, error) {
+	var leaf trillian.LogLeaf
+	if err := proto.Unmarshal(b, &leaf); err != nil {
+		return nil, fmt.Errorf("failed to read sequenced leaf: %v", err)
+	}
+	return &leaf, nil
+}
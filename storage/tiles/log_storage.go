@@ -0,0 +1,1189 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/compact"
+	"github.com/google/trillian/merkle/hashers"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/commitlog"
+	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	logIDLabel = "logid"
+
+	// dequeueBatch is the number of entries of the queue read at a time by
+	// DequeueLeaves.
+	dequeueBatch = 256
+)
+
+var (
+	once            sync.Once
+	queuedCounter   monitoring.Counter
+	dequeuedCounter monitoring.Counter
+)
+
+func createMetrics(mf monitoring.MetricFactory) {
+	queuedCounter = mf.NewCounter("tiles_queued_leaves", "Number of leaves queued", logIDLabel)
+	dequeuedCounter = mf.NewCounter("tiles_dequeued_leaves", "Number of leaves dequeued", logIDLabel)
+}
+
+func labelForTX(t *logTreeTX) string {
+	return strconv.FormatInt(t.treeID, 10)
+}
+
+func queueTopic(treeID int64) string {
+	return "queue/" + strconv.FormatInt(treeID, 10)
+}
+
+// queuedLeaf is an entry of the queue of a log.
+type queuedLeaf struct {
+	// Leaf is the marshaled LogLeaf, with its QueueTimestamp set.
+	Leaf      []byte
+	Submitter string `json:",omitempty"`
+}
+
+type tileLogStorage struct {
+	dir           string
+	log           commitlog.Log
+	admin         storage.AdminStorage
+	metricFactory monitoring.MetricFactory
+	// submitterWeights weights the submitters of FAIR_SHARE_ORDER trees.
+	submitterWeights storage.SubmitterWeights
+
+	// mu guards logs, which holds the state shared by the transactions of
+	// each log.
+	mu   sync.Mutex
+	logs map[int64]*logData
+}
+
+// logData is the state shared by the transactions of a log.
+type logData struct {
+	// writer serialises the read-write transactions of the log within this
+	// process. The lock file of the log serialises them across processes.
+	writer sync.Mutex
+	index  *leafIndex
+}
+
+// NewLogStorage creates a storage.LogStorage instance which keeps each log in
+// a subdirectory of dir, and its queue in l. The trees are read from l, as
+// kept by commitlog.NewAdminStorage.
+func NewLogStorage(dir string, l commitlog.Log, mf monitoring.MetricFactory) storage.LogStorage {
+	return newLogStorage(dir, l, mf, nil)
+}
+
+func newLogStorage(dir string, l commitlog.Log, mf monitoring.MetricFactory, weights storage.SubmitterWeights) *tileLogStorage {
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	return &tileLogStorage{
+		dir:              dir,
+		log:              l,
+		admin:            commitlog.NewAdminStorage(l),
+		metricFactory:    mf,
+		submitterWeights: weights,
+		logs:             make(map[int64]*logData),
+	}
+}
+
+func (m *tileLogStorage) CheckDatabaseAccessible(ctx context.Context) error {
+	fi, err := os.Stat(m.dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", m.dir)
+	}
+	return m.admin.CheckDatabaseAccessible(ctx)
+}
+
+func (m *tileLogStorage) logDir(treeID int64) string {
+	return filepath.Join(m.dir, strconv.FormatInt(treeID, 10))
+}
+
+func (m *tileLogStorage) logData(treeID int64) *logData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.logs[treeID]
+	if !ok {
+		d = &logData{index: newLeafIndex()}
+		m.logs[treeID] = d
+	}
+	return d
+}
+
+// lockWriter takes the writer lock of the log in dir, and returns a function
+// which releases it.
+func (d *logData) lockWriter(dir string) (func(), error) {
+	d.writer.Lock()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		d.writer.Unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, lockName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		d.writer.Unlock()
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		d.writer.Unlock()
+		return nil, fmt.Errorf("failed to lock %s: %v", dir, err)
+	}
+	return func() {
+		unlockFile(f) // nolint: errcheck
+		f.Close()
+		d.writer.Unlock()
+	}, nil
+}
+
+// readOnlyLogTX implements storage.ReadOnlyLogTX
+type readOnlyLogTX struct {
+	admin storage.AdminStorage
+}
+
+func (m *tileLogStorage) Snapshot(ctx context.Context) (storage.ReadOnlyLogTX, error) {
+	return &readOnlyLogTX{m.admin}, nil
+}
+
+func (t *readOnlyLogTX) Commit(context.Context) error {
+	return nil
+}
+
+func (t *readOnlyLogTX) Rollback() error {
+	return nil
+}
+
+func (t *readOnlyLogTX) Close() error {
+	return nil
+}
+
+func (t *readOnlyLogTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	return getActiveLogIDs(ctx, t.admin)
+}
+
+// getActiveLogIDs returns the IDs of all logs that are currently in a state
+// that requires sequencing (e.g. ACTIVE, DRAINING).
+func getActiveLogIDs(ctx context.Context, admin storage.AdminStorage) ([]int64, error) {
+	trees, err := storage.ListTrees(ctx, admin, false /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int64{}
+	for _, tree := range trees {
+		switch tree.TreeType {
+		case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+			switch tree.TreeState {
+			case trillian.TreeState_ACTIVE, trillian.TreeState_DRAINING:
+				ids = append(ids, tree.TreeId)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (m *tileLogStorage) beginInternal(ctx context.Context, tree *trillian.Tree, writable bool) (*logTreeTX, error) {
+	once.Do(func() {
+		createMetrics(m.metricFactory)
+	})
+	hasher, err := registry.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := m.logDir(tree.TreeId)
+	data := m.logData(tree.TreeId)
+	unlock := func() {}
+	if writable {
+		if unlock, err = data.lockWriter(dir); err != nil {
+			return nil, err
+		}
+	}
+	state, err := readState(dir)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	if err := data.index.update(dir, &state); err != nil {
+		unlock()
+		return nil, fmt.Errorf("failed to index leaves of log %d: %v", tree.TreeId, err)
+	}
+
+	ltx := &logTreeTX{
+		ls:            m,
+		data:          data,
+		dir:           dir,
+		unlock:        unlock,
+		writable:      writable,
+		treeID:        tree.TreeId,
+		treeType:      tree.TreeType,
+		order:         tree.DequeueOrder,
+		hasher:        hasher,
+		hashSizeBytes: hasher.Size(),
+		state:         state,
+		staged:        make(map[int64]bool, len(state.Staged)),
+		tiles:         make(map[string][][]byte),
+		writeRevision: -1,
+		nextQueue:     state.QueueOffset,
+		held:          math.MaxInt64,
+		dequeued:      make(map[string]dequeuedLeaf),
+		sequenced:     make(map[int64]*trillian.LogLeaf),
+		seqIDs:        make(map[string]bool),
+		nodes:         make(map[compact.NodeID][]byte),
+	}
+	for _, index := range state.Staged {
+		ltx.staged[index] = true
+	}
+	if state.Root == nil {
+		// It's possible there are no roots for this tree yet
+		ltx.writeRevision = 0
+		return ltx, storage.ErrTreeNeedsInit
+	}
+
+	var slr trillian.SignedLogRoot
+	if err := proto.Unmarshal(state.Root, &slr); err != nil {
+		ltx.rollbackInternal() // nolint: errcheck
+		return nil, fmt.Errorf("failed to read signed log root: %v", err)
+	}
+	if err := ltx.root.UnmarshalBinary(slr.LogRoot); err != nil {
+		ltx.rollbackInternal() // nolint: errcheck
+		return nil, err
+	}
+	ltx.slr = &slr
+	ltx.writeRevision = int64(ltx.root.Revision) + 1
+	return ltx, nil
+}
+
+func (m *tileLogStorage) ReadWriteTransaction(ctx context.Context, tree *trillian.Tree, f storage.LogTXFunc) error {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return err
+	}
+	defer tx.Close()
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *tileLogStorage) AddSequencedLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, true /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if AddSequencedLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.AddSequencedLeaves(ctx, leaves, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *tileLogStorage) SnapshotForTree(ctx context.Context, tree *trillian.Tree) (storage.ReadOnlyLogTreeTX, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* writable */)
+	if err != nil && err != storage.ErrTreeNeedsInit {
+		return nil, err
+	}
+	return tx, err
+}
+
+// QueueLeaves appends the leaves to the queue of the log. Leaves which have
+// already been sequenced are returned as duplicates; duplicates of leaves
+// which are still queued are only detected when dequeued.
+func (m *tileLogStorage) QueueLeaves(ctx context.Context, tree *trillian.Tree, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	tx, err := m.beginInternal(ctx, tree, false /* writable */)
+	if tx != nil {
+		// Ensure we don't leak the transaction. For example if we get an
+		// ErrTreeNeedsInit from beginInternal() or if QueueLeaves fails
+		// below.
+		defer tx.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tx.queueLeaves(ctx, leaves, queueTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*trillian.QueuedLogLeaf, len(leaves))
+	for i, e := range existing {
+		if e != nil {
+			ret[i] = &trillian.QueuedLogLeaf{
+				Leaf:   e,
+				Status: status.Newf(codes.AlreadyExists, "leaf already exists: %v", e.LeafIdentityHash).Proto(),
+			}
+			continue
+		}
+		ret[i] = &trillian.QueuedLogLeaf{Leaf: leaves[i]}
+	}
+	return ret, nil
+}
+
+// dequeuedLeaf is a leaf returned by DequeueLeaves, along with its offset in
+// the queue.
+type dequeuedLeaf struct {
+	offset int64
+	leaf   *trillian.LogLeaf
+}
+
+// logTreeTX reads the files of a log listed by the state it began with, and
+// buffers its writes until it is committed, when the new files are written
+// and the state is replaced.
+type logTreeTX struct {
+	// mu ensures that the transaction can only be used for one operation at
+	// a time.
+	mu     sync.Mutex
+	closed bool
+
+	ls   *tileLogStorage
+	data *logData
+	dir  string
+	// unlock releases the writer lock held by a read-write transaction.
+	unlock   func()
+	writable bool
+
+	treeID        int64
+	treeType      trillian.TreeType
+	order         trillian.DequeueOrder
+	hasher        hashers.LogHasher
+	hashSizeBytes int
+	writeRevision int64
+	state         treeState
+	// staged holds the indices of the leaves in the staged directory.
+	staged map[int64]bool
+	root   types.LogRootV1
+	slr    *trillian.SignedLogRoot
+	// tiles caches the entries of the tiles and bundles read, by path.
+	tiles map[string][][]byte
+
+	// nextQueue is the offset in the queue from which the next call to
+	// DequeueLeaves reads.
+	nextQueue int64
+	// held is the lowest offset of a leaf which was read by DequeueLeaves,
+	// but left in the queue, or math.MaxInt64 if there is none.
+	held int64
+	// dequeued holds the leaves which have been dequeued but not sequenced
+	// yet, keyed by identity hash.
+	dequeued map[string]dequeuedLeaf
+
+	// sequenced holds the leaves to be added to the log by index, and seqIDs
+	// their identity hashes.
+	sequenced map[int64]*trillian.LogLeaf
+	seqIDs    map[string]bool
+	// nodes holds the hashes set which are on the rows stored in tiles.
+	nodes   map[compact.NodeID][]byte
+	newRoot *trillian.SignedLogRoot
+	newSize int64
+}
+
+// partWidth returns the number of entries of tile n of a row of count
+// entries.
+func partWidth(count, n int64) int64 {
+	if w := count - n*tileWidth; w < tileWidth {
+		return w
+	}
+	return tileWidth
+}
+
+// tile returns the first width entries of the given tile.
+func (t *logTreeTX) tile(name string, n, width int64, entrySize int) ([][]byte, error) {
+	p := tilePath(name, n, int(width))
+	if entries, ok := t.tiles[p]; ok {
+		return entries, nil
+	}
+	entries, err := readTile(t.dir, name, n, int(width), entrySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tile %s: %v", p, err)
+	}
+	if int64(len(entries)) < width {
+		return nil, fmt.Errorf("tile %s has %d entries, want %d", p, len(entries), width)
+	}
+	entries = entries[:width]
+	t.tiles[p] = entries
+	return entries, nil
+}
+
+// visible returns whether the leaf with the given index is in the state read
+// by the transaction.
+func (t *logTreeTX) visible(index int64) bool {
+	return index < t.state.Leaves || t.staged[index]
+}
+
+// hasLeafID returns whether a leaf with the given identity hash is in the
+// log, or is being added to it by this transaction.
+func (t *logTreeTX) hasLeafID(leafIDHash []byte) bool {
+	if t.seqIDs[string(leafIDHash)] {
+		return true
+	}
+	index, ok := t.data.index.lookupID(leafIDHash)
+	return ok && t.visible(index)
+}
+
+// hasLeafIndex returns whether the log has a leaf at the given index, or one
+// is being added by this transaction.
+func (t *logTreeTX) hasLeafIndex(seq int64) bool {
+	return t.sequenced[seq] != nil || t.visible(seq)
+}
+
+// addSequenced buffers the given leaf to be added to the log on commit.
+func (t *logTreeTX) addSequenced(leaf *trillian.LogLeaf) {
+	t.sequenced[leaf.LeafIndex] = leaf
+	t.seqIDs[string(leaf.LeafIdentityHash)] = true
+}
+
+func (t *logTreeTX) ReadRevision(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return int64(t.root.Revision), nil
+}
+
+func (t *logTreeTX) WriteRevision(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.writeRevision < 0 {
+		return t.writeRevision, errors.New("logTreeTX write revision not populated")
+	}
+	return t.writeRevision, nil
+}
+
+// GetMerkleNodes returns the requested nodes of the tree as of the latest
+// root. The nodes which are not on the rows stored in tiles are computed
+// from the nodes below them which are.
+func (t *logTreeTX) GetMerkleNodes(ctx context.Context, ids []compact.NodeID) ([]stree.Node, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ret := make([]stree.Node, 0, len(ids))
+	for _, id := range ids {
+		level := int(id.Level / tileHeight)
+		height := id.Level % tileHeight
+		begin, end := int64(id.Index<<height), int64((id.Index+1)<<height)
+		if end > t.state.row(level) {
+			continue
+		}
+		// The node is the root of a perfect subtree of height < tileHeight,
+		// so its descendants on the row are all in the same tile.
+		n := begin / tileWidth
+		entries, err := t.tile(levelName(level), n, partWidth(t.state.row(level), n), t.hashSizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		hashes := entries[begin-n*tileWidth : end-n*tileWidth]
+		for len(hashes) > 1 {
+			parents := make([][]byte, len(hashes)/2)
+			for i := range parents {
+				parents[i] = t.hasher.HashChildren(hashes[2*i], hashes[2*i+1])
+			}
+			hashes = parents
+		}
+		ret = append(ret, stree.Node{ID: id, Hash: hashes[0]})
+	}
+	return ret, nil
+}
+
+// SetMerkleNodes buffers the nodes which are on the rows stored in tiles, and
+// discards the others. The nodes which are not in perfect subtrees of the
+// tree as of the root stored by the transaction are discarded on commit.
+func (t *logTreeTX) SetMerkleNodes(ctx context.Context, nodes []stree.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, n := range nodes {
+		if len(n.Hash) != t.hashSizeBytes {
+			return fmt.Errorf("node %+v has hash size %d, want %d", n.ID, len(n.Hash), t.hashSizeBytes)
+		}
+		if n.ID.Level%tileHeight == 0 {
+			t.nodes[n.ID] = n.Hash
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_PREORDERED_LOG {
+		return t.getLeavesByRangeInternal(int64(t.root.TreeSize), int64(limit))
+	}
+
+	var leaves []*trillian.LogLeaf
+	var err error
+	offsets := make(map[*trillian.LogLeaf]int64)
+	if t.order == trillian.DequeueOrder_FAIR_SHARE_ORDER {
+		// The queue is in queue order, so each submitter's queue will be too.
+		queues := make(map[string][]*trillian.LogLeaf)
+		err = t.scanQueue(ctx, cutoffTime, func(offset int64, leaf *trillian.LogLeaf, submitter string) bool {
+			if len(queues[submitter]) < limit {
+				queues[submitter] = append(queues[submitter], leaf)
+				offsets[leaf] = offset
+			} else if offset < t.held {
+				t.held = offset
+			}
+			return true
+		})
+		leaves = storage.FairShare(queues, t.ls.submitterWeights.ForTree(t.treeID), limit)
+		chosen := make(map[*trillian.LogLeaf]bool, len(leaves))
+		for _, leaf := range leaves {
+			chosen[leaf] = true
+		}
+		for leaf, offset := range offsets {
+			if !chosen[leaf] && offset < t.held {
+				t.held = offset
+			}
+		}
+	} else {
+		leaves = make([]*trillian.LogLeaf, 0, limit)
+		err = t.scanQueue(ctx, cutoffTime, func(offset int64, leaf *trillian.LogLeaf, _ string) bool {
+			leaves = append(leaves, leaf)
+			offsets[leaf] = offset
+			return len(leaves) < limit
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leaf := range leaves {
+		t.dequeued[string(leaf.LeafIdentityHash)] = dequeuedLeaf{offset: offsets[leaf], leaf: leaf}
+	}
+	dequeuedCounter.Add(float64(len(leaves)), labelForTX(t))
+	return leaves, nil
+}
+
+// scanQueue calls f with the leaves of the queue from nextQueue on, in queue
+// order, until f returns false or a leaf queued after cutoffTime is reached.
+// Leaves which are already in the log, or have been dequeued by this
+// transaction, are skipped. nextQueue is advanced past the leaves passed to f.
+func (t *logTreeTX) scanQueue(ctx context.Context, cutoffTime time.Time, f func(offset int64, leaf *trillian.LogLeaf, submitter string) bool) error {
+	cutoff := cutoffTime.UnixNano()
+	for {
+		entries, err := t.ls.log.Read(ctx, queueTopic(t.treeID), t.nextQueue, dequeueBatch)
+		if err != nil {
+			return fmt.Errorf("failed to read queued leaves: %v", err)
+		}
+		for _, e := range entries {
+			var q queuedLeaf
+			if err := json.Unmarshal(e, &q); err != nil {
+				return fmt.Errorf("failed to read queued leaf %d: %v", t.nextQueue, err)
+			}
+			var leaf trillian.LogLeaf
+			if err := proto.Unmarshal(q.Leaf, &leaf); err != nil {
+				return fmt.Errorf("failed to read queued leaf %d: %v", t.nextQueue, err)
+			}
+			queueTimestamp, err := ptypes.Timestamp(leaf.QueueTimestamp)
+			if err != nil {
+				return fmt.Errorf("got invalid queue timestamp: %v", err)
+			}
+			if queueTimestamp.UnixNano() > cutoff {
+				return nil
+			}
+			offset := t.nextQueue
+			t.nextQueue++
+			if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+				return errors.New("dequeued a leaf with incorrect hash size")
+			}
+			if _, ok := t.dequeued[string(leaf.LeafIdentityHash)]; ok || t.hasLeafID(leaf.LeafIdentityHash) {
+				continue
+			}
+			if !f(offset, &leaf, q.Submitter) {
+				return nil
+			}
+		}
+		if len(entries) < dequeueBatch {
+			return nil
+		}
+	}
+}
+
+// queueLeaves appends the leaves which are not in the log yet to its queue,
+// and returns the existing leaves for the others.
+func (t *logTreeTX) queueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Don't accept batches if any of the leaves are invalid.
+	for _, leaf := range leaves {
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return nil, fmt.Errorf("queued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+		var err error
+		leaf.QueueTimestamp, err = ptypes.TimestampProto(queueTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+		}
+	}
+	submitter := storage.SubmitterFromContext(ctx)
+
+	existingLeaves := make([]*trillian.LogLeaf, len(leaves))
+	var entries [][]byte
+	for i, leaf := range leaves {
+		existing, err := t.getSequencedByID(leaf.LeafIdentityHash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existingLeaves[i] = existing
+			continue
+		}
+
+		v, err := proto.Marshal(&trillian.LogLeaf{
+			LeafIdentityHash: leaf.LeafIdentityHash,
+			MerkleLeafHash:   leaf.MerkleLeafHash,
+			LeafValue:        leaf.LeafValue,
+			ExtraData:        leaf.ExtraData,
+			QueueTimestamp:   leaf.QueueTimestamp,
+		})
+		if err != nil {
+			return nil, err
+		}
+		e, err := json.Marshal(queuedLeaf{Leaf: v, Submitter: submitter})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) > 0 {
+		if _, err := t.ls.log.Append(ctx, queueTopic(t.treeID), entries...); err != nil {
+			glog.Warningf("Error queueing leaves: %s", err)
+			return nil, err
+		}
+	}
+	queuedCounter.Add(float64(len(entries)), labelForTX(t))
+	return existingLeaves, nil
+}
+
+func (t *logTreeTX) AddSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf, timestamp time.Time) ([]*trillian.QueuedLogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	queueTimestamp, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("got invalid queue timestamp: %v", err)
+	}
+	integrateTimestamp, err := ptypes.TimestampProto(time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*trillian.QueuedLogLeaf, len(leaves))
+	ok := status.New(codes.OK, "OK").Proto()
+	for i, leaf := range leaves {
+		if got, want := len(leaf.LeafIdentityHash), t.hashSizeBytes; got != want {
+			return nil, status.Errorf(codes.FailedPrecondition, "leaves[%d] has incorrect hash size %d, want %d", i, got, want)
+		}
+		res[i] = &trillian.QueuedLogLeaf{Status: ok}
+
+		if t.hasLeafID(leaf.LeafIdentityHash) {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIdentityHash").Proto()
+			continue
+		}
+		if t.hasLeafIndex(leaf.LeafIndex) {
+			res[i].Status = status.New(codes.FailedPrecondition, "conflicting LeafIndex").Proto()
+			continue
+		}
+
+		t.addSequenced(&trillian.LogLeaf{
+			LeafIdentityHash:   leaf.LeafIdentityHash,
+			MerkleLeafHash:     leaf.MerkleLeafHash,
+			LeafValue:          leaf.LeafValue,
+			ExtraData:          leaf.ExtraData,
+			LeafIndex:          leaf.LeafIndex,
+			QueueTimestamp:     queueTimestamp,
+			IntegrateTimestamp: integrateTimestamp,
+		})
+	}
+	return res, nil
+}
+
+// getSequenced reads the leaf at the given index, or returns nil if there is
+// none.
+func (t *logTreeTX) getSequenced(seq int64) (*trillian.LogLeaf, error) {
+	if seq < 0 {
+		return nil, nil
+	}
+	if seq < t.state.Leaves {
+		return t.bundleLeaf(seq, t.state.Leaves)
+	}
+	if !t.staged[seq] {
+		return nil, nil
+	}
+	leaf, err := readStaged(t.dir, seq)
+	if os.IsNotExist(err) {
+		// A commit made since the state was read has moved the leaf into the
+		// entry bundles.
+		state, err := readState(t.dir)
+		if err != nil {
+			return nil, err
+		}
+		if seq < state.Leaves {
+			return t.bundleLeaf(seq, state.Leaves)
+		}
+		return nil, fmt.Errorf("missing staged leaf %d", seq)
+	}
+	return leaf, err
+}
+
+// bundleLeaf reads the leaf at the given index from the entry bundles, which
+// hold the given number of leaves.
+func (t *logTreeTX) bundleLeaf(seq, leaves int64) (*trillian.LogLeaf, error) {
+	n := seq / tileWidth
+	entries, err := t.tile(entriesDir, n, partWidth(leaves, n), 0)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalLeaf(entries[seq-n*tileWidth])
+}
+
+// getSequencedByID reads the leaf with the given identity hash, or returns
+// nil if there is none.
+func (t *logTreeTX) getSequencedByID(leafIDHash []byte) (*trillian.LogLeaf, error) {
+	seq, ok := t.data.index.lookupID(leafIDHash)
+	if !ok || !t.visible(seq) {
+		return nil, nil
+	}
+	leaf, err := t.getSequenced(seq)
+	if err != nil {
+		return nil, err
+	}
+	if leaf == nil {
+		return nil, fmt.Errorf("missing leaf with identity hash %x", leafIDHash)
+	}
+	return leaf, nil
+}
+
+func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := t.state.Leaves
+	for seq := range t.staged {
+		if seq >= count {
+			count = seq + 1
+		}
+	}
+	return count, nil
+}
+
+func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		for _, leaf := range leaves {
+			if leaf < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "index %d is < 0", leaf)
+			}
+			if leaf >= treeSize {
+				return nil, status.Errorf(codes.OutOfRange, "invalid leaf index %d, want < TreeSize(%d)", leaf, treeSize)
+			}
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, seq := range leaves {
+		leaf, err := t.getSequenced(seq)
+		if err != nil {
+			return nil, err
+		}
+		if leaf != nil {
+			ret = append(ret, leaf)
+		}
+	}
+	if got, want := len(ret), len(leaves); got != want {
+		return nil, status.Errorf(codes.Internal, "len(ret): %d, want %d", got, want)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.getLeavesByRangeInternal(start, count)
+}
+
+func (t *logTreeTX) getLeavesByRangeInternal(start, count int64) ([]*trillian.LogLeaf, error) {
+	if count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid count %d, want > 0", count)
+	}
+	if start < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid start %d, want >= 0", start)
+	}
+
+	if t.treeType == trillian.TreeType_LOG {
+		treeSize := int64(t.root.TreeSize)
+		if treeSize <= 0 {
+			return nil, status.Errorf(codes.OutOfRange, "empty tree")
+		} else if start >= treeSize {
+			return nil, status.Errorf(codes.OutOfRange, "invalid start %d, want < TreeSize(%d)", start, treeSize)
+		}
+		// Ensure no entries queried/returned beyond the tree.
+		if maxCount := treeSize - start; count > maxCount {
+			count = maxCount
+		}
+	}
+
+	ret := make([]*trillian.LogLeaf, 0, count)
+	for seq := start; seq < start+count; seq++ {
+		leaf, err := t.getSequenced(seq)
+		if err != nil {
+			return nil, err
+		}
+		if leaf == nil {
+			if seq < int64(t.root.TreeSize) {
+				return nil, fmt.Errorf("missing leaf %d, want leaves up to %d", seq, t.root.TreeSize)
+			}
+			break
+		}
+		ret = append(ret, leaf)
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ret []*trillian.LogLeaf
+	for _, hash := range leafHashes {
+		for _, seq := range t.data.index.lookupHash(hash) {
+			if !t.visible(seq) {
+				continue
+			}
+			leaf, err := t.getSequenced(seq)
+			if err != nil {
+				return nil, err
+			}
+			if leaf == nil {
+				return nil, fmt.Errorf("missing leaf %d with hash %x", seq, hash)
+			}
+			ret = append(ret, leaf)
+		}
+	}
+	if orderBySequence {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].LeafIndex < ret[j].LeafIndex })
+	}
+	return ret, nil
+}
+
+func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (*trillian.SignedLogRoot, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.slr == nil {
+		return nil, storage.ErrTreeNeedsInit
+	}
+
+	return t.slr, nil
+}
+
+func (t *logTreeTX) StoreSignedLogRoot(ctx context.Context, root *trillian.SignedLogRoot) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var logRoot types.LogRootV1
+	if err := logRoot.UnmarshalBinary(root.LogRoot); err != nil {
+		glog.Warningf("Failed to parse log root: %x %v", root.LogRoot, err)
+		return err
+	}
+	if got, want := int64(logRoot.Revision), t.writeRevision; got != want {
+		return status.Errorf(codes.Internal, "root.Revision: %v, want %v", got, want)
+	}
+	if t.newRoot != nil || (t.slr != nil && logRoot.TimestampNanos == t.root.TimestampNanos) {
+		return status.Errorf(codes.AlreadyExists, "root with timestamp %d already exists", logRoot.TimestampNanos)
+	}
+	t.newRoot = root
+	t.newSize = int64(logRoot.TreeSize)
+	return nil
+}
+
+func (t *logTreeTX) UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, leaf := range leaves {
+		// This should fail on insert but catch it early
+		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
+			return errors.New("sequenced leaf has incorrect hash size")
+		}
+		if t.hasLeafIndex(leaf.LeafIndex) {
+			return fmt.Errorf("leaf index %d is already sequenced", leaf.LeafIndex)
+		}
+		d, ok := t.dequeued[string(leaf.LeafIdentityHash)]
+		if !ok {
+			return fmt.Errorf("attempting to update leaf that wasn't dequeued. IdentityHash: %x", leaf.LeafIdentityHash)
+		}
+		delete(t.dequeued, string(leaf.LeafIdentityHash))
+		t.addSequenced(&trillian.LogLeaf{
+			LeafIdentityHash:   leaf.LeafIdentityHash,
+			MerkleLeafHash:     leaf.MerkleLeafHash,
+			LeafValue:          d.leaf.LeafValue,
+			ExtraData:          d.leaf.ExtraData,
+			LeafIndex:          leaf.LeafIndex,
+			QueueTimestamp:     d.leaf.QueueTimestamp,
+			IntegrateTimestamp: leaf.IntegrateTimestamp,
+		})
+	}
+	return nil
+}
+
+func (t *logTreeTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return getActiveLogIDs(ctx, t.ls.admin)
+}
+
+// queueOffset returns the offset in the queue from which the next transaction
+// should dequeue: the first leaf which this transaction read but did not
+// sequence, if any.
+func (t *logTreeTX) queueOffset() int64 {
+	offset := t.nextQueue
+	if t.held < offset {
+		offset = t.held
+	}
+	for _, d := range t.dequeued {
+		if d.offset < offset {
+			offset = d.offset
+		}
+	}
+	return offset
+}
+
+// appendTiles writes the tiles of the named tile directory which get the
+// given entries, which follow the first start entries of the row, and
+// returns the paths of the tiles they supersede.
+func (t *logTreeTX) appendTiles(name string, start int64, entries [][]byte, entrySize int) ([]string, error) {
+	var superseded []string
+	end := start + int64(len(entries))
+	for n := start / tileWidth; n*tileWidth < end && start < end; n++ {
+		first := n * tileWidth
+		var tile [][]byte
+		if oldWidth := start - first; oldWidth > 0 {
+			old, err := t.tile(name, n, oldWidth, entrySize)
+			if err != nil {
+				return nil, err
+			}
+			tile = append(tile, old...)
+			superseded = append(superseded, tilePath(name, n, int(oldWidth)))
+			first = start
+		}
+		width := partWidth(end, n)
+		tile = append(tile, entries[first-start:n*tileWidth+width-start]...)
+		if err := writeFile(t.dir, tilePath(name, n, int(width)), joinEntries(tile, entrySize)); err != nil {
+			return nil, err
+		}
+		if width == tileWidth {
+			// Remove all the partial tiles.
+			superseded = append(superseded, filepath.Dir(tilePath(name, n, 1)))
+		}
+	}
+	return superseded, nil
+}
+
+// commitInternal writes the changes made by the transaction, and commits
+// them by replacing the state of the log.
+func (t *logTreeTX) commitInternal() error {
+	state := treeState{
+		Root:        t.state.Root,
+		Leaves:      t.state.Leaves,
+		Rows:        append([]int64(nil), t.state.Rows...),
+		QueueOffset: t.queueOffset(),
+	}
+	if len(t.sequenced) == 0 && len(t.nodes) == 0 && t.newRoot == nil && state.QueueOffset == t.state.QueueOffset {
+		return nil
+	}
+	// superseded holds the files replaced by the commit, which are deleted
+	// once it is done.
+	var superseded []string
+
+	// Add the leaves which follow the entry bundles to them, and stage the
+	// others.
+	var entries [][]byte
+	for {
+		leaf, ok := t.sequenced[state.Leaves]
+		if ok {
+			delete(t.sequenced, state.Leaves)
+		} else if t.staged[state.Leaves] {
+			var err error
+			if leaf, err = readStaged(t.dir, state.Leaves); err != nil {
+				return err
+			}
+			delete(t.staged, state.Leaves)
+			superseded = append(superseded, stagedPath(state.Leaves))
+		} else {
+			break
+		}
+		v, err := proto.Marshal(leaf)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, v)
+		state.Leaves++
+	}
+	for seq, leaf := range t.sequenced {
+		v, err := proto.Marshal(leaf)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(t.dir, stagedPath(seq), v); err != nil {
+			return err
+		}
+		t.staged[seq] = true
+	}
+	for seq := range t.staged {
+		state.Staged = append(state.Staged, seq)
+	}
+	sort.Slice(state.Staged, func(i, j int) bool { return state.Staged[i] < state.Staged[j] })
+	old, err := t.appendTiles(entriesDir, t.state.Leaves, entries, 0)
+	if err != nil {
+		return err
+	}
+	superseded = append(superseded, old...)
+
+	// Add the nodes of the perfect subtrees of the new tree which are on the
+	// rows of the tiles.
+	size := int64(t.root.TreeSize)
+	if t.newRoot != nil {
+		size = t.newSize
+	}
+	for level := 0; size>>(uint(level)*tileHeight) > 0; level++ {
+		begin, end := state.row(level), size>>(uint(level)*tileHeight)
+		var hashes [][]byte
+		for i := begin; i < end; i++ {
+			hash, ok := t.nodes[compact.NewNodeID(uint(level*tileHeight), uint64(i))]
+			if !ok {
+				break
+			}
+			hashes = append(hashes, hash)
+		}
+		if len(hashes) == 0 {
+			continue
+		}
+		old, err := t.appendTiles(levelName(level), begin, hashes, t.hashSizeBytes)
+		if err != nil {
+			return err
+		}
+		superseded = append(superseded, old...)
+		for len(state.Rows) <= level {
+			state.Rows = append(state.Rows, 0)
+		}
+		state.Rows[level] = begin + int64(len(hashes))
+	}
+
+	if t.newRoot != nil {
+		if state.Root, err = proto.Marshal(t.newRoot); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(t.dir, stateName, b); err != nil {
+		return err
+	}
+
+	// The transaction has committed.
+	if t.newRoot != nil {
+		if err := writeFile(t.dir, rootName, state.Root); err != nil {
+			glog.Warningf("Failed to write checkpoint of log %d: %v", t.treeID, err)
+		}
+	}
+	for _, p := range superseded {
+		if err := os.RemoveAll(filepath.Join(t.dir, p)); err != nil {
+			glog.Warningf("Failed to remove %s of log %d: %v", p, t.treeID, err)
+		}
+	}
+	return nil
+}
+
+func (t *logTreeTX) Commit(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.writable {
+		return t.rollbackInternal()
+	}
+	defer t.rollbackInternal() // nolint: errcheck
+	if t.closed {
+		return errors.New("transaction is closed")
+	}
+	return t.commitInternal()
+}
+
+func (t *logTreeTX) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rollbackInternal()
+}
+
+func (t *logTreeTX) rollbackInternal() error {
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	t.unlock()
+	return nil
+}
+
+func (t *logTreeTX) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rollbackInternal()
+}
+
+func (t *logTreeTX) IsOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return !t.closed
+}
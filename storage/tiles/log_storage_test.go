@@ -0,0 +1,180 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/integration/storagetest"
+	"github.com/google/trillian/merkle/compact"
+	rfc6962 "github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/commitlog"
+	"github.com/google/trillian/storage/testonly"
+	stree "github.com/google/trillian/storage/tree"
+	"github.com/google/trillian/types"
+
+	_ "github.com/google/trillian/merkle/rfc6962" // Register the hasher.
+)
+
+// newTestStorage creates a LogStorage in a temporary directory, along with
+// the AdminStorage of its trees.
+func newTestStorage(t *testing.T) (string, storage.LogStorage, storage.AdminStorage) {
+	t.Helper()
+	dir := t.TempDir()
+	l, err := commitlog.NewFileLog(filepath.Join(dir, "log"), 1<<20)
+	if err != nil {
+		t.Fatalf("NewFileLog(): %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return dir, NewLogStorage(dir, l, nil), commitlog.NewAdminStorage(l)
+}
+
+func TestLogSuite(t *testing.T) {
+	storageFactory := func(_ context.Context, t *testing.T) (storage.LogStorage, storage.AdminStorage) {
+		_, ls, as := newTestStorage(t)
+		return ls, as
+	}
+
+	storagetest.RunLogStorageTests(t, storageFactory)
+}
+
+func TestTilePath(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		n     int64
+		width int
+		want  string
+	}{
+		{name: "0", n: 0, width: 256, want: "tile/0/000"},
+		{name: "1", n: 7, width: 12, want: "tile/1/007.p/12"},
+		{name: "entries", n: 1234067, width: 256, want: "tile/entries/x001/x234/067"},
+		{name: "entries", n: 1000, width: 1, want: "tile/entries/x001/000.p/1"},
+	} {
+		if got := filepath.ToSlash(tilePath(tc.name, tc.n, tc.width)); got != tc.want {
+			t.Errorf("tilePath(%q, %d, %d): %q, want %q", tc.name, tc.n, tc.width, got, tc.want)
+		}
+	}
+}
+
+// leafHash returns the Merkle leaf hash of the leaf with the given index.
+func leafHash(index int64) []byte {
+	return rfc6962.DefaultHasher.HashLeaf([]byte(strconv.FormatInt(index, 10)))
+}
+
+// grow adds leaves to a pre-ordered log up to the given size, and stores a
+// root of that size, along with the level 0 nodes of the new leaves and the
+// given other nodes.
+func grow(ctx context.Context, t *testing.T, ls storage.LogStorage, tree *trillian.Tree, from, size int64, nodes ...stree.Node) {
+	t.Helper()
+	var leaves []*trillian.LogLeaf
+	for i := from; i < size; i++ {
+		id := sha256.Sum256([]byte(strconv.FormatInt(i, 10)))
+		leaves = append(leaves, &trillian.LogLeaf{LeafIdentityHash: id[:], MerkleLeafHash: leafHash(i), LeafIndex: i})
+		nodes = append(nodes, stree.Node{ID: compact.NewNodeID(0, uint64(i)), Hash: leafHash(i)})
+	}
+	if len(leaves) > 0 {
+		if _, err := ls.AddSequencedLeaves(ctx, tree, leaves, time.Now()); err != nil {
+			t.Fatalf("AddSequencedLeaves(): %v", err)
+		}
+	}
+	if err := ls.ReadWriteTransaction(ctx, tree, func(ctx context.Context, tx storage.LogTreeTX) error {
+		if err := tx.SetMerkleNodes(ctx, nodes); err != nil {
+			return err
+		}
+		rev, err := tx.WriteRevision(ctx)
+		if err != nil {
+			return err
+		}
+		root, err := (&types.LogRootV1{TreeSize: uint64(size), RootHash: make([]byte, 32), TimestampNanos: uint64(size), Revision: uint64(rev)}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return tx.StoreSignedLogRoot(ctx, &trillian.SignedLogRoot{LogRoot: root})
+	}); err != nil {
+		t.Fatalf("ReadWriteTransaction(): %v", err)
+	}
+}
+
+func TestTiles(t *testing.T) {
+	ctx := context.Background()
+	dir, ls, as := newTestStorage(t)
+	tree, err := storage.CreateTree(ctx, as, testonly.PreorderedLogTree)
+	if err != nil {
+		t.Fatalf("CreateTree(): %v", err)
+	}
+	logDir := filepath.Join(dir, strconv.FormatInt(tree.TreeId, 10))
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(logDir, filepath.FromSlash(path)))
+		return err == nil
+	}
+
+	grow(ctx, t, ls, tree, 0, 0)
+	grow(ctx, t, ls, tree, 0, 100)
+	for _, p := range []string{"tile/0/000.p/100", "tile/entries/000.p/100", "checkpoint", "state"} {
+		if !exists(p) {
+			t.Errorf("%s is missing at size 100", p)
+		}
+	}
+
+	node8 := stree.Node{ID: compact.NewNodeID(8, 0), Hash: bytes.Repeat([]byte{8}, 32)}
+	grow(ctx, t, ls, tree, 100, 300, node8)
+	for _, p := range []string{"tile/0/000", "tile/0/001.p/44", "tile/1/000.p/1", "tile/entries/000", "tile/entries/001.p/44"} {
+		if !exists(p) {
+			t.Errorf("%s is missing at size 300", p)
+		}
+	}
+	for _, p := range []string{"tile/0/000.p", "tile/entries/000.p"} {
+		if exists(p) {
+			t.Errorf("%s is not removed at size 300", p)
+		}
+	}
+
+	tx, err := ls.SnapshotForTree(ctx, tree)
+	if err != nil {
+		t.Fatalf("SnapshotForTree(): %v", err)
+	}
+	defer tx.Close()
+	ids := []compact.NodeID{compact.NewNodeID(1, 149), node8.ID, compact.NewNodeID(2, 75), compact.NewNodeID(9, 0)}
+	nodes, err := tx.GetMerkleNodes(ctx, ids)
+	if err != nil {
+		t.Fatalf("GetMerkleNodes(): %v", err)
+	}
+	want := []stree.Node{
+		{ID: ids[0], Hash: rfc6962.DefaultHasher.HashChildren(leafHash(298), leafHash(299))},
+		node8,
+	}
+	if got := fmt.Sprint(nodes); got != fmt.Sprint(want) {
+		t.Errorf("GetMerkleNodes(): %v, want %v", got, want)
+	}
+	leaves, err := tx.GetLeavesByRange(ctx, 254, 4)
+	if err != nil {
+		t.Fatalf("GetLeavesByRange(): %v", err)
+	}
+	for i, leaf := range leaves {
+		if want := int64(254 + i); leaf.LeafIndex != want || !bytes.Equal(leaf.MerkleLeafHash, leafHash(want)) {
+			t.Errorf("GetLeavesByRange(): leaf %d is %d, want %d", i, leaf.LeafIndex, want)
+		}
+	}
+}
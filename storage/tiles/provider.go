@@ -0,0 +1,87 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiles
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/commitlog"
+)
+
+// logSegmentBytes is the size at which the segment files of the commit log
+// holding the queues and trees are rolled over.
+const logSegmentBytes = 64 << 20
+
+var (
+	tilesDir = flag.String("tiles_dir", "trillian-tiles", "Directory in which the tiles storage keeps the logs, their queues and the trees")
+
+	tilesOnce            sync.Once
+	tilesOnceErr         error
+	tilesStorageInstance *tilesProvider
+)
+
+func init() {
+	if err := storage.RegisterProviderWithOptions("tiles", newTilesProvider); err != nil {
+		glog.Fatalf("Failed to register storage provider tiles: %v", err)
+	}
+}
+
+type tilesProvider struct {
+	dir     string
+	log     *commitlog.FileLog
+	mf      monitoring.MetricFactory
+	weights storage.SubmitterWeights
+}
+
+func newTilesProvider(mf monitoring.MetricFactory, opts storage.ProviderOptions) (storage.Provider, error) {
+	tilesOnce.Do(func() {
+		if tilesOnceErr = os.MkdirAll(*tilesDir, 0755); tilesOnceErr != nil {
+			return
+		}
+		var l *commitlog.FileLog
+		l, tilesOnceErr = commitlog.NewFileLog(filepath.Join(*tilesDir, "log"), logSegmentBytes)
+		if tilesOnceErr != nil {
+			return
+		}
+		tilesStorageInstance = &tilesProvider{
+			dir:     *tilesDir,
+			log:     l,
+			mf:      mf,
+			weights: opts.SubmitterWeights,
+		}
+	})
+	if tilesOnceErr != nil {
+		return nil, tilesOnceErr
+	}
+	return tilesStorageInstance, nil
+}
+
+func (s *tilesProvider) LogStorage() storage.LogStorage {
+	return newLogStorage(s.dir, s.log, s.mf, s.weights)
+}
+
+func (s *tilesProvider) AdminStorage() storage.AdminStorage {
+	return commitlog.NewAdminStorage(s.log)
+}
+
+func (s *tilesProvider) Close() error {
+	return s.log.Close()
+}
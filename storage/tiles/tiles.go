@@ -0,0 +1,237 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tiles provides a log storage implementation which keeps each log in
+// a directory as immutable tlog-style tiles and entry bundles, which can be
+// served as static files.
+//
+// The directory of a log, named by its tree ID, holds:
+//
+//   - tile/<L>/<N> and tile/<L>/<N>.p/<W>, the full and partial tiles of the
+//     Merkle tree, as in https://c2sp.org/tlog-tiles. Tile N at level L holds
+//     the hashes of the nodes at level 8*L with indices 256*N to 256*N+W-1,
+//     concatenated. The other nodes are computed from these when read.
+//   - tile/entries/<N> and tile/entries/<N>.p/<W>, the full and partial entry
+//     bundles, in which each leaf is a marshaled trillian.LogLeaf preceded by
+//     its length as a big-endian uint32.
+//   - staged/<index>, the marshaled leaves which were sequenced above a gap in
+//     the log, as pre-ordered logs allow. They are moved into the entry
+//     bundles once the gap is filled.
+//   - state, the JSON-encoded state of the log, which lists the files above
+//     that are part of it. Replacing it commits a transaction.
+//   - checkpoint, the latest trillian.SignedLogRoot in its binary encoding.
+//   - lock, which is locked by the writer of the log, so that there is a
+//     single writer at a time, across processes.
+//
+// Full tiles and bundles are never rewritten. Partial ones are rewritten with
+// a new name as the log grows, and the ones they supersede are deleted.
+//
+// The queue of each log, and the trees, are kept in a commitlog.Log, which
+// is a FileLog in the "log" subdirectory of --tiles_dir when using the
+// provider.
+//
+// Leaves are de-duplicated through an index of the sequenced leaves which is
+// held in memory, and is built by reading the whole log when first used.
+// Duplicates which are still in the queue are skipped when dequeued.
+package tiles
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// tileHeight is the number of levels of the Merkle tree in a tile.
+	tileHeight = 8
+	// tileWidth is the number of entries of a full tile or bundle.
+	tileWidth = 1 << tileHeight
+
+	entriesDir = "entries"
+	stagedDir  = "staged"
+	stateName  = "state"
+	rootName   = "checkpoint"
+	lockName   = "lock"
+)
+
+// treeState is the content of the state file of a log.
+type treeState struct {
+	// Root is the marshaled trillian.SignedLogRoot of the log, or nil if it
+	// has none yet.
+	Root []byte `json:",omitempty"`
+	// Leaves is the number of leaves in the entry bundles, which hold the
+	// leaves with indices 0 to Leaves-1.
+	Leaves int64
+	// Staged holds the indices of the leaves in the staged directory, which
+	// are all above Leaves.
+	Staged []int64 `json:",omitempty"`
+	// Rows holds the number of hashes in the tiles of each level.
+	Rows []int64 `json:",omitempty"`
+	// QueueOffset is the offset in the queue of the log from which the next
+	// transaction dequeues leaves.
+	QueueOffset int64
+}
+
+// readState reads the state of the log in dir, which is empty if the log
+// has never been written to.
+func readState(dir string) (treeState, error) {
+	var st treeState
+	b, err := ioutil.ReadFile(filepath.Join(dir, stateName))
+	if os.IsNotExist(err) {
+		return st, nil
+	} else if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("failed to read state of %s: %v", dir, err)
+	}
+	return st, nil
+}
+
+// row returns the number of hashes in the tiles of the given level.
+func (st *treeState) row(level int) int64 {
+	if level < len(st.Rows) {
+		return st.Rows[level]
+	}
+	return 0
+}
+
+// indexPath encodes a tile index as a path, as per tlog-tiles: the index is
+// split into groups of three digits, all but the last prefixed with "x".
+func indexPath(n int64) string {
+	p := fmt.Sprintf("%03d", n%1000)
+	for n >= 1000 {
+		n /= 1000
+		p = fmt.Sprintf("x%03d/%s", n%1000, p)
+	}
+	return p
+}
+
+// tilePath returns the path, relative to the directory of a log, of the tile
+// with the given index and width in the named directory of tile, which is
+// either a level or entriesDir.
+func tilePath(name string, n int64, width int) string {
+	p := filepath.Join("tile", name, filepath.FromSlash(indexPath(n)))
+	if width < tileWidth {
+		p = filepath.Join(p+".p", strconv.Itoa(width))
+	}
+	return p
+}
+
+// levelName returns the name of the tile directory of the given level.
+func levelName(level int) string {
+	return strconv.Itoa(level)
+}
+
+// readTile reads the tile with the given index of the named tile directory,
+// and returns at least width entries of it, or fewer if there are not as
+// many. A partial tile which is missing may have been superseded by a commit
+// made since the state was read, so the full tile, or a wider partial one,
+// is read instead.
+func readTile(dir, name string, n int64, width, entrySize int) ([][]byte, error) {
+	paths := []string{tilePath(name, n, width)}
+	if width < tileWidth {
+		paths = append(paths, tilePath(name, n, tileWidth))
+		if infos, err := ioutil.ReadDir(filepath.Join(dir, filepath.Dir(paths[0]))); err == nil {
+			// The names don't sort by width, but this is only used in races.
+			for _, fi := range infos {
+				if w, err := strconv.Atoi(fi.Name()); err == nil && w > width {
+					paths = append(paths, tilePath(name, n, w))
+				}
+			}
+		}
+	}
+	var err error
+	for _, p := range paths {
+		var b []byte
+		if b, err = ioutil.ReadFile(filepath.Join(dir, p)); err == nil {
+			return splitEntries(b, entrySize)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// splitEntries splits the content of a tile into its entries, which are
+// entrySize bytes long, or prefixed by their length if entrySize is 0.
+func splitEntries(b []byte, entrySize int) ([][]byte, error) {
+	var ret [][]byte
+	for len(b) > 0 {
+		size := entrySize
+		if size == 0 {
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated entry length: %d bytes", len(b))
+			}
+			size = int(binary.BigEndian.Uint32(b))
+			b = b[4:]
+		}
+		if len(b) < size {
+			return nil, fmt.Errorf("truncated entry: %d bytes, want %d", len(b), size)
+		}
+		ret = append(ret, b[:size])
+		b = b[size:]
+	}
+	return ret, nil
+}
+
+// joinEntries is the inverse of splitEntries.
+func joinEntries(entries [][]byte, entrySize int) []byte {
+	var b []byte
+	for _, e := range entries {
+		if entrySize == 0 {
+			var hdr [4]byte
+			binary.BigEndian.PutUint32(hdr[:], uint32(len(e)))
+			b = append(b, hdr[:]...)
+		}
+		b = append(b, e...)
+	}
+	return b
+}
+
+// writeFile replaces the file at path, in dir, with data, creating its
+// directory if needed. The data is written to a temporary file which is then
+// renamed, so readers never see a partial file. The file is world-readable,
+// so that it can be served.
+func writeFile(dir, path string, data []byte) error {
+	path = filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -248,7 +248,8 @@ func Main(args Options) string {
 		ls,
 		tSigner,
 		nil,
-		quota.Noop())
+		quota.Noop(),
+		nil)
 
 	// Create the initial tree head at size 0, which is required. And then sequence the leaves.
 	sequence(tree, seq, 0, args.BatchSize)
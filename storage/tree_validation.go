@@ -124,6 +124,12 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 	} else if duration < 0 {
 		return status.Errorf(codes.InvalidArgument, "max_root_duration negative: %v", tree.MaxRootDuration)
 	}
+	if _, ok := trillian.LeafCompression_name[int32(tree.LeafCompression)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid leaf_compression: %v", tree.LeafCompression)
+	}
+	if tree.LeafCompressionDictionaryId != 0 && tree.LeafCompression != trillian.LeafCompression_ZSTD_LEAF_COMPRESSION {
+		return status.Errorf(codes.InvalidArgument, "leaf_compression_dictionary_id requires leaf_compression %v", trillian.LeafCompression_ZSTD_LEAF_COMPRESSION)
+	}
 
 	// Implementations may vary, so let's assume storage_settings is mutable.
 	// Other than checking that it's a valid Any there isn't much to do at this layer, though.
@@ -285,6 +285,27 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "validLeafCompression",
+			updatefn: func(tree *trillian.Tree) {
+				tree.LeafCompression = trillian.LeafCompression_ZSTD_LEAF_COMPRESSION
+				tree.LeafCompressionDictionaryId = 42
+			},
+		},
+		{
+			desc: "invalidLeafCompression",
+			updatefn: func(tree *trillian.Tree) {
+				tree.LeafCompression = trillian.LeafCompression(99)
+			},
+			wantErr: true,
+		},
+		{
+			desc: "dictionaryWithoutLeafCompression",
+			updatefn: func(tree *trillian.Tree) {
+				tree.LeafCompressionDictionaryId = 42
+			},
+			wantErr: true,
+		},
 		{
 			desc: "differentPrivateKeyProtoButSameKeyMaterial",
 			updatefn: func(tree *trillian.Tree) {
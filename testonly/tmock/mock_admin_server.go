@@ -80,6 +80,21 @@ func (mr *MockTrillianAdminServerMockRecorder) GetTree(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockTrillianAdminServer)(nil).GetTree), arg0, arg1)
 }
 
+// ListQuarantinedLeaves mocks base method.
+func (m *MockTrillianAdminServer) ListQuarantinedLeaves(arg0 context.Context, arg1 *trillian.ListQuarantinedLeavesRequest) (*trillian.ListQuarantinedLeavesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQuarantinedLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.ListQuarantinedLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQuarantinedLeaves indicates an expected call of ListQuarantinedLeaves.
+func (mr *MockTrillianAdminServerMockRecorder) ListQuarantinedLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQuarantinedLeaves", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListQuarantinedLeaves), arg0, arg1)
+}
+
 // ListTrees mocks base method.
 func (m *MockTrillianAdminServer) ListTrees(arg0 context.Context, arg1 *trillian.ListTreesRequest) (*trillian.ListTreesResponse, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +110,21 @@ func (mr *MockTrillianAdminServerMockRecorder) ListTrees(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrees", reflect.TypeOf((*MockTrillianAdminServer)(nil).ListTrees), arg0, arg1)
 }
 
+// RequeueQuarantinedLeaves mocks base method.
+func (m *MockTrillianAdminServer) RequeueQuarantinedLeaves(arg0 context.Context, arg1 *trillian.RequeueQuarantinedLeavesRequest) (*trillian.RequeueQuarantinedLeavesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueQuarantinedLeaves", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.RequeueQuarantinedLeavesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueQuarantinedLeaves indicates an expected call of RequeueQuarantinedLeaves.
+func (mr *MockTrillianAdminServerMockRecorder) RequeueQuarantinedLeaves(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueQuarantinedLeaves", reflect.TypeOf((*MockTrillianAdminServer)(nil).RequeueQuarantinedLeaves), arg0, arg1)
+}
+
 // UndeleteTree mocks base method.
 func (m *MockTrillianAdminServer) UndeleteTree(arg0 context.Context, arg1 *trillian.UndeleteTreeRequest) (*trillian.Tree, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +140,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetLatestSignedLogRoot(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSignedLogRoot", reflect.TypeOf((*MockTrillianLogServer)(nil).GetLatestSignedLogRoot), arg0, arg1)
 }
 
+// GetLeafIndexRangeByTime mocks base method.
+func (m *MockTrillianLogServer) GetLeafIndexRangeByTime(arg0 context.Context, arg1 *trillian.GetLeafIndexRangeByTimeRequest) (*trillian.GetLeafIndexRangeByTimeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeafIndexRangeByTime", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetLeafIndexRangeByTimeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeafIndexRangeByTime indicates an expected call of GetLeafIndexRangeByTime.
+func (mr *MockTrillianLogServerMockRecorder) GetLeafIndexRangeByTime(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeafIndexRangeByTime", reflect.TypeOf((*MockTrillianLogServer)(nil).GetLeafIndexRangeByTime), arg0, arg1)
+}
+
 // GetLeavesByHash mocks base method.
 func (m *MockTrillianLogServer) GetLeavesByHash(arg0 context.Context, arg1 *trillian.GetLeavesByHashRequest) (*trillian.GetLeavesByHashResponse, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +215,21 @@ func (mr *MockTrillianLogServerMockRecorder) GetSequencedLeafCount(arg0, arg1 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSequencedLeafCount", reflect.TypeOf((*MockTrillianLogServer)(nil).GetSequencedLeafCount), arg0, arg1)
 }
 
+// GetSignedLogRoots mocks base method.
+func (m *MockTrillianLogServer) GetSignedLogRoots(arg0 context.Context, arg1 *trillian.GetSignedLogRootsRequest) (*trillian.GetSignedLogRootsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSignedLogRoots", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.GetSignedLogRootsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSignedLogRoots indicates an expected call of GetSignedLogRoots.
+func (mr *MockTrillianLogServerMockRecorder) GetSignedLogRoots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignedLogRoots", reflect.TypeOf((*MockTrillianLogServer)(nil).GetSignedLogRoots), arg0, arg1)
+}
+
 // InitLog mocks base method.
 func (m *MockTrillianLogServer) InitLog(arg0 context.Context, arg1 *trillian.InitLogRequest) (*trillian.InitLogResponse, error) {
 	m.ctrl.T.Helper()
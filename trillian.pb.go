@@ -296,6 +296,113 @@ func (TreeType) EnumDescriptor() ([]byte, []int) {
 	return file_trillian_proto_rawDescGZIP(), []int{3}
 }
 
+// Defines the order in which leaves queued to a LOG tree are dequeued for
+// sequencing.
+type DequeueOrder int32
+
+const (
+	// Leaves are dequeued in the order in which they were queued. This is the
+	// default.
+	DequeueOrder_QUEUE_TIMESTAMP_ORDER DequeueOrder = 0
+	// Leaves are dequeued in weighted round-robin order across submitters, so
+	// that a single submitter cannot starve the others. Submitters are identified
+	// by the first ChargeTo.user of the request which queued the leaves. Leaves
+	// of each submitter are dequeued in the order in which they were queued.
+	DequeueOrder_FAIR_SHARE_ORDER DequeueOrder = 1
+)
+
+// Enum value maps for DequeueOrder.
+var (
+	DequeueOrder_name = map[int32]string{
+		0: "QUEUE_TIMESTAMP_ORDER",
+		1: "FAIR_SHARE_ORDER",
+	}
+	DequeueOrder_value = map[string]int32{
+		"QUEUE_TIMESTAMP_ORDER": 0,
+		"FAIR_SHARE_ORDER":      1,
+	}
+)
+
+func (x DequeueOrder) Enum() *DequeueOrder {
+	p := new(DequeueOrder)
+	*p = x
+	return p
+}
+
+func (x DequeueOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DequeueOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_trillian_proto_enumTypes[4].Descriptor()
+}
+
+func (DequeueOrder) Type() protoreflect.EnumType {
+	return &file_trillian_proto_enumTypes[4]
+}
+
+func (x DequeueOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DequeueOrder.Descriptor instead.
+func (DequeueOrder) EnumDescriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{4}
+}
+
+// Defines how storage compresses the leaf_value and extra_data of the leaves
+// of a LOG tree. Compression is invisible to API callers, which always see the
+// original leaf data.
+type LeafCompression int32
+
+const (
+	// Leaf data is stored as is. This is the default.
+	LeafCompression_NO_LEAF_COMPRESSION LeafCompression = 0
+	// Leaf data is compressed with zstd, optionally using a dictionary (see
+	// Tree.leaf_compression_dictionary_id). Leaves which don't get any smaller
+	// are stored as is.
+	LeafCompression_ZSTD_LEAF_COMPRESSION LeafCompression = 1
+)
+
+// Enum value maps for LeafCompression.
+var (
+	LeafCompression_name = map[int32]string{
+		0: "NO_LEAF_COMPRESSION",
+		1: "ZSTD_LEAF_COMPRESSION",
+	}
+	LeafCompression_value = map[string]int32{
+		"NO_LEAF_COMPRESSION":   0,
+		"ZSTD_LEAF_COMPRESSION": 1,
+	}
+)
+
+func (x LeafCompression) Enum() *LeafCompression {
+	p := new(LeafCompression)
+	*p = x
+	return p
+}
+
+func (x LeafCompression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LeafCompression) Descriptor() protoreflect.EnumDescriptor {
+	return file_trillian_proto_enumTypes[5].Descriptor()
+}
+
+func (LeafCompression) Type() protoreflect.EnumType {
+	return &file_trillian_proto_enumTypes[5]
+}
+
+func (x LeafCompression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LeafCompression.Descriptor instead.
+func (LeafCompression) EnumDescriptor() ([]byte, []int) {
+	return file_trillian_proto_rawDescGZIP(), []int{5}
+}
+
 // Represents a tree, which may be either a verifiable log or map.
 // Readonly attributes are assigned at tree creation, after which they may not
 // be modified.
@@ -366,6 +473,18 @@ type Tree struct {
 	// Time of tree deletion, if any.
 	// Readonly.
 	DeleteTime *timestamp.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	// Order in which queued leaves are dequeued for sequencing.
+	// Only applies to LOG trees.
+	DequeueOrder DequeueOrder `protobuf:"varint,21,opt,name=dequeue_order,json=dequeueOrder,proto3,enum=trillian.DequeueOrder" json:"dequeue_order,omitempty"`
+	// Compression of the data of newly stored leaves. Changing it doesn't affect
+	// leaves which are already stored, which stay readable.
+	// Only applies to LOG trees, and is ignored by storage which doesn't support
+	// it.
+	LeafCompression LeafCompression `protobuf:"varint,22,opt,name=leaf_compression,json=leafCompression,proto3,enum=trillian.LeafCompression" json:"leaf_compression,omitempty"`
+	// ID of the zstd dictionary used with ZSTD_LEAF_COMPRESSION, or zero for no
+	// dictionary. The dictionary must be loaded by every server which reads or
+	// writes the tree.
+	LeafCompressionDictionaryId uint32 `protobuf:"varint,23,opt,name=leaf_compression_dictionary_id,json=leafCompressionDictionaryId,proto3" json:"leaf_compression_dictionary_id,omitempty"`
 }
 
 func (x *Tree) Reset() {
@@ -512,6 +631,27 @@ func (x *Tree) GetDeleteTime() *timestamp.Timestamp {
 	return nil
 }
 
+func (x *Tree) GetDequeueOrder() DequeueOrder {
+	if x != nil {
+		return x.DequeueOrder
+	}
+	return DequeueOrder_QUEUE_TIMESTAMP_ORDER
+}
+
+func (x *Tree) GetLeafCompression() LeafCompression {
+	if x != nil {
+		return x.LeafCompression
+	}
+	return LeafCompression_NO_LEAF_COMPRESSION
+}
+
+func (x *Tree) GetLeafCompressionDictionaryId() uint32 {
+	if x != nil {
+		return x.LeafCompressionDictionaryId
+	}
+	return 0
+}
+
 // SignedLogRoot represents a commitment by a Log to a particular tree.
 type SignedLogRoot struct {
 	state         protoimpl.MessageState
@@ -764,7 +904,7 @@ var file_trillian_proto_rawDesc = []byte{
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72,
 	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x89, 0x07, 0x0a,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd1, 0x08, 0x0a,
 	0x04, 0x54, 0x72, 0x65, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x69, 0x64,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x65, 0x65, 0x49, 0x64, 0x12, 0x32,
 	0x0a, 0x0a, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01,
@@ -820,57 +960,78 @@ var file_trillian_proto_rawDesc = []byte{
 	0x6d, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
 	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65,
-	0x4a, 0x04, 0x08, 0x12, 0x10, 0x13, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x4a, 0x04, 0x08, 0x0a,
-	0x10, 0x0b, 0x4a, 0x04, 0x08, 0x0b, 0x10, 0x0c, 0x22, 0x97, 0x01, 0x0a, 0x0d, 0x53, 0x69, 0x67,
-	0x6e, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65,
-	0x79, 0x5f, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65,
-	0x79, 0x48, 0x69, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f,
-	0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74,
-	0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x73, 0x69, 0x67,
-	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x6f,
-	0x67, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04,
-	0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04,
-	0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06,
-	0x10, 0x07, 0x22, 0x72, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52,
-	0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18,
-	0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x1c,
-	0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04, 0x08, 0x01,
-	0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a, 0x04,
-	0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08,
-	0x4a, 0x04, 0x08, 0x08, 0x10, 0x09, 0x22, 0x44, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12,
-	0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x16,
-	0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06,
-	0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x2a, 0x44, 0x0a, 0x0d,
-	0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a,
-	0x17, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54,
-	0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4c, 0x4f,
-	0x47, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x56, 0x31,
-	0x10, 0x01, 0x2a, 0x97, 0x01, 0x0a, 0x0c, 0x48, 0x61, 0x73, 0x68, 0x53, 0x74, 0x72, 0x61, 0x74,
-	0x65, 0x67, 0x79, 0x12, 0x19, 0x0a, 0x15, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x48,
-	0x41, 0x53, 0x48, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47, 0x59, 0x10, 0x00, 0x12, 0x12,
-	0x0a, 0x0e, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36, 0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36,
-	0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x45, 0x53, 0x54, 0x5f, 0x4d, 0x41, 0x50, 0x5f, 0x48,
-	0x41, 0x53, 0x48, 0x45, 0x52, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x4f, 0x42, 0x4a, 0x45, 0x43,
-	0x54, 0x5f, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36, 0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36,
-	0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41,
-	0x35, 0x31, 0x32, 0x5f, 0x32, 0x35, 0x36, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4e,
-	0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x05, 0x2a, 0x8b, 0x01, 0x0a,
-	0x09, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x55, 0x4e,
-	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45,
-	0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x0a,
-	0x0a, 0x06, 0x46, 0x52, 0x4f, 0x5a, 0x45, 0x4e, 0x10, 0x02, 0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45,
-	0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x53, 0x4f, 0x46, 0x54, 0x5f, 0x44, 0x45,
-	0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x1a, 0x02, 0x08, 0x01, 0x12, 0x1f, 0x0a, 0x17, 0x44,
-	0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x48, 0x41, 0x52, 0x44, 0x5f, 0x44,
-	0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x04, 0x1a, 0x02, 0x08, 0x01, 0x12, 0x0c, 0x0a, 0x08,
-	0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x05, 0x2a, 0x47, 0x0a, 0x08, 0x54, 0x72,
-	0x65, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
-	0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x10, 0x00, 0x12, 0x07, 0x0a,
-	0x03, 0x4c, 0x4f, 0x47, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4d, 0x41, 0x50, 0x10, 0x02, 0x12,
-	0x12, 0x0a, 0x0e, 0x50, 0x52, 0x45, 0x4f, 0x52, 0x44, 0x45, 0x52, 0x45, 0x44, 0x5f, 0x4c, 0x4f,
-	0x47, 0x10, 0x03, 0x42, 0x48, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x12, 0x3b, 0x0a, 0x0d, 0x64, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6f, 0x72, 0x64, 0x65,
+	0x72, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x44, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52,
+	0x0c, 0x64, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x44, 0x0a,
+	0x10, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69,
+	0x61, 0x6e, 0x2e, 0x4c, 0x65, 0x61, 0x66, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x0f, 0x6c, 0x65, 0x61, 0x66, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x43, 0x0a, 0x1e, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x63, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x1b, 0x6c, 0x65, 0x61,
+	0x66, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x72, 0x79, 0x49, 0x64, 0x4a, 0x04, 0x08, 0x12, 0x10, 0x13, 0x4a, 0x04,
+	0x08, 0x07, 0x10, 0x08, 0x4a, 0x04, 0x08, 0x0a, 0x10, 0x0b, 0x4a, 0x04, 0x08, 0x0b, 0x10, 0x0c,
+	0x22, 0x97, 0x01, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x6f,
+	0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x48, 0x69, 0x6e, 0x74, 0x12, 0x19, 0x0a,
+	0x08, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x07, 0x6c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x6f, 0x67, 0x5f,
+	0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02,
+	0x10, 0x03, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x4a, 0x04,
+	0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x22, 0x72, 0x0a, 0x0d, 0x53, 0x69,
+	0x67, 0x6e, 0x65, 0x64, 0x4d, 0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6d,
+	0x61, 0x70, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d,
+	0x61, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03,
+	0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x4a, 0x04, 0x08, 0x06,
+	0x10, 0x07, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x4a, 0x04, 0x08, 0x08, 0x10, 0x09, 0x22, 0x44,
+	0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61,
+	0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x4a, 0x04,
+	0x08, 0x02, 0x10, 0x03, 0x2a, 0x44, 0x0a, 0x0d, 0x4c, 0x6f, 0x67, 0x52, 0x6f, 0x6f, 0x74, 0x46,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a, 0x17, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x4f, 0x4f,
+	0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e,
+	0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x5f, 0x46,
+	0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x56, 0x31, 0x10, 0x01, 0x2a, 0x97, 0x01, 0x0a, 0x0c, 0x48,
+	0x61, 0x73, 0x68, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x19, 0x0a, 0x15, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x5f, 0x53, 0x54, 0x52, 0x41,
+	0x54, 0x45, 0x47, 0x59, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36,
+	0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x45,
+	0x53, 0x54, 0x5f, 0x4d, 0x41, 0x50, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x45, 0x52, 0x10, 0x02, 0x12,
+	0x19, 0x0a, 0x15, 0x4f, 0x42, 0x4a, 0x45, 0x43, 0x54, 0x5f, 0x52, 0x46, 0x43, 0x36, 0x39, 0x36,
+	0x32, 0x5f, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f,
+	0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x5f, 0x32, 0x35, 0x36, 0x10,
+	0x04, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4e, 0x49, 0x4b, 0x53, 0x5f, 0x53, 0x48, 0x41, 0x32,
+	0x35, 0x36, 0x10, 0x05, 0x2a, 0x8b, 0x01, 0x0a, 0x09, 0x54, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52,
+	0x45, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43,
+	0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x52, 0x4f, 0x5a, 0x45, 0x4e,
+	0x10, 0x02, 0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44,
+	0x5f, 0x53, 0x4f, 0x46, 0x54, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x1a,
+	0x02, 0x08, 0x01, 0x12, 0x1f, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45,
+	0x44, 0x5f, 0x48, 0x41, 0x52, 0x44, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x04,
+	0x1a, 0x02, 0x08, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47,
+	0x10, 0x05, 0x2a, 0x47, 0x0a, 0x08, 0x54, 0x72, 0x65, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15,
+	0x0a, 0x11, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x4c, 0x4f, 0x47, 0x10, 0x01, 0x12, 0x07,
+	0x0a, 0x03, 0x4d, 0x41, 0x50, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x50, 0x52, 0x45, 0x4f, 0x52,
+	0x44, 0x45, 0x52, 0x45, 0x44, 0x5f, 0x4c, 0x4f, 0x47, 0x10, 0x03, 0x2a, 0x3f, 0x0a, 0x0c, 0x44,
+	0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x15, 0x51,
+	0x55, 0x45, 0x55, 0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x4f,
+	0x52, 0x44, 0x45, 0x52, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x46, 0x41, 0x49, 0x52, 0x5f, 0x53,
+	0x48, 0x41, 0x52, 0x45, 0x5f, 0x4f, 0x52, 0x44, 0x45, 0x52, 0x10, 0x01, 0x2a, 0x45, 0x0a, 0x0f,
+	0x4c, 0x65, 0x61, 0x66, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x17, 0x0a, 0x13, 0x4e, 0x4f, 0x5f, 0x4c, 0x45, 0x41, 0x46, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52,
+	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x5a, 0x53, 0x54, 0x44,
+	0x5f, 0x4c, 0x45, 0x41, 0x46, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f,
+	0x4e, 0x10, 0x01, 0x42, 0x48, 0x0a, 0x19, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2e, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x42, 0x0d, 0x54, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50,
 	0x01, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f,
@@ -890,42 +1051,46 @@ func file_trillian_proto_rawDescGZIP() []byte {
 	return file_trillian_proto_rawDescData
 }
 
-var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_trillian_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
 var file_trillian_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_trillian_proto_goTypes = []interface{}{
 	(LogRootFormat)(0),                       // 0: trillian.LogRootFormat
 	(HashStrategy)(0),                        // 1: trillian.HashStrategy
 	(TreeState)(0),                           // 2: trillian.TreeState
 	(TreeType)(0),                            // 3: trillian.TreeType
-	(*Tree)(nil),                             // 4: trillian.Tree
-	(*SignedLogRoot)(nil),                    // 5: trillian.SignedLogRoot
-	(*SignedMapRoot)(nil),                    // 6: trillian.SignedMapRoot
-	(*Proof)(nil),                            // 7: trillian.Proof
-	(sigpb.DigitallySigned_HashAlgorithm)(0), // 8: sigpb.DigitallySigned.HashAlgorithm
-	(sigpb.DigitallySigned_SignatureAlgorithm)(0), // 9: sigpb.DigitallySigned.SignatureAlgorithm
-	(*any.Any)(nil),             // 10: google.protobuf.Any
-	(*keyspb.PublicKey)(nil),    // 11: keyspb.PublicKey
-	(*duration.Duration)(nil),   // 12: google.protobuf.Duration
-	(*timestamp.Timestamp)(nil), // 13: google.protobuf.Timestamp
+	(DequeueOrder)(0),                        // 4: trillian.DequeueOrder
+	(LeafCompression)(0),                     // 5: trillian.LeafCompression
+	(*Tree)(nil),                             // 6: trillian.Tree
+	(*SignedLogRoot)(nil),                    // 7: trillian.SignedLogRoot
+	(*SignedMapRoot)(nil),                    // 8: trillian.SignedMapRoot
+	(*Proof)(nil),                            // 9: trillian.Proof
+	(sigpb.DigitallySigned_HashAlgorithm)(0), // 10: sigpb.DigitallySigned.HashAlgorithm
+	(sigpb.DigitallySigned_SignatureAlgorithm)(0), // 11: sigpb.DigitallySigned.SignatureAlgorithm
+	(*any.Any)(nil),             // 12: google.protobuf.Any
+	(*keyspb.PublicKey)(nil),    // 13: keyspb.PublicKey
+	(*duration.Duration)(nil),   // 14: google.protobuf.Duration
+	(*timestamp.Timestamp)(nil), // 15: google.protobuf.Timestamp
 }
 var file_trillian_proto_depIdxs = []int32{
 	2,  // 0: trillian.Tree.tree_state:type_name -> trillian.TreeState
 	3,  // 1: trillian.Tree.tree_type:type_name -> trillian.TreeType
 	1,  // 2: trillian.Tree.hash_strategy:type_name -> trillian.HashStrategy
-	8,  // 3: trillian.Tree.hash_algorithm:type_name -> sigpb.DigitallySigned.HashAlgorithm
-	9,  // 4: trillian.Tree.signature_algorithm:type_name -> sigpb.DigitallySigned.SignatureAlgorithm
-	10, // 5: trillian.Tree.private_key:type_name -> google.protobuf.Any
-	10, // 6: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
-	11, // 7: trillian.Tree.public_key:type_name -> keyspb.PublicKey
-	12, // 8: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
-	13, // 9: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
-	13, // 10: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
-	13, // 11: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
-	12, // [12:12] is the sub-list for method output_type
-	12, // [12:12] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	10, // 3: trillian.Tree.hash_algorithm:type_name -> sigpb.DigitallySigned.HashAlgorithm
+	11, // 4: trillian.Tree.signature_algorithm:type_name -> sigpb.DigitallySigned.SignatureAlgorithm
+	12, // 5: trillian.Tree.private_key:type_name -> google.protobuf.Any
+	12, // 6: trillian.Tree.storage_settings:type_name -> google.protobuf.Any
+	13, // 7: trillian.Tree.public_key:type_name -> keyspb.PublicKey
+	14, // 8: trillian.Tree.max_root_duration:type_name -> google.protobuf.Duration
+	15, // 9: trillian.Tree.create_time:type_name -> google.protobuf.Timestamp
+	15, // 10: trillian.Tree.update_time:type_name -> google.protobuf.Timestamp
+	15, // 11: trillian.Tree.delete_time:type_name -> google.protobuf.Timestamp
+	4,  // 12: trillian.Tree.dequeue_order:type_name -> trillian.DequeueOrder
+	5,  // 13: trillian.Tree.leaf_compression:type_name -> trillian.LeafCompression
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_trillian_proto_init() }
@@ -988,7 +1153,7 @@ func file_trillian_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_proto_rawDesc,
-			NumEnums:      4,
+			NumEnums:      6,
 			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election
+
+import (
+	"sort"
+
+	"github.com/google/trillian/util/election2"
+)
+
+// ToResign returns the IDs of the resources that the instance with the given
+// ID should resign mastership of, in order to even out the loads of all the
+// instances. Nothing is returned unless the instance carries more than
+// (1+tolerance) times its fair share of the total load, and the instance never
+// resigns so much that it would drop below its fair share. The heaviest
+// resources are picked first, so as to move as few of them as possible.
+//
+// The pinned resources, e.g. those which the instance acquired recently, count
+// towards its load but are never returned, so that resources don't keep
+// moving back and forth between instances.
+func ToResign(self string, loads []election2.Load, tolerance float64, pinned map[string]bool) []string {
+	if len(loads) < 2 {
+		return nil
+	}
+	var total float64
+	var mine election2.Load
+	for _, l := range loads {
+		total += l.Total()
+		if l.InstanceID == self {
+			mine = l
+		}
+	}
+	fair := total / float64(len(loads))
+	load := mine.Total()
+	if load <= fair*(1+tolerance) {
+		return nil
+	}
+
+	ids := make([]string, 0, len(mine.Held))
+	for id := range mine.Held {
+		if !pinned[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if wi, wj := mine.Held[ids[i]], mine.Held[ids[j]]; wi != wj {
+			return wi > wj
+		}
+		return ids[i] < ids[j]
+	})
+
+	var ret []string
+	for _, id := range ids {
+		if w := mine.Held[id]; load-w >= fair {
+			load -= w
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/util/election"
+	"github.com/google/trillian/util/election2"
+)
+
+func TestToResign(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		loads     []election2.Load
+		tolerance float64
+		pinned    map[string]bool
+		want      []string
+	}{
+		{desc: "no-loads"},
+		{
+			desc:  "alone",
+			loads: []election2.Load{{InstanceID: "self", Held: map[string]float64{"1": 10, "2": 10}}},
+		},
+		{
+			desc: "balanced",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 1}},
+				{InstanceID: "other", Held: map[string]float64{"3": 1, "4": 1}},
+			},
+		},
+		{
+			desc: "underloaded",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1}},
+				{InstanceID: "other", Held: map[string]float64{"2": 1, "3": 1, "4": 1}},
+			},
+		},
+		{
+			desc: "overloaded",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 1, "3": 1, "4": 1}},
+				{InstanceID: "other"},
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			desc: "within-tolerance",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 1, "3": 1}},
+				{InstanceID: "other", Held: map[string]float64{"4": 1}},
+			},
+			tolerance: 0.5,
+		},
+		{
+			desc: "beyond-tolerance",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 1, "3": 1}},
+				{InstanceID: "other", Held: map[string]float64{"4": 1}},
+			},
+			tolerance: 0.2,
+			want:      []string{"1"},
+		},
+		{
+			desc: "heaviest-first",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 8, "3": 3, "4": 2}},
+				{InstanceID: "other1", Held: map[string]float64{"5": 1}},
+				{InstanceID: "other2", Held: map[string]float64{"6": 1}},
+			},
+			want: []string{"2"},
+		},
+		{
+			desc: "skip-overshooting",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 8, "3": 3, "4": 2}},
+				{InstanceID: "other1", Held: map[string]float64{"5": 10}},
+				{InstanceID: "other2"},
+			},
+			// Fair share is 8, so resigning "2" would overshoot.
+			want: []string{"3", "4", "1"},
+		},
+		{
+			desc: "pinned",
+			loads: []election2.Load{
+				{InstanceID: "self", Held: map[string]float64{"1": 1, "2": 8, "3": 3, "4": 2}},
+				{InstanceID: "other1", Held: map[string]float64{"5": 1}},
+				{InstanceID: "other2", Held: map[string]float64{"6": 1}},
+			},
+			// The pinned resource still counts towards the load.
+			pinned: map[string]bool{"2": true},
+			want:   []string{"3", "4", "1"},
+		},
+		{
+			desc: "not-listed",
+			loads: []election2.Load{
+				{InstanceID: "other1", Held: map[string]float64{"1": 1}},
+				{InstanceID: "other2"},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := election.ToResign("self", tc.loads, tc.tolerance, tc.pinned)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("ToResign() diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -80,6 +80,7 @@ type Runner struct {
 	cfg      *RunnerConfig
 	tracker  *MasterTracker
 	election election2.Election
+	resign   chan struct{}
 }
 
 // NewRunner builds a new election Runner instance with the given config. On
@@ -93,6 +94,17 @@ func NewRunner(id string, cfg *RunnerConfig, tracker *MasterTracker, cancel cont
 		cfg:      cfg,
 		tracker:  tracker,
 		election: el,
+		resign:   make(chan struct{}, 1),
+	}
+}
+
+// RequestResign asks the runner to resign mastership early, e.g. in order to
+// let a less loaded instance take over. The request is ignored if the runner
+// is not the master at the time.
+func (er *Runner) RequestResign() {
+	select {
+	case er.resign <- struct{}{}:
+	default: // A request is already pending.
 	}
 }
 
@@ -131,6 +143,12 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 	er.tracker.Set(er.id, true)
 	defer er.tracker.Set(er.id, false)
 
+	// Drop any resignation request made while not being the master.
+	select {
+	case <-er.resign:
+	default:
+	}
+
 	mctx, err := er.election.WithMastership(ctx)
 	if err != nil {
 		return fmt.Errorf("election.WithMastership() failed: %v", err)
@@ -146,18 +164,26 @@ func (er *Runner) beMaster(ctx context.Context, pending chan<- Resignation) erro
 
 	case <-timer.Chan():
 		glog.Infof("%s: queue up resignation of mastership", er.id)
-		done := make(chan struct{})
-		r := Resignation{ID: er.id, er: er, done: done}
-		select {
-		case pending <- r:
-			<-done // Block until acted on.
-		default:
-			glog.Warning("Dropping resignation because operation manager seems to be exiting")
-		}
+		er.queueResignation(pending)
+
+	case <-er.resign:
+		glog.Infof("%s: queue up requested resignation of mastership", er.id)
+		er.queueResignation(pending)
 	}
 	return nil
 }
 
+func (er *Runner) queueResignation(pending chan<- Resignation) {
+	done := make(chan struct{})
+	r := Resignation{ID: er.id, er: er, done: done}
+	select {
+	case pending <- r:
+		<-done // Block until acted on.
+	default:
+		glog.Warning("Dropping resignation because operation manager seems to be exiting")
+	}
+}
+
 // Resignation indicates that a master should explicitly resign mastership, and
 // call the Execute() method as soon as no master-related activity is ongoing.
 type Resignation struct {
@@ -74,12 +74,14 @@ func TestElectionRunnerRun(t *testing.T) {
 		wantMaster bool
 		loseMaster bool
 		resign     bool
+		requested  bool
 	}{
 		// Basic cases.
 		{desc: "not-master"},
 		{desc: "is-master", isMaster: true, wantMaster: true},
 		{desc: "lose-master", isMaster: true, wantMaster: true, loseMaster: true},
 		{desc: "resign", isMaster: true, wantMaster: true, resign: true},
+		{desc: "request-resign", isMaster: true, wantMaster: true, resign: true, requested: true},
 		// Error cases.
 		{desc: "err-await", errs: to.Errs{Await: errors.New("ErrAwait")}},
 		{desc: "err-mctx", errs: to.Errs{WithMastership: errors.New("ErrMastership")}},
@@ -140,8 +142,12 @@ func TestElectionRunnerRun(t *testing.T) {
 
 			if tc.resign {
 				d.BlockAwait(true)
-				// Advance fake time so that resignation triggers too, if still master.
-				ts.Set(start.Add(24 * 60 * time.Hour))
+				if tc.requested {
+					er.RequestResign()
+				} else {
+					// Advance fake time so that resignation triggers too, if still master.
+					ts.Set(start.Add(24 * 60 * time.Hour))
+				}
 				time.Sleep(100 * time.Millisecond)
 				for len(resignations) > 0 {
 					r := <-resignations
@@ -85,3 +85,38 @@ type Election interface {
 type Factory interface {
 	NewElection(ctx context.Context, resourceID string) (Election, error)
 }
+
+// Load describes the resources that an instance is the master for, as shared
+// with the other instances in order to balance mastership between them.
+type Load struct {
+	// InstanceID identifies the instance.
+	InstanceID string
+	// Held maps the IDs of the resources that the instance is the master for to
+	// their application-defined weights.
+	Held map[string]float64
+}
+
+// Total returns the total weight of the held resources.
+func (l Load) Total() float64 {
+	var total float64
+	for _, w := range l.Held {
+		total += w
+	}
+	return total
+}
+
+// LoadBoard allows instances to share their Load with each other through the
+// election backend. It is optionally implemented by Factory implementations.
+type LoadBoard interface {
+	// InstanceID returns the ID under which this instance publishes its load.
+	InstanceID() string
+
+	// PublishLoad makes the held resources of this instance visible to the
+	// other instances, replacing what was previously published. The published
+	// load is removed once the instance stops participating in the election.
+	PublishLoad(ctx context.Context, held map[string]float64) error
+
+	// Loads returns the latest published Load of every live instance,
+	// including this one.
+	Loads(ctx context.Context) ([]Load, error)
+}
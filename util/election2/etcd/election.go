@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/util/election2"
@@ -130,6 +131,9 @@ type Factory struct {
 	client     *clientv3.Client
 	instanceID string
 	lockDir    string
+
+	mu          sync.Mutex
+	loadSession *concurrency.Session // Keeps the published load alive.
 }
 
 // NewFactory builds an election factory that uses the given parameters. The
@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/util/election2"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// loadDir is the sub-directory of the lock directory which holds the published
+// loads, keyed by instance ID. It can't clash with the election lock files,
+// which are keyed by resource ID.
+const loadDir = "_load"
+
+var _ election2.LoadBoard = (*Factory)(nil)
+
+func (f *Factory) loadPrefix() string {
+	return fmt.Sprintf("%s/%s/", strings.TrimRight(f.lockDir, "/"), loadDir)
+}
+
+// session returns the session which the published load is attached to, so
+// that it disappears when this instance goes away.
+func (f *Factory) session() (*concurrency.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loadSession != nil {
+		select {
+		case <-f.loadSession.Done():
+			glog.Warningf("%s: load session expired, re-creating", f.instanceID)
+		default:
+			return f.loadSession, nil
+		}
+	}
+	session, err := concurrency.NewSession(f.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd session: %v", err)
+	}
+	f.loadSession = session
+	return session, nil
+}
+
+// InstanceID returns the ID of this instance.
+func (f *Factory) InstanceID() string {
+	return f.instanceID
+}
+
+// PublishLoad stores the load of this instance under the lock directory.
+func (f *Factory) PublishLoad(ctx context.Context, held map[string]float64) error {
+	session, err := f.session()
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(election2.Load{InstanceID: f.instanceID, Held: held})
+	if err != nil {
+		return err
+	}
+	_, err = f.client.Put(ctx, f.loadPrefix()+f.instanceID, string(value), clientv3.WithLease(session.Lease()))
+	return err
+}
+
+// Loads returns the loads published by all the live instances.
+func (f *Factory) Loads(ctx context.Context) ([]election2.Load, error) {
+	resp, err := f.client.Get(ctx, f.loadPrefix(), clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	loads := make([]election2.Load, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var load election2.Load
+		if err := json.Unmarshal(kv.Value, &load); err != nil {
+			return nil, fmt.Errorf("failed to parse load at %q: %v", kv.Key, err)
+		}
+		loads = append(loads, load)
+	}
+	return loads, nil
+}
+
+// Close stops publishing the load of this instance.
+func (f *Factory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loadSession == nil {
+		return nil
+	}
+	err := f.loadSession.Close()
+	f.loadSession = nil
+	return err
+}
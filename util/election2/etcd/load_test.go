@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian/testonly/integration/etcd"
+	"github.com/google/trillian/util/election2"
+)
+
+func TestLoads(t *testing.T) {
+	_, client, cleanup, err := etcd.StartEtcd()
+	if err != nil {
+		t.Fatalf("StartEtcd(): %v", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	fact1 := NewFactory("serv1", client, "res/")
+	fact2 := NewFactory("serv2", client, "res")
+
+	if err := fact1.PublishLoad(ctx, map[string]float64{"10": 1, "20": 5}); err != nil {
+		t.Fatalf("PublishLoad(serv1): %v", err)
+	}
+	if err := fact2.PublishLoad(ctx, map[string]float64{"30": 2}); err != nil {
+		t.Fatalf("PublishLoad(serv2): %v", err)
+	}
+	// Republishing replaces the previous load.
+	if err := fact1.PublishLoad(ctx, map[string]float64{"10": 3}); err != nil {
+		t.Fatalf("PublishLoad(serv1): %v", err)
+	}
+
+	check := func(want []election2.Load) {
+		t.Helper()
+		got, err := fact2.Loads(ctx)
+		if err != nil {
+			t.Fatalf("Loads(): %v", err)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].InstanceID < got[j].InstanceID })
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("Loads() diff (-got +want):\n%s", diff)
+		}
+	}
+	check([]election2.Load{
+		{InstanceID: "serv1", Held: map[string]float64{"10": 3}},
+		{InstanceID: "serv2", Held: map[string]float64{"30": 2}},
+	})
+
+	// The load goes away with the instance.
+	if err := fact1.Close(); err != nil {
+		t.Fatalf("Close(serv1): %v", err)
+	}
+	check([]election2.Load{
+		{InstanceID: "serv2", Held: map[string]float64{"30": 2}},
+	})
+}